@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+
+	"github.com/joho/godotenv"
+	"github.com/taipm/go-llm-agent/pkg/builtin"
+	"github.com/taipm/go-llm-agent/pkg/logger"
+	"github.com/taipm/go-llm-agent/pkg/mcp"
+	"github.com/taipm/go-llm-agent/pkg/tools"
+)
+
+func main() {
+	// Load .env file if exists
+	_ = godotenv.Load()
+
+	transport := flag.String("transport", "stdio", "stdio or http")
+	addr := flag.String("addr", ":8090", "listen address for the http transport")
+	allowUnsafe := flag.Bool("allow-unsafe", false, "also serve tools that modify state (file_write, file_delete, mongodb_*, ...)")
+	categories := flag.String("categories", "", "comma-separated tool categories to serve (default: all)")
+	flag.Parse()
+
+	// Logs go to stderr: stdout carries the protocol over stdio
+	log.SetOutput(os.Stderr)
+	consoleLogger := logger.NewConsoleLogger()
+	consoleLogger.SetOutput(os.Stderr)
+
+	config := mcp.ServerConfig{
+		Registry:    builtin.GetRegistryWithConfig(builtin.DefaultConfig()),
+		Info:        mcp.Implementation{Name: "go-llm-agent-builtin", Version: "1.0.0"},
+		AllowUnsafe: *allowUnsafe,
+		Logger:      consoleLogger,
+	}
+	if *categories != "" {
+		allowed := strings.Split(*categories, ",")
+		config.Filter = func(t tools.Tool) bool {
+			return slices.Contains(allowed, string(t.Category()))
+		}
+	}
+
+	// Comma-separated bearer tokens for the http transport
+	if keys := os.Getenv("MCP_API_KEYS"); keys != "" {
+		config.APIKeys = strings.Split(keys, ",")
+	}
+
+	server, err := mcp.NewServer(config)
+	if err != nil {
+		log.Fatalf("Failed to create MCP server: %v", err)
+	}
+
+	switch *transport {
+	case "stdio":
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		log.Printf("Serving %d tools over stdio", len(server.Tools()))
+		if err := server.ServeStdio(ctx, os.Stdin, os.Stdout); err != nil && ctx.Err() == nil {
+			log.Fatalf("MCP server failed: %v", err)
+		}
+	case "http":
+		if len(config.APIKeys) == 0 {
+			log.Println("⚠️  MCP_API_KEYS not set - authentication disabled")
+		}
+		log.Fatal(server.ListenAndServe(*addr))
+	default:
+		log.Fatalf("Unknown transport %q (use stdio or http)", *transport)
+	}
+}
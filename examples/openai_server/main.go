@@ -0,0 +1,68 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"github.com/taipm/go-llm-agent/pkg/agent"
+	"github.com/taipm/go-llm-agent/pkg/builtin"
+	"github.com/taipm/go-llm-agent/pkg/logger"
+	"github.com/taipm/go-llm-agent/pkg/provider"
+	"github.com/taipm/go-llm-agent/pkg/server"
+)
+
+func main() {
+	// Load .env file if exists
+	_ = godotenv.Load()
+
+	addr := flag.String("addr", "127.0.0.1:8080", "listen address (use :8080 to accept remote clients)")
+	mode := flag.String("mode", "agent", "agent (tools run server-side), provider (tool-call passthrough) or both")
+	model := flag.String("model", "go-llm-agent", "model ID reported by /v1/models")
+	ttl := flag.Duration("session-ttl", 30*time.Minute, "unload agent sessions idle for this long")
+	insecure := flag.Bool("insecure", false, "serve without authentication when SERVER_API_KEYS is not set")
+	flag.Parse()
+
+	llm, err := provider.FromEnv()
+	if err != nil {
+		log.Fatalf("Failed to create provider: %v", err)
+	}
+
+	config := server.Config{
+		Models: []string{*model},
+		Logger: logger.NewConsoleLogger(),
+	}
+
+	// Comma-separated bearer tokens; -insecure is required to run without them
+	if keys := os.Getenv("SERVER_API_KEYS"); keys != "" {
+		config.APIKeys = strings.Split(keys, ",")
+	} else if *insecure {
+		log.Println("⚠️  SERVER_API_KEYS not set - authentication disabled (-insecure)")
+	} else {
+		log.Fatal("SERVER_API_KEYS is not set; set it or pass -insecure to serve without authentication")
+	}
+
+	if *mode == "agent" || *mode == "both" {
+		// Clients drive the agent's tools, so only side-effect-free tools are
+		// registered: no file writes, deletes or system commands
+		ag := agent.New(llm, agent.WithLogLevel(logger.LogLevelInfo), agent.WithoutBuiltinTools())
+		for _, tool := range append(builtin.GetMathTools(), builtin.GetDateTimeTools()...) {
+			if err := ag.AddTool(tool); err != nil {
+				log.Fatalf("Failed to add tool %s: %v", tool.Name(), err)
+			}
+		}
+		config.Sessions = agent.NewSessionManager(ag, agent.SessionConfig{TTL: *ttl})
+	}
+	if *mode == "provider" || *mode == "both" {
+		config.Provider = llm
+	}
+
+	srv, err := server.New(config)
+	if err != nil {
+		log.Fatalf("Failed to create server: %v", err)
+	}
+	log.Fatal(srv.ListenAndServe(*addr))
+}
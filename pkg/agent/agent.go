@@ -30,15 +30,30 @@ type Agent struct {
 	cotAgent   *reasoning.CoTAgent
 	reflector  *reasoning.Reflector
 	planner    *reasoning.Planner
+	planStore  reasoning.PlanStore  // Checkpoints for resumable plans (optional)
+	verifiers  []reasoning.Verifier // Extra verifiers for the reflector
+
+	selfConsistency *reasoning.SelfConsistencyConfig // CoT self-consistency sampling (nil = single chain)
 
 	// Learning system (lazy initialized)
-	experienceStore *learning.ExperienceStore
-	toolSelector    *learning.ToolSelector
-	errorAnalyzer   *learning.ErrorAnalyzer
-	conversationID  string // Current session ID
+	experienceStore   *learning.ExperienceStore
+	experienceBackend learning.ExperienceBackend // Structured experience storage (nil = in memory)
+	toolSelector      *learning.ToolSelector
+	errorAnalyzer     *learning.ErrorAnalyzer
+	conversationID    string // Current session ID
 
 	// Auto-reasoning settings
 	enableAutoReasoning bool
+
+	// Human-in-the-loop approval for unsafe tools
+	approvalHandler ApprovalHandler
+	approvalPolicy  ApprovalPolicy
+
+	// Token usage, cost and budgets across all provider calls
+	usage *usageTracker
+
+	// Context window management (nil = send the full history)
+	contextManager *ContextManager
 }
 
 // Options contains configuration for the agent
@@ -50,6 +65,9 @@ type Options struct {
 	MinConfidence    float64 // Minimum confidence for reflection (0.0 = disabled)
 	EnableReflection bool    // Enable self-reflection verification
 	EnableLearning   bool    // Enable experience tracking and learning
+
+	MaxParallelTools int           // Maximum tool calls executed concurrently per turn (1 = sequential)
+	ToolTimeout      time.Duration // Timeout for each tool call (0 = no timeout)
 }
 
 // DefaultOptions returns default agent options
@@ -62,6 +80,8 @@ func DefaultOptions() *Options {
 		MinConfidence:    0.7,  // Default: require 70% confidence
 		EnableReflection: true, // Enable reflection by default
 		EnableLearning:   true, // Enable learning by default
+		MaxParallelTools: 4,
+		ToolTimeout:      0,
 	}
 }
 
@@ -75,8 +95,11 @@ func New(provider types.LLMProvider, opts ...Option) *Agent {
 	// Fallback to BufferMemory if Qdrant not available
 	defaultMemory := tryCreateVectorMemory(defaultLogger)
 
+	usage := newUsageTracker()
+
 	agent := &Agent{
-		provider:            provider,
+		provider:            &meteredProvider{next: provider, tracker: usage},
+		usage:               usage,
 		tools:               tools.NewRegistry(),
 		memory:              defaultMemory,
 		options:             DefaultOptions(),
@@ -98,30 +121,48 @@ func New(provider types.LLMProvider, opts ...Option) *Agent {
 	return agent
 }
 
-// tryCreateVectorMemory attempts to create VectorMemory with default settings
-// Falls back to BufferMemory if Qdrant is not available
+// tryCreateVectorMemory attempts to create VectorMemory with default settings.
+// Without Qdrant it falls back to the in-process LocalVectorMemory when the
+// Ollama embedder is reachable, and to BufferMemory otherwise.
 func tryCreateVectorMemory(log logger.Logger) types.Memory {
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 	defer cancel()
 
+	embedder := memory.NewOllamaEmbedder("", "") // Default Ollama embedder
+
 	// Try to create VectorMemory with default Qdrant settings
 	vectorMem, err := memory.NewVectorMemory(ctx, memory.VectorMemoryConfig{
-		QdrantURL:      "localhost:6334",                 // Default Qdrant address
-		CollectionName: "agent_memory",                   // Default collection name
-		Embedder:       memory.NewOllamaEmbedder("", ""), // Default Ollama embedder
+		QdrantURL:      "localhost:6334", // Default Qdrant address
+		CollectionName: "agent_memory",   // Default collection name
+		Embedder:       embedder,
 		CacheSize:      100,
 	})
-
-	if err != nil {
-		// Qdrant not available, use BufferMemory instead
-		log.Info("ℹ️  Using BufferMemory (Qdrant not available)")
-		log.Info("💡 For full learning with semantic search, start Qdrant:")
-		log.Info("   docker run -p 6334:6334 -p 6333:6333 qdrant/qdrant")
-		return memory.NewBuffer(100)
+	if err == nil {
+		log.Info("✅ VectorMemory initialized - full learning enabled")
+		return vectorMem
+	}
+
+	// Qdrant not available, keep semantic memory in process if we can embed
+	probeCtx, probeCancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer probeCancel()
+	if _, err := embedder.Embed(probeCtx, "ping"); err == nil {
+		localMem, err := memory.NewLocalVectorMemory(memory.LocalVectorMemoryConfig{
+			Embedder:    embedder,
+			Index:       memory.IndexHNSW,
+			HistorySize: 100, // Same history as the VectorMemory cache and BufferMemory
+			MaxRecords:  10000,
+		})
+		if err == nil {
+			log.Info("✅ LocalVectorMemory initialized (in-process index, Qdrant not required)")
+			return localMem
+		}
 	}
 
-	log.Info("✅ VectorMemory initialized - full learning enabled")
-	return vectorMem
+	log.Info("ℹ️  Using BufferMemory (Qdrant and Ollama embeddings not available)")
+	log.Info("💡 For full learning with semantic search, start Qdrant or pull an embedding model:")
+	log.Info("   docker run -p 6334:6334 -p 6333:6333 qdrant/qdrant")
+	log.Info("   ollama pull nomic-embed-text")
+	return memory.NewBuffer(100)
 }
 
 // Option is a function that configures the agent
@@ -194,7 +235,7 @@ func WithoutAutoReasoning() Option {
 func WithoutBuiltinTools() Option {
 	return func(a *Agent) {
 		// Clear the tools registry
-		a.tools = tools.NewRegistry()
+		a.tools.Clear()
 	}
 }
 
@@ -212,6 +253,68 @@ func WithMinConfidence(threshold float64) Option {
 	}
 }
 
+// WithVerifiers registers verifiers used by self-reflection in addition to
+// the built-in fact, calculation and consistency checks
+func WithVerifiers(verifiers ...reasoning.Verifier) Option {
+	return func(a *Agent) {
+		a.verifiers = append(a.verifiers, verifiers...)
+	}
+}
+
+// WithSelfConsistency makes Chain-of-Thought reasoning sample several chains
+// and answer by majority vote (nil: a single chain). With EnableReflection,
+// reflection then only runs when the chains disagree.
+func WithSelfConsistency(config *reasoning.SelfConsistencyConfig) Option {
+	return func(a *Agent) {
+		a.selfConsistency = config
+	}
+}
+
+// WithMaxParallelTools sets how many tool calls from one assistant turn may run concurrently
+// Use 1 to execute tool calls sequentially
+func WithMaxParallelTools(n int) Option {
+	return func(a *Agent) {
+		if n < 1 {
+			n = 1
+		}
+		a.options.MaxParallelTools = n
+	}
+}
+
+// WithToolTimeout sets the timeout applied to each tool call
+func WithToolTimeout(timeout time.Duration) Option {
+	return func(a *Agent) {
+		a.options.ToolTimeout = timeout
+	}
+}
+
+// WithToolValidation sets how tool arguments from the model are validated
+// against each tool's schema before the tool runs (default:
+// tools.DefaultValidationOptions). Pass nil to disable validation.
+func WithToolValidation(opts *tools.ValidationOptions) Option {
+	return func(a *Agent) {
+		a.tools.SetValidation(opts)
+	}
+}
+
+// WithPlanStore checkpoints plans created and executed by the agent to
+// store, so that interrupted plans can be continued with ResumePlan
+func WithPlanStore(store reasoning.PlanStore) Option {
+	return func(a *Agent) {
+		a.planStore = store
+	}
+}
+
+// WithExperienceBackend sets where learning stores experiences for
+// structured queries, tool statistics and error analysis (default: in
+// memory, lost on restart). Use learning.SQLiteExperienceBackend to keep the
+// experience history across restarts.
+func WithExperienceBackend(backend learning.ExperienceBackend) Option {
+	return func(a *Agent) {
+		a.experienceBackend = backend
+	}
+}
+
 // WithLearning enables experience tracking and learning
 // Note: Requires AdvancedMemory (e.g., VectorMemory) to work properly
 // If using BufferMemory, learning will log a warning but continue to work with limited functionality
@@ -299,6 +402,9 @@ type AgentStatus struct {
 	Provider struct {
 		Type string `json:"type"`
 	} `json:"provider"`
+
+	// Usage (tokens and cost)
+	Usage Usage `json:"usage"`
 }
 
 // Status returns comprehensive agent configuration and runtime state
@@ -356,8 +462,8 @@ func (a *Agent) Status() *AgentStatus {
 		// Get experiences for analysis (non-blocking, best effort)
 		ctx := context.Background()
 		experiences, err := a.experienceStore.Query(ctx, learning.ExperienceFilters{
-			Query: a.conversationID,
-			Limit: 1000,
+			ConversationID: a.conversationID,
+			Limit:          1000,
 		})
 		if err == nil {
 			status.Learning.TotalExperiences = len(experiences)
@@ -423,10 +529,11 @@ func (a *Agent) Status() *AgentStatus {
 
 			// Recent improvements (compare recent vs older experiences)
 			if status.Learning.TotalExperiences >= 10 {
+				// Experiences are returned newest first
 				recentCount := min(10, len(experiences))
 				recentSuccesses := 0
-				for i := len(experiences) - recentCount; i < len(experiences); i++ {
-					if experiences[i].Success {
+				for _, exp := range experiences[:recentCount] {
+					if exp.Success {
 						recentSuccesses++
 					}
 				}
@@ -444,6 +551,9 @@ func (a *Agent) Status() *AgentStatus {
 	// Provider type (detect based on type assertion)
 	status.Provider.Type = a.getProviderType()
 
+	// Usage
+	status.Usage = a.usage.snapshot()
+
 	return status
 }
 
@@ -457,16 +567,19 @@ func (a *Agent) initExperienceStore() {
 	if advMem, ok := a.memory.(types.AdvancedMemory); ok {
 		a.experienceStore = learning.NewExperienceStore(advMem)
 		a.logger.Info("✅ Experience store ready (using VectorMemory)")
-
-		// Also initialize tool selector
-		a.initToolSelector()
 	} else {
-		// Running in limited mode with BufferMemory
-		a.logger.Info("ℹ️  Learning running in limited mode (BufferMemory)")
+		// Structured queries work without vector memory; similarity falls back to keywords
+		a.experienceStore = learning.NewExperienceStore(nil)
+		a.logger.Info("ℹ️  Experience store ready without semantic search (BufferMemory)")
 		a.logger.Info("   For full learning with semantic search:")
 		a.logger.Info("   docker run -p 6334:6334 -p 6333:6333 qdrant/qdrant")
-		// Don't fail - agent still works, just without advanced learning features
 	}
+	if a.experienceBackend != nil {
+		a.experienceStore.WithBackend(a.experienceBackend)
+	}
+
+	// Also initialize tool selector
+	a.initToolSelector()
 }
 
 // initToolSelector initializes the tool selector for learned tool recommendations
@@ -520,6 +633,7 @@ func (a *Agent) recordExperience(ctx context.Context, query string, response str
 	// Add error details if failed
 	if err != nil {
 		exp.Error = err.Error()
+		exp.ErrorType = learning.ClassifyError(err)
 	}
 
 	// Extract intent and reasoning mode from metadata
@@ -547,6 +661,9 @@ func (a *Agent) recordExperience(ctx context.Context, query string, response str
 	if corrected, ok := metadata["was_corrected"].(bool); ok {
 		exp.WasCorrected = corrected
 	}
+	if tokens, ok := metadata["tokens_used"].(int); ok {
+		exp.TokensUsed = tokens
+	}
 
 	// Record experience (async, don't block)
 	go func() {
@@ -600,6 +717,10 @@ func (a *Agent) getMemoryType() string {
 		return "buffer"
 	case *memory.VectorMemory:
 		return "vector"
+	case *memory.LocalVectorMemory:
+		return "local_vector"
+	case *memory.SQLiteMemory:
+		return "sqlite"
 	default:
 		if _, ok := a.memory.(types.AdvancedMemory); ok {
 			return "advanced"
@@ -610,7 +731,11 @@ func (a *Agent) getMemoryType() string {
 
 // getProviderType returns a human-readable provider type
 func (a *Agent) getProviderType() string {
-	providerType := fmt.Sprintf("%T", a.provider)
+	provider := a.provider
+	if metered, ok := provider.(*meteredProvider); ok {
+		provider = metered.next
+	}
+	providerType := fmt.Sprintf("%T", provider)
 
 	// Extract simple name from full package path
 	// e.g., "*ollama.Provider" -> "ollama"
@@ -636,12 +761,16 @@ func (a *Agent) Chat(ctx context.Context, message string) (string, error) {
 	// Track learning progress (experiences before)
 	expCountBefore := 0
 	if a.experienceStore != nil {
-		allExp, _ := a.experienceStore.Query(ctx, learning.ExperienceFilters{})
-		expCountBefore = len(allExp)
+		expCountBefore, _ = a.experienceStore.Count(ctx, learning.ExperienceFilters{})
 	}
 
-	// Track start time for latency
+	// Track start time for latency and usage for this turn
 	startTime := time.Now()
+	turnStart := a.usage.currentTurn()
+	if !inTurn(ctx) {
+		a.usage.beginTurn()
+		turnStart = TokenUsage{}
+	}
 
 	// Log user message
 	logger.LogUserMessage(a.logger, message)
@@ -674,8 +803,11 @@ func (a *Agent) Chat(ctx context.Context, message string) (string, error) {
 		response, err = a.chatSimple(ctx, message)
 	}
 
-	// Calculate latency
+	// Calculate latency and usage
 	metadata["latency_ms"] = time.Since(startTime).Milliseconds()
+	turnUsage := a.usage.currentTurn()
+	metadata["tokens_used"] = turnUsage.TotalTokens - turnStart.TotalTokens
+	metadata["cost"] = turnUsage.Cost - turnStart.Cost
 
 	// Extract tool usage from conversation history (if any)
 	if a.memory != nil {
@@ -690,8 +822,7 @@ func (a *Agent) Chat(ctx context.Context, message string) (string, error) {
 
 	// Log learning progress (experiences after)
 	if a.experienceStore != nil {
-		allExpAfter, _ := a.experienceStore.Query(ctx, learning.ExperienceFilters{})
-		expCountAfter := len(allExpAfter)
+		expCountAfter, _ := a.experienceStore.Count(ctx, learning.ExperienceFilters{})
 		learned := expCountAfter > expCountBefore
 		logger.LogLearningProgress(a.logger, expCountBefore, expCountAfter, learned)
 	}
@@ -729,6 +860,28 @@ func (a *Agent) extractLastToolUsage() map[string]interface{} {
 
 // chatSimple performs simple LLM chat with tool calling (original behavior)
 func (a *Agent) chatSimple(ctx context.Context, message string) (string, error) {
+	messages, chatOpts, err := a.prepareConversation(message)
+	if err != nil {
+		return "", err
+	}
+
+	// Run agent loop with tool calling
+	response, err := a.runLoop(ctx, messages, chatOpts)
+	if err != nil {
+		a.logger.Error("Agent execution failed: %v", err)
+		return "", err
+	}
+
+	// Log final response
+	logger.LogResponse(a.logger, response)
+
+	// Note: runLoop already saves the final response to memory
+	return response, nil
+}
+
+// prepareConversation saves the user message to memory and returns the
+// conversation history together with the chat options for the agent loop
+func (a *Agent) prepareConversation(message string) ([]types.Message, *types.ChatOptions, error) {
 	// Add user message to memory if available
 	userMsg := types.Message{
 		Role:    types.RoleUser,
@@ -737,7 +890,7 @@ func (a *Agent) chatSimple(ctx context.Context, message string) (string, error)
 
 	if a.memory != nil {
 		if err := a.memory.Add(userMsg); err != nil {
-			return "", fmt.Errorf("failed to add message to memory: %w", err)
+			return nil, nil, fmt.Errorf("failed to add message to memory: %w", err)
 		}
 		a.logger.Debug("💾 Saved user message to memory")
 	}
@@ -747,7 +900,7 @@ func (a *Agent) chatSimple(ctx context.Context, message string) (string, error)
 	if a.memory != nil {
 		history, err := a.memory.GetHistory(0) // Get all
 		if err != nil {
-			return "", fmt.Errorf("failed to get history: %w", err)
+			return nil, nil, fmt.Errorf("failed to get history: %w", err)
 		}
 		messages = history
 		a.logger.Debug("💾 Retrieved %d messages from memory", len(messages))
@@ -767,18 +920,26 @@ func (a *Agent) chatSimple(ctx context.Context, message string) (string, error)
 		chatOpts.Tools = a.tools.ToToolDefinitions()
 	}
 
-	// Run agent loop with tool calling
-	response, err := a.runLoop(ctx, messages, chatOpts)
-	if err != nil {
-		a.logger.Error("Agent execution failed: %v", err)
-		return "", err
+	return messages, chatOpts, nil
+}
+
+// fitContext trims the conversation to the context window before an LLM call
+func (a *Agent) fitContext(ctx context.Context, messages []types.Message, opts *types.ChatOptions) ([]types.Message, *types.ChatOptions) {
+	if a.contextManager == nil {
+		return messages, opts
 	}
 
-	// Log final response
-	logger.LogResponse(a.logger, response)
+	window, err := a.contextManager.Fit(ctx, opts, messages)
+	if err != nil {
+		a.logger.Warn("⚠️  %v, dropping older messages instead", err)
+	}
+	if window.Dropped > 0 {
+		a.logger.Debug("✂️  Context window: kept %d of %d messages (~%d tokens)", len(window.Messages), len(messages), window.Tokens)
+	}
 
-	// Note: runLoop already saves the final response to memory
-	return response, nil
+	windowOpts := *opts
+	windowOpts.SystemPrompt = window.SystemPrompt
+	return window.Messages, &windowOpts
 }
 
 // runLoop executes the agent loop with tool calling
@@ -793,7 +954,8 @@ func (a *Agent) runLoop(ctx context.Context, messages []types.Message, opts *typ
 		logger.LogThinking(a.logger)
 
 		// Call LLM
-		response, err := a.provider.Chat(ctx, currentMessages, opts)
+		window, windowOpts := a.fitContext(ctx, currentMessages, opts)
+		response, err := a.provider.Chat(ctx, window, windowOpts)
 		if err != nil {
 			return "", fmt.Errorf("LLM call failed: %w", err)
 		}
@@ -836,52 +998,12 @@ func (a *Agent) runLoop(ctx context.Context, messages []types.Message, opts *typ
 		// Log tool calls
 		a.logger.Info("🔧 Agent wants to call %d tool(s): %s", len(response.ToolCalls), logger.FormatToolCalls(response.ToolCalls))
 
-		// Execute tool calls
-		assistantMsg := types.Message{
-			Role:      types.RoleAssistant,
-			Content:   response.Content,
-			ToolCalls: response.ToolCalls,
-		}
-		currentMessages = append(currentMessages, assistantMsg)
-
-		// Save assistant message to memory
-		if a.memory != nil {
-			if err := a.memory.Add(assistantMsg); err != nil {
-				return "", fmt.Errorf("failed to add assistant message to memory: %w", err)
-			}
-			a.logger.Debug("💾 Saved assistant message with %d tool calls to memory", len(response.ToolCalls))
-		}
-
-		// Execute each tool
-		for _, toolCall := range response.ToolCalls {
-			// Log tool call
-			logger.LogToolCall(a.logger, toolCall.Function.Name, toolCall.Function.Arguments)
+		// Execute tools (concurrently where allowed); results come back in call order
+		results := a.executeToolCalls(ctx, response.ToolCalls)
 
-			result, err := a.tools.Execute(ctx, toolCall.Function.Name, toolCall.Function.Arguments)
-			if err != nil {
-				// Return error as tool result
-				result = map[string]interface{}{
-					"error": err.Error(),
-				}
-				logger.LogToolResult(a.logger, toolCall.Function.Name, false, err)
-			} else {
-				logger.LogToolResult(a.logger, toolCall.Function.Name, true, result)
-			}
-
-			// Add tool result to messages
-			toolMsg := types.Message{
-				Role:    types.RoleTool,
-				Content: fmt.Sprintf("%v", result),
-				ToolID:  toolCall.ID,
-			}
-			currentMessages = append(currentMessages, toolMsg)
-
-			// Save tool result to memory
-			if a.memory != nil {
-				if err := a.memory.Add(toolMsg); err != nil {
-					return "", fmt.Errorf("failed to add tool message to memory: %w", err)
-				}
-			}
+		currentMessages, err = a.recordToolRound(currentMessages, response.Content, results)
+		if err != nil {
+			return "", err
 		}
 
 		if a.memory != nil {
@@ -894,145 +1016,65 @@ func (a *Agent) runLoop(ctx context.Context, messages []types.Message, opts *typ
 	return "", fmt.Errorf("max iterations (%d) reached", a.options.MaxIterations)
 }
 
-// Reset clears the conversation history
-func (a *Agent) Reset() error {
-	if a.memory != nil {
-		return a.memory.Clear()
-	}
-	return nil
-}
-
-// GetHistory returns the conversation history
-func (a *Agent) GetHistory() ([]types.Message, error) {
-	if a.memory == nil {
-		return []types.Message{}, nil
-	}
-	return a.memory.GetHistory(0)
-}
-
-// ChatStream sends a message and streams the response via callback
-func (a *Agent) ChatStream(ctx context.Context, message string, handler types.StreamHandler) error {
-	// Add user message to memory if available
-	userMsg := types.Message{
-		Role:    types.RoleUser,
-		Content: message,
+// recordToolRound appends the assistant message with the executed tool calls
+// and the tool results to the conversation and saves them to memory
+func (a *Agent) recordToolRound(messages []types.Message, content string, results []toolCallResult) ([]types.Message, error) {
+	// Record the calls as executed (arguments may have been edited during approval)
+	executedCalls := make([]types.ToolCall, len(results))
+	for i, res := range results {
+		executedCalls[i] = res.call
 	}
 
-	if a.memory != nil {
-		if err := a.memory.Add(userMsg); err != nil {
-			return fmt.Errorf("failed to add message to memory: %w", err)
-		}
+	assistantMsg := types.Message{
+		Role:      types.RoleAssistant,
+		Content:   content,
+		ToolCalls: executedCalls,
 	}
+	messages = append(messages, assistantMsg)
 
-	// Get conversation history
-	var messages []types.Message
+	// Save assistant message to memory
 	if a.memory != nil {
-		history, err := a.memory.GetHistory(0)
-		if err != nil {
-			return fmt.Errorf("failed to get history: %w", err)
-		}
-		messages = history
-	} else {
-		messages = []types.Message{userMsg}
-	}
-
-	// Prepare chat options
-	chatOpts := &types.ChatOptions{
-		SystemPrompt: a.options.SystemPrompt,
-		Temperature:  a.options.Temperature,
-		MaxTokens:    a.options.MaxTokens,
-	}
-
-	// Add tools if available
-	if a.tools.Count() > 0 {
-		chatOpts.Tools = a.tools.ToToolDefinitions()
-	}
-
-	// Accumulate full response for memory
-	var fullResponse string
-	var toolCalls []types.ToolCall
-
-	// Wrap handler to accumulate response
-	wrappedHandler := func(chunk types.StreamChunk) error {
-		// Accumulate content
-		fullResponse += chunk.Content
-
-		// Store tool calls from final chunk
-		if chunk.Done && len(chunk.ToolCalls) > 0 {
-			toolCalls = chunk.ToolCalls
+		if err := a.memory.Add(assistantMsg); err != nil {
+			return nil, fmt.Errorf("failed to add assistant message to memory: %w", err)
 		}
-
-		// Call user's handler
-		return handler(chunk)
+		a.logger.Debug("💾 Saved assistant message with %d tool calls to memory", len(executedCalls))
 	}
 
-	// Stream response
-	if err := a.provider.Stream(ctx, messages, chatOpts, wrappedHandler); err != nil {
-		return fmt.Errorf("streaming failed: %w", err)
-	}
+	for _, res := range results {
+		// Add tool result to messages
+		toolMsg := res.message()
+		messages = append(messages, toolMsg)
 
-	// Handle tool calls if present
-	if len(toolCalls) > 0 {
-		// Add assistant message with tool calls to memory
+		// Save tool result to memory
 		if a.memory != nil {
-			assistantMsg := types.Message{
-				Role:      types.RoleAssistant,
-				Content:   fullResponse,
-				ToolCalls: toolCalls,
-			}
-			if err := a.memory.Add(assistantMsg); err != nil {
-				return fmt.Errorf("failed to add assistant message to memory: %w", err)
-			}
-		}
-
-		// Execute tools and continue (non-streaming for now, can enhance later)
-		// This is a simplified version - for full streaming with tools,
-		// we'd need a more complex loop
-		for _, tc := range toolCalls {
-			result, err := a.tools.Execute(ctx, tc.Function.Name, tc.Function.Arguments)
-			if err != nil {
-				result = map[string]interface{}{"error": err.Error()}
-			}
-
-			toolMsg := types.Message{
-				Role:    types.RoleTool,
-				Content: fmt.Sprintf("%v", result),
-				ToolID:  tc.ID,
-			}
-
-			if a.memory != nil {
-				a.memory.Add(toolMsg)
+			if err := a.memory.Add(toolMsg); err != nil {
+				return nil, fmt.Errorf("failed to add tool message to memory: %w", err)
 			}
 		}
+	}
 
-		// Get final response after tool execution (non-streaming)
-		finalResp, err := a.Chat(ctx, "")
-		if err != nil {
-			return err
-		}
+	return messages, nil
+}
 
-		// Send final response as a single chunk
-		finalChunk := types.StreamChunk{
-			Content: finalResp,
-			Done:    true,
-		}
-		return handler(finalChunk)
+// Reset clears the conversation history
+func (a *Agent) Reset() error {
+	if a.contextManager != nil {
+		a.contextManager.Reset()
 	}
-
-	// Add assistant response to memory
 	if a.memory != nil {
-		assistantMsg := types.Message{
-			Role:    types.RoleAssistant,
-			Content: fullResponse,
-		}
-		if err := a.memory.Add(assistantMsg); err != nil {
-			return fmt.Errorf("failed to add response to memory: %w", err)
-		}
+		return a.memory.Clear()
 	}
-
 	return nil
 }
 
+// GetHistory returns the conversation history
+func (a *Agent) GetHistory() ([]types.Message, error) {
+	if a.memory == nil {
+		return []types.Message{}, nil
+	}
+	return a.memory.GetHistory(0)
+}
+
 // analyzeQuery determines which reasoning approach to use
 func (a *Agent) analyzeQuery(query string) string {
 	queryLower := strings.ToLower(query)
@@ -1199,12 +1241,13 @@ func (a *Agent) chatWithCoT(ctx context.Context, message string) (string, error)
 		a.cotAgent = reasoning.NewCoTAgent(a.provider, a.memory, 10)
 		a.cotAgent.WithLogger(a.logger)
 		// Provide all available tools to CoT
-		allTools := a.tools.All()
+		allTools := a.reasoningTools()
 		a.cotAgent.WithTools(allTools...)
+		a.cotAgent.WithSelfConsistency(a.selfConsistency)
 	}
 
 	// Think through the problem
-	answer, err := a.cotAgent.Think(ctx, message)
+	answer, err := a.cotAgent.Think(withUsageSource(ctx, UsageSourceCoT), message)
 	if err != nil {
 		a.logger.Warn("⚠️  CoT reasoning failed, falling back to simple chat: %v", err)
 		return a.chatSimple(ctx, message)
@@ -1215,9 +1258,15 @@ func (a *Agent) chatWithCoT(ctx context.Context, message string) (string, error)
 		a.cotAgent.SaveToMemory(ctx)
 	}
 
-	// Apply reflection if enabled
+	// Apply reflection if enabled; with self-consistency only when the
+	// sampled chains disagree
 	if a.options.EnableReflection {
-		answer = a.applyReflection(ctx, message, answer)
+		if a.selfConsistency == nil {
+			answer = a.applyReflection(ctx, message, answer)
+		} else if a.cotAgent.LowAgreement() {
+			a.logger.Info("🗳️  Low agreement (%.2f), verifying with reflection", a.cotAgent.GetChain().Confidence)
+			answer = a.applyReflection(ctx, message, answer)
+		}
 	}
 
 	return answer, nil
@@ -1229,16 +1278,17 @@ func (a *Agent) chatWithReAct(ctx context.Context, message string) (string, erro
 
 	// Lazy initialize ReAct agent
 	if a.reactAgent == nil {
-		allTools := a.tools.All()
+		allTools := a.reasoningTools()
 		a.reactAgent = reasoning.NewReActAgent(a.provider, a.memory, a.options.MaxIterations)
 		a.reactAgent.WithLogger(a.logger)
 		a.reactAgent.WithTools(allTools...)
 	}
+	a.reactAgent.ClearSteps()
 
 	// Run ReAct loop
 	var finalAnswer string
 	for i := 0; i < a.options.MaxIterations; i++ {
-		step, err := a.reactAgent.Think(ctx, message)
+		step, err := a.reactAgent.Think(withUsageSource(ctx, UsageSourceReAct), message)
 		if err != nil {
 			a.logger.Warn("⚠️  ReAct iteration %d failed: %v", i+1, err)
 			return "", fmt.Errorf("ReAct reasoning failed: %w", err)
@@ -1273,17 +1323,8 @@ func (a *Agent) chatWithReAct(ctx context.Context, message string) (string, erro
 
 // applyReflection performs self-reflection on an answer and returns the final (possibly corrected) answer
 func (a *Agent) applyReflection(ctx context.Context, question string, initialAnswer string) string {
-	// Lazy initialize reflector
-	if a.reflector == nil {
-		a.reflector = reasoning.NewReflector(a.provider, a.memory)
-		a.reflector.WithLogger(a.logger)
-		// Add tools for verification
-		allTools := a.tools.All()
-		a.reflector.WithTools(allTools...)
-	}
-
 	// Perform reflection
-	reflection, err := a.reflector.Reflect(ctx, question, initialAnswer)
+	reflection, err := a.getReflector().Reflect(withUsageSource(ctx, UsageSourceReflection), question, initialAnswer)
 	if err != nil {
 		a.logger.Warn("⚠️  Reflection failed: %v, using initial answer", err)
 		return initialAnswer
@@ -1315,6 +1356,18 @@ func (a *Agent) applyReflection(ctx context.Context, question string, initialAns
 	return reflection.FinalAnswer
 }
 
+// getReflector lazily creates the reflector with the agent's tools and verifiers
+func (a *Agent) getReflector() *reasoning.Reflector {
+	if a.reflector == nil {
+		a.reflector = reasoning.NewReflector(a.provider, a.memory)
+		a.reflector.WithLogger(a.logger)
+		// Add tools for verification (web_search, math_calculate, etc.)
+		a.reflector.WithTools(a.reasoningTools()...)
+		a.reflector.WithVerifiers(a.verifiers...)
+	}
+	return a.reflector
+}
+
 // ChatWithReflection performs chat with self-reflection and verification
 // Returns the reflection check for analysis
 // NOTE: For normal usage, just use Chat() with EnableReflection=true
@@ -1327,17 +1380,8 @@ func (a *Agent) ChatWithReflection(ctx context.Context, message string, minConfi
 		return nil, fmt.Errorf("failed to get initial answer: %w", err)
 	}
 
-	// Step 2: Lazy initialize reflector
-	if a.reflector == nil {
-		a.reflector = reasoning.NewReflector(a.provider, a.memory)
-		a.reflector.WithLogger(a.logger)
-		// Add tools for verification (web_search, math_calculate, etc.)
-		allTools := a.tools.All()
-		a.reflector.WithTools(allTools...)
-	}
-
-	// Step 3: Perform reflection
-	reflection, err := a.reflector.Reflect(ctx, message, initialAnswer)
+	// Step 2: Perform reflection
+	reflection, err := a.getReflector().Reflect(withUsageSource(ctx, UsageSourceReflection), message, initialAnswer)
 	if err != nil {
 		a.logger.Warn("⚠️  Reflection failed: %v, returning initial answer", err)
 		// Return reflection with initial answer even if reflection failed
@@ -1349,7 +1393,7 @@ func (a *Agent) ChatWithReflection(ctx context.Context, message string, minConfi
 		}, nil
 	}
 
-	// Step 4: Check if confidence meets threshold
+	// Step 3: Check if confidence meets threshold
 	if reflection.Confidence < minConfidence {
 		a.logger.Warn("⚠️  Confidence (%.2f) below threshold (%.2f)", reflection.Confidence, minConfidence)
 		if !reflection.WasCorrected {
@@ -1359,7 +1403,7 @@ func (a *Agent) ChatWithReflection(ctx context.Context, message string, minConfi
 		a.logger.Info("✅ Confidence (%.2f) meets threshold", reflection.Confidence)
 	}
 
-	// Step 5: Update memory with final answer if it was corrected
+	// Step 4: Update memory with final answer if it was corrected
 	if reflection.WasCorrected && a.memory != nil {
 		// The initial answer was already saved by Chat()
 		// Now we need to update or add a correction note
@@ -1391,52 +1435,97 @@ func (a *Agent) WithMinConfidence(minConfidence float64) *Agent {
 func (a *Agent) Plan(ctx context.Context, goal string) (*types.Plan, error) {
 	a.logger.Info("📋 Creating plan for goal: %s", goal)
 
-	// Lazy initialize planner
-	if a.planner == nil {
-		a.planner = reasoning.NewPlanner(a.provider, a.memory, a.logger, true)
-	}
-
 	// Decompose goal into plan
-	plan, err := a.planner.DecomposeGoal(ctx, goal)
+	plan, err := a.getPlanner().DecomposeGoal(withUsageSource(ctx, UsageSourcePlanner), goal)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create plan: %w", err)
 	}
 
 	// Store plan in memory
-	if err := a.planner.SaveToMemory(ctx, plan); err != nil {
+	if err := a.getPlanner().SaveToMemory(ctx, plan); err != nil {
 		a.logger.Warn("⚠️  Failed to save plan to memory: %v", err)
 	}
 
 	return plan, nil
 }
 
-// ExecutePlan executes a plan by running each step through the agent
+// ExecutePlan executes a plan by running each step through the agent. Steps
+// share the agent's conversation, so they run one at a time in plan order;
+// use ExecutePlanWithConfig for parallel steps, retries and replanning.
 func (a *Agent) ExecutePlan(ctx context.Context, plan *types.Plan) error {
+	config := reasoning.DefaultExecutionConfig()
+	config.MaxParallel = 1
+	return a.ExecutePlanWithConfig(ctx, plan, config)
+}
+
+// ExecutePlanWithConfig executes a plan through the agent using config. With
+// MaxParallel 1 the steps run in the agent's conversation. Otherwise each
+// step runs in its own copy of the agent with a fresh memory (like a
+// session), so parallel steps see only the results of their dependencies
+// and do not add to the agent's history. The whole execution is one turn
+// for the per-turn budget.
+func (a *Agent) ExecutePlanWithConfig(ctx context.Context, plan *types.Plan, config reasoning.ExecutionConfig) error {
 	if plan == nil {
 		return fmt.Errorf("plan is nil")
 	}
 
-	// Lazy initialize planner
-	if a.planner == nil {
-		a.planner = reasoning.NewPlanner(a.provider, a.memory, a.logger, true)
+	executor := a.planStepExecutor
+	if config.MaxParallel != 1 {
+		// Copies share the learning stores, so create them up front
+		if a.options.EnableLearning {
+			a.initExperienceStore()
+		}
+		executor = a.isolatedPlanStepExecutor
 	}
 
-	// Execute plan with agent as executor
-	executor := func(ctx context.Context, task string) (interface{}, error) {
-		// Use agent.Chat to execute each step
-		result, err := a.Chat(ctx, task)
-		return result, err
+	a.usage.beginTurn()
+	ctx = withinTurn(withUsageSource(ctx, UsageSourcePlanner))
+	return a.getPlanner().ExecutePlanWithConfig(ctx, plan, executor, config)
+}
+
+// ResumePlan continues a plan checkpointed to the store set with
+// WithPlanStore. Completed steps are skipped; the others run one at a time
+// in a single turn.
+func (a *Agent) ResumePlan(ctx context.Context, planID string) (*types.Plan, error) {
+	if a.planStore == nil {
+		return nil, fmt.Errorf("plan store not configured (use WithPlanStore)")
+	}
+	config := reasoning.DefaultExecutionConfig()
+	config.MaxParallel = 1
+	a.usage.beginTurn()
+	ctx = withinTurn(withUsageSource(ctx, UsageSourcePlanner))
+	return a.getPlanner().ResumePlan(ctx, planID, a.planStepExecutor, config)
+}
+
+// planStepExecutor runs a plan step through Chat, including the results of
+// the step's dependencies in the message
+func (a *Agent) planStepExecutor(ctx context.Context, task string) (interface{}, error) {
+	if input, ok := reasoning.StepInputFromContext(ctx); ok {
+		task = input.Prompt()
 	}
+	// Steps are billed as chat; decomposition and replanning as planner
+	return a.Chat(withUsageSource(ctx, UsageSourceChat), task)
+}
 
-	return a.planner.ExecutePlan(ctx, plan, executor)
+// isolatedPlanStepExecutor runs a plan step in a copy of the agent, so that
+// steps can run concurrently. The copy keeps the conversation ID and records
+// usage to the agent.
+func (a *Agent) isolatedPlanStepExecutor(ctx context.Context, task string) (interface{}, error) {
+	step := a.clone(a.conversationID, memory.NewBuffer(100), a.usage)
+	return step.planStepExecutor(ctx, task)
 }
 
-// GetPlanProgress returns the execution progress of a plan
-func (a *Agent) GetPlanProgress(plan *types.Plan) *types.PlanProgress {
+// getPlanner lazily initializes the planner
+func (a *Agent) getPlanner() *reasoning.Planner {
 	if a.planner == nil {
-		a.planner = reasoning.NewPlanner(a.provider, a.memory, a.logger, true)
+		a.planner = reasoning.NewPlanner(a.provider, a.memory, a.logger, true).WithStore(a.planStore)
 	}
-	return a.planner.GetProgress(plan)
+	return a.planner
+}
+
+// GetPlanProgress returns the execution progress of a plan
+func (a *Agent) GetPlanProgress(plan *types.Plan) *types.PlanProgress {
+	return a.getPlanner().GetProgress(plan)
 }
 
 // GetToolRecommendation returns a learned recommendation for which tool to use
@@ -1490,8 +1579,8 @@ func (a *Agent) GetLearningReport(ctx context.Context) (*LearningReport, error)
 
 	// Get experiences to analyze
 	experiences, err := a.experienceStore.Query(ctx, learning.ExperienceFilters{
-		Query: a.conversationID,
-		Limit: 1000,
+		ConversationID: a.conversationID,
+		Limit:          1000,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to query experiences: %w", err)
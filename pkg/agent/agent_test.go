@@ -9,6 +9,7 @@ import (
 
 	"github.com/taipm/go-llm-agent/pkg/memory"
 	"github.com/taipm/go-llm-agent/pkg/provider/ollama"
+	"github.com/taipm/go-llm-agent/pkg/tools"
 	"github.com/taipm/go-llm-agent/pkg/types"
 )
 
@@ -63,6 +64,18 @@ func (t *TestTool) Execute(ctx context.Context, params map[string]interface{}) (
 	return fmt.Sprintf("Test tool received: %s", msg), nil
 }
 
+func (t *TestTool) Category() tools.ToolCategory {
+	return tools.CategoryData
+}
+
+func (t *TestTool) RequiresAuth() bool {
+	return false
+}
+
+func (t *TestTool) IsSafe() bool {
+	return true
+}
+
 func TestNewAgent(t *testing.T) {
 	provider := ollama.New(testBaseURL, testModel)
 	ag := New(provider)
@@ -99,13 +112,13 @@ func TestAddTool(t *testing.T) {
 	ag.AddTool(testTool)
 
 	// Verify tool was added
-	tools := ag.tools.GetDefinitions()
-	if len(tools) != 1 {
-		t.Errorf("Expected 1 tool, got %d", len(tools))
+	defs := ag.tools.ToToolDefinitions()
+	if len(defs) != 1 {
+		t.Fatalf("Expected 1 tool, got %d", len(defs))
 	}
 
-	if tools[0].Function.Name != "test_tool" {
-		t.Errorf("Expected tool name 'test_tool', got %s", tools[0].Function.Name)
+	if defs[0].Function.Name != "test_tool" {
+		t.Errorf("Expected tool name 'test_tool', got %s", defs[0].Function.Name)
 	}
 }
 
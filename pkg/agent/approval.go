@@ -0,0 +1,198 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/taipm/go-llm-agent/pkg/tools"
+	"github.com/taipm/go-llm-agent/pkg/types"
+)
+
+// ApprovalAction is the outcome of an approval request
+type ApprovalAction string
+
+const (
+	// ActionApprove lets the tool call run unchanged
+	ActionApprove ApprovalAction = "approve"
+	// ActionDeny blocks the tool call; the reason is fed back to the model
+	ActionDeny ApprovalAction = "deny"
+	// ActionEdit runs the tool call with replacement arguments
+	ActionEdit ApprovalAction = "edit"
+)
+
+// Decision is returned by an ApprovalHandler
+type Decision struct {
+	Action    ApprovalAction
+	Reason    string                 // Why the call was denied (sent to the model)
+	Arguments map[string]interface{} // Replacement arguments for ActionEdit
+}
+
+// Approve returns a decision that lets the tool call run
+func Approve() Decision {
+	return Decision{Action: ActionApprove}
+}
+
+// Deny returns a decision that blocks the tool call with a reason for the model
+func Deny(reason string) Decision {
+	return Decision{Action: ActionDeny, Reason: reason}
+}
+
+// EditArguments returns a decision that runs the tool call with new arguments
+func EditArguments(args map[string]interface{}) Decision {
+	return Decision{Action: ActionEdit, Arguments: args}
+}
+
+// ApprovalHandler is asked before an unsafe or auth-requiring tool runs.
+// Returning an error blocks the call and reports the error to the model.
+type ApprovalHandler func(ctx context.Context, call types.ToolCall) (Decision, error)
+
+// ApprovalPolicy auto-approves or auto-denies tool calls without asking the handler.
+// Deny rules take precedence over approve rules.
+type ApprovalPolicy struct {
+	AutoApprove           []string             // Tool names that never need approval
+	AutoApproveCategories []tools.ToolCategory // Categories that never need approval
+	AutoDeny              []string             // Tool names that are always blocked
+	AutoDenyCategories    []tools.ToolCategory // Categories that are always blocked
+	AlwaysAsk             []string             // Tool names that need approval even if safe
+}
+
+// WithApprovalHandler sets the handler asked before unsafe or auth-requiring tools run
+func WithApprovalHandler(handler ApprovalHandler) Option {
+	return func(a *Agent) {
+		a.approvalHandler = handler
+	}
+}
+
+// WithApprovalPolicy sets rules for auto-approving or auto-denying tool calls
+func WithApprovalPolicy(policy ApprovalPolicy) Option {
+	return func(a *Agent) {
+		a.approvalPolicy = policy
+	}
+}
+
+// ToolDeniedError is returned as the tool result when a call is blocked
+type ToolDeniedError struct {
+	Tool   string
+	Reason string
+}
+
+func (e *ToolDeniedError) Error() string {
+	if e.Reason == "" {
+		return fmt.Sprintf("tool call %s was denied", e.Tool)
+	}
+	return fmt.Sprintf("tool call %s was denied: %s", e.Tool, e.Reason)
+}
+
+// needsApproval reports whether a tool must go through the approval handler
+func (p ApprovalPolicy) needsApproval(tool tools.Tool) bool {
+	if contains(p.AlwaysAsk, tool.Name()) {
+		return true
+	}
+	if contains(p.AutoApprove, tool.Name()) || containsCategory(p.AutoApproveCategories, tool.Category()) {
+		return false
+	}
+	return tools.NeedsConfirmation(tool)
+}
+
+// denies reports whether the policy blocks a tool outright
+func (p ApprovalPolicy) denies(tool tools.Tool) bool {
+	return contains(p.AutoDeny, tool.Name()) || containsCategory(p.AutoDenyCategories, tool.Category())
+}
+
+// reviewToolCall applies the approval policy and handler to a tool call.
+// Returns the call to execute (arguments may have been edited) or a
+// *ToolDeniedError if the call must not run.
+func (a *Agent) reviewToolCall(ctx context.Context, call types.ToolCall) (types.ToolCall, error) {
+	tool := a.tools.Get(call.Function.Name)
+	if tool == nil {
+		// Unknown tool: the registry reports the error
+		return call, nil
+	}
+
+	if a.approvalPolicy.denies(tool) {
+		a.logger.Warn("🚫 Tool %s blocked by approval policy", call.Function.Name)
+		return call, &ToolDeniedError{Tool: call.Function.Name, Reason: "blocked by policy"}
+	}
+
+	if a.approvalHandler == nil || !a.approvalPolicy.needsApproval(tool) {
+		return call, nil
+	}
+
+	a.logger.Info("✋ Requesting approval for tool %s", call.Function.Name)
+	decision, err := a.approvalHandler(ctx, call)
+	if err != nil {
+		return call, &ToolDeniedError{Tool: call.Function.Name, Reason: fmt.Sprintf("approval failed: %v", err)}
+	}
+
+	switch decision.Action {
+	case ActionApprove:
+		a.logger.Debug("✅ Tool %s approved", call.Function.Name)
+		return call, nil
+
+	case ActionEdit:
+		a.logger.Info("✏️  Tool %s approved with edited arguments", call.Function.Name)
+		call.Function.Arguments = decision.Arguments
+		return call, nil
+
+	case ActionDeny:
+		a.logger.Warn("🚫 Tool %s denied: %s", call.Function.Name, decision.Reason)
+		return call, &ToolDeniedError{Tool: call.Function.Name, Reason: decision.Reason}
+
+	default:
+		return call, &ToolDeniedError{Tool: call.Function.Name, Reason: fmt.Sprintf("unknown approval action %q", decision.Action)}
+	}
+}
+
+// approvalGatedTool runs reviewToolCall before delegating to the wrapped tool.
+// Used for tools handed to the reasoning engines, which execute tools themselves.
+type approvalGatedTool struct {
+	tools.Tool
+	agent *Agent
+}
+
+// Execute implements tools.Tool
+func (t *approvalGatedTool) Execute(ctx context.Context, params map[string]interface{}) (interface{}, error) {
+	call := types.ToolCall{
+		Type: "function",
+		Function: types.FunctionCall{
+			Name:      t.Name(),
+			Arguments: params,
+		},
+	}
+
+	call, err := t.agent.reviewToolCall(ctx, call)
+	if err != nil {
+		return nil, err
+	}
+	// Go through the registry so arguments are validated like in Chat
+	return t.agent.tools.Execute(ctx, t.Name(), call.Function.Arguments)
+}
+
+// reasoningTools returns the registered tools, gated by the approval policy
+// and handler, for use by the reasoning engines
+func (a *Agent) reasoningTools() []tools.Tool {
+	all := a.tools.All()
+	result := make([]tools.Tool, len(all))
+	for i, tool := range all {
+		result[i] = &approvalGatedTool{Tool: tool, agent: a}
+	}
+	return result
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func containsCategory(list []tools.ToolCategory, value tools.ToolCategory) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,237 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/taipm/go-llm-agent/pkg/tools"
+	"github.com/taipm/go-llm-agent/pkg/tools/file"
+	"github.com/taipm/go-llm-agent/pkg/types"
+)
+
+// recordingTool records the arguments it was executed with
+type recordingTool struct {
+	tools.BaseTool
+	executed []map[string]interface{}
+}
+
+func newRecordingTool(name string, category tools.ToolCategory, requiresAuth, safe bool) *recordingTool {
+	return &recordingTool{BaseTool: tools.NewBaseTool(name, "Records calls", category, requiresAuth, safe)}
+}
+
+func (t *recordingTool) Parameters() *types.JSONSchema {
+	return &types.JSONSchema{Type: "object"}
+}
+
+func (t *recordingTool) Execute(ctx context.Context, params map[string]interface{}) (interface{}, error) {
+	t.executed = append(t.executed, params)
+	return "ok", nil
+}
+
+func TestApprovalHandlerDecisions(t *testing.T) {
+	tests := []struct {
+		name        string
+		decision    Decision
+		handlerErr  error
+		wantRun     bool
+		wantErrText string
+		wantArg     interface{}
+	}{
+		{name: "approve", decision: Approve(), wantRun: true, wantArg: "/tmp/a.txt"},
+		{name: "deny with reason", decision: Deny("not in this directory"), wantErrText: "not in this directory"},
+		{name: "edit arguments", decision: EditArguments(map[string]interface{}{"path": "/tmp/b.txt"}), wantRun: true, wantArg: "/tmp/b.txt"},
+		{name: "handler error", handlerErr: errors.New("no operator"), wantErrText: "no operator"},
+		{name: "unknown action", decision: Decision{Action: "maybe"}, wantErrText: "unknown approval action"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tool := newRecordingTool("file_write", tools.CategoryFile, false, false)
+
+			var asked []types.ToolCall
+			ag := newTestAgent(&mockProvider{}, WithApprovalHandler(func(ctx context.Context, call types.ToolCall) (Decision, error) {
+				asked = append(asked, call)
+				return tt.decision, tt.handlerErr
+			}))
+			ag.AddTool(tool)
+
+			call := types.ToolCall{ID: "c1", Function: types.FunctionCall{
+				Name:      "file_write",
+				Arguments: map[string]interface{}{"path": "/tmp/a.txt"},
+			}}
+			results := ag.executeToolCalls(context.Background(), []types.ToolCall{call})
+
+			if len(asked) != 1 {
+				t.Fatalf("Expected handler to be asked once, got %d", len(asked))
+			}
+			if ran := len(tool.executed) == 1; ran != tt.wantRun {
+				t.Errorf("Expected tool run = %v, got %v", tt.wantRun, ran)
+			}
+			if tt.wantRun {
+				if got := tool.executed[0]["path"]; got != tt.wantArg {
+					t.Errorf("Expected path %v, got %v", tt.wantArg, got)
+				}
+				if got := results[0].call.Function.Arguments["path"]; got != tt.wantArg {
+					t.Errorf("Expected executed call to carry path %v, got %v", tt.wantArg, got)
+				}
+			}
+			if tt.wantErrText != "" {
+				var denied *ToolDeniedError
+				if !errors.As(results[0].err, &denied) {
+					t.Fatalf("Expected ToolDeniedError, got %v", results[0].err)
+				}
+				if msg := results[0].message(); !strings.Contains(msg.Content, tt.wantErrText) {
+					t.Errorf("Expected tool message to contain %q, got %q", tt.wantErrText, msg.Content)
+				}
+			}
+		})
+	}
+}
+
+func TestApprovalOnlyForUnsafeTools(t *testing.T) {
+	safe := newRecordingTool("calc", tools.CategoryMath, false, true)
+	auth := newRecordingTool("gmail_read", tools.CategoryEmail, true, true)
+
+	var asked []string
+	ag := newTestAgent(&mockProvider{}, WithApprovalHandler(func(ctx context.Context, call types.ToolCall) (Decision, error) {
+		asked = append(asked, call.Function.Name)
+		return Approve(), nil
+	}))
+	ag.AddTool(safe)
+	ag.AddTool(auth)
+
+	ag.executeToolCalls(context.Background(), []types.ToolCall{toolCall("c1", "calc"), toolCall("c2", "gmail_read")})
+
+	if strings.Join(asked, ",") != "gmail_read" {
+		t.Errorf("Expected approval only for auth-requiring tool, got %v", asked)
+	}
+	if len(safe.executed) != 1 || len(auth.executed) != 1 {
+		t.Error("Expected both tools to run")
+	}
+}
+
+func TestApprovalPolicy(t *testing.T) {
+	write := newRecordingTool("file_write", tools.CategoryFile, false, false)
+	del := newRecordingTool("file_delete", tools.CategoryFile, false, false)
+	send := newRecordingTool("gmail_send", tools.CategoryEmail, true, true)
+	calc := newRecordingTool("calc", tools.CategoryMath, false, true)
+
+	var asked []string
+	ag := newTestAgent(&mockProvider{},
+		WithApprovalHandler(func(ctx context.Context, call types.ToolCall) (Decision, error) {
+			asked = append(asked, call.Function.Name)
+			return Approve(), nil
+		}),
+		WithApprovalPolicy(ApprovalPolicy{
+			AutoApprove:        []string{"file_write"},
+			AutoDeny:           []string{"file_delete"},
+			AutoDenyCategories: []tools.ToolCategory{tools.CategoryEmail},
+			AlwaysAsk:          []string{"calc"},
+		}),
+	)
+	for _, tool := range []tools.Tool{write, del, send, calc} {
+		ag.AddTool(tool)
+	}
+
+	results := ag.executeToolCalls(context.Background(), []types.ToolCall{
+		toolCall("c1", "file_write"), toolCall("c2", "file_delete"),
+		toolCall("c3", "gmail_send"), toolCall("c4", "calc"),
+	})
+
+	if strings.Join(asked, ",") != "calc" {
+		t.Errorf("Expected handler to be asked only for calc, got %v", asked)
+	}
+	if len(write.executed) != 1 || len(calc.executed) != 1 {
+		t.Error("Expected auto-approved and approved tools to run")
+	}
+	if len(del.executed) != 0 || len(send.executed) != 0 {
+		t.Error("Expected auto-denied tools not to run")
+	}
+	if results[1].err == nil || results[2].err == nil {
+		t.Error("Expected denied calls to report an error")
+	}
+}
+
+func TestApprovalWithoutHandler(t *testing.T) {
+	tool := newRecordingTool("file_write", tools.CategoryFile, false, false)
+	ag := newTestAgent(&mockProvider{})
+	ag.AddTool(tool)
+
+	ag.executeToolCalls(context.Background(), []types.ToolCall{toolCall("c1", "file_write")})
+	if len(tool.executed) != 1 {
+		t.Error("Expected unsafe tool to run when no approval handler is configured")
+	}
+}
+
+func TestApprovalDenyFeedsBackToModel(t *testing.T) {
+	tool := newRecordingTool("file_write", tools.CategoryFile, false, false)
+	provider := &mockProvider{responses: []*types.Response{
+		{ToolCalls: []types.ToolCall{toolCall("c1", "file_write")}},
+		{Content: "ok, I won't write the file"},
+	}}
+
+	ag := newTestAgent(provider, WithApprovalHandler(func(ctx context.Context, call types.ToolCall) (Decision, error) {
+		return Deny("user declined"), nil
+	}))
+	ag.AddTool(tool)
+
+	if _, err := ag.Chat(context.Background(), "write a file"); err != nil {
+		t.Fatalf("Chat failed: %v", err)
+	}
+
+	msgs := provider.received[1]
+	last := msgs[len(msgs)-1]
+	if last.Role != types.RoleTool || !strings.Contains(last.Content, "user declined") {
+		t.Errorf("Expected denial reason in tool result, got %+v", last)
+	}
+}
+
+func TestReasoningToolsAreGated(t *testing.T) {
+	tool := newRecordingTool("file_write", tools.CategoryFile, false, false)
+	ag := newTestAgent(&mockProvider{}, WithApprovalHandler(func(ctx context.Context, call types.ToolCall) (Decision, error) {
+		return Deny("no"), nil
+	}))
+	ag.AddTool(tool)
+
+	gated := ag.reasoningTools()
+	if len(gated) != 1 || gated[0].Name() != "file_write" {
+		t.Fatalf("Expected gated file_write tool, got %v", gated)
+	}
+	if _, err := gated[0].Execute(context.Background(), map[string]interface{}{}); err == nil {
+		t.Error("Expected denied execution")
+	}
+	if len(tool.executed) != 0 {
+		t.Error("Expected wrapped tool not to run")
+	}
+}
+
+func TestNeedsConfirmation(t *testing.T) {
+	if tools.NeedsConfirmation(newRecordingTool("a", tools.CategoryMath, false, true)) {
+		t.Error("Expected safe tool not to need confirmation")
+	}
+	if !tools.NeedsConfirmation(newRecordingTool("b", tools.CategoryFile, false, false)) {
+		t.Error("Expected unsafe tool to need confirmation")
+	}
+	if !tools.NeedsConfirmation(newRecordingTool("c", tools.CategoryEmail, true, true)) {
+		t.Error("Expected auth-requiring tool to need confirmation")
+	}
+	if !tools.NeedsConfirmation(&confirmingTool{newRecordingTool("d", tools.CategoryFile, false, false), false}) {
+		t.Error("Expected unsafe tool to need confirmation whatever its configuration")
+	}
+	if !tools.NeedsConfirmation(&confirmingTool{newRecordingTool("e", tools.CategoryMath, false, true), true}) {
+		t.Error("Expected safe tool requiring confirmation to need it")
+	}
+	if !tools.NeedsConfirmation(file.NewDeleteTool(file.DeleteConfig{})) {
+		t.Error("Expected file_delete to need confirmation by default")
+	}
+}
+
+// confirmingTool is a recordingTool implementing tools.ConfirmationAware
+type confirmingTool struct {
+	*recordingTool
+	confirm bool
+}
+
+func (t *confirmingTool) RequiresConfirmation() bool { return t.confirm }
@@ -0,0 +1,297 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/taipm/go-llm-agent/pkg/types"
+)
+
+// TokenEstimator estimates how many tokens a message occupies in the prompt
+type TokenEstimator func(msg types.Message) int
+
+// EstimateTokens is the default TokenEstimator: roughly 4 characters per
+// token plus a small per-message overhead for role and formatting
+func EstimateTokens(msg types.Message) int {
+	chars := len(msg.Content) + len(msg.Role) + len(msg.ToolID)
+	for _, call := range msg.ToolCalls {
+		chars += len(call.ID) + len(call.Function.Name)
+		if args, err := json.Marshal(call.Function.Arguments); err == nil {
+			chars += len(args)
+		}
+	}
+	return chars/4 + 4
+}
+
+// ContextConfig configures a ContextManager
+type ContextConfig struct {
+	// MaxTokens is the token budget for the prompt: system prompt, tool
+	// definitions, summary and history
+	MaxTokens int
+
+	// Estimator counts tokens per message (default: EstimateTokens)
+	Estimator TokenEstimator
+
+	// Summarize compresses trimmed turns into a running summary produced by
+	// the provider instead of dropping them
+	Summarize bool
+
+	// SummaryMaxTokens limits the length of the summary (default: 500)
+	SummaryMaxTokens int
+}
+
+// ContextWindow is the part of the conversation that fits the budget
+type ContextWindow struct {
+	SystemPrompt string          // System prompt, with the running summary appended
+	Messages     []types.Message // Most recent messages that fit
+	Dropped      int             // Number of older messages left out
+	Tokens       int             // Estimated prompt tokens
+}
+
+// ContextManager keeps the prompt within the model's context window.
+// It always keeps the system prompt and the latest messages, never separates
+// an assistant tool call from its tool results, and optionally folds older
+// messages into a running summary.
+type ContextManager struct {
+	config   ContextConfig
+	provider types.LLMProvider
+
+	mu         sync.Mutex
+	summary    string
+	history    []types.Message // Conversation passed to the last Fit
+	summarized int             // Leading messages of history folded into the summary
+}
+
+// NewContextManager creates a context manager; provider is only used when
+// summarization is enabled
+func NewContextManager(config ContextConfig, provider types.LLMProvider) *ContextManager {
+	if config.Estimator == nil {
+		config.Estimator = EstimateTokens
+	}
+	if config.SummaryMaxTokens <= 0 {
+		config.SummaryMaxTokens = 500
+	}
+	return &ContextManager{config: config, provider: provider}
+}
+
+// WithContextWindow trims (and optionally summarizes) the history sent to
+// the provider so that it fits config.MaxTokens
+func WithContextWindow(config ContextConfig) Option {
+	return func(a *Agent) {
+		a.contextManager = NewContextManager(config, a.provider)
+	}
+}
+
+// Summary returns the current running summary
+func (m *ContextManager) Summary() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.summary
+}
+
+// Reset discards the running summary
+func (m *ContextManager) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.summary = ""
+	m.history = nil
+	m.summarized = 0
+}
+
+// Fit selects the messages that fit the budget. If summarization fails the
+// trimmed window is still returned together with the error.
+func (m *ContextManager) Fit(ctx context.Context, opts *types.ChatOptions, messages []types.Message) (*ContextWindow, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	systemPrompt := ""
+	fixed := 0
+	if opts != nil {
+		systemPrompt = opts.SystemPrompt
+		fixed += m.config.Estimator(types.Message{Role: types.RoleSystem, Content: systemPrompt})
+		if len(opts.Tools) > 0 {
+			if defs, err := json.Marshal(opts.Tools); err == nil {
+				fixed += len(defs) / 4
+			}
+		}
+	}
+
+	groups := groupMessages(messages)
+
+	// Walk back from the newest group; the last one is always kept
+	cut := len(groups)
+	used := 0
+	reserved := m.summaryTokens()
+	if m.config.Summarize && reserved < m.config.SummaryMaxTokens {
+		reserved = m.config.SummaryMaxTokens // Room for the summary produced below
+	}
+	budget := m.config.MaxTokens - fixed - reserved
+	for i := len(groups) - 1; i >= 0; i-- {
+		tokens := m.groupTokens(groups[i])
+		if cut < len(groups) && used+tokens > budget {
+			break
+		}
+		used += tokens
+		cut = i
+	}
+
+	// A tool result without its call is rejected by providers
+	for cut < len(groups) && groups[cut][0].Role == types.RoleTool {
+		cut++
+	}
+
+	var kept, dropped []types.Message
+	for i, group := range groups {
+		if i < cut {
+			dropped = append(dropped, group...)
+		} else {
+			kept = append(kept, group...)
+		}
+	}
+
+	var err error
+	if m.config.Summarize && m.provider != nil {
+		err = m.summarize(ctx, messages, len(dropped))
+	}
+
+	window := &ContextWindow{
+		SystemPrompt: systemPrompt,
+		Messages:     kept,
+		Dropped:      len(dropped),
+		Tokens:       fixed + m.summaryTokens(),
+	}
+	for _, msg := range kept {
+		window.Tokens += m.config.Estimator(msg)
+	}
+	if m.summary != "" {
+		window.SystemPrompt = strings.TrimSpace(systemPrompt + "\n\nSummary of the earlier conversation:\n" + m.summary)
+	}
+	return window, err
+}
+
+// summarize folds the first cut messages that are not yet in the summary
+// into it. Memories return a sliding window of the conversation, so the
+// messages are aligned with those of the last Fit to tell how many of them
+// were already summarized.
+func (m *ContextManager) summarize(ctx context.Context, messages []types.Message, cut int) error {
+	done := m.summarizedIn(messages)
+	pending := messages[min(done, cut):cut]
+	if len(pending) == 0 {
+		m.remember(messages, done)
+		return nil
+	}
+
+	var prompt strings.Builder
+	if m.summary != "" {
+		prompt.WriteString("Current summary:\n")
+		prompt.WriteString(m.summary)
+		prompt.WriteString("\n\nNew messages:\n")
+	} else {
+		prompt.WriteString("Messages:\n")
+	}
+	for _, msg := range pending {
+		prompt.WriteString(formatForSummary(msg))
+		prompt.WriteString("\n")
+	}
+	prompt.WriteString("\nWrite an updated summary of the conversation. Keep facts, decisions, tool results and open questions the assistant needs to continue. Reply with the summary only.")
+
+	resp, err := m.provider.Chat(withUsageSource(ctx, UsageSourceSummary), []types.Message{
+		{Role: types.RoleUser, Content: prompt.String()},
+	}, &types.ChatOptions{
+		SystemPrompt: "You summarize conversations concisely for another assistant.",
+		Temperature:  0.2,
+		MaxTokens:    m.config.SummaryMaxTokens,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to summarize conversation: %w", err)
+	}
+
+	m.summary = strings.TrimSpace(resp.Content)
+	m.remember(messages, max(done, cut))
+	return nil
+}
+
+// summarizedIn returns how many leading messages of messages are already in
+// the summary. Between two calls the history only loses messages at the front
+// (evicted by the memory) and gains messages at the end, so the last history
+// minus its first s messages is a prefix of messages for the number s of
+// evicted messages. Without any overlap nothing counts as summarized.
+func (m *ContextManager) summarizedIn(messages []types.Message) int {
+	for s := 0; s < len(m.history); s++ {
+		overlap := m.history[s:]
+		if len(overlap) <= len(messages) && slices.EqualFunc(overlap, messages[:len(overlap)], sameMessage) {
+			return max(m.summarized-s, 0)
+		}
+	}
+	return 0
+}
+
+func (m *ContextManager) remember(messages []types.Message, summarized int) {
+	m.history = slices.Clone(messages)
+	m.summarized = summarized
+}
+
+func (m *ContextManager) summaryTokens() int {
+	if m.summary == "" {
+		return 0
+	}
+	return m.config.Estimator(types.Message{Role: types.RoleSystem, Content: m.summary})
+}
+
+func (m *ContextManager) groupTokens(group []types.Message) int {
+	tokens := 0
+	for _, msg := range group {
+		tokens += m.config.Estimator(msg)
+	}
+	return tokens
+}
+
+// groupMessages splits messages into units that must be kept or dropped
+// together: an assistant message with tool calls plus the tool results that
+// follow it, or any other single message
+func groupMessages(messages []types.Message) [][]types.Message {
+	var groups [][]types.Message
+	for i := 0; i < len(messages); i++ {
+		group := []types.Message{messages[i]}
+		if messages[i].Role == types.RoleAssistant && len(messages[i].ToolCalls) > 0 {
+			for i+1 < len(messages) && messages[i+1].Role == types.RoleTool {
+				i++
+				group = append(group, messages[i])
+			}
+		}
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+func sameMessage(a, b types.Message) bool {
+	if a.Role != b.Role || a.Content != b.Content || a.ToolID != b.ToolID || len(a.ToolCalls) != len(b.ToolCalls) {
+		return false
+	}
+	for i := range a.ToolCalls {
+		if a.ToolCalls[i].ID != b.ToolCalls[i].ID || a.ToolCalls[i].Function.Name != b.ToolCalls[i].Function.Name {
+			return false
+		}
+	}
+	return true
+}
+
+func formatForSummary(msg types.Message) string {
+	switch {
+	case msg.Role == types.RoleTool:
+		return fmt.Sprintf("tool result (%s): %s", msg.ToolID, msg.Content)
+	case len(msg.ToolCalls) > 0:
+		var calls []string
+		for _, call := range msg.ToolCalls {
+			args, _ := json.Marshal(call.Function.Arguments)
+			calls = append(calls, fmt.Sprintf("%s(%s)", call.Function.Name, args))
+		}
+		return fmt.Sprintf("%s: %s [called %s]", msg.Role, msg.Content, strings.Join(calls, ", "))
+	default:
+		return fmt.Sprintf("%s: %s", msg.Role, msg.Content)
+	}
+}
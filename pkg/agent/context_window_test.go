@@ -0,0 +1,237 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/taipm/go-llm-agent/pkg/types"
+)
+
+// tenTokens counts every message as 10 tokens
+func tenTokens(types.Message) int { return 10 }
+
+func conversation(turns int) []types.Message {
+	var msgs []types.Message
+	for i := 0; i < turns; i++ {
+		msgs = append(msgs,
+			types.Message{Role: types.RoleUser, Content: fmt.Sprintf("question %d", i)},
+			types.Message{Role: types.RoleAssistant, Content: fmt.Sprintf("answer %d", i)},
+		)
+	}
+	return msgs
+}
+
+func TestContextManagerKeepsLatestMessages(t *testing.T) {
+	cm := NewContextManager(ContextConfig{MaxTokens: 40, Estimator: tenTokens}, nil)
+
+	window, err := cm.Fit(context.Background(), &types.ChatOptions{SystemPrompt: "sys"}, conversation(5))
+	if err != nil {
+		t.Fatalf("Fit failed: %v", err)
+	}
+
+	// 10 tokens for the system prompt leaves room for 3 messages
+	if len(window.Messages) != 3 || window.Dropped != 7 {
+		t.Fatalf("Expected 3 kept / 7 dropped, got %d / %d", len(window.Messages), window.Dropped)
+	}
+	if window.Messages[2].Content != "answer 4" || window.SystemPrompt != "sys" || window.Tokens != 40 {
+		t.Errorf("Unexpected window: %+v", window)
+	}
+}
+
+func TestContextManagerKeepsToolCallsWithResults(t *testing.T) {
+	msgs := []types.Message{
+		{Role: types.RoleUser, Content: "weather in Hanoi and Paris?"},
+		{Role: types.RoleAssistant, ToolCalls: []types.ToolCall{toolCall("c1", "weather"), toolCall("c2", "weather")}},
+		{Role: types.RoleTool, ToolID: "c1", Content: "30C"},
+		{Role: types.RoleTool, ToolID: "c2", Content: "12C"},
+		{Role: types.RoleAssistant, Content: "Hanoi 30C, Paris 12C"},
+	}
+
+	tests := []struct {
+		name      string
+		maxTokens int
+		wantKept  int
+	}{
+		{"everything fits", 100, 5},
+		{"group does not fit", 30, 1}, // 40 tokens needed for the tool call group
+		{"group fits", 45, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cm := NewContextManager(ContextConfig{MaxTokens: tt.maxTokens, Estimator: tenTokens}, nil)
+			window, _ := cm.Fit(context.Background(), nil, msgs)
+			if len(window.Messages) != tt.wantKept {
+				t.Fatalf("Expected %d messages, got %d", tt.wantKept, len(window.Messages))
+			}
+			if window.Messages[0].Role == types.RoleTool {
+				t.Error("Expected window not to start with a tool result")
+			}
+		})
+	}
+}
+
+func TestContextManagerAlwaysKeepsLastMessage(t *testing.T) {
+	cm := NewContextManager(ContextConfig{MaxTokens: 5, Estimator: tenTokens}, nil)
+	window, _ := cm.Fit(context.Background(), nil, conversation(2))
+	if len(window.Messages) != 1 || window.Messages[0].Content != "answer 1" {
+		t.Errorf("Expected only the latest message, got %+v", window.Messages)
+	}
+}
+
+func TestContextManagerDropsOrphanToolResults(t *testing.T) {
+	// History whose tool call was already evicted from memory
+	msgs := []types.Message{
+		{Role: types.RoleTool, ToolID: "c0", Content: "stale"},
+		{Role: types.RoleUser, Content: "hi"},
+	}
+	cm := NewContextManager(ContextConfig{MaxTokens: 100, Estimator: tenTokens}, nil)
+	window, _ := cm.Fit(context.Background(), nil, msgs)
+	if len(window.Messages) != 1 || window.Messages[0].Content != "hi" {
+		t.Errorf("Expected orphan tool result to be dropped, got %+v", window.Messages)
+	}
+}
+
+func TestContextManagerSummarizes(t *testing.T) {
+	provider := &mockProvider{responses: []*types.Response{
+		{Content: "User asked questions 0-1."},
+		{Content: "User asked questions 0-2."},
+	}}
+	cm := NewContextManager(ContextConfig{MaxTokens: 60, Estimator: tenTokens, Summarize: true, SummaryMaxTokens: 20}, provider)
+	opts := &types.ChatOptions{SystemPrompt: "sys"}
+
+	// 60 - 10 (system) - 20 (summary) leaves room for 3 messages
+	window, err := cm.Fit(context.Background(), opts, conversation(3))
+	if err != nil {
+		t.Fatalf("Fit failed: %v", err)
+	}
+	if len(window.Messages) != 3 || !strings.Contains(window.SystemPrompt, "User asked questions 0-1.") {
+		t.Fatalf("Expected summary in system prompt and 3 messages, got %+v", window)
+	}
+
+	// Only messages not yet summarized are sent next time
+	if _, err := cm.Fit(context.Background(), opts, conversation(4)); err != nil {
+		t.Fatalf("Fit failed: %v", err)
+	}
+	if len(provider.received) != 2 {
+		t.Fatalf("Expected 2 summarization calls, got %d", len(provider.received))
+	}
+	second := provider.received[1][0].Content
+	if !strings.Contains(second, "User asked questions 0-1.") || strings.Contains(second, "question 1") || !strings.Contains(second, "question 2") {
+		t.Errorf("Expected incremental summary prompt, got:\n%s", second)
+	}
+	if cm.Summary() != "User asked questions 0-2." {
+		t.Errorf("Unexpected summary %q", cm.Summary())
+	}
+
+	cm.Reset()
+	if cm.Summary() != "" {
+		t.Error("Expected Reset to clear the summary")
+	}
+}
+
+func TestContextManagerSummarizesRepeatedMessages(t *testing.T) {
+	provider := &mockProvider{responses: []*types.Response{
+		{Content: "The user said continue twice."},
+		{Content: "The user said continue three times."},
+	}}
+	cm := NewContextManager(ContextConfig{MaxTokens: 60, Estimator: tenTokens, Summarize: true, SummaryMaxTokens: 20}, provider)
+	opts := &types.ChatOptions{SystemPrompt: "sys"}
+
+	var msgs []types.Message
+	for i := 0; i < 4; i++ {
+		msgs = append(msgs,
+			types.Message{Role: types.RoleUser, Content: "continue"},
+			types.Message{Role: types.RoleAssistant, Content: "done"},
+		)
+	}
+
+	// 3 of 6 messages are dropped, then 5 of 8
+	if _, err := cm.Fit(context.Background(), opts, msgs[:6]); err != nil {
+		t.Fatalf("Fit failed: %v", err)
+	}
+	if _, err := cm.Fit(context.Background(), opts, msgs); err != nil {
+		t.Fatalf("Fit failed: %v", err)
+	}
+
+	if len(provider.received) != 2 {
+		t.Fatalf("Expected 2 summarization calls, got %d", len(provider.received))
+	}
+	second := provider.received[1][0].Content
+	if strings.Count(second, "user: continue") != 1 || strings.Count(second, "assistant: done") != 1 {
+		t.Errorf("Expected the 2 newly dropped messages in the summary prompt, got:\n%s", second)
+	}
+}
+
+func TestContextManagerSummarizesSlidingHistory(t *testing.T) {
+	provider := &mockProvider{}
+	cm := NewContextManager(ContextConfig{MaxTokens: 60, Estimator: tenTokens, Summarize: true, SummaryMaxTokens: 20}, provider)
+	opts := &types.ChatOptions{SystemPrompt: "sys"}
+
+	// A memory capped at 10 messages: every turn evicts 2 and adds 2
+	msgs := conversation(10)
+	for start := 0; start+10 <= len(msgs); start += 2 {
+		if _, err := cm.Fit(context.Background(), opts, msgs[start:start+10]); err != nil {
+			t.Fatalf("Fit failed: %v", err)
+		}
+	}
+
+	// The last window drops messages up to index 16; each is summarized once
+	summarized := make(map[string]int)
+	for _, received := range provider.received {
+		for _, line := range strings.Split(received[0].Content, "\n") {
+			if strings.HasPrefix(line, "user: ") || strings.HasPrefix(line, "assistant: ") {
+				summarized[line]++
+			}
+		}
+	}
+	for i, msg := range msgs[:17] {
+		if line := formatForSummary(msg); summarized[line] != 1 {
+			t.Errorf("Expected message %d (%q) summarized once, got %d times", i, line, summarized[line])
+		}
+	}
+	if len(summarized) != 17 || len(provider.received) != 6 {
+		t.Errorf("Expected 17 messages in 6 summarization calls, got %d in %d", len(summarized), len(provider.received))
+	}
+}
+
+type failingProvider struct{ mockProvider }
+
+func (p *failingProvider) Chat(ctx context.Context, messages []types.Message, options *types.ChatOptions) (*types.Response, error) {
+	return nil, errors.New("provider down")
+}
+
+func TestContextManagerSummaryFailure(t *testing.T) {
+	cm := NewContextManager(ContextConfig{MaxTokens: 30, Estimator: tenTokens, Summarize: true, SummaryMaxTokens: 10}, &failingProvider{})
+
+	window, err := cm.Fit(context.Background(), nil, conversation(3))
+	if err == nil {
+		t.Error("Expected summarization error")
+	}
+	if window == nil || len(window.Messages) != 2 {
+		t.Errorf("Expected trimmed window despite the error, got %+v", window)
+	}
+}
+
+func TestAgentUsesContextWindow(t *testing.T) {
+	provider := &mockProvider{}
+	ag := newTestAgent(provider, WithContextWindow(ContextConfig{MaxTokens: 30, Estimator: tenTokens}))
+	for _, msg := range conversation(5) {
+		ag.memory.Add(msg)
+	}
+
+	if _, err := ag.Chat(context.Background(), "latest question"); err != nil {
+		t.Fatalf("Chat failed: %v", err)
+	}
+
+	sent := provider.received[0]
+	if len(sent) != 2 || sent[1].Content != "latest question" {
+		t.Errorf("Expected 2 most recent messages to be sent, got %+v", sent)
+	}
+	if history, _ := ag.GetHistory(); len(history) != 12 {
+		t.Errorf("Expected full history to stay in memory, got %d", len(history))
+	}
+}
@@ -0,0 +1,62 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/taipm/go-llm-agent/pkg/learning"
+)
+
+func TestStatusRecentImprovements(t *testing.T) {
+	ag := newTestAgent(&echoProvider{}, WithLearning(true))
+	ag.initExperienceStore()
+
+	// 10 old failures followed by 10 recent successes
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 20; i++ {
+		exp := learning.Experience{
+			ID:             fmt.Sprintf("exp-%02d", i),
+			Timestamp:      start.Add(time.Duration(i) * time.Minute),
+			ConversationID: ag.conversationID,
+			Success:        i >= 10,
+		}
+		if err := ag.experienceStore.Record(context.Background(), exp); err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+	}
+
+	status := ag.Status()
+	if len(status.Learning.RecentImprovements) != 1 {
+		t.Errorf("Expected the 10 newest experiences to show an improvement, got %v", status.Learning.RecentImprovements)
+	}
+}
+
+func TestWithExperienceBackend(t *testing.T) {
+	ctx := context.Background()
+
+	// Experiences recorded before a restart are kept by the backend
+	backend := learning.NewInMemoryExperienceBackend()
+	backend.Save(ctx, learning.Experience{ID: "old", Timestamp: time.Now(), Success: false, Error: "timeout"})
+
+	ag := newTestAgent(&echoProvider{}, WithLearning(true), WithExperienceBackend(backend))
+	if _, err := ag.Chat(ctx, "hello"); err != nil {
+		t.Fatalf("Chat failed: %v", err)
+	}
+
+	failures, err := ag.experienceStore.GetAllFailures(ctx, 0)
+	if err != nil || len(failures) != 1 || failures[0].ID != "old" {
+		t.Errorf("Expected the stored failure, got %v (%v)", failures, err)
+	}
+
+	// Experiences are recorded asynchronously
+	deadline := time.Now().Add(2 * time.Second)
+	count := 0
+	for ; count != 2 && time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		count, _ = backend.Count(ctx, learning.ExperienceFilters{})
+	}
+	if count != 2 {
+		t.Errorf("Expected the new experience in the backend, got %d experiences", count)
+	}
+}
@@ -0,0 +1,116 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/taipm/go-llm-agent/pkg/reasoning"
+	"github.com/taipm/go-llm-agent/pkg/types"
+)
+
+// slowEchoProvider echoes like echoProvider after a delay and tracks how
+// many calls run at once
+type slowEchoProvider struct {
+	echoProvider
+	delay        time.Duration
+	active, peak int32
+}
+
+func (p *slowEchoProvider) Chat(ctx context.Context, messages []types.Message, options *types.ChatOptions) (*types.Response, error) {
+	n := atomic.AddInt32(&p.active, 1)
+	defer atomic.AddInt32(&p.active, -1)
+	for {
+		peak := atomic.LoadInt32(&p.peak)
+		if n <= peak || atomic.CompareAndSwapInt32(&p.peak, peak, n) {
+			break
+		}
+	}
+	time.Sleep(p.delay)
+	return p.echoProvider.Chat(ctx, messages, options)
+}
+
+func newAgentTestPlan(steps ...types.PlanStep) *types.Plan {
+	for i := range steps {
+		steps[i].Status = types.PlanStatusPending
+	}
+	return &types.Plan{ID: "plan", Goal: "test goal", Status: types.PlanStatusPending, Steps: steps}
+}
+
+func TestExecutePlanParallelStepsAreIsolated(t *testing.T) {
+	provider := &slowEchoProvider{delay: 20 * time.Millisecond, echoProvider: echoProvider{meta: usageMeta("m", 4, 1)}}
+	ag := newTestAgent(provider)
+	before, _ := ag.GetHistory()
+
+	plan := newAgentTestPlan(
+		types.PlanStep{ID: "a", Description: "step a"},
+		types.PlanStep{ID: "b", Description: "step b"},
+		types.PlanStep{ID: "c", Description: "step c"},
+		types.PlanStep{ID: "d", Description: "step d", Dependencies: []string{"a", "b", "c"}},
+	)
+	config := reasoning.DefaultExecutionConfig()
+	config.MaxParallel = 3
+
+	if err := ag.ExecutePlanWithConfig(context.Background(), plan, config); err != nil {
+		t.Fatalf("ExecutePlanWithConfig failed: %v", err)
+	}
+
+	if plan.Status != types.PlanStatusCompleted {
+		t.Fatalf("Expected completed plan, got %s", plan.Status)
+	}
+	if provider.peak < 2 {
+		t.Errorf("Expected steps to run concurrently, peak was %d", provider.peak)
+	}
+	if result, _ := plan.Steps[3].Result.(string); !strings.Contains(result, "step d") || !strings.Contains(result, "step a") {
+		t.Errorf("Expected step d to receive its dependencies' results, got %q", result)
+	}
+
+	// Parallel steps run in copies: only the planner's completion note reaches
+	// the agent's history, while usage is billed to the agent
+	if history, _ := ag.GetHistory(); len(history)-len(before) != 1 {
+		t.Errorf("Expected only the plan completion in memory, got %d messages", len(history)-len(before))
+	}
+	if usage := ag.Usage(); usage.Calls != 4 {
+		t.Errorf("Expected 4 calls billed to the agent, got %d", usage.Calls)
+	}
+}
+
+func TestExecutePlanSequentialUsesConversation(t *testing.T) {
+	ag := newTestAgent(&echoProvider{})
+	before, _ := ag.GetHistory()
+	plan := newAgentTestPlan(
+		types.PlanStep{ID: "a", Description: "step a"},
+		types.PlanStep{ID: "b", Description: "step b", Dependencies: []string{"a"}},
+	)
+
+	if err := ag.ExecutePlan(context.Background(), plan); err != nil {
+		t.Fatalf("ExecutePlan failed: %v", err)
+	}
+	if history, _ := ag.GetHistory(); len(history)-len(before) != 5 {
+		t.Errorf("Expected both steps and the plan completion in the agent's history, got %d messages", len(history)-len(before))
+	}
+}
+
+func TestExecutePlanIsOneTurn(t *testing.T) {
+	ag := newTestAgent(&echoProvider{meta: usageMeta("m", 4, 1)}, WithBudget(Budget{MaxTurnTokens: 12}))
+	plan := newAgentTestPlan(
+		types.PlanStep{ID: "a", Description: "step a"},
+		types.PlanStep{ID: "b", Description: "step b", Dependencies: []string{"a"}},
+		types.PlanStep{ID: "c", Description: "step c", Dependencies: []string{"b"}},
+		types.PlanStep{ID: "d", Description: "step d", Dependencies: []string{"c"}},
+	)
+
+	// 5 tokens per step: the fourth step is refused by the turn budget
+	err := ag.ExecutePlan(context.Background(), plan)
+	if err == nil {
+		t.Fatal("Expected the turn budget to stop the plan")
+	}
+	if plan.Steps[2].Status != types.PlanStatusCompleted || plan.Steps[3].Status != types.PlanStatusFailed {
+		t.Errorf("Expected steps a-c to complete and d to fail, got %s and %s", plan.Steps[2].Status, plan.Steps[3].Status)
+	}
+	if usage := ag.Usage(); usage.Turn.TotalTokens != 15 {
+		t.Errorf("Expected the plan's usage in one turn, got %d tokens", usage.Turn.TotalTokens)
+	}
+}
@@ -0,0 +1,377 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/taipm/go-llm-agent/pkg/memory"
+	"github.com/taipm/go-llm-agent/pkg/types"
+)
+
+// SessionConfig configures a SessionManager
+type SessionConfig struct {
+	// NewMemory creates the memory of a session when it is created or
+	// reloaded after expiry; a persistent memory keyed by the session ID
+	// brings the history back. Default: a SQLiteMemory or LocalVectorMemory
+	// base memory is scoped to the session ID (see their WithConversation);
+	// otherwise each session gets a BufferMemory of 100 messages (history is
+	// lost on expiry) and a warning is logged.
+	NewMemory func(sessionID string) (types.Memory, error)
+
+	// TTL unloads sessions idle for longer than this (0 = never)
+	TTL time.Duration
+
+	// MaxSessions caps the number of loaded sessions; the least recently
+	// used session is unloaded to make room (0 = unlimited)
+	MaxSessions int
+}
+
+// SessionManager runs many conversations on top of one Agent. Each session
+// has its own memory, conversation ID, reasoning state and context window,
+// and shares the agent's provider, tools, options, approval policy and
+// learning stores. Chat may be called concurrently for different sessions;
+// turns within one session are serialized.
+type SessionManager struct {
+	base   *Agent
+	config SessionConfig
+
+	mu       sync.Mutex
+	sessions map[string]*Session
+}
+
+// Session is one conversation managed by a SessionManager
+type Session struct {
+	id        string
+	agent     *Agent
+	createdAt time.Time
+
+	mu       sync.Mutex // Serializes turns
+	lastUsed time.Time  // Guarded by SessionManager.mu
+}
+
+// SessionInfo describes a loaded session
+type SessionInfo struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	LastUsed  time.Time `json:"last_used"`
+	Messages  int       `json:"messages"`
+}
+
+// NewSessionManager creates a session manager using base as the template for
+// every session. Configure base (tools, options, budget, approval) first;
+// later changes are not picked up by sessions that already exist.
+func NewSessionManager(base *Agent, config SessionConfig) *SessionManager {
+	if config.NewMemory == nil {
+		config.NewMemory = defaultSessionMemory(base)
+	}
+
+	// Initialize learning once so that all sessions share the same stores
+	if base.options.EnableLearning {
+		base.initExperienceStore()
+	}
+
+	return &SessionManager{
+		base:     base,
+		config:   config,
+		sessions: make(map[string]*Session),
+	}
+}
+
+// defaultSessionMemory scopes a SQLite or local vector memory to the session
+// and falls back to a buffer for memories that cannot be shared between
+// conversations
+func defaultSessionMemory(base *Agent) func(string) (types.Memory, error) {
+	switch mem := base.memory.(type) {
+	case *memory.SQLiteMemory:
+		return func(id string) (types.Memory, error) {
+			return mem.WithConversation(id), nil
+		}
+	case *memory.LocalVectorMemory:
+		return func(id string) (types.Memory, error) {
+			return mem.WithConversation(id)
+		}
+	case nil, *memory.BufferMemory:
+	default:
+		base.logger.Warn("⚠️  %T cannot be scoped per session; sessions use a BufferMemory of 100 messages (set SessionConfig.NewMemory to keep their history)", mem)
+	}
+	return func(string) (types.Memory, error) {
+		return memory.NewBuffer(100), nil
+	}
+}
+
+// Create starts a session with a new random ID
+func (m *SessionManager) Create() (*Session, error) {
+	return m.Get(uuid.New().String())
+}
+
+// Get returns the session with the given ID, creating (or reloading) it if
+// it is not loaded
+func (m *SessionManager) Get(id string) (*Session, error) {
+	if id == "" {
+		return nil, fmt.Errorf("session ID is required")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	m.expireLocked(now)
+
+	if s, ok := m.sessions[id]; ok {
+		s.lastUsed = now
+		return s, nil
+	}
+
+	mem, err := m.config.NewMemory(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create memory for session %s: %w", id, err)
+	}
+
+	if m.config.MaxSessions > 0 && len(m.sessions) >= m.config.MaxSessions {
+		m.evictLocked()
+	}
+
+	s := &Session{
+		id:        id,
+		agent:     m.base.forSession(id, mem),
+		createdAt: now,
+		lastUsed:  now,
+	}
+	m.sessions[id] = s
+	return s, nil
+}
+
+// Ephemeral returns a session that the manager does not track: it is not
+// listed, expired or evicted, does not count towards MaxSessions, and keeps
+// its history in a BufferMemory that is discarded with the session
+func (m *SessionManager) Ephemeral(id string) *Session {
+	if id == "" {
+		id = uuid.New().String()
+	}
+	now := time.Now()
+	return &Session{
+		id:        id,
+		agent:     m.base.forSession(id, memory.NewBuffer(100)),
+		createdAt: now,
+		lastUsed:  now,
+	}
+}
+
+// Lookup returns a loaded session without creating one
+func (m *SessionManager) Lookup(id string) (*Session, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.expireLocked(time.Now())
+	s, ok := m.sessions[id]
+	return s, ok
+}
+
+// Chat sends a message in the given session, creating it if needed
+func (m *SessionManager) Chat(ctx context.Context, sessionID, message string) (string, error) {
+	s, err := m.Get(sessionID)
+	if err != nil {
+		return "", err
+	}
+	return s.Chat(ctx, message)
+}
+
+// List returns the loaded sessions, most recently used first
+func (m *SessionManager) List() []SessionInfo {
+	m.mu.Lock()
+	m.expireLocked(time.Now())
+	sessions := make([]*Session, 0, len(m.sessions))
+	lastUsed := make(map[*Session]time.Time, len(m.sessions))
+	for _, s := range m.sessions {
+		sessions = append(sessions, s)
+		lastUsed[s] = s.lastUsed
+	}
+	m.mu.Unlock()
+
+	infos := make([]SessionInfo, 0, len(sessions))
+	for _, s := range sessions {
+		infos = append(infos, SessionInfo{
+			ID:        s.id,
+			CreatedAt: s.createdAt,
+			LastUsed:  lastUsed[s],
+			Messages:  s.agent.memory.Size(),
+		})
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		if !infos[i].LastUsed.Equal(infos[j].LastUsed) {
+			return infos[i].LastUsed.After(infos[j].LastUsed)
+		}
+		return infos[i].ID < infos[j].ID
+	})
+	return infos
+}
+
+// Len returns the number of loaded sessions
+func (m *SessionManager) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.sessions)
+}
+
+// Unload removes a session from the manager without touching its memory;
+// with a persistent memory the next Get reloads the history
+func (m *SessionManager) Unload(id string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.sessions[id]; !ok {
+		return false
+	}
+	delete(m.sessions, id)
+	return true
+}
+
+// Delete clears the session's history and removes it from the manager
+func (m *SessionManager) Delete(id string) error {
+	m.mu.Lock()
+	s, ok := m.sessions[id]
+	delete(m.sessions, id)
+	m.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("session %s not found", id)
+	}
+	return s.Reset()
+}
+
+// Expire unloads sessions idle for longer than the TTL and returns how many
+// were unloaded. Expiry also happens lazily on Get, Lookup and List.
+func (m *SessionManager) Expire() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.expireLocked(time.Now())
+}
+
+func (m *SessionManager) expireLocked(now time.Time) int {
+	if m.config.TTL <= 0 {
+		return 0
+	}
+	expired := 0
+	for id, s := range m.sessions {
+		if now.Sub(s.lastUsed) > m.config.TTL {
+			delete(m.sessions, id)
+			expired++
+		}
+	}
+	return expired
+}
+
+// evictLocked unloads the least recently used session
+func (m *SessionManager) evictLocked() {
+	var oldest *Session
+	for _, s := range m.sessions {
+		if oldest == nil || s.lastUsed.Before(oldest.lastUsed) {
+			oldest = s
+		}
+	}
+	if oldest != nil {
+		delete(m.sessions, oldest.id)
+	}
+}
+
+// forSession returns a copy of the agent for one session: it shares the
+// provider, tools, logger, approval, plan and learning stores, and gets its own
+// memory, conversation ID, reasoning engines, context window and turn usage
+// (which still counts towards the agent's totals and lifetime budget)
+func (a *Agent) forSession(id string, mem types.Memory) *Agent {
+	return a.clone(id, mem, a.usage.child())
+}
+
+// clone returns a copy of the agent with its own memory, conversation ID,
+// reasoning engines and context window that records usage to usage
+func (a *Agent) clone(id string, mem types.Memory, usage *usageTracker) *Agent {
+	provider := a.provider
+	if metered, ok := provider.(*meteredProvider); ok {
+		provider = metered.next
+	}
+	options := *a.options
+
+	c := &Agent{
+		provider:            &meteredProvider{next: provider, tracker: usage},
+		usage:               usage,
+		tools:               a.tools,
+		memory:              mem,
+		options:             &options,
+		logger:              a.logger,
+		experienceStore:     a.experienceStore,
+		toolSelector:        a.toolSelector,
+		errorAnalyzer:       a.errorAnalyzer,
+		conversationID:      id,
+		enableAutoReasoning: a.enableAutoReasoning,
+		approvalHandler:     a.approvalHandler,
+		approvalPolicy:      a.approvalPolicy,
+		planStore:           a.planStore,
+		verifiers:           a.verifiers,
+		selfConsistency:     a.selfConsistency,
+	}
+	if a.contextManager != nil {
+		c.contextManager = NewContextManager(a.contextManager.config, c.provider)
+	}
+	return c
+}
+
+// ID returns the session ID (also used as the conversation ID for learning)
+func (s *Session) ID() string {
+	return s.id
+}
+
+// Chat sends a message in this session
+func (s *Session) Chat(ctx context.Context, message string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.agent.Chat(ctx, message)
+}
+
+// ChatStream sends a message in this session and streams the response
+func (s *Session) ChatStream(ctx context.Context, message string, handler types.StreamHandler) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.agent.ChatStream(ctx, message, handler)
+}
+
+// ChatStreamEvents sends a message in this session and reports typed progress events
+func (s *Session) ChatStreamEvents(ctx context.Context, message string, handler StreamEventHandler) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.agent.ChatStreamEvents(ctx, message, handler)
+}
+
+// GetHistory returns the session's conversation history
+func (s *Session) GetHistory() ([]types.Message, error) {
+	return s.agent.GetHistory()
+}
+
+// AddHistory appends messages to the session's memory without calling the
+// model, e.g. to seed a new session with an existing conversation
+func (s *Session) AddHistory(messages ...types.Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, msg := range messages {
+		if err := s.agent.memory.Add(msg); err != nil {
+			return fmt.Errorf("failed to add message to memory: %w", err)
+		}
+	}
+	return nil
+}
+
+// Reset clears the session's conversation history
+func (s *Session) Reset() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.agent.Reset()
+}
+
+// Usage returns the token usage and cost of this session
+func (s *Session) Usage() Usage {
+	return s.agent.Usage()
+}
@@ -0,0 +1,220 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/taipm/go-llm-agent/pkg/memory"
+	"github.com/taipm/go-llm-agent/pkg/types"
+)
+
+// echoProvider answers with the last user message
+type echoProvider struct {
+	meta *types.Metadata
+}
+
+func (p *echoProvider) Chat(ctx context.Context, messages []types.Message, options *types.ChatOptions) (*types.Response, error) {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == types.RoleUser {
+			return &types.Response{Content: "echo: " + messages[i].Content, Metadata: p.meta}, nil
+		}
+	}
+	return &types.Response{Content: "echo", Metadata: p.meta}, nil
+}
+
+func (p *echoProvider) Stream(ctx context.Context, messages []types.Message, options *types.ChatOptions, handler types.StreamHandler) error {
+	resp, _ := p.Chat(ctx, messages, options)
+	return handler(types.StreamChunk{Content: resp.Content, Done: true, Metadata: resp.Metadata})
+}
+
+func TestSessionManagerIsolatesConversations(t *testing.T) {
+	ctx := context.Background()
+	mgr := NewSessionManager(newTestAgent(&echoProvider{}), SessionConfig{})
+
+	if _, err := mgr.Chat(ctx, "alice", "hi from alice"); err != nil {
+		t.Fatalf("Chat failed: %v", err)
+	}
+	bob, _ := mgr.Get("bob")
+	if answer, _ := bob.Chat(ctx, "hi from bob"); answer != "echo: hi from bob" {
+		t.Errorf("Unexpected answer %q", answer)
+	}
+	mgr.Chat(ctx, "alice", "second")
+
+	alice, ok := mgr.Lookup("alice")
+	if !ok {
+		t.Fatal("Expected alice to be loaded")
+	}
+	history, _ := alice.GetHistory()
+	if len(history) != 4 || history[0].Content != "hi from alice" || history[2].Content != "second" {
+		t.Errorf("Unexpected alice history: %+v", history)
+	}
+	if history, _ := bob.GetHistory(); len(history) != 2 {
+		t.Errorf("Expected 2 messages for bob, got %d", len(history))
+	}
+
+	list := mgr.List()
+	if len(list) != 2 || list[0].ID != "alice" || list[0].Messages != 4 {
+		t.Errorf("Expected alice first with 4 messages, got %+v", list)
+	}
+
+	if _, err := mgr.Get(""); err == nil {
+		t.Error("Expected error for empty session ID")
+	}
+	created, _ := mgr.Create()
+	if created.ID() == "" || mgr.Len() != 3 {
+		t.Errorf("Expected a generated session, got %q with %d sessions", created.ID(), mgr.Len())
+	}
+}
+
+func TestSessionManagerConcurrentChat(t *testing.T) {
+	ctx := context.Background()
+	mgr := NewSessionManager(newTestAgent(&echoProvider{}), SessionConfig{})
+
+	const sessions, turns = 8, 10
+	var wg sync.WaitGroup
+	errs := make(chan error, sessions*turns)
+	for s := 0; s < sessions; s++ {
+		for c := 0; c < 2; c++ { // Two writers per session
+			wg.Add(1)
+			go func(s, c int) {
+				defer wg.Done()
+				for i := 0; i < turns/2; i++ {
+					msg := fmt.Sprintf("s%d-c%d-%d", s, c, i)
+					answer, err := mgr.Chat(ctx, fmt.Sprintf("session-%d", s), msg)
+					if err != nil {
+						errs <- err
+					} else if answer != "echo: "+msg {
+						errs <- fmt.Errorf("expected echo of %s, got %s", msg, answer)
+					}
+				}
+			}(s, c)
+		}
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	for s := 0; s < sessions; s++ {
+		session, _ := mgr.Lookup(fmt.Sprintf("session-%d", s))
+		history, _ := session.GetHistory()
+		if len(history) != 2*turns {
+			t.Fatalf("Expected %d messages in session %d, got %d", 2*turns, s, len(history))
+		}
+		// Turns must not interleave: every answer follows its question
+		for i := 0; i < len(history); i += 2 {
+			if history[i+1].Content != "echo: "+history[i].Content {
+				t.Errorf("Session %d: turn %d interleaved: %q then %q", s, i/2, history[i].Content, history[i+1].Content)
+			}
+		}
+	}
+}
+
+func TestSessionManagerExpiryAndReload(t *testing.T) {
+	ctx := context.Background()
+
+	// Memories outlive the sessions, like a persistent store would
+	stores := make(map[string]types.Memory)
+	var mu sync.Mutex
+	mgr := NewSessionManager(newTestAgent(&echoProvider{}), SessionConfig{
+		TTL:         50 * time.Millisecond,
+		MaxSessions: 2,
+		NewMemory: func(id string) (types.Memory, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if mem, ok := stores[id]; ok {
+				return mem, nil
+			}
+			mem := memory.NewBuffer(100)
+			stores[id] = mem
+			return mem, nil
+		},
+	})
+
+	mgr.Chat(ctx, "a", "one")
+	time.Sleep(2 * time.Millisecond)
+	mgr.Chat(ctx, "b", "two")
+	time.Sleep(2 * time.Millisecond)
+	mgr.Chat(ctx, "c", "three") // Evicts "a", the least recently used
+	if _, ok := mgr.Lookup("a"); ok || mgr.Len() != 2 {
+		t.Errorf("Expected a to be evicted, got %d sessions", mgr.Len())
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if n := mgr.Expire(); n != 2 || mgr.Len() != 0 {
+		t.Errorf("Expected 2 expired sessions, got %d (%d left)", n, mgr.Len())
+	}
+
+	// Reloading brings the history back from the memory
+	a, _ := mgr.Get("a")
+	if history, _ := a.GetHistory(); len(history) != 2 || history[0].Content != "one" {
+		t.Errorf("Expected reloaded history, got %+v", history)
+	}
+
+	if !mgr.Unload("a") || mgr.Unload("a") {
+		t.Error("Expected Unload to report whether the session was loaded")
+	}
+	mgr.Get("a")
+	if err := mgr.Delete("a"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if stores["a"].Size() != 0 {
+		t.Error("Expected Delete to clear the session memory")
+	}
+	if err := mgr.Delete("a"); err == nil {
+		t.Error("Expected error deleting an unknown session")
+	}
+}
+
+func TestSessionManagerScopesLocalVectorMemory(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "memory.json")
+	local, err := memory.NewLocalVectorMemory(memory.LocalVectorMemoryConfig{Path: path, AutoSave: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mgr := NewSessionManager(newTestAgent(&echoProvider{}, WithMemory(local)), SessionConfig{})
+
+	mgr.Chat(ctx, "a", "one")
+	mgr.Chat(ctx, "b", "two")
+	mgr.Unload("a")
+
+	// Reloading reads the session's own file
+	a, _ := mgr.Get("a")
+	if history, _ := a.GetHistory(); len(history) != 2 || history[0].Content != "one" {
+		t.Errorf("Expected session a's history to be reloaded, got %+v", history)
+	}
+	if local.Size() != 0 {
+		t.Errorf("Expected the base memory to stay empty, got %d", local.Size())
+	}
+}
+
+func TestSessionUsageAndSharedBudget(t *testing.T) {
+	ctx := context.Background()
+	base := newTestAgent(&echoProvider{meta: usageMeta("m", 40, 10)}, WithBudget(Budget{MaxTokens: 120}))
+	mgr := NewSessionManager(base, SessionConfig{})
+
+	mgr.Chat(ctx, "a", "one")
+	mgr.Chat(ctx, "b", "two")
+
+	a, _ := mgr.Lookup("a")
+	if usage := a.Usage(); usage.TotalTokens != 50 || usage.Turn.TotalTokens != 50 {
+		t.Errorf("Expected 50 tokens for session a, got %+v", usage)
+	}
+	if usage := base.Usage(); usage.TotalTokens != 100 || usage.Calls != 2 {
+		t.Errorf("Expected sessions to add up on the agent, got %+v", usage)
+	}
+
+	// The lifetime budget is shared: the third call crosses it, the fourth is refused
+	mgr.Chat(ctx, "c", "three")
+	_, err := mgr.Chat(ctx, "a", "four")
+	if !errors.Is(err, ErrBudgetExceeded) {
+		t.Errorf("Expected shared budget to be exhausted, got %v", err)
+	}
+}
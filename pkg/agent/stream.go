@@ -0,0 +1,180 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/taipm/go-llm-agent/pkg/logger"
+	"github.com/taipm/go-llm-agent/pkg/types"
+)
+
+// StreamEventType identifies the kind of event emitted by ChatStreamEvents
+type StreamEventType string
+
+const (
+	// EventIterationStart marks the beginning of an LLM call in the agent loop
+	EventIterationStart StreamEventType = "iteration_start"
+	// EventTextDelta carries a piece of assistant text as it is generated
+	EventTextDelta StreamEventType = "text_delta"
+	// EventToolCallStarted is emitted before a requested tool call is executed
+	EventToolCallStarted StreamEventType = "tool_call_started"
+	// EventToolResult carries the outcome of a tool call
+	EventToolResult StreamEventType = "tool_result"
+	// EventFinal carries the final answer; no more events follow
+	EventFinal StreamEventType = "final"
+)
+
+// StreamEvent is a typed progress event from the streaming agent loop
+type StreamEvent struct {
+	Type      StreamEventType
+	Iteration int // 0-based agent loop iteration
+
+	// Content is the text delta (EventTextDelta), the tool result
+	// (EventToolResult) or the complete answer (EventFinal)
+	Content string
+
+	// ToolCall is set for EventToolCallStarted and EventToolResult
+	ToolCall *types.ToolCall
+
+	// Error is set on EventToolResult when the tool failed or was denied
+	Error error
+
+	// Metadata from the provider, set on EventFinal when available
+	Metadata *types.Metadata
+}
+
+// StreamEventHandler receives stream events; returning an error stops the loop
+type StreamEventHandler func(event StreamEvent) error
+
+// ChatStreamEvents runs the agent loop with streaming LLM calls, executing
+// tool calls across iterations until the model answers or MaxIterations is reached.
+//
+// Text is streamed as it arrives, so the answer is not passed through
+// self-reflection as it is with Chat.
+func (a *Agent) ChatStreamEvents(ctx context.Context, message string, handler StreamEventHandler) error {
+	logger.LogUserMessage(a.logger, message)
+	a.usage.beginTurn()
+
+	messages, chatOpts, err := a.prepareConversation(message)
+	if err != nil {
+		return err
+	}
+
+	answer, err := a.streamLoop(ctx, messages, chatOpts, handler)
+	if err != nil {
+		a.logger.Error("Agent execution failed: %v", err)
+		return err
+	}
+
+	logger.LogResponse(a.logger, answer)
+	return nil
+}
+
+// ChatStream sends a message and streams the response via callback.
+// Text deltas are delivered as chunks and the last chunk has Done set;
+// use ChatStreamEvents to also observe tool calls and iterations.
+func (a *Agent) ChatStream(ctx context.Context, message string, handler types.StreamHandler) error {
+	return a.ChatStreamEvents(ctx, message, func(event StreamEvent) error {
+		switch event.Type {
+		case EventTextDelta:
+			return handler(types.StreamChunk{Content: event.Content})
+		case EventFinal:
+			return handler(types.StreamChunk{Done: true, Metadata: event.Metadata})
+		}
+		return nil
+	})
+}
+
+// streamLoop is the streaming counterpart of runLoop
+func (a *Agent) streamLoop(ctx context.Context, messages []types.Message, opts *types.ChatOptions, handler StreamEventHandler) (string, error) {
+	currentMessages := make([]types.Message, len(messages))
+	copy(currentMessages, messages)
+
+	for iteration := 0; iteration < a.options.MaxIterations; iteration++ {
+		logger.LogIteration(a.logger, iteration, a.options.MaxIterations)
+
+		if err := handler(StreamEvent{Type: EventIterationStart, Iteration: iteration}); err != nil {
+			return "", err
+		}
+
+		// Stream the LLM call, forwarding text as it arrives
+		var content string
+		var toolCalls []types.ToolCall
+		var metadata *types.Metadata
+
+		window, windowOpts := a.fitContext(ctx, currentMessages, opts)
+		err := a.provider.Stream(ctx, window, windowOpts, func(chunk types.StreamChunk) error {
+			if chunk.Error != nil {
+				return chunk.Error
+			}
+			if len(chunk.ToolCalls) > 0 {
+				if chunk.Done {
+					// Final chunk carries the complete set of tool calls
+					toolCalls = chunk.ToolCalls
+				} else {
+					toolCalls = append(toolCalls, chunk.ToolCalls...)
+				}
+			}
+			if chunk.Metadata != nil {
+				metadata = chunk.Metadata
+			}
+			if chunk.Content == "" {
+				return nil
+			}
+			content += chunk.Content
+			return handler(StreamEvent{Type: EventTextDelta, Iteration: iteration, Content: chunk.Content})
+		})
+		if err != nil {
+			return "", fmt.Errorf("streaming failed: %w", err)
+		}
+
+		// If no tool calls, we're done
+		if len(toolCalls) == 0 {
+			if a.memory != nil {
+				finalMsg := types.Message{
+					Role:    types.RoleAssistant,
+					Content: content,
+				}
+				if err := a.memory.Add(finalMsg); err != nil {
+					return "", fmt.Errorf("failed to add final response to memory: %w", err)
+				}
+				a.logger.Debug("💾 Saved assistant response to memory")
+			}
+
+			if err := handler(StreamEvent{Type: EventFinal, Iteration: iteration, Content: content, Metadata: metadata}); err != nil {
+				return "", err
+			}
+			return content, nil
+		}
+
+		a.logger.Info("🔧 Agent wants to call %d tool(s): %s", len(toolCalls), logger.FormatToolCalls(toolCalls))
+
+		for i := range toolCalls {
+			if err := handler(StreamEvent{Type: EventToolCallStarted, Iteration: iteration, ToolCall: &toolCalls[i]}); err != nil {
+				return "", err
+			}
+		}
+
+		results := a.executeToolCalls(ctx, toolCalls)
+
+		for _, res := range results {
+			call := res.call
+			if err := handler(StreamEvent{
+				Type:      EventToolResult,
+				Iteration: iteration,
+				ToolCall:  &call,
+				Content:   res.message().Content,
+				Error:     res.err,
+			}); err != nil {
+				return "", err
+			}
+		}
+
+		currentMessages, err = a.recordToolRound(currentMessages, content, results)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return "", fmt.Errorf("max iterations (%d) reached", a.options.MaxIterations)
+}
@@ -0,0 +1,176 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/taipm/go-llm-agent/pkg/types"
+)
+
+func TestChatStreamEventsToolLoop(t *testing.T) {
+	var active, peak int32
+	var finished []string
+	var mu sync.Mutex
+
+	provider := &mockProvider{responses: []*types.Response{
+		{Content: "Let me check. ", ToolCalls: []types.ToolCall{toolCall("c1", "lookup"), toolCall("c2", "lookup")}},
+		{ToolCalls: []types.ToolCall{toolCall("c3", "lookup")}},
+		{Content: "The answer is 42", Metadata: &types.Metadata{Model: "mock", TotalTokens: 10}},
+	}}
+
+	ag := newTestAgent(provider)
+	ag.AddTool(newSleepTool("lookup", 0, true, &active, &peak, &finished, &mu))
+
+	var events []StreamEvent
+	err := ag.ChatStreamEvents(context.Background(), "what is the answer?", func(event StreamEvent) error {
+		events = append(events, event)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ChatStreamEvents failed: %v", err)
+	}
+
+	var kinds []string
+	var text strings.Builder
+	for _, e := range events {
+		kinds = append(kinds, string(e.Type))
+		if e.Type == EventTextDelta {
+			text.WriteString(e.Content)
+		}
+	}
+
+	want := []string{
+		"iteration_start", "text_delta", "text_delta", "text_delta",
+		"tool_call_started", "tool_call_started", "tool_result", "tool_result",
+		"iteration_start",
+		"tool_call_started", "tool_result",
+		"iteration_start", "text_delta", "text_delta", "text_delta", "text_delta",
+		"final",
+	}
+	if strings.Join(kinds, ",") != strings.Join(want, ",") {
+		t.Errorf("Unexpected event sequence:\n got: %v\nwant: %v", kinds, want)
+	}
+
+	final := events[len(events)-1]
+	if final.Content != "The answer is 42" || final.Iteration != 2 {
+		t.Errorf("Expected final answer in iteration 2, got %q (iteration %d)", final.Content, final.Iteration)
+	}
+	if final.Metadata == nil || final.Metadata.TotalTokens != 10 {
+		t.Errorf("Expected metadata on final event, got %+v", final.Metadata)
+	}
+	if text.String() != "Let me check. The answer is 42" {
+		t.Errorf("Unexpected streamed text: %q", text.String())
+	}
+
+	// Tool results are ordered and reference their call
+	if events[6].ToolCall.ID != "c1" || events[7].ToolCall.ID != "c2" || events[6].Content != "done c1" {
+		t.Errorf("Expected ordered tool results for c1, c2, got %+v / %+v", events[6], events[7])
+	}
+
+	// The last LLM call saw both tool rounds
+	last := provider.received[len(provider.received)-1]
+	var toolMsgs int
+	for _, m := range last {
+		if m.Role == types.RoleTool {
+			toolMsgs++
+		}
+	}
+	if toolMsgs != 3 {
+		t.Errorf("Expected 3 tool results in conversation, got %d", toolMsgs)
+	}
+
+	// Conversation is persisted like Chat does: user, assistant+tools, tool x2, assistant+tool, tool, answer
+	history, _ := ag.GetHistory()
+	if len(history) != 7 {
+		t.Errorf("Expected 7 messages in memory, got %d", len(history))
+	}
+	if history[0].Content != "what is the answer?" || history[6].Content != "The answer is 42" {
+		t.Errorf("Unexpected history: %+v", history)
+	}
+}
+
+func TestChatStreamEventsMaxIterations(t *testing.T) {
+	var active, peak int32
+	var finished []string
+	var mu sync.Mutex
+
+	loop := &types.Response{ToolCalls: []types.ToolCall{toolCall("c", "lookup")}}
+	provider := &mockProvider{responses: []*types.Response{loop, loop, loop, loop}}
+
+	ag := newTestAgent(provider)
+	ag.options.MaxIterations = 2
+	ag.AddTool(newSleepTool("lookup", 0, true, &active, &peak, &finished, &mu))
+
+	iterations := 0
+	err := ag.ChatStreamEvents(context.Background(), "loop", func(event StreamEvent) error {
+		if event.Type == EventIterationStart {
+			iterations++
+		}
+		return nil
+	})
+	if err == nil || !strings.Contains(err.Error(), "max iterations (2)") {
+		t.Errorf("Expected max iterations error, got %v", err)
+	}
+	if iterations != 2 {
+		t.Errorf("Expected 2 iterations, got %d", iterations)
+	}
+}
+
+func TestChatStreamEventsHandlerError(t *testing.T) {
+	provider := &mockProvider{responses: []*types.Response{{Content: "hello there"}}}
+	ag := newTestAgent(provider)
+
+	stop := errors.New("stop")
+	err := ag.ChatStreamEvents(context.Background(), "hi", func(event StreamEvent) error {
+		if event.Type == EventTextDelta {
+			return stop
+		}
+		return nil
+	})
+	if !errors.Is(err, stop) {
+		t.Errorf("Expected handler error, got %v", err)
+	}
+}
+
+func TestChatStreamChunks(t *testing.T) {
+	var active, peak int32
+	var finished []string
+	var mu sync.Mutex
+
+	provider := &mockProvider{responses: []*types.Response{
+		{ToolCalls: []types.ToolCall{toolCall("c1", "lookup")}},
+		{Content: "done now"},
+	}}
+	ag := newTestAgent(provider)
+	ag.AddTool(newSleepTool("lookup", 0, true, &active, &peak, &finished, &mu))
+
+	var content string
+	var doneChunks int
+	err := ag.ChatStream(context.Background(), "go", func(chunk types.StreamChunk) error {
+		content += chunk.Content
+		if chunk.Done {
+			doneChunks++
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ChatStream failed: %v", err)
+	}
+	if content != "done now" {
+		t.Errorf("Expected streamed content 'done now', got %q", content)
+	}
+	if doneChunks != 1 {
+		t.Errorf("Expected exactly one Done chunk, got %d", doneChunks)
+	}
+
+	// No empty user message is added to memory anymore
+	history, _ := ag.GetHistory()
+	for _, m := range history {
+		if m.Role == types.RoleUser && m.Content == "" {
+			t.Error("Expected no empty user message in history")
+		}
+	}
+}
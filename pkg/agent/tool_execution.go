@@ -0,0 +1,148 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/taipm/go-llm-agent/pkg/logger"
+	"github.com/taipm/go-llm-agent/pkg/tools"
+	"github.com/taipm/go-llm-agent/pkg/types"
+)
+
+// toolCallResult holds the outcome of a single tool call
+type toolCallResult struct {
+	call   types.ToolCall
+	result interface{}
+	err    error
+}
+
+// message converts the result into a tool message for the conversation
+func (r toolCallResult) message() types.Message {
+	result := r.result
+	if r.err != nil {
+		// Return error as tool result
+		result = map[string]interface{}{
+			"error": r.err.Error(),
+		}
+	}
+
+	return types.Message{
+		Role:    types.RoleTool,
+		Content: fmt.Sprintf("%v", result),
+		ToolID:  r.call.ID,
+	}
+}
+
+// executeToolCalls runs the tool calls of one assistant turn and returns
+// the results in the original call order.
+//
+// Approval is requested first, one call at a time. Approved calls then run:
+// consecutive concurrency-safe calls in parallel (up to MaxParallelTools);
+// a tool that is not concurrency-safe (see tools.IsConcurrencySafe) waits for
+// the calls before it and runs on its own.
+func (a *Agent) executeToolCalls(ctx context.Context, calls []types.ToolCall) []toolCallResult {
+	results := make([]toolCallResult, len(calls))
+
+	// Ask for approval sequentially so interactive handlers see one request at a time
+	approved := make([]bool, len(calls))
+	for i, call := range calls {
+		reviewed, err := a.reviewToolCall(ctx, call)
+		results[i] = toolCallResult{call: reviewed, err: err}
+		approved[i] = err == nil
+	}
+
+	maxParallel := a.options.MaxParallelTools
+	if maxParallel < 1 {
+		maxParallel = 1
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, maxParallel)
+
+	for i := range calls {
+		if !approved[i] {
+			logger.LogToolResult(a.logger, calls[i].Function.Name, false, results[i].err)
+			continue
+		}
+
+		call := results[i].call
+		if !a.canRunConcurrently(call) || maxParallel == 1 {
+			// Barrier: wait for in-flight calls, then run this one alone
+			wg.Wait()
+			results[i] = a.executeToolCall(ctx, call)
+			continue
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, call types.ToolCall) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = a.executeToolCall(ctx, call)
+		}(i, call)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// canRunConcurrently reports whether a tool call may run in parallel with others
+func (a *Agent) canRunConcurrently(call types.ToolCall) bool {
+	tool := a.tools.Get(call.Function.Name)
+	if tool == nil {
+		// Unknown tools fail immediately, no need to serialize them
+		return true
+	}
+	return tools.IsConcurrencySafe(tool)
+}
+
+// executeToolCall executes a single tool call, honouring ToolTimeout
+func (a *Agent) executeToolCall(ctx context.Context, call types.ToolCall) toolCallResult {
+	// Log tool call
+	logger.LogToolCall(a.logger, call.Function.Name, call.Function.Arguments)
+
+	var result interface{}
+	var err error
+	if a.options.ToolTimeout > 0 {
+		result, err = a.executeWithTimeout(ctx, call)
+	} else {
+		result, err = a.tools.Execute(ctx, call.Function.Name, call.Function.Arguments)
+	}
+
+	if err != nil {
+		logger.LogToolResult(a.logger, call.Function.Name, false, err)
+	} else {
+		logger.LogToolResult(a.logger, call.Function.Name, true, result)
+	}
+
+	return toolCallResult{call: call, result: result, err: err}
+}
+
+// executeWithTimeout runs the tool in its own goroutine so that tools which
+// ignore context cancellation cannot block the agent past ToolTimeout
+func (a *Agent) executeWithTimeout(ctx context.Context, call types.ToolCall) (interface{}, error) {
+	ctx, cancel := context.WithTimeout(ctx, a.options.ToolTimeout)
+	defer cancel()
+
+	type outcome struct {
+		result interface{}
+		err    error
+	}
+	done := make(chan outcome, 1)
+
+	go func() {
+		result, err := a.tools.Execute(ctx, call.Function.Name, call.Function.Arguments)
+		done <- outcome{result, err}
+	}()
+
+	select {
+	case out := <-done:
+		return out.result, out.err
+	case <-ctx.Done():
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("tool %s timed out after %v: %w", call.Function.Name, a.options.ToolTimeout, ctx.Err())
+		}
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,276 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/taipm/go-llm-agent/pkg/memory"
+	"github.com/taipm/go-llm-agent/pkg/tools"
+	"github.com/taipm/go-llm-agent/pkg/types"
+)
+
+// mockProvider returns scripted responses and records the messages it was sent
+type mockProvider struct {
+	mu        sync.Mutex
+	responses []*types.Response
+	calls     int
+	received  [][]types.Message
+}
+
+func (m *mockProvider) Chat(ctx context.Context, messages []types.Message, options *types.ChatOptions) (*types.Response, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.received = append(m.received, append([]types.Message(nil), messages...))
+	if m.calls >= len(m.responses) {
+		return &types.Response{Content: "Final answer"}, nil
+	}
+	resp := m.responses[m.calls]
+	m.calls++
+	return resp, nil
+}
+
+func (m *mockProvider) Stream(ctx context.Context, messages []types.Message, options *types.ChatOptions, handler types.StreamHandler) error {
+	resp, err := m.Chat(ctx, messages, options)
+	if err != nil {
+		return err
+	}
+	// Stream the content word by word, tool calls arrive with the final chunk
+	for i, word := range strings.SplitAfter(resp.Content, " ") {
+		if word == "" {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := handler(types.StreamChunk{Content: word}); err != nil {
+			return fmt.Errorf("handler error at chunk %d: %w", i, err)
+		}
+	}
+	return handler(types.StreamChunk{ToolCalls: resp.ToolCalls, Done: true, Metadata: resp.Metadata})
+}
+
+// sleepTool sleeps for the given duration and tracks peak concurrency
+type sleepTool struct {
+	tools.BaseTool
+	delay    time.Duration
+	active   *int32
+	peak     *int32
+	finished *[]string
+	mu       *sync.Mutex
+}
+
+func newSleepTool(name string, delay time.Duration, safe bool, active, peak *int32, finished *[]string, mu *sync.Mutex) *sleepTool {
+	return &sleepTool{
+		BaseTool: tools.NewBaseTool(name, "Sleeps and echoes its id", tools.CategoryData, false, safe),
+		delay:    delay,
+		active:   active,
+		peak:     peak,
+		finished: finished,
+		mu:       mu,
+	}
+}
+
+func (t *sleepTool) Parameters() *types.JSONSchema {
+	return &types.JSONSchema{Type: "object"}
+}
+
+func (t *sleepTool) Execute(ctx context.Context, params map[string]interface{}) (interface{}, error) {
+	n := atomic.AddInt32(t.active, 1)
+	defer atomic.AddInt32(t.active, -1)
+	for {
+		p := atomic.LoadInt32(t.peak)
+		if n <= p || atomic.CompareAndSwapInt32(t.peak, p, n) {
+			break
+		}
+	}
+
+	// Only honour the delay, ignore ctx to exercise the timeout path
+	time.Sleep(t.delay)
+
+	id := fmt.Sprintf("%v", params["id"])
+	t.mu.Lock()
+	*t.finished = append(*t.finished, id)
+	t.mu.Unlock()
+	return "done " + id, nil
+}
+
+func toolCall(id, name string) types.ToolCall {
+	return types.ToolCall{
+		ID:       id,
+		Type:     "function",
+		Function: types.FunctionCall{Name: name, Arguments: map[string]interface{}{"id": id}},
+	}
+}
+
+func newTestAgent(provider types.LLMProvider, opts ...Option) *Agent {
+	base := []Option{
+		WithMemory(memory.NewBuffer(100)),
+		WithoutBuiltinTools(),
+		WithoutAutoReasoning(),
+		WithReflection(false),
+		WithLearning(false),
+		DisableLogging(),
+	}
+	return New(provider, append(base, opts...)...)
+}
+
+func TestExecuteToolCallsParallel(t *testing.T) {
+	var active, peak int32
+	var finished []string
+	var mu sync.Mutex
+
+	ag := newTestAgent(&mockProvider{}, WithMaxParallelTools(3))
+	ag.AddTool(newSleepTool("slow", 50*time.Millisecond, true, &active, &peak, &finished, &mu))
+
+	calls := []types.ToolCall{
+		toolCall("c1", "slow"), toolCall("c2", "slow"), toolCall("c3", "slow"),
+		toolCall("c4", "slow"), toolCall("c5", "slow"),
+	}
+
+	start := time.Now()
+	results := ag.executeToolCalls(context.Background(), calls)
+	elapsed := time.Since(start)
+
+	if peak > 3 {
+		t.Errorf("Expected at most 3 concurrent calls, got %d", peak)
+	}
+	if peak < 2 {
+		t.Errorf("Expected calls to run concurrently, peak was %d", peak)
+	}
+	if elapsed >= 250*time.Millisecond {
+		t.Errorf("Expected parallel execution to be faster than sequential, took %v", elapsed)
+	}
+
+	for i, res := range results {
+		if res.call.ID != calls[i].ID {
+			t.Errorf("Result %d: expected call %s, got %s", i, calls[i].ID, res.call.ID)
+		}
+		if want := "done " + calls[i].ID; res.result != want {
+			t.Errorf("Result %d: expected %q, got %v", i, want, res.result)
+		}
+	}
+}
+
+func TestExecuteToolCallsSequentialTool(t *testing.T) {
+	var active, peak int32
+	var finished []string
+	var mu sync.Mutex
+
+	ag := newTestAgent(&mockProvider{}, WithMaxParallelTools(4))
+	ag.AddTool(newSleepTool("read", 30*time.Millisecond, true, &active, &peak, &finished, &mu))
+	ag.AddTool(newSleepTool("write", 10*time.Millisecond, false, &active, &peak, &finished, &mu))
+
+	calls := []types.ToolCall{
+		toolCall("r1", "read"), toolCall("r2", "read"),
+		toolCall("w1", "write"),
+		toolCall("r3", "read"),
+	}
+	ag.executeToolCalls(context.Background(), calls)
+
+	// The write must start after both reads finished and before the last read
+	order := strings.Join(finished, ",")
+	if !strings.HasSuffix(order, "w1,r3") || len(finished) != 4 {
+		t.Errorf("Expected non-concurrent tool to run alone in order, got %s", order)
+	}
+}
+
+func TestExecuteToolCallsMaxParallelOne(t *testing.T) {
+	var active, peak int32
+	var finished []string
+	var mu sync.Mutex
+
+	ag := newTestAgent(&mockProvider{}, WithMaxParallelTools(1))
+	ag.AddTool(newSleepTool("slow", 5*time.Millisecond, true, &active, &peak, &finished, &mu))
+
+	ag.executeToolCalls(context.Background(), []types.ToolCall{
+		toolCall("a", "slow"), toolCall("b", "slow"), toolCall("c", "slow"),
+	})
+
+	if peak != 1 {
+		t.Errorf("Expected sequential execution, peak concurrency was %d", peak)
+	}
+	if got := strings.Join(finished, ","); got != "a,b,c" {
+		t.Errorf("Expected order a,b,c, got %s", got)
+	}
+}
+
+func TestExecuteToolCallTimeout(t *testing.T) {
+	var active, peak int32
+	var finished []string
+	var mu sync.Mutex
+
+	ag := newTestAgent(&mockProvider{}, WithToolTimeout(20*time.Millisecond))
+	ag.AddTool(newSleepTool("hang", time.Second, true, &active, &peak, &finished, &mu))
+
+	start := time.Now()
+	res := ag.executeToolCall(context.Background(), toolCall("h1", "hang"))
+	if res.err == nil || !strings.Contains(res.err.Error(), "timed out") {
+		t.Errorf("Expected timeout error, got %v", res.err)
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Error("Expected tool call to be abandoned after the timeout")
+	}
+	if msg := res.message(); !strings.Contains(msg.Content, "error") || msg.ToolID != "h1" {
+		t.Errorf("Expected error tool message for h1, got %+v", msg)
+	}
+}
+
+func TestRunLoopToolResultOrder(t *testing.T) {
+	var active, peak int32
+	var finished []string
+	var mu sync.Mutex
+
+	provider := &mockProvider{responses: []*types.Response{
+		{ToolCalls: []types.ToolCall{toolCall("c1", "slow"), toolCall("c2", "fast"), toolCall("c3", "missing")}},
+		{Content: "all done"},
+	}}
+
+	ag := newTestAgent(provider)
+	ag.AddTool(newSleepTool("slow", 40*time.Millisecond, true, &active, &peak, &finished, &mu))
+	ag.AddTool(newSleepTool("fast", time.Millisecond, true, &active, &peak, &finished, &mu))
+
+	answer, err := ag.Chat(context.Background(), "run the tools")
+	if err != nil {
+		t.Fatalf("Chat failed: %v", err)
+	}
+	if answer != "all done" {
+		t.Errorf("Expected 'all done', got '%s'", answer)
+	}
+
+	// Second LLM call sees: user, assistant(tool calls), then tool results in call order
+	if len(provider.received) != 2 {
+		t.Fatalf("Expected 2 LLM calls, got %d", len(provider.received))
+	}
+	msgs := provider.received[1]
+	var toolIDs []string
+	for _, m := range msgs {
+		if m.Role == types.RoleTool {
+			toolIDs = append(toolIDs, m.ToolID)
+		}
+	}
+	if got := strings.Join(toolIDs, ","); got != "c1,c2,c3" {
+		t.Errorf("Expected tool results in call order c1,c2,c3, got %s", got)
+	}
+	if last := msgs[len(msgs)-1]; !strings.Contains(last.Content, "error") {
+		t.Errorf("Expected unknown tool to produce an error result, got %s", last.Content)
+	}
+}
+
+func TestIsConcurrencySafe(t *testing.T) {
+	var active, peak int32
+	var finished []string
+	var mu sync.Mutex
+
+	if !tools.IsConcurrencySafe(newSleepTool("a", 0, true, &active, &peak, &finished, &mu)) {
+		t.Error("Expected safe tool to be concurrency-safe")
+	}
+	if tools.IsConcurrencySafe(newSleepTool("b", 0, false, &active, &peak, &finished, &mu)) {
+		t.Error("Expected unsafe tool to run sequentially")
+	}
+}
@@ -0,0 +1,350 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/taipm/go-llm-agent/pkg/types"
+)
+
+// Usage sources identify which part of the agent made a provider call
+const (
+	UsageSourceChat       = "chat"
+	UsageSourceCoT        = "cot"
+	UsageSourceReAct      = "react"
+	UsageSourceReflection = "reflection"
+	UsageSourcePlanner    = "planner"
+	UsageSourceSummary    = "summary"
+)
+
+// ErrBudgetExceeded is returned (wrapped in *BudgetExceededError) when a
+// token or cost budget is exhausted
+var ErrBudgetExceeded = errors.New("usage budget exceeded")
+
+// TokenUsage accumulates token counts and cost
+type TokenUsage struct {
+	Calls            int     `json:"calls"`
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	TotalTokens      int     `json:"total_tokens"`
+	Cost             float64 `json:"cost"` // In the currency of the price table (0 if no price is known)
+}
+
+func (u *TokenUsage) add(meta *types.Metadata, cost float64) {
+	u.Calls++
+	u.Cost += cost
+	if meta == nil {
+		return
+	}
+	u.PromptTokens += meta.PromptTokens
+	u.CompletionTokens += meta.CompletionTokens
+	total := meta.TotalTokens
+	if total == 0 {
+		total = meta.PromptTokens + meta.CompletionTokens
+	}
+	u.TotalTokens += total
+}
+
+// Usage is a snapshot of the agent's token usage and cost
+type Usage struct {
+	TokenUsage
+
+	// Turn is the usage of the current (or last) Chat/ChatStream call
+	Turn TokenUsage `json:"turn"`
+
+	// BySource breaks usage down by agent component (chat, cot, react, reflection, planner, summary)
+	BySource map[string]TokenUsage `json:"by_source,omitempty"`
+
+	// ByModel breaks usage down by the model reported in the response metadata
+	ByModel map[string]TokenUsage `json:"by_model,omitempty"`
+}
+
+// ModelPrice is the price of a model per million tokens
+type ModelPrice struct {
+	InputPerMillion  float64 // Price per 1M prompt tokens
+	OutputPerMillion float64 // Price per 1M completion tokens
+}
+
+// PriceTable maps model names to prices. A model matches its exact name or,
+// failing that, the longest entry that prefixes it (so "gpt-4o" also prices
+// "gpt-4o-2024-08-06").
+type PriceTable map[string]ModelPrice
+
+// Cost computes the cost of a call. Returns false if the model has no price.
+func (t PriceTable) Cost(model string, promptTokens, completionTokens int) (float64, bool) {
+	price, ok := t[model]
+	if !ok {
+		best := ""
+		for name, p := range t {
+			if strings.HasPrefix(model, name) && len(name) > len(best) {
+				best, price, ok = name, p, true
+			}
+		}
+	}
+	if !ok {
+		return 0, false
+	}
+	return float64(promptTokens)/1e6*price.InputPerMillion + float64(completionTokens)/1e6*price.OutputPerMillion, true
+}
+
+// Budget limits token usage and cost. Zero values mean unlimited.
+// Limits are checked before each provider call, so the call that crosses a
+// limit completes and the next one is refused.
+type Budget struct {
+	MaxTokens     int     // Total tokens over the agent's lifetime (until ResetUsage)
+	MaxCost       float64 // Total cost over the agent's lifetime (until ResetUsage)
+	MaxTurnTokens int     // Tokens per Chat/ChatStream call or plan execution
+	MaxTurnCost   float64 // Cost per Chat/ChatStream call or plan execution
+}
+
+// BudgetExceededError reports which budget limit was hit
+type BudgetExceededError struct {
+	Limit string  // "tokens", "cost", "turn_tokens" or "turn_cost"
+	Used  float64 // Amount used so far
+	Max   float64 // Configured limit
+}
+
+func (e *BudgetExceededError) Error() string {
+	return fmt.Sprintf("%v: %s used %.4g of %.4g", ErrBudgetExceeded, e.Limit, e.Used, e.Max)
+}
+
+func (e *BudgetExceededError) Unwrap() error {
+	return ErrBudgetExceeded
+}
+
+// WithPriceTable sets the model prices used to compute cost
+func WithPriceTable(prices PriceTable) Option {
+	return func(a *Agent) {
+		a.usage.mu.Lock()
+		defer a.usage.mu.Unlock()
+		a.usage.prices = prices
+	}
+}
+
+// WithBudget sets token and cost limits; a turn that exhausts them is aborted
+// with an error wrapping ErrBudgetExceeded
+func WithBudget(budget Budget) Option {
+	return func(a *Agent) {
+		a.usage.mu.Lock()
+		defer a.usage.mu.Unlock()
+		a.usage.budget = budget
+	}
+}
+
+// Usage returns the token usage and cost accumulated across all provider calls
+func (a *Agent) Usage() Usage {
+	return a.usage.snapshot()
+}
+
+// ResetUsage clears the accumulated usage (and with it the lifetime budget)
+func (a *Agent) ResetUsage() {
+	a.usage.reset()
+}
+
+// usageTracker accumulates usage and enforces budgets
+type usageTracker struct {
+	mu       sync.Mutex
+	total    TokenUsage
+	turn     TokenUsage
+	bySource map[string]TokenUsage
+	byModel  map[string]TokenUsage
+	prices   PriceTable
+	budget   Budget
+
+	// parent also receives every call and enforces its lifetime budget
+	// (session trackers report to the agent they were created from)
+	parent *usageTracker
+}
+
+func newUsageTracker() *usageTracker {
+	return &usageTracker{
+		bySource: make(map[string]TokenUsage),
+		byModel:  make(map[string]TokenUsage),
+	}
+}
+
+// child creates a tracker whose calls also count towards t. The child keeps
+// t's prices and per-turn limits; lifetime limits are enforced by t.
+func (t *usageTracker) child() *usageTracker {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	c := newUsageTracker()
+	c.prices = t.prices
+	c.budget = Budget{MaxTurnTokens: t.budget.MaxTurnTokens, MaxTurnCost: t.budget.MaxTurnCost}
+	c.parent = t
+	return c
+}
+
+// record adds the usage reported by one provider call
+func (t *usageTracker) record(source string, meta *types.Metadata) {
+	t.add(source, meta, true)
+	if t.parent != nil {
+		t.parent.add(source, meta, false)
+	}
+}
+
+// add accumulates one call; turn reports whether it counts towards the current turn
+func (t *usageTracker) add(source string, meta *types.Metadata, turn bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var cost float64
+	model := ""
+	if meta != nil {
+		model = meta.Model
+		cost, _ = t.prices.Cost(model, meta.PromptTokens, meta.CompletionTokens)
+	}
+
+	t.total.add(meta, cost)
+	if turn {
+		t.turn.add(meta, cost)
+	}
+
+	s := t.bySource[source]
+	s.add(meta, cost)
+	t.bySource[source] = s
+
+	if model != "" {
+		m := t.byModel[model]
+		m.add(meta, cost)
+		t.byModel[model] = m
+	}
+}
+
+// check returns a *BudgetExceededError if any budget is exhausted
+func (t *usageTracker) check() error {
+	if t.parent != nil {
+		if err := t.parent.check(); err != nil {
+			return err
+		}
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	b := t.budget
+	switch {
+	case b.MaxTokens > 0 && t.total.TotalTokens >= b.MaxTokens:
+		return &BudgetExceededError{Limit: "tokens", Used: float64(t.total.TotalTokens), Max: float64(b.MaxTokens)}
+	case b.MaxCost > 0 && t.total.Cost >= b.MaxCost:
+		return &BudgetExceededError{Limit: "cost", Used: t.total.Cost, Max: b.MaxCost}
+	case b.MaxTurnTokens > 0 && t.turn.TotalTokens >= b.MaxTurnTokens:
+		return &BudgetExceededError{Limit: "turn_tokens", Used: float64(t.turn.TotalTokens), Max: float64(b.MaxTurnTokens)}
+	case b.MaxTurnCost > 0 && t.turn.Cost >= b.MaxTurnCost:
+		return &BudgetExceededError{Limit: "turn_cost", Used: t.turn.Cost, Max: b.MaxTurnCost}
+	}
+	return nil
+}
+
+// beginTurn resets the per-turn counters
+func (t *usageTracker) beginTurn() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.turn = TokenUsage{}
+}
+
+// currentTurn returns the usage of the current turn
+func (t *usageTracker) currentTurn() TokenUsage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.turn
+}
+
+func (t *usageTracker) reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.total = TokenUsage{}
+	t.turn = TokenUsage{}
+	t.bySource = make(map[string]TokenUsage)
+	t.byModel = make(map[string]TokenUsage)
+}
+
+func (t *usageTracker) snapshot() Usage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	u := Usage{
+		TokenUsage: t.total,
+		Turn:       t.turn,
+		BySource:   make(map[string]TokenUsage, len(t.bySource)),
+		ByModel:    make(map[string]TokenUsage, len(t.byModel)),
+	}
+	for k, v := range t.bySource {
+		u.BySource[k] = v
+	}
+	for k, v := range t.byModel {
+		u.ByModel[k] = v
+	}
+	return u
+}
+
+// turnKey marks contexts in which a turn is already running
+type turnKey struct{}
+
+// withinTurn makes Chat calls with ctx continue the current turn instead of
+// starting a new one (plan steps are part of the plan execution's turn)
+func withinTurn(ctx context.Context) context.Context {
+	return context.WithValue(ctx, turnKey{}, true)
+}
+
+func inTurn(ctx context.Context) bool {
+	within, _ := ctx.Value(turnKey{}).(bool)
+	return within
+}
+
+// usageSourceKey is the context key for the usage source
+type usageSourceKey struct{}
+
+// withUsageSource tags provider calls made with ctx as coming from source
+func withUsageSource(ctx context.Context, source string) context.Context {
+	return context.WithValue(ctx, usageSourceKey{}, source)
+}
+
+func usageSource(ctx context.Context) string {
+	if source, ok := ctx.Value(usageSourceKey{}).(string); ok {
+		return source
+	}
+	return UsageSourceChat
+}
+
+// meteredProvider records usage and enforces budgets for every call made
+// through the agent, including those from the reasoning engines
+type meteredProvider struct {
+	next    types.LLMProvider
+	tracker *usageTracker
+}
+
+// Chat implements the LLMProvider interface
+func (p *meteredProvider) Chat(ctx context.Context, messages []types.Message, options *types.ChatOptions) (*types.Response, error) {
+	if err := p.tracker.check(); err != nil {
+		return nil, err
+	}
+
+	resp, err := p.next.Chat(ctx, messages, options)
+	if err != nil {
+		return nil, err
+	}
+	p.tracker.record(usageSource(ctx), resp.Metadata)
+	return resp, nil
+}
+
+// Stream implements the LLMProvider interface
+func (p *meteredProvider) Stream(ctx context.Context, messages []types.Message, options *types.ChatOptions, handler types.StreamHandler) error {
+	if err := p.tracker.check(); err != nil {
+		return err
+	}
+
+	var meta *types.Metadata
+	err := p.next.Stream(ctx, messages, options, func(chunk types.StreamChunk) error {
+		if chunk.Metadata != nil {
+			meta = chunk.Metadata
+		}
+		return handler(chunk)
+	})
+	p.tracker.record(usageSource(ctx), meta)
+	return err
+}
@@ -0,0 +1,203 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"testing"
+
+	"github.com/taipm/go-llm-agent/pkg/reasoning"
+	"github.com/taipm/go-llm-agent/pkg/types"
+)
+
+func usageMeta(model string, prompt, completion int) *types.Metadata {
+	return &types.Metadata{Model: model, PromptTokens: prompt, CompletionTokens: completion, TotalTokens: prompt + completion}
+}
+
+func TestPriceTableCost(t *testing.T) {
+	prices := PriceTable{
+		"gpt-4o":      {InputPerMillion: 2.5, OutputPerMillion: 10},
+		"gpt-4o-mini": {InputPerMillion: 0.15, OutputPerMillion: 0.6},
+	}
+
+	tests := []struct {
+		model  string
+		want   float64
+		wantOK bool
+	}{
+		{"gpt-4o", 2.5 + 10, true},
+		{"gpt-4o-2024-08-06", 2.5 + 10, true},
+		{"gpt-4o-mini-2024-07-18", 0.15 + 0.6, true},
+		{"llama3.2", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			got, ok := prices.Cost(tt.model, 1_000_000, 1_000_000)
+			if ok != tt.wantOK || math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Expected (%v, %v), got (%v, %v)", tt.want, tt.wantOK, got, ok)
+			}
+		})
+	}
+}
+
+func TestUsageAccumulatesAcrossToolLoop(t *testing.T) {
+	var active, peak int32
+	var finished []string
+	var mu sync.Mutex
+
+	provider := &mockProvider{responses: []*types.Response{
+		{ToolCalls: []types.ToolCall{toolCall("c1", "lookup")}, Metadata: usageMeta("gpt-4o", 100, 20)},
+		{Content: "done", Metadata: usageMeta("gpt-4o", 150, 30)},
+	}}
+	ag := newTestAgent(provider, WithPriceTable(PriceTable{
+		"gpt-4o": {InputPerMillion: 2.5, OutputPerMillion: 10},
+	}))
+	ag.AddTool(newSleepTool("lookup", 0, true, &active, &peak, &finished, &mu))
+
+	if _, err := ag.Chat(context.Background(), "look it up"); err != nil {
+		t.Fatalf("Chat failed: %v", err)
+	}
+
+	usage := ag.Usage()
+	if usage.Calls != 2 || usage.PromptTokens != 250 || usage.CompletionTokens != 50 || usage.TotalTokens != 300 {
+		t.Errorf("Unexpected totals: %+v", usage.TokenUsage)
+	}
+	wantCost := 250*2.5/1e6 + 50*10/1e6
+	if math.Abs(usage.Cost-wantCost) > 1e-12 {
+		t.Errorf("Expected cost %v, got %v", wantCost, usage.Cost)
+	}
+	if usage.Turn.TotalTokens != 300 {
+		t.Errorf("Expected turn usage 300, got %d", usage.Turn.TotalTokens)
+	}
+	if usage.BySource[UsageSourceChat].Calls != 2 || usage.ByModel["gpt-4o"].TotalTokens != 300 {
+		t.Errorf("Unexpected breakdown: %+v / %+v", usage.BySource, usage.ByModel)
+	}
+
+	// A new turn resets the turn counters only
+	provider.responses = append(provider.responses, &types.Response{Content: "again", Metadata: usageMeta("gpt-4o", 10, 5)})
+	if _, err := ag.Chat(context.Background(), "again"); err != nil {
+		t.Fatalf("Chat failed: %v", err)
+	}
+	usage = ag.Usage()
+	if usage.Turn.TotalTokens != 15 || usage.TotalTokens != 315 {
+		t.Errorf("Expected turn 15 / total 315, got %d / %d", usage.Turn.TotalTokens, usage.TotalTokens)
+	}
+	if status := ag.Status(); status.Usage.TotalTokens != 315 {
+		t.Errorf("Expected usage in status, got %+v", status.Usage)
+	}
+
+	ag.ResetUsage()
+	if usage := ag.Usage(); usage.Calls != 0 || len(usage.BySource) != 0 {
+		t.Errorf("Expected usage to be reset, got %+v", usage)
+	}
+}
+
+func TestUsageFromStream(t *testing.T) {
+	provider := &mockProvider{responses: []*types.Response{
+		{Content: "streamed answer", Metadata: usageMeta("llama3.2", 40, 8)},
+	}}
+	ag := newTestAgent(provider)
+
+	if err := ag.ChatStream(context.Background(), "hi", func(types.StreamChunk) error { return nil }); err != nil {
+		t.Fatalf("ChatStream failed: %v", err)
+	}
+
+	usage := ag.Usage()
+	if usage.Calls != 1 || usage.TotalTokens != 48 || usage.Cost != 0 {
+		t.Errorf("Unexpected stream usage: %+v", usage.TokenUsage)
+	}
+}
+
+func TestUsageSourceAttribution(t *testing.T) {
+	provider := &mockProvider{responses: []*types.Response{
+		{Content: "a", Metadata: usageMeta("m", 1, 1)},
+		{Content: "b", Metadata: usageMeta("m", 2, 2)},
+	}}
+	ag := newTestAgent(provider)
+
+	ctx := context.Background()
+	if _, err := ag.provider.Chat(withUsageSource(ctx, UsageSourceReflection), nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ag.provider.Chat(ctx, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	usage := ag.Usage()
+	if usage.BySource[UsageSourceReflection].TotalTokens != 2 || usage.BySource[UsageSourceChat].TotalTokens != 4 {
+		t.Errorf("Unexpected source breakdown: %+v", usage.BySource)
+	}
+}
+
+func TestSelfConsistencyReflectsOnlyWhenEnabled(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		provider := &mockProvider{responses: []*types.Response{
+			{Content: "Step 1: Add\n\nAnswer: 4", Metadata: usageMeta("m", 1, 1)},
+			{Content: "Step 1: Guess\n\nAnswer: 5", Metadata: usageMeta("m", 1, 1)},
+		}}
+		ag := newTestAgent(provider,
+			WithReflection(enabled),
+			WithSelfConsistency(&reasoning.SelfConsistencyConfig{Samples: 2, MinAgreement: 0.9}),
+		)
+
+		if _, err := ag.chatWithCoT(context.Background(), "What is 2 + 2?"); err != nil {
+			t.Fatalf("chatWithCoT failed: %v", err)
+		}
+
+		reflected := ag.Usage().BySource[UsageSourceReflection].Calls > 0
+		if reflected != enabled {
+			t.Errorf("Expected reflection on low agreement only when enabled (enabled=%v), got reflected=%v", enabled, reflected)
+		}
+	}
+}
+
+func TestBudgetAbortsTurn(t *testing.T) {
+	var active, peak int32
+	var finished []string
+	var mu sync.Mutex
+
+	loop := &types.Response{ToolCalls: []types.ToolCall{toolCall("c", "lookup")}, Metadata: usageMeta("m", 80, 20)}
+	provider := &mockProvider{responses: []*types.Response{loop, loop, loop, loop}}
+
+	ag := newTestAgent(provider, WithBudget(Budget{MaxTurnTokens: 150}))
+	ag.AddTool(newSleepTool("lookup", 0, true, &active, &peak, &finished, &mu))
+
+	_, err := ag.Chat(context.Background(), "loop forever")
+	if !errors.Is(err, ErrBudgetExceeded) {
+		t.Fatalf("Expected ErrBudgetExceeded, got %v", err)
+	}
+	var budgetErr *BudgetExceededError
+	if !errors.As(err, &budgetErr) || budgetErr.Limit != "turn_tokens" || budgetErr.Used != 200 {
+		t.Errorf("Unexpected budget error: %+v", budgetErr)
+	}
+	if provider.calls != 2 {
+		t.Errorf("Expected the third call to be refused, got %d calls", provider.calls)
+	}
+
+	// The turn budget resets with the next turn
+	provider.responses = append(provider.responses[:provider.calls], &types.Response{Content: "ok"})
+	if _, err := ag.Chat(context.Background(), "answer now"); err != nil {
+		t.Errorf("Expected new turn to start with a fresh budget, got %v", err)
+	}
+}
+
+func TestBudgetCost(t *testing.T) {
+	provider := &mockProvider{responses: []*types.Response{
+		{Content: "expensive", Metadata: usageMeta("big", 1_000_000, 0)},
+	}}
+	ag := newTestAgent(provider,
+		WithPriceTable(PriceTable{"big": {InputPerMillion: 5}}),
+		WithBudget(Budget{MaxCost: 5}),
+	)
+
+	if _, err := ag.Chat(context.Background(), "first"); err != nil {
+		t.Fatalf("First call should be allowed: %v", err)
+	}
+	err := ag.ChatStream(context.Background(), "second", func(types.StreamChunk) error { return nil })
+	var budgetErr *BudgetExceededError
+	if !errors.As(err, &budgetErr) || budgetErr.Limit != "cost" {
+		t.Errorf("Expected cost budget error, got %v", err)
+	}
+}
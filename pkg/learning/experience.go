@@ -3,7 +3,10 @@ package learning
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/taipm/go-llm-agent/pkg/types"
@@ -93,23 +96,40 @@ type ExperienceFilters struct {
 
 // ExperienceStore manages storage and retrieval of experiences for learning
 type ExperienceStore struct {
-	memory types.AdvancedMemory // Vector memory for semantic search
-	// TODO: Add SQLite for structured queries in Phase 2.2
+	memory  types.AdvancedMemory // Vector memory for semantic search (optional)
+	backend ExperienceBackend    // Structured storage for filters, counts and stats
 }
 
-// NewExperienceStore creates a new experience store
+// NewExperienceStore creates a new experience store with an in-memory
+// structured backend. memory may be nil, in which case Query falls back to
+// keyword matching instead of semantic search.
 func NewExperienceStore(memory types.AdvancedMemory) *ExperienceStore {
 	return &ExperienceStore{
-		memory: memory,
+		memory:  memory,
+		backend: NewInMemoryExperienceBackend(),
 	}
 }
 
+// WithBackend replaces the structured backend (e.g. with a SQLite backend)
+func (e *ExperienceStore) WithBackend(backend ExperienceBackend) *ExperienceStore {
+	e.backend = backend
+	return e
+}
+
 // Record stores a new experience in the store
 func (e *ExperienceStore) Record(ctx context.Context, exp Experience) error {
 	if exp.ID == "" {
 		return fmt.Errorf("experience ID is required")
 	}
 
+	if err := e.backend.Save(ctx, exp); err != nil {
+		return fmt.Errorf("failed to store experience: %w", err)
+	}
+
+	if e.memory == nil {
+		return nil
+	}
+
 	// Serialize experience to JSON for storage
 	data, err := json.Marshal(exp)
 	if err != nil {
@@ -136,51 +156,109 @@ func (e *ExperienceStore) Record(ctx context.Context, exp Experience) error {
 	return nil
 }
 
-// Query retrieves experiences matching the given filters
+// Query retrieves experiences matching the given filters.
+// Without filters.Query the structured backend answers (newest first);
+// with it, results are ranked by similarity to the query.
 func (e *ExperienceStore) Query(ctx context.Context, filters ExperienceFilters) ([]Experience, error) {
-	var results []Experience
+	if filters.Query == "" {
+		return e.backend.Find(ctx, filters)
+	}
+	if e.memory == nil {
+		return e.queryKeywords(ctx, filters)
+	}
+
+	// Semantic search by query
+	limit := filters.Limit
+	if limit == 0 {
+		limit = 10 // Default limit
+	}
+	limit += filters.Offset
 
-	// If semantic search by query
-	if filters.Query != "" {
-		limit := filters.Limit
-		if limit == 0 {
-			limit = 10 // Default limit
+	// Search by semantic similarity
+	messages, err := e.memory.SearchSemantic(ctx, filters.Query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("semantic search failed: %w", err)
+	}
+
+	// Parse messages into experiences
+	var results []Experience
+	for _, msg := range messages {
+		var exp Experience
+		if err := json.Unmarshal([]byte(msg.Content), &exp); err != nil {
+			continue // Skip invalid entries
 		}
 
-		// Search by semantic similarity
-		messages, err := e.memory.SearchSemantic(ctx, filters.Query, limit)
-		if err != nil {
-			return nil, fmt.Errorf("semantic search failed: %w", err)
+		// Apply additional filters
+		if matchesFilters(exp, filters) {
+			results = append(results, exp)
 		}
+	}
+
+	// Apply offset and limit
+	return paginate(results, filters), nil
+}
+
+// queryKeywords ranks structured results by keyword overlap with the query
+func (e *ExperienceStore) queryKeywords(ctx context.Context, filters ExperienceFilters) ([]Experience, error) {
+	all := filters
+	all.Query, all.Limit, all.Offset = "", 0, 0
+	candidates, err := e.backend.Find(ctx, all)
+	if err != nil {
+		return nil, err
+	}
 
-		// Parse messages into experiences
-		for _, msg := range messages {
-			var exp Experience
-			if err := json.Unmarshal([]byte(msg.Content), &exp); err != nil {
-				continue // Skip invalid entries
-			}
-
-			// Apply additional filters
-			if e.matchesFilters(exp, filters) {
-				results = append(results, exp)
-			}
+	type scored struct {
+		exp   Experience
+		score float64
+	}
+	matches := make([]scored, 0)
+	for _, exp := range candidates {
+		score := keywordSimilarity(filters.Query, exp.Query)
+		if score > 0 && score >= filters.MinSimilarity {
+			matches = append(matches, scored{exp, score})
 		}
-	} else {
-		// TODO: Implement non-semantic queries using SQLite in Phase 2.2
-		// For now, return error if no query provided
-		return nil, fmt.Errorf("non-semantic queries not yet implemented")
 	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
 
-	// Apply limit
-	if filters.Limit > 0 && len(results) > filters.Limit {
-		results = results[:filters.Limit]
+	results := make([]Experience, len(matches))
+	for i, m := range matches {
+		results[i] = m.exp
 	}
+	return paginate(results, filters), nil
+}
 
-	return results, nil
+// Count returns the number of experiences matching the filters.
+// filters.Query is ignored: counting is always structured.
+func (e *ExperienceStore) Count(ctx context.Context, filters ExperienceFilters) (int, error) {
+	return e.backend.Count(ctx, filters)
+}
+
+// Get returns a single experience by ID
+func (e *ExperienceStore) Get(ctx context.Context, id string) (*Experience, error) {
+	return e.backend.Get(ctx, id)
+}
+
+// RecordFeedback attaches user feedback to a stored experience
+func (e *ExperienceStore) RecordFeedback(ctx context.Context, id string, feedback Feedback) error {
+	exp, err := e.backend.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if feedback.Timestamp.IsZero() {
+		feedback.Timestamp = time.Now()
+	}
+	exp.UserFeedback = &feedback
+	if err := e.backend.Save(ctx, *exp); err != nil {
+		return fmt.Errorf("failed to store feedback: %w", err)
+	}
+	return nil
 }
 
 // matchesFilters checks if an experience matches the given filters
-func (e *ExperienceStore) matchesFilters(exp Experience, filters ExperienceFilters) bool {
+// (all fields except Query and MinSimilarity)
+func matchesFilters(exp Experience, filters ExperienceFilters) bool {
 	// Time filters
 	if !filters.StartTime.IsZero() && exp.Timestamp.Before(filters.StartTime) {
 		return false
@@ -253,14 +331,51 @@ func (e *ExperienceStore) GetToolSuccessRate(ctx context.Context, toolName strin
 	return successRate, len(experiences), nil
 }
 
-// GetStats returns statistics about stored experiences
+// GetStats returns statistics about all stored experiences
 func (e *ExperienceStore) GetStats(ctx context.Context) (*ExperienceStats, error) {
-	// TODO: Implement using SQLite queries in Phase 2.2
-	// For now, return basic stats
-	return &ExperienceStats{
-		TotalExperiences: 0, // Placeholder
-		SuccessRate:      0.0,
-	}, nil
+	return e.Aggregate(ctx, ExperienceFilters{})
+}
+
+// Aggregate computes statistics over the experiences matching the filters
+// (filters.Query, Limit and Offset are ignored)
+func (e *ExperienceStore) Aggregate(ctx context.Context, filters ExperienceFilters) (*ExperienceStats, error) {
+	filters.Query, filters.Limit, filters.Offset = "", 0, 0
+	experiences, err := e.backend.Find(ctx, filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query experiences: %w", err)
+	}
+
+	stats := &ExperienceStats{
+		TotalExperiences:   len(experiences),
+		ToolUsageCount:     make(map[string]int),
+		IntentDistribution: make(map[string]int),
+	}
+	if len(experiences) == 0 {
+		return stats, nil
+	}
+
+	var successes int
+	var latency int64
+	var confidence float64
+	for _, exp := range experiences {
+		if exp.Success {
+			successes++
+		}
+		if exp.ToolCalled != "" {
+			stats.ToolUsageCount[exp.ToolCalled]++
+		}
+		if exp.Intent != "" {
+			stats.IntentDistribution[exp.Intent]++
+		}
+		latency += exp.LatencyMs
+		confidence += exp.Confidence
+	}
+
+	n := len(experiences)
+	stats.SuccessRate = float64(successes) / float64(n)
+	stats.AvgLatencyMs = latency / int64(n)
+	stats.AvgConfidence = confidence / float64(n)
+	return stats, nil
 }
 
 // ExperienceStats provides overview statistics
@@ -273,35 +388,49 @@ type ExperienceStats struct {
 	AvgConfidence      float64        `json:"avg_confidence"`
 }
 
-// GetAllFailures retrieves all failed experiences (for error pattern detection)
-// This is more efficient than semantic search when we just need all failures
+// GetAllFailures retrieves the most recent failed experiences (for error pattern detection)
 func (e *ExperienceStore) GetAllFailures(ctx context.Context, limit int) ([]Experience, error) {
 	if limit <= 0 {
 		limit = 1000 // Default to last 1000 failures
 	}
 
-	// Get all experiences from category
-	messages, err := e.memory.GetByCategory(ctx, types.CategoryExperience, limit*2) // Get more to account for filtering
+	failed := false
+	results, err := e.backend.Find(ctx, ExperienceFilters{Success: &failed, Limit: limit})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get experiences: %w", err)
 	}
+	return results, nil
+}
 
-	// Parse and filter for failures only
-	results := make([]Experience, 0)
-	for _, msg := range messages {
-		var exp Experience
-		if err := json.Unmarshal([]byte(msg.Content), &exp); err != nil {
-			continue // Skip invalid entries
-		}
-
-		// Only include failures
-		if !exp.Success {
-			results = append(results, exp)
-			if len(results) >= limit {
-				break
-			}
-		}
+// ClassifyError maps an error to a coarse category stored in Experience.ErrorType
+func ClassifyError(err error) string {
+	if err == nil {
+		return ""
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return "timeout"
+	}
+	if errors.Is(err, context.Canceled) {
+		return "canceled"
 	}
 
-	return results, nil
+	msg := strings.ToLower(err.Error())
+	switch {
+	case strings.Contains(msg, "timed out") || strings.Contains(msg, "timeout"):
+		return "timeout"
+	case strings.Contains(msg, "budget exceeded"):
+		return "budget"
+	case strings.Contains(msg, "rate limit") || strings.Contains(msg, "429"):
+		return "rate_limit"
+	case strings.Contains(msg, "denied"):
+		return "tool_denied"
+	case strings.Contains(msg, "max iterations"):
+		return "max_iterations"
+	case strings.Contains(msg, "connection") || strings.Contains(msg, "network"):
+		return "network"
+	case strings.Contains(msg, "tool"):
+		return "tool_error"
+	default:
+		return "other"
+	}
 }
@@ -0,0 +1,130 @@
+package learning
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ExperienceBackend stores experiences for structured (non-semantic) queries.
+// Find and Count honour every field of ExperienceFilters except Query and
+// MinSimilarity; results are ordered newest first.
+type ExperienceBackend interface {
+	// Save inserts or replaces an experience (keyed by ID)
+	Save(ctx context.Context, exp Experience) error
+
+	// Get returns the experience with the given ID
+	Get(ctx context.Context, id string) (*Experience, error)
+
+	// Find returns experiences matching the filters, newest first
+	Find(ctx context.Context, filters ExperienceFilters) ([]Experience, error)
+
+	// Count returns the number of matching experiences (ignoring Limit/Offset)
+	Count(ctx context.Context, filters ExperienceFilters) (int, error)
+}
+
+// ErrExperienceNotFound is returned when an experience ID is unknown
+var ErrExperienceNotFound = fmt.Errorf("experience not found")
+
+// InMemoryExperienceBackend keeps experiences in process memory
+type InMemoryExperienceBackend struct {
+	mu          sync.RWMutex
+	experiences map[string]Experience
+}
+
+// NewInMemoryExperienceBackend creates an empty in-memory backend
+func NewInMemoryExperienceBackend() *InMemoryExperienceBackend {
+	return &InMemoryExperienceBackend{
+		experiences: make(map[string]Experience),
+	}
+}
+
+// Save implements ExperienceBackend
+func (b *InMemoryExperienceBackend) Save(ctx context.Context, exp Experience) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.experiences[exp.ID] = exp
+	return nil
+}
+
+// Get implements ExperienceBackend
+func (b *InMemoryExperienceBackend) Get(ctx context.Context, id string) (*Experience, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	exp, ok := b.experiences[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrExperienceNotFound, id)
+	}
+	return &exp, nil
+}
+
+// Find implements ExperienceBackend
+func (b *InMemoryExperienceBackend) Find(ctx context.Context, filters ExperienceFilters) ([]Experience, error) {
+	return paginate(b.matching(filters), filters), nil
+}
+
+// Count implements ExperienceBackend
+func (b *InMemoryExperienceBackend) Count(ctx context.Context, filters ExperienceFilters) (int, error) {
+	return len(b.matching(filters)), nil
+}
+
+// matching returns all experiences matching the filters, newest first
+func (b *InMemoryExperienceBackend) matching(filters ExperienceFilters) []Experience {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	results := make([]Experience, 0)
+	for _, exp := range b.experiences {
+		if matchesFilters(exp, filters) {
+			results = append(results, exp)
+		}
+	}
+	sortNewestFirst(results)
+	return results
+}
+
+// sortNewestFirst orders experiences by timestamp (then ID for stability)
+func sortNewestFirst(exps []Experience) {
+	sort.Slice(exps, func(i, j int) bool {
+		if !exps[i].Timestamp.Equal(exps[j].Timestamp) {
+			return exps[i].Timestamp.After(exps[j].Timestamp)
+		}
+		return exps[i].ID > exps[j].ID
+	})
+}
+
+// paginate applies Offset and Limit
+func paginate(exps []Experience, filters ExperienceFilters) []Experience {
+	if filters.Offset > 0 {
+		if filters.Offset >= len(exps) {
+			return []Experience{}
+		}
+		exps = exps[filters.Offset:]
+	}
+	if filters.Limit > 0 && len(exps) > filters.Limit {
+		exps = exps[:filters.Limit]
+	}
+	return exps
+}
+
+// keywordSimilarity is the share of query words found in text (0.0-1.0).
+// It stands in for semantic similarity when no vector memory is available.
+func keywordSimilarity(query, text string) float64 {
+	words := strings.Fields(strings.ToLower(query))
+	if len(words) == 0 {
+		return 0
+	}
+	textWords := make(map[string]bool)
+	for _, w := range strings.Fields(strings.ToLower(text)) {
+		textWords[w] = true
+	}
+	found := 0
+	for _, w := range words {
+		if textWords[w] {
+			found++
+		}
+	}
+	return float64(found) / float64(len(words))
+}
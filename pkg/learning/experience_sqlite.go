@@ -0,0 +1,175 @@
+package learning
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// SQLiteExperienceBackend stores experiences in a SQLite table so that
+// filters, counts and pagination run as SQL queries and survive restarts.
+// It uses database/sql; register a driver such as modernc.org/sqlite.
+type SQLiteExperienceBackend struct {
+	db *sql.DB
+}
+
+const experienceSchema = `
+CREATE TABLE IF NOT EXISTS experiences (
+	id              TEXT    PRIMARY KEY,
+	timestamp       INTEGER NOT NULL,
+	intent          TEXT    NOT NULL DEFAULT '',
+	reasoning_mode  TEXT    NOT NULL DEFAULT '',
+	conversation_id TEXT    NOT NULL DEFAULT '',
+	tool_called     TEXT    NOT NULL DEFAULT '',
+	success         INTEGER NOT NULL,
+	error_type      TEXT    NOT NULL DEFAULT '',
+	confidence      REAL    NOT NULL DEFAULT 0,
+	has_feedback    INTEGER NOT NULL DEFAULT 0,
+	data            TEXT    NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_experiences_timestamp ON experiences(timestamp);
+CREATE INDEX IF NOT EXISTS idx_experiences_tool ON experiences(tool_called, intent);
+CREATE INDEX IF NOT EXISTS idx_experiences_success ON experiences(success, timestamp);
+`
+
+// NewSQLiteExperienceBackend creates the experiences table in db if needed
+func NewSQLiteExperienceBackend(ctx context.Context, db *sql.DB) (*SQLiteExperienceBackend, error) {
+	if _, err := db.ExecContext(ctx, experienceSchema); err != nil {
+		return nil, fmt.Errorf("failed to create experiences table: %w", err)
+	}
+	return &SQLiteExperienceBackend{db: db}, nil
+}
+
+// Save implements ExperienceBackend
+func (b *SQLiteExperienceBackend) Save(ctx context.Context, exp Experience) error {
+	data, err := json.Marshal(exp)
+	if err != nil {
+		return fmt.Errorf("failed to serialize experience: %w", err)
+	}
+
+	_, err = b.db.ExecContext(ctx,
+		`INSERT OR REPLACE INTO experiences
+		 (id, timestamp, intent, reasoning_mode, conversation_id, tool_called, success, error_type, confidence, has_feedback, data)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		exp.ID, exp.Timestamp.UnixNano(), exp.Intent, exp.ReasoningMode, exp.ConversationID, exp.ToolCalled,
+		exp.Success, exp.ErrorType, exp.Confidence, exp.UserFeedback != nil, string(data),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save experience: %w", err)
+	}
+	return nil
+}
+
+// Get implements ExperienceBackend
+func (b *SQLiteExperienceBackend) Get(ctx context.Context, id string) (*Experience, error) {
+	var data string
+	err := b.db.QueryRowContext(ctx, `SELECT data FROM experiences WHERE id = ?`, id).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s", ErrExperienceNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get experience: %w", err)
+	}
+
+	var exp Experience
+	if err := json.Unmarshal([]byte(data), &exp); err != nil {
+		return nil, fmt.Errorf("failed to parse experience: %w", err)
+	}
+	return &exp, nil
+}
+
+// Find implements ExperienceBackend
+func (b *SQLiteExperienceBackend) Find(ctx context.Context, filters ExperienceFilters) ([]Experience, error) {
+	where, args := experienceWhere(filters)
+	query := `SELECT data FROM experiences` + where + ` ORDER BY timestamp DESC, id DESC`
+	if filters.Limit > 0 || filters.Offset > 0 {
+		limit := filters.Limit
+		if limit <= 0 {
+			limit = -1 // No limit in SQLite
+		}
+		query += ` LIMIT ? OFFSET ?`
+		args = append(args, limit, filters.Offset)
+	}
+
+	rows, err := b.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query experiences: %w", err)
+	}
+	defer rows.Close()
+
+	results := make([]Experience, 0)
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to scan experience: %w", err)
+		}
+		var exp Experience
+		if err := json.Unmarshal([]byte(data), &exp); err != nil {
+			continue // Skip invalid entries
+		}
+		results = append(results, exp)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read experiences: %w", err)
+	}
+	return results, nil
+}
+
+// Count implements ExperienceBackend
+func (b *SQLiteExperienceBackend) Count(ctx context.Context, filters ExperienceFilters) (int, error) {
+	where, args := experienceWhere(filters)
+	var count int
+	if err := b.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM experiences`+where, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count experiences: %w", err)
+	}
+	return count, nil
+}
+
+// experienceWhere translates filters into a WHERE clause
+func experienceWhere(filters ExperienceFilters) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	add := func(condition string, arg interface{}) {
+		conditions = append(conditions, condition)
+		args = append(args, arg)
+	}
+
+	if !filters.StartTime.IsZero() {
+		add("timestamp >= ?", filters.StartTime.UnixNano())
+	}
+	if !filters.EndTime.IsZero() {
+		add("timestamp <= ?", filters.EndTime.UnixNano())
+	}
+	if filters.Intent != "" {
+		add("intent = ?", filters.Intent)
+	}
+	if filters.ReasoningMode != "" {
+		add("reasoning_mode = ?", filters.ReasoningMode)
+	}
+	if filters.ConversationID != "" {
+		add("conversation_id = ?", filters.ConversationID)
+	}
+	if filters.Success != nil {
+		add("success = ?", *filters.Success)
+	}
+	if filters.ToolUsed != "" {
+		add("tool_called = ?", filters.ToolUsed)
+	}
+	if filters.ErrorType != "" {
+		add("error_type = ?", filters.ErrorType)
+	}
+	if filters.MinConfidence > 0 {
+		add("confidence >= ?", filters.MinConfidence)
+	}
+	if filters.WithFeedback {
+		conditions = append(conditions, "has_feedback = 1")
+	}
+
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
@@ -0,0 +1,202 @@
+package learning
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// seedExperiences records 6 experiences one minute apart (e0 oldest)
+func seedExperiences(t *testing.T, store *ExperienceStore) time.Time {
+	t.Helper()
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	specs := []struct {
+		intent, tool, errType string
+		success               bool
+		confidence            float64
+	}{
+		{"calculation", "math_calculate", "", true, 0.9},
+		{"calculation", "math_calculate", "tool_error", false, 0.4},
+		{"web_search", "web_search", "", true, 0.8},
+		{"web_search", "web_search", "timeout", false, 0.3},
+		{"web_search", "web_fetch", "", true, 0.7},
+		{"calculation", "", "", true, 0.95},
+	}
+	for i, s := range specs {
+		exp := Experience{
+			ID:             fmt.Sprintf("e%d", i),
+			Timestamp:      base.Add(time.Duration(i) * time.Minute),
+			Query:          fmt.Sprintf("query %d about %s", i, s.intent),
+			Intent:         s.intent,
+			ToolCalled:     s.tool,
+			Success:        s.success,
+			ErrorType:      s.errType,
+			Confidence:     s.confidence,
+			ConversationID: "conv",
+			LatencyMs:      int64(100 * (i + 1)),
+		}
+		if err := store.Record(context.Background(), exp); err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+	}
+	return base
+}
+
+func ids(exps []Experience) string {
+	var out []string
+	for _, e := range exps {
+		out = append(out, e.ID)
+	}
+	return strings.Join(out, ",")
+}
+
+func testBackendQueries(t *testing.T, store *ExperienceStore) {
+	ctx := context.Background()
+	base := seedExperiences(t, store)
+	failed, succeeded := false, true
+
+	tests := []struct {
+		name    string
+		filters ExperienceFilters
+		want    string
+	}{
+		{"all newest first", ExperienceFilters{}, "e5,e4,e3,e2,e1,e0"},
+		{"intent", ExperienceFilters{Intent: "calculation"}, "e5,e1,e0"},
+		{"tool", ExperienceFilters{ToolUsed: "web_search"}, "e3,e2"},
+		{"failures", ExperienceFilters{Success: &failed}, "e3,e1"},
+		{"successes with intent", ExperienceFilters{Success: &succeeded, Intent: "web_search"}, "e4,e2"},
+		{"error type", ExperienceFilters{ErrorType: "timeout"}, "e3"},
+		{"min confidence", ExperienceFilters{MinConfidence: 0.85}, "e5,e0"},
+		{"time range", ExperienceFilters{StartTime: base.Add(time.Minute), EndTime: base.Add(3 * time.Minute)}, "e3,e2,e1"},
+		{"conversation", ExperienceFilters{ConversationID: "other"}, ""},
+		{"limit", ExperienceFilters{Limit: 2}, "e5,e4"},
+		{"offset", ExperienceFilters{Limit: 2, Offset: 2}, "e3,e2"},
+		{"offset past end", ExperienceFilters{Offset: 10}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := store.Query(ctx, tt.filters)
+			if err != nil {
+				t.Fatalf("Query failed: %v", err)
+			}
+			if got := ids(results); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+
+	count, err := store.Count(ctx, ExperienceFilters{Intent: "web_search", Limit: 1})
+	if err != nil || count != 3 {
+		t.Errorf("Expected count 3 (ignoring limit), got %d (%v)", count, err)
+	}
+
+	// Feedback
+	if err := store.RecordFeedback(ctx, "e2", Feedback{Rating: FeedbackPositive, Helpful: true}); err != nil {
+		t.Fatalf("RecordFeedback failed: %v", err)
+	}
+	withFeedback, _ := store.Query(ctx, ExperienceFilters{WithFeedback: true})
+	if ids(withFeedback) != "e2" || withFeedback[0].UserFeedback.Timestamp.IsZero() {
+		t.Errorf("Expected e2 with timestamped feedback, got %+v", withFeedback)
+	}
+	if err := store.RecordFeedback(ctx, "missing", Feedback{}); !errors.Is(err, ErrExperienceNotFound) {
+		t.Errorf("Expected ErrExperienceNotFound, got %v", err)
+	}
+
+	failures, _ := store.GetAllFailures(ctx, 1)
+	if ids(failures) != "e3" {
+		t.Errorf("Expected most recent failure e3, got %q", ids(failures))
+	}
+}
+
+func TestExperienceStoreStructuredQueries(t *testing.T) {
+	testBackendQueries(t, NewExperienceStore(nil))
+}
+
+func TestSQLiteExperienceBackend(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	defer db.Close()
+
+	backend, err := NewSQLiteExperienceBackend(context.Background(), db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testBackendQueries(t, NewExperienceStore(nil).WithBackend(backend))
+}
+
+func TestExperienceStoreAggregate(t *testing.T) {
+	store := NewExperienceStore(nil)
+	seedExperiences(t, store)
+
+	stats, err := store.GetStats(context.Background())
+	if err != nil {
+		t.Fatalf("GetStats failed: %v", err)
+	}
+	if stats.TotalExperiences != 6 || stats.AvgLatencyMs != 350 {
+		t.Errorf("Unexpected totals: %+v", stats)
+	}
+	if stats.SuccessRate < 0.66 || stats.SuccessRate > 0.67 {
+		t.Errorf("Expected success rate 4/6, got %v", stats.SuccessRate)
+	}
+	if stats.ToolUsageCount["web_search"] != 2 || stats.IntentDistribution["calculation"] != 3 {
+		t.Errorf("Unexpected distributions: %v / %v", stats.ToolUsageCount, stats.IntentDistribution)
+	}
+
+	calc, _ := store.Aggregate(context.Background(), ExperienceFilters{Intent: "calculation"})
+	if calc.TotalExperiences != 3 || calc.ToolUsageCount["math_calculate"] != 2 {
+		t.Errorf("Unexpected filtered stats: %+v", calc)
+	}
+
+	empty, _ := NewExperienceStore(nil).GetStats(context.Background())
+	if empty.TotalExperiences != 0 || empty.SuccessRate != 0 {
+		t.Errorf("Expected empty stats, got %+v", empty)
+	}
+}
+
+func TestExperienceStoreKeywordQuery(t *testing.T) {
+	store := NewExperienceStore(nil)
+	seedExperiences(t, store)
+
+	results, err := store.Query(context.Background(), ExperienceFilters{Query: "about web_search", Limit: 2})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(results) != 2 || results[0].Intent != "web_search" {
+		t.Errorf("Expected web_search experiences first, got %q", ids(results))
+	}
+
+	strict, _ := store.Query(context.Background(), ExperienceFilters{Query: "query 3 about web_search", MinSimilarity: 1})
+	if ids(strict) != "e3" {
+		t.Errorf("Expected exact keyword match e3, got %q", ids(strict))
+	}
+}
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{nil, ""},
+		{context.DeadlineExceeded, "timeout"},
+		{fmt.Errorf("LLM call failed: %w", context.Canceled), "canceled"},
+		{errors.New("tool slow timed out after 1s"), "timeout"},
+		{errors.New("usage budget exceeded: tokens used 10 of 5"), "budget"},
+		{errors.New("max iterations (10) reached"), "max_iterations"},
+		{errors.New("something odd"), "other"},
+	}
+	for _, tt := range tests {
+		if got := ClassifyError(tt.err); got != tt.want {
+			t.Errorf("ClassifyError(%v): expected %q, got %q", tt.err, tt.want, got)
+		}
+	}
+}
@@ -356,10 +356,6 @@ func (t *ToolSelector) GetToolStats(ctx context.Context, toolName string, intent
 		Limit:    1000,
 	}
 
-	// For now, we need to use semantic search with a generic query
-	// TODO: Add non-semantic filtering in Phase 2.2 with SQLite
-	filters.Query = intent + " " + toolName
-
 	experiences, err := t.experiences.Query(ctx, filters)
 	if err != nil {
 		return nil, err
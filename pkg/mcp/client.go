@@ -0,0 +1,502 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/taipm/go-llm-agent/pkg/logger"
+	"github.com/taipm/go-llm-agent/pkg/tools"
+)
+
+// ClientConfig configures a Client. Exactly one of Stdio, HTTP or
+// NewTransport selects how to reach the server.
+type ClientConfig struct {
+	// Name namespaces the server's tools: tool "search" of server "docs" is
+	// registered as "docs__search". Empty keeps the server's tool names.
+	Name string
+
+	Stdio *StdioConfig // Run the server as a subprocess
+	HTTP  *HTTPConfig  // Connect over streamable HTTP
+
+	// NewTransport creates the transport for each (re)connection
+	NewTransport func() Transport
+
+	// ClientInfo identifies this client to the server
+	ClientInfo Implementation
+
+	// Timeout bounds each request (default 60s)
+	Timeout time.Duration
+
+	// MaxReconnects is the number of attempts to restore a lost connection
+	// (default 3, negative disables reconnection)
+	MaxReconnects int
+
+	// ReconnectDelay is the delay before the first reconnection attempt,
+	// doubled after each failure (default 500ms)
+	ReconnectDelay time.Duration
+
+	// OnToolsChanged is called after the tool list was refreshed because the
+	// server announced a change or the connection was restored
+	OnToolsChanged func(tools []Tool)
+
+	// Logger logs connection events (default: no logging)
+	Logger logger.Logger
+}
+
+// Client talks to one MCP server and adapts its tools into tools.Tool
+type Client struct {
+	config ClientConfig
+	logger logger.Logger
+	nextID atomic.Int64
+
+	connectMu sync.Mutex // Serializes (re)connection
+	refreshMu sync.Mutex // Serializes tool list refreshes
+
+	mu           sync.Mutex
+	conn         *connection
+	closed       bool
+	serverInfo   Implementation
+	capabilities ServerCapabilities
+	instructions string
+	tools        []Tool
+	registries   []*registration
+}
+
+// connection is one started transport and its in-flight requests
+type connection struct {
+	transport Transport
+
+	mu      sync.Mutex
+	pending map[int64]chan *message
+	done    chan struct{}
+	err     error
+}
+
+// NewClient creates a client; call Connect (or any request) to connect
+func NewClient(config ClientConfig) (*Client, error) {
+	sources := 0
+	for _, set := range []bool{config.Stdio != nil, config.HTTP != nil, config.NewTransport != nil} {
+		if set {
+			sources++
+		}
+	}
+	if sources != 1 {
+		return nil, fmt.Errorf("mcp client requires exactly one of Stdio, HTTP or NewTransport")
+	}
+
+	if config.NewTransport == nil {
+		if config.Stdio != nil {
+			stdio := *config.Stdio
+			config.NewTransport = func() Transport { return NewStdioTransport(stdio) }
+		} else {
+			httpConfig := *config.HTTP
+			config.NewTransport = func() Transport { return NewHTTPTransport(httpConfig) }
+		}
+	}
+	if config.ClientInfo.Name == "" {
+		config.ClientInfo = Implementation{Name: "go-llm-agent", Version: "1.0.0"}
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 60 * time.Second
+	}
+	if config.MaxReconnects == 0 {
+		config.MaxReconnects = 3
+	}
+	if config.ReconnectDelay <= 0 {
+		config.ReconnectDelay = 500 * time.Millisecond
+	}
+	if config.Logger == nil {
+		config.Logger = &logger.NoopLogger{}
+	}
+
+	return &Client{config: config, logger: config.Logger}, nil
+}
+
+// Connect starts the transport and performs the MCP handshake
+func (c *Client) Connect(ctx context.Context) error {
+	_, err := c.connection(ctx)
+	return err
+}
+
+// ServerInfo returns the name and version reported by the server
+func (c *Client) ServerInfo() Implementation {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.serverInfo
+}
+
+// Instructions returns the usage instructions sent by the server, if any
+func (c *Client) Instructions() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.instructions
+}
+
+// Ping checks that the server responds
+func (c *Client) Ping(ctx context.Context) error {
+	return c.request(ctx, MethodPing, struct{}{}, nil)
+}
+
+// ListTools returns all tools of the server, following pagination
+func (c *Client) ListTools(ctx context.Context) ([]Tool, error) {
+	var all []Tool
+	cursor := ""
+	for {
+		var result listToolsResult
+		if err := c.request(ctx, MethodToolsList, listToolsParams{Cursor: cursor}, &result); err != nil {
+			return nil, fmt.Errorf("failed to list tools: %w", err)
+		}
+		all = append(all, result.Tools...)
+		if result.NextCursor == "" || result.NextCursor == cursor {
+			break
+		}
+		cursor = result.NextCursor
+	}
+
+	c.mu.Lock()
+	c.tools = all
+	c.mu.Unlock()
+	return all, nil
+}
+
+// CallTool invokes a tool by its server-side name. A tool that fails
+// returns a result with IsError set, not an error.
+func (c *Client) CallTool(ctx context.Context, name string, arguments map[string]interface{}) (*CallToolResult, error) {
+	var result CallToolResult
+	if err := c.request(ctx, MethodToolsCall, callToolParams{Name: name, Arguments: arguments}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Tools lists the server's tools as tools.Tool adapters
+func (c *Client) Tools(ctx context.Context) ([]tools.Tool, error) {
+	list, err := c.ListTools(ctx)
+	if err != nil {
+		return nil, err
+	}
+	adapted := make([]tools.Tool, 0, len(list))
+	for _, t := range list {
+		tool, err := c.newRemoteTool(t)
+		if err != nil {
+			c.logger.Warn("⚠️  Skipping MCP tool %s: %v", t.Name, err)
+			continue
+		}
+		adapted = append(adapted, tool)
+	}
+	return adapted, nil
+}
+
+// Close disconnects from the server; the client cannot be used afterwards
+func (c *Client) Close() error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil
+	}
+	c.closed = true
+	conn := c.conn
+	c.conn = nil
+	c.mu.Unlock()
+
+	if conn != nil {
+		conn.close(ErrClosed)
+		return conn.transport.Close()
+	}
+	return nil
+}
+
+// request sends a request, reconnecting once if the connection was lost
+func (c *Client) request(ctx context.Context, method string, params, result interface{}) error {
+	conn, err := c.connection(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = c.call(ctx, conn, method, params, result)
+	if errors.Is(err, ErrClosed) && c.config.MaxReconnects > 0 && ctx.Err() == nil {
+		c.logger.Debug("🔌 MCP %s: connection lost during %s, reconnecting", c.config.Name, method)
+		if conn, err = c.connection(ctx); err != nil {
+			return err
+		}
+		err = c.call(ctx, conn, method, params, result)
+	}
+	return err
+}
+
+// connection returns the live connection, connecting with retries if needed
+func (c *Client) connection(ctx context.Context) (*connection, error) {
+	c.connectMu.Lock()
+	defer c.connectMu.Unlock()
+
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil, ErrClosed
+	}
+	if conn := c.conn; conn != nil && conn.alive() {
+		c.mu.Unlock()
+		return conn, nil
+	}
+	reconnecting := c.conn != nil
+	c.mu.Unlock()
+
+	attempts := 1
+	if reconnecting {
+		if c.config.MaxReconnects < 0 {
+			return nil, ErrClosed
+		}
+		attempts = c.config.MaxReconnects
+	}
+
+	delay := c.config.ReconnectDelay
+	var lastErr error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 || reconnecting {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(delay):
+			}
+			delay *= 2
+		}
+
+		conn, err := c.connect(ctx)
+		if err == nil {
+			if reconnecting {
+				c.logger.Info("🔌 MCP %s: reconnected", c.config.Name)
+				go c.refreshTools()
+			}
+			return conn, nil
+		}
+		lastErr = err
+		c.logger.Warn("⚠️  MCP %s: connection attempt %d failed: %v", c.config.Name, attempt+1, err)
+	}
+	return nil, lastErr
+}
+
+// connect starts a new transport and runs the initialization handshake
+func (c *Client) connect(ctx context.Context) (*connection, error) {
+	conn := &connection{
+		transport: c.config.NewTransport(),
+		pending:   make(map[int64]chan *message),
+		done:      make(chan struct{}),
+	}
+
+	onMessage := func(data []byte) { c.handleMessage(conn, data) }
+	onClose := func(err error) { c.handleClose(conn, err) }
+	if err := conn.transport.Start(ctx, onMessage, onClose); err != nil {
+		return nil, err
+	}
+
+	var result initializeResult
+	params := initializeParams{
+		ProtocolVersion: ProtocolVersion,
+		Capabilities:    map[string]interface{}{},
+		ClientInfo:      c.config.ClientInfo,
+	}
+	if err := c.call(ctx, conn, MethodInitialize, params, &result); err != nil {
+		conn.transport.Close()
+		return nil, fmt.Errorf("MCP initialize failed: %w", err)
+	}
+	if err := negotiateVersion(result.ProtocolVersion); err != nil {
+		conn.transport.Close()
+		return nil, err
+	}
+	if versioned, ok := conn.transport.(interface{ setProtocolVersion(string) }); ok {
+		versioned.setProtocolVersion(result.ProtocolVersion)
+	}
+	if err := c.notify(ctx, conn, MethodInitialized, nil); err != nil {
+		conn.transport.Close()
+		return nil, fmt.Errorf("MCP initialized notification failed: %w", err)
+	}
+
+	c.mu.Lock()
+	c.conn = conn
+	c.serverInfo = result.ServerInfo
+	c.capabilities = result.Capabilities
+	c.instructions = result.Instructions
+	c.mu.Unlock()
+
+	c.logger.Debug("🔌 MCP %s: connected to %s %s (protocol %s)", c.config.Name, result.ServerInfo.Name, result.ServerInfo.Version, result.ProtocolVersion)
+	return conn, nil
+}
+
+// call sends one request on conn and waits for its response
+func (c *Client) call(ctx context.Context, conn *connection, method string, params, result interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+
+	id := c.nextID.Add(1)
+	rawID := json.RawMessage(strconv.FormatInt(id, 10))
+	msg := message{JSONRPC: "2.0", ID: &rawID, Method: method}
+	if params != nil {
+		encoded, err := json.Marshal(params)
+		if err != nil {
+			return fmt.Errorf("failed to encode %s params: %w", method, err)
+		}
+		msg.Params = encoded
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	ch := make(chan *message, 1)
+	if !conn.addPending(id, ch) {
+		return ErrClosed
+	}
+	defer conn.removePending(id)
+
+	if err := conn.transport.Send(ctx, data); err != nil {
+		return err
+	}
+
+	select {
+	case resp := <-ch:
+		if resp.Error != nil {
+			return resp.Error
+		}
+		if result != nil && len(resp.Result) > 0 {
+			if err := json.Unmarshal(resp.Result, result); err != nil {
+				return fmt.Errorf("invalid %s result: %w", method, err)
+			}
+		}
+		return nil
+	case <-conn.done:
+		return fmt.Errorf("%w: %v", ErrClosed, conn.err)
+	case <-ctx.Done():
+		// Tell the server to stop working on the request (best effort)
+		notifyCtx, notifyCancel := context.WithTimeout(context.Background(), time.Second)
+		defer notifyCancel()
+		c.notify(notifyCtx, conn, MethodCancelled, map[string]interface{}{"requestId": id, "reason": ctx.Err().Error()})
+		return fmt.Errorf("MCP %s: %w", method, ctx.Err())
+	}
+}
+
+// notify sends a notification on conn
+func (c *Client) notify(ctx context.Context, conn *connection, method string, params interface{}) error {
+	msg := message{JSONRPC: "2.0", Method: method}
+	if params != nil {
+		encoded, err := json.Marshal(params)
+		if err != nil {
+			return err
+		}
+		msg.Params = encoded
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return conn.transport.Send(ctx, data)
+}
+
+// handleMessage dispatches a message received from the server
+func (c *Client) handleMessage(conn *connection, data []byte) {
+	var msg message
+	if err := json.Unmarshal(data, &msg); err != nil {
+		c.logger.Debug("MCP %s: ignoring invalid message: %v", c.config.Name, err)
+		return
+	}
+
+	switch {
+	case msg.isResponse():
+		id, err := strconv.ParseInt(string(*msg.ID), 10, 64)
+		if err != nil {
+			return
+		}
+		if ch := conn.takePending(id); ch != nil {
+			ch <- &msg
+		}
+
+	case msg.isNotification():
+		if msg.Method == MethodToolsListChanged {
+			c.logger.Debug("🔌 MCP %s: tool list changed", c.config.Name)
+			go c.refreshTools()
+		}
+
+	case msg.isRequest():
+		// Answer pings; this client offers no other capabilities
+		reply := message{JSONRPC: "2.0", ID: msg.ID}
+		if msg.Method == MethodPing {
+			reply.Result = json.RawMessage("{}")
+		} else {
+			reply.Error = &RPCError{Code: CodeMethodNotFound, Message: "method not found: " + msg.Method}
+		}
+		go func() {
+			if data, err := json.Marshal(reply); err == nil {
+				ctx, cancel := context.WithTimeout(context.Background(), c.config.Timeout)
+				defer cancel()
+				conn.transport.Send(ctx, data)
+			}
+		}()
+	}
+}
+
+// handleClose is called by the transport when the connection ends
+func (c *Client) handleClose(conn *connection, err error) {
+	conn.close(err)
+
+	c.mu.Lock()
+	current := c.conn == conn && !c.closed
+	watched := len(c.registries) > 0 || c.config.OnToolsChanged != nil
+	c.mu.Unlock()
+	if !current {
+		return
+	}
+
+	c.logger.Warn("⚠️  MCP %s: connection lost: %v", c.config.Name, err)
+	if watched && c.config.MaxReconnects > 0 {
+		// Reconnect right away so that tool list notifications keep flowing
+		go c.connection(context.Background())
+	}
+}
+
+func (conn *connection) alive() bool {
+	select {
+	case <-conn.done:
+		return false
+	default:
+		return true
+	}
+}
+
+func (conn *connection) close(err error) {
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+	if !conn.alive() {
+		return
+	}
+	conn.err = err
+	close(conn.done)
+}
+
+func (conn *connection) addPending(id int64, ch chan *message) bool {
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+	if !conn.alive() {
+		return false
+	}
+	conn.pending[id] = ch
+	return true
+}
+
+func (conn *connection) takePending(id int64) chan *message {
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+	ch := conn.pending[id]
+	delete(conn.pending, id)
+	return ch
+}
+
+func (conn *connection) removePending(id int64) {
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+	delete(conn.pending, id)
+}
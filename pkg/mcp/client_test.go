@@ -0,0 +1,456 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/taipm/go-llm-agent/pkg/tools"
+)
+
+// TestMain turns the test binary into a stdio MCP server when asked to
+func TestMain(m *testing.M) {
+	if os.Getenv("MCP_FAKE_SERVER") == "1" {
+		runStdioServer()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// fakeServer answers MCP requests for the tests
+type fakeServer struct {
+	mu          sync.Mutex
+	tools       []Tool
+	initialized int
+}
+
+func newFakeServer() *fakeServer {
+	readOnly := true
+	return &fakeServer{tools: []Tool{
+		{
+			Name:        "echo",
+			Description: "Echo the text",
+			InputSchema: json.RawMessage(`{"type":"object","properties":{"text":{"type":"string"}},"required":["text"]}`),
+			Annotations: &ToolAnnotations{ReadOnlyHint: &readOnly},
+		},
+		{
+			Name:        "add",
+			Description: "Add two numbers",
+			InputSchema: json.RawMessage(`{"type":"object","properties":{"a":{"type":"number"},"b":{"type":"number"}}}`),
+		},
+		{
+			Name:        "fail",
+			InputSchema: json.RawMessage(`{"type":"object"}`),
+		},
+	}}
+}
+
+func (s *fakeServer) addTool(tool Tool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tools = append(s.tools, tool)
+}
+
+// handle returns the response to msg, or nil for notifications
+func (s *fakeServer) handle(msg message) *message {
+	if msg.ID == nil {
+		return nil
+	}
+	reply := &message{JSONRPC: "2.0", ID: msg.ID}
+	var result interface{}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch msg.Method {
+	case MethodInitialize:
+		s.initialized++
+		result = map[string]interface{}{
+			"protocolVersion": ProtocolVersion,
+			"capabilities":    map[string]interface{}{"tools": map[string]interface{}{"listChanged": true}},
+			"serverInfo":      Implementation{Name: "fake", Version: "0.1"},
+			"instructions":    "Use echo to test",
+		}
+	case MethodPing:
+		result = struct{}{}
+	case MethodToolsList:
+		// Two pages to exercise pagination
+		var params listToolsParams
+		json.Unmarshal(msg.Params, &params)
+		if params.Cursor == "" {
+			result = listToolsResult{Tools: s.tools[:1], NextCursor: "page2"}
+		} else {
+			result = listToolsResult{Tools: s.tools[1:]}
+		}
+	case MethodToolsCall:
+		var params callToolParams
+		json.Unmarshal(msg.Params, &params)
+		switch params.Name {
+		case "echo":
+			result = CallToolResult{Content: []Content{TextContent(fmt.Sprint(params.Arguments["text"]))}}
+		case "add":
+			a, _ := params.Arguments["a"].(float64)
+			b, _ := params.Arguments["b"].(float64)
+			result = CallToolResult{
+				Content:           []Content{TextContent(fmt.Sprint(a + b))},
+				StructuredContent: map[string]interface{}{"sum": a + b},
+			}
+		case "describe":
+			result = CallToolResult{Content: []Content{TextContent(fmt.Sprintf("%T", params.Arguments["value"]))}}
+		case "crash":
+			os.Exit(1)
+		default:
+			result = CallToolResult{Content: []Content{TextContent("boom")}, IsError: true}
+		}
+	default:
+		reply.Error = &RPCError{Code: CodeMethodNotFound, Message: "method not found"}
+		return reply
+	}
+
+	reply.Result, _ = json.Marshal(result)
+	return reply
+}
+
+func runStdioServer() {
+	server := newFakeServer()
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		var msg message
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			continue
+		}
+		if reply := server.handle(msg); reply != nil {
+			data, _ := json.Marshal(reply)
+			fmt.Fprintf(os.Stdout, "%s\n", data)
+		}
+	}
+}
+
+// httpFakeServer serves fakeServer over the streamable HTTP transport
+type httpFakeServer struct {
+	*fakeServer
+	sessions map[string]bool
+	nextID   int
+	notify   chan string
+}
+
+func newHTTPFakeServer() *httpFakeServer {
+	return &httpFakeServer{fakeServer: newFakeServer(), sessions: make(map[string]bool), notify: make(chan string, 10)}
+}
+
+func (s *httpFakeServer) expireSessions() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions = make(map[string]bool)
+}
+
+func (s *httpFakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	sessionID := r.Header.Get(headerSessionID)
+	valid := s.sessions[sessionID]
+	s.mu.Unlock()
+
+	switch r.Method {
+	case http.MethodGet:
+		if !valid {
+			http.Error(w, "unknown session", http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case method := <-s.notify:
+				fmt.Fprintf(w, "data: {\"jsonrpc\":\"2.0\",\"method\":%q}\n\n", method)
+				w.(http.Flusher).Flush()
+			}
+		}
+
+	case http.MethodDelete:
+		s.mu.Lock()
+		delete(s.sessions, sessionID)
+		s.mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+
+	case http.MethodPost:
+		var msg message
+		if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if msg.Method == MethodInitialize {
+			s.mu.Lock()
+			s.nextID++
+			sessionID = fmt.Sprintf("session-%d", s.nextID)
+			s.sessions[sessionID] = true
+			s.mu.Unlock()
+			w.Header().Set(headerSessionID, sessionID)
+		} else if !valid {
+			http.Error(w, "unknown session", http.StatusNotFound)
+			return
+		}
+
+		reply := s.handle(msg)
+		if reply == nil {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		data, _ := json.Marshal(reply)
+		if msg.Method == MethodToolsCall {
+			// Answer tool calls as an SSE stream
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprintf(w, "event: message\ndata: %s\n\n", data)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func newHTTPClient(t *testing.T, url string, config ClientConfig) *Client {
+	t.Helper()
+	config.HTTP = &HTTPConfig{URL: url}
+	config.ReconnectDelay = 10 * time.Millisecond
+	config.Timeout = 5 * time.Second
+	client, err := NewClient(config)
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestNewClientValidation(t *testing.T) {
+	if _, err := NewClient(ClientConfig{}); err == nil {
+		t.Error("Expected error without a transport")
+	}
+	if _, err := NewClient(ClientConfig{Stdio: &StdioConfig{Command: "x"}, HTTP: &HTTPConfig{URL: "http://x"}}); err == nil {
+		t.Error("Expected error with two transports")
+	}
+}
+
+func TestHTTPClientTools(t *testing.T) {
+	fake := newHTTPFakeServer()
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close) // After the client closed its notification stream
+
+	client := newHTTPClient(t, server.URL, ClientConfig{Name: "fake"})
+	ctx := context.Background()
+
+	if err := client.Connect(ctx); err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	if info := client.ServerInfo(); info.Name != "fake" {
+		t.Errorf("Expected server name fake, got %s", info.Name)
+	}
+	if client.Instructions() != "Use echo to test" {
+		t.Errorf("Expected instructions, got %q", client.Instructions())
+	}
+	if err := client.Ping(ctx); err != nil {
+		t.Errorf("Ping failed: %v", err)
+	}
+
+	registry := tools.NewRegistry()
+	if err := client.Register(ctx, registry); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if registry.Count() != 3 {
+		t.Fatalf("Expected 3 tools across both pages, got %v", registry.Names())
+	}
+
+	echo := registry.Get("fake__echo")
+	if echo == nil {
+		t.Fatal("Expected namespaced tool fake__echo")
+	}
+	if echo.Category() != tools.CategoryMCP || !echo.IsSafe() {
+		t.Errorf("Expected safe MCP tool, got category %s safe %v", echo.Category(), echo.IsSafe())
+	}
+	if echo.Parameters().Properties["text"] == nil {
+		t.Error("Expected text parameter from input schema")
+	}
+	if registry.Get("fake__add").IsSafe() {
+		t.Error("Expected tool without read-only hint to be unsafe")
+	}
+
+	result, err := registry.Execute(ctx, "fake__echo", map[string]interface{}{"text": "hello"})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if result != "hello" {
+		t.Errorf("Expected hello, got %v", result)
+	}
+
+	result, err = registry.Execute(ctx, "fake__add", map[string]interface{}{"a": 2, "b": 3})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if sum, _ := result.(map[string]interface{})["sum"].(float64); sum != 5 {
+		t.Errorf("Expected structured sum 5, got %v", result)
+	}
+
+	if _, err := registry.Execute(ctx, "fake__fail", nil); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("Expected tool error with boom, got %v", err)
+	}
+}
+
+func TestRegistryPassesMCPArgumentsThrough(t *testing.T) {
+	fake := newHTTPFakeServer()
+	fake.addTool(Tool{
+		Name:        "describe",
+		InputSchema: json.RawMessage(`{"type":"object","properties":{"value":{"type":["string","number"]},"id":{"anyOf":[{"type":"integer"},{"type":"string"}]}}}`),
+	})
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	client := newHTTPClient(t, server.URL, ClientConfig{Name: "fake"})
+	ctx := context.Background()
+	registry := tools.NewRegistry()
+	if err := client.Register(ctx, registry); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	// The converted schema keeps only "string"; the server accepts numbers
+	result, err := registry.Execute(ctx, "fake__describe", map[string]interface{}{"value": 5, "id": "abc"})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if result != "float64" {
+		t.Errorf("Expected the number to reach the server unchanged, got %v", result)
+	}
+}
+
+func TestHTTPClientToolListChanged(t *testing.T) {
+	fake := newHTTPFakeServer()
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	changed := make(chan []Tool, 1)
+	client := newHTTPClient(t, server.URL, ClientConfig{
+		Name:           "fake",
+		OnToolsChanged: func(tools []Tool) { changed <- tools },
+	})
+
+	registry := tools.NewRegistry()
+	if err := client.Register(context.Background(), registry); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	fake.addTool(Tool{Name: "extra", InputSchema: json.RawMessage(`{"type":"object"}`)})
+	fake.notify <- MethodToolsListChanged
+
+	select {
+	case list := <-changed:
+		if len(list) != 4 {
+			t.Errorf("Expected 4 tools after change, got %d", len(list))
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for tool list refresh")
+	}
+	if !registry.Has("fake__extra") {
+		t.Errorf("Expected registry to gain fake__extra, got %v", registry.Names())
+	}
+}
+
+func TestHTTPClientSessionExpiry(t *testing.T) {
+	fake := newHTTPFakeServer()
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	client := newHTTPClient(t, server.URL, ClientConfig{})
+	ctx := context.Background()
+
+	if _, err := client.CallTool(ctx, "echo", map[string]interface{}{"text": "one"}); err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+
+	fake.expireSessions()
+
+	result, err := client.CallTool(ctx, "echo", map[string]interface{}{"text": "two"})
+	if err != nil {
+		t.Fatalf("Expected call to succeed after reconnecting, got %v", err)
+	}
+	if result.Text() != "two" {
+		t.Errorf("Expected two, got %q", result.Text())
+	}
+
+	fake.mu.Lock()
+	initialized := fake.initialized
+	fake.mu.Unlock()
+	if initialized != 2 {
+		t.Errorf("Expected 2 initializations, got %d", initialized)
+	}
+}
+
+func TestStdioClientReconnect(t *testing.T) {
+	executable, err := os.Executable()
+	if err != nil {
+		t.Skipf("Cannot locate test binary: %v", err)
+	}
+
+	client, err := NewClient(ClientConfig{
+		Name:           "local",
+		Stdio:          &StdioConfig{Command: executable, Env: []string{"MCP_FAKE_SERVER=1"}},
+		ReconnectDelay: 10 * time.Millisecond,
+		Timeout:        5 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	list, err := client.Tools(ctx)
+	if err != nil {
+		t.Fatalf("Tools failed: %v", err)
+	}
+	if len(list) != 3 || list[0].Name() != "local__echo" {
+		t.Fatalf("Expected 3 namespaced tools, got %d", len(list))
+	}
+
+	// The server exits while handling the call; the client reports the error
+	if _, err := client.CallTool(ctx, "crash", nil); err == nil {
+		t.Error("Expected error when the server exits")
+	}
+
+	// and starts a new server process for the next request
+	result, err := client.CallTool(ctx, "echo", map[string]interface{}{"text": "back"})
+	if err != nil {
+		t.Fatalf("Expected reconnect, got %v", err)
+	}
+	if result.Text() != "back" {
+		t.Errorf("Expected back, got %q", result.Text())
+	}
+
+	client.Close()
+	if err := client.Ping(ctx); err != ErrClosed {
+		t.Errorf("Expected ErrClosed after Close, got %v", err)
+	}
+}
+
+func TestCallToolResultText(t *testing.T) {
+	result := CallToolResult{Content: []Content{
+		TextContent("line one"),
+		{Type: "image", MimeType: "image/png", Data: "AAAA"},
+		{Type: "resource_link", URI: "file:///a.txt"},
+		{Type: "resource", Resource: json.RawMessage(`{"uri":"file:///b.txt","text":"inline"}`)},
+	}}
+
+	want := "line one\n[image image/png]\n[resource file:///a.txt]\ninline"
+	if got := result.Text(); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
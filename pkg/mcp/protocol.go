@@ -0,0 +1,165 @@
+// Package mcp implements the Model Context Protocol (MCP) tool surface: a
+// Client that imports the tools of MCP servers into a tools.Registry, and a
+// Server that exposes a tools.Registry to MCP clients.
+//
+// Only the parts of the protocol needed for tools are implemented:
+// initialization, ping, tools/list (with pagination), tools/call and the
+// notifications/tools/list_changed notification, over the stdio and
+// streamable HTTP transports.
+package mcp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// ProtocolVersion is the MCP revision requested during initialization
+const ProtocolVersion = "2025-06-18"
+
+// supportedVersions lists the revisions this package can speak, newest first
+var supportedVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+// MCP methods used by this package
+const (
+	MethodInitialize       = "initialize"
+	MethodInitialized      = "notifications/initialized"
+	MethodPing             = "ping"
+	MethodToolsList        = "tools/list"
+	MethodToolsCall        = "tools/call"
+	MethodToolsListChanged = "notifications/tools/list_changed"
+	MethodCancelled        = "notifications/cancelled"
+)
+
+// JSON-RPC error codes
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+)
+
+// ErrClosed is returned when the connection to the server is gone
+var ErrClosed = errors.New("mcp: connection closed")
+
+// message is a JSON-RPC 2.0 request, notification or response
+type message struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method,omitempty"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+	Result  json.RawMessage  `json:"result,omitempty"`
+	Error   *RPCError        `json:"error,omitempty"`
+}
+
+func (m *message) isRequest() bool      { return m.Method != "" && m.ID != nil }
+func (m *message) isNotification() bool { return m.Method != "" && m.ID == nil }
+func (m *message) isResponse() bool     { return m.Method == "" && m.ID != nil }
+
+// RPCError is a JSON-RPC error returned by the peer
+type RPCError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("mcp error %d: %s", e.Code, e.Message)
+}
+
+// Implementation names an MCP client or server
+type Implementation struct {
+	Name    string `json:"name"`
+	Title   string `json:"title,omitempty"`
+	Version string `json:"version"`
+}
+
+type initializeParams struct {
+	ProtocolVersion string                 `json:"protocolVersion"`
+	Capabilities    map[string]interface{} `json:"capabilities"`
+	ClientInfo      Implementation         `json:"clientInfo"`
+}
+
+type initializeResult struct {
+	ProtocolVersion string             `json:"protocolVersion"`
+	Capabilities    ServerCapabilities `json:"capabilities"`
+	ServerInfo      Implementation     `json:"serverInfo"`
+	Instructions    string             `json:"instructions,omitempty"`
+}
+
+// ServerCapabilities are the features announced by a server
+type ServerCapabilities struct {
+	Tools *struct {
+		ListChanged bool `json:"listChanged,omitempty"`
+	} `json:"tools,omitempty"`
+	Resources json.RawMessage `json:"resources,omitempty"`
+	Prompts   json.RawMessage `json:"prompts,omitempty"`
+	Logging   json.RawMessage `json:"logging,omitempty"`
+}
+
+// Tool describes a tool offered by an MCP server
+type Tool struct {
+	Name         string           `json:"name"`
+	Title        string           `json:"title,omitempty"`
+	Description  string           `json:"description,omitempty"`
+	InputSchema  json.RawMessage  `json:"inputSchema"`
+	OutputSchema json.RawMessage  `json:"outputSchema,omitempty"`
+	Annotations  *ToolAnnotations `json:"annotations,omitempty"`
+}
+
+// ToolAnnotations are behavioural hints about a tool (untrusted unless the
+// server is trusted)
+type ToolAnnotations struct {
+	Title           string `json:"title,omitempty"`
+	ReadOnlyHint    *bool  `json:"readOnlyHint,omitempty"`
+	DestructiveHint *bool  `json:"destructiveHint,omitempty"`
+	IdempotentHint  *bool  `json:"idempotentHint,omitempty"`
+	OpenWorldHint   *bool  `json:"openWorldHint,omitempty"`
+}
+
+type listToolsParams struct {
+	Cursor string `json:"cursor,omitempty"`
+}
+
+type listToolsResult struct {
+	Tools      []Tool `json:"tools"`
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+type callToolParams struct {
+	Name      string                 `json:"name"`
+	Arguments map[string]interface{} `json:"arguments,omitempty"`
+}
+
+// CallToolResult is the outcome of tools/call
+type CallToolResult struct {
+	Content           []Content   `json:"content"`
+	StructuredContent interface{} `json:"structuredContent,omitempty"`
+	IsError           bool        `json:"isError,omitempty"`
+}
+
+// Content is one block of a tool result
+type Content struct {
+	Type     string          `json:"type"` // "text", "image", "audio", "resource" or "resource_link"
+	Text     string          `json:"text,omitempty"`
+	Data     string          `json:"data,omitempty"` // Base64 for image and audio
+	MimeType string          `json:"mimeType,omitempty"`
+	URI      string          `json:"uri,omitempty"` // resource_link
+	Name     string          `json:"name,omitempty"`
+	Resource json.RawMessage `json:"resource,omitempty"` // Embedded resource
+}
+
+// TextContent creates a text content block
+func TextContent(text string) Content {
+	return Content{Type: "text", Text: text}
+}
+
+// negotiateVersion accepts the server's protocol version if we support it
+func negotiateVersion(version string) error {
+	if slices.Contains(supportedVersions, version) {
+		return nil
+	}
+	return fmt.Errorf("unsupported MCP protocol version %q", version)
+}
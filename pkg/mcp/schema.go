@@ -0,0 +1,207 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/taipm/go-llm-agent/pkg/types"
+)
+
+// maxSchemaDepth bounds $ref expansion for recursive schemas
+const maxSchemaDepth = 16
+
+// ConvertSchema maps a JSON Schema document into types.JSONSchema.
+// types.JSONSchema covers the subset LLM providers use, so the mapping is
+// lossy:
+//   - local $ref ("#/$defs/..." or "#/definitions/...") are inlined
+//   - a type list such as ["string", "null"] keeps the first non-null type
+//   - anyOf/oneOf/allOf without a type use the first usable alternative
+//   - "const" becomes a single-value enum
+//   - keywords without a counterpart (format, pattern, ...) are dropped;
+//     format and default are appended to the description
+//
+// An empty or missing schema becomes an object without properties.
+func ConvertSchema(raw json.RawMessage) (*types.JSONSchema, error) {
+	if len(strings.TrimSpace(string(raw))) == 0 || string(raw) == "null" {
+		return &types.JSONSchema{Type: "object", Properties: map[string]*types.JSONSchema{}}, nil
+	}
+
+	var root map[string]interface{}
+	if err := json.Unmarshal(raw, &root); err != nil {
+		return nil, fmt.Errorf("invalid JSON schema: %w", err)
+	}
+
+	c := &schemaConverter{root: root}
+	schema := c.convert(root, 0)
+	if schema.Type == "" {
+		schema.Type = "object"
+	}
+	if schema.Type == "object" && schema.Properties == nil {
+		schema.Properties = map[string]*types.JSONSchema{}
+	}
+	return schema, nil
+}
+
+type schemaConverter struct {
+	root map[string]interface{}
+}
+
+func (c *schemaConverter) convert(node map[string]interface{}, depth int) *types.JSONSchema {
+	schema := &types.JSONSchema{}
+	if node == nil || depth > maxSchemaDepth {
+		return schema
+	}
+
+	if ref, ok := node["$ref"].(string); ok {
+		if target := c.resolve(ref); target != nil {
+			resolved := c.convert(target, depth+1)
+			if desc, ok := node["description"].(string); ok && desc != "" {
+				resolved.Description = desc
+			}
+			return resolved
+		}
+	}
+
+	schema.Type = schemaType(node["type"])
+	schema.Description, _ = node["description"].(string)
+
+	// Without a type, take the first usable alternative
+	if schema.Type == "" {
+		for _, key := range []string{"anyOf", "oneOf", "allOf"} {
+			alternatives, _ := node[key].([]interface{})
+			for _, alt := range alternatives {
+				altNode, ok := alt.(map[string]interface{})
+				if !ok || schemaType(altNode["type"]) == "null" {
+					continue
+				}
+				converted := c.convert(altNode, depth+1)
+				if schema.Description != "" {
+					converted.Description = schema.Description
+				}
+				return converted
+			}
+		}
+	}
+
+	if props, ok := node["properties"].(map[string]interface{}); ok {
+		schema.Properties = make(map[string]*types.JSONSchema, len(props))
+		for name, prop := range props {
+			propNode, _ := prop.(map[string]interface{})
+			schema.Properties[name] = c.convert(propNode, depth+1)
+		}
+		if schema.Type == "" {
+			schema.Type = "object"
+		}
+	}
+
+	if required, ok := node["required"].([]interface{}); ok {
+		for _, r := range required {
+			if name, ok := r.(string); ok {
+				schema.Required = append(schema.Required, name)
+			}
+		}
+	}
+
+	if items, ok := node["items"].(map[string]interface{}); ok {
+		schema.Items = c.convert(items, depth+1)
+		if schema.Type == "" {
+			schema.Type = "array"
+		}
+	}
+
+	if enum, ok := node["enum"].([]interface{}); ok {
+		schema.Enum = enum
+	} else if constant, ok := node["const"]; ok {
+		schema.Enum = []interface{}{constant}
+	}
+
+	switch additional := node["additionalProperties"].(type) {
+	case bool:
+		schema.AdditionalProperties = additional
+	case map[string]interface{}:
+		schema.AdditionalProperties = c.convert(additional, depth+1)
+	}
+
+	schema.Minimum = numberKeyword(node, "minimum")
+	schema.Maximum = numberKeyword(node, "maximum")
+	schema.MinLength = intKeyword(node, "minLength")
+	schema.MaxLength = intKeyword(node, "maxLength")
+	schema.MinItems = intKeyword(node, "minItems")
+	schema.MaxItems = intKeyword(node, "maxItems")
+
+	var notes []string
+	if format, ok := node["format"].(string); ok && format != "" {
+		notes = append(notes, "format: "+format)
+	}
+	if def, ok := node["default"]; ok {
+		if encoded, err := json.Marshal(def); err == nil {
+			notes = append(notes, "default: "+string(encoded))
+		}
+	}
+	if len(notes) > 0 {
+		note := "(" + strings.Join(notes, ", ") + ")"
+		if schema.Description == "" {
+			schema.Description = note
+		} else {
+			schema.Description += " " + note
+		}
+	}
+
+	return schema
+}
+
+// resolve looks up a local reference such as "#/$defs/Item"
+func (c *schemaConverter) resolve(ref string) map[string]interface{} {
+	path, ok := strings.CutPrefix(ref, "#/")
+	if !ok {
+		return nil
+	}
+	var node interface{} = c.root
+	for _, part := range strings.Split(path, "/") {
+		part = strings.ReplaceAll(strings.ReplaceAll(part, "~1", "/"), "~0", "~")
+		m, ok := node.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		node = m[part]
+	}
+	target, _ := node.(map[string]interface{})
+	return target
+}
+
+// schemaType reads "type" given as a string or a list of strings
+func schemaType(v interface{}) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case []interface{}:
+		for _, item := range t {
+			if s, ok := item.(string); ok && s != "null" {
+				return s
+			}
+		}
+		if len(t) > 0 {
+			s, _ := t[0].(string)
+			return s
+		}
+	}
+	return ""
+}
+
+// numberKeyword reads a numeric keyword such as "minimum"
+func numberKeyword(node map[string]interface{}, key string) *float64 {
+	if v, ok := node[key].(float64); ok {
+		return &v
+	}
+	return nil
+}
+
+// intKeyword reads a count keyword such as "maxLength"
+func intKeyword(node map[string]interface{}, key string) *int {
+	if v, ok := node[key].(float64); ok && v >= 0 {
+		n := int(v)
+		return &n
+	}
+	return nil
+}
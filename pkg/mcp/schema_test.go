@@ -0,0 +1,135 @@
+package mcp
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestConvertSchema(t *testing.T) {
+	raw := json.RawMessage(`{
+		"type": "object",
+		"properties": {
+			"query": {"type": "string", "description": "Search text"},
+			"limit": {"type": ["integer", "null"], "default": 10, "minimum": 1, "maximum": 50},
+			"mode": {"const": "fast"},
+			"tags": {"type": "array", "items": {"$ref": "#/$defs/Tag"}},
+			"when": {"anyOf": [{"type": "null"}, {"type": "string", "format": "date"}]}
+		},
+		"required": ["query"],
+		"additionalProperties": false,
+		"$defs": {
+			"Tag": {"type": "string", "enum": ["a", "b"]}
+		}
+	}`)
+
+	schema, err := ConvertSchema(raw)
+	if err != nil {
+		t.Fatalf("ConvertSchema failed: %v", err)
+	}
+
+	if schema.Type != "object" {
+		t.Errorf("Expected type object, got %s", schema.Type)
+	}
+	if len(schema.Required) != 1 || schema.Required[0] != "query" {
+		t.Errorf("Expected required [query], got %v", schema.Required)
+	}
+	if schema.AdditionalProperties != false {
+		t.Errorf("Expected additionalProperties false, got %v", schema.AdditionalProperties)
+	}
+
+	tests := []struct {
+		name        string
+		wantType    string
+		wantDesc    string
+		wantEnumLen int
+	}{
+		{"query", "string", "Search text", 0},
+		{"limit", "integer", "(default: 10)", 0},
+		{"mode", "", "", 1},
+		{"when", "string", "(format: date)", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prop := schema.Properties[tt.name]
+			if prop == nil {
+				t.Fatalf("Expected property %s", tt.name)
+			}
+			if prop.Type != tt.wantType {
+				t.Errorf("Expected type %q, got %q", tt.wantType, prop.Type)
+			}
+			if prop.Description != tt.wantDesc {
+				t.Errorf("Expected description %q, got %q", tt.wantDesc, prop.Description)
+			}
+			if len(prop.Enum) != tt.wantEnumLen {
+				t.Errorf("Expected %d enum values, got %v", tt.wantEnumLen, prop.Enum)
+			}
+		})
+	}
+
+	limit := schema.Properties["limit"]
+	if limit.Minimum == nil || *limit.Minimum != 1 || limit.Maximum == nil || *limit.Maximum != 50 {
+		t.Errorf("Expected bounds 1..50, got %v..%v", limit.Minimum, limit.Maximum)
+	}
+
+	tags := schema.Properties["tags"]
+	if tags.Type != "array" || tags.Items == nil || tags.Items.Type != "string" || len(tags.Items.Enum) != 2 {
+		t.Errorf("Expected $ref to be inlined into items, got %+v", tags.Items)
+	}
+}
+
+func TestConvertSchemaEmpty(t *testing.T) {
+	for _, raw := range []string{"", "null", "{}"} {
+		schema, err := ConvertSchema(json.RawMessage(raw))
+		if err != nil {
+			t.Fatalf("ConvertSchema(%q) failed: %v", raw, err)
+		}
+		if schema.Type != "object" || schema.Properties == nil {
+			t.Errorf("Expected empty object schema for %q, got %+v", raw, schema)
+		}
+	}
+
+	if _, err := ConvertSchema(json.RawMessage("[1,2]")); err == nil {
+		t.Error("Expected error for non-object schema")
+	}
+}
+
+func TestConvertSchemaRecursive(t *testing.T) {
+	raw := json.RawMessage(`{
+		"$ref": "#/definitions/Node",
+		"definitions": {
+			"Node": {
+				"type": "object",
+				"properties": {"children": {"type": "array", "items": {"$ref": "#/definitions/Node"}}}
+			}
+		}
+	}`)
+
+	schema, err := ConvertSchema(raw)
+	if err != nil {
+		t.Fatalf("ConvertSchema failed: %v", err)
+	}
+	if schema.Properties["children"] == nil {
+		t.Fatal("Expected recursive schema to be expanded")
+	}
+}
+
+func TestToolName(t *testing.T) {
+	tests := []struct {
+		server, tool, want string
+	}{
+		{"docs", "search", "docs__search"},
+		{"", "search", "search"},
+		{"my server", "read.file", "my_server__read_file"},
+	}
+	for _, tt := range tests {
+		if got := ToolName(tt.server, tt.tool); got != tt.want {
+			t.Errorf("ToolName(%q, %q): expected %q, got %q", tt.server, tt.tool, tt.want, got)
+		}
+	}
+
+	long := ToolName("server", strings.Repeat("x", 100))
+	if len(long) != maxToolNameLength {
+		t.Errorf("Expected name truncated to %d, got %d", maxToolNameLength, len(long))
+	}
+}
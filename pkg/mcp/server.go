@@ -0,0 +1,539 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/taipm/go-llm-agent/pkg/logger"
+	"github.com/taipm/go-llm-agent/pkg/tools"
+)
+
+// defaultPageSize is the number of tools per tools/list page
+const defaultPageSize = 100
+
+// ServerConfig configures a Server
+type ServerConfig struct {
+	// Registry holds the tools to serve (required)
+	Registry *tools.Registry
+
+	// Info identifies the server to clients (default "go-llm-agent")
+	Info Implementation
+
+	// Instructions tell clients how to use the server (optional)
+	Instructions string
+
+	// AllowUnsafe also serves tools whose IsSafe() is false. MCP clients
+	// usually ask the user before calling tools not marked read-only.
+	AllowUnsafe bool
+
+	// AllowAuth also serves tools whose RequiresAuth() is true
+	AllowAuth bool
+
+	// Filter, when set, must return true for a tool to be served
+	Filter func(tools.Tool) bool
+
+	// Timeout bounds each tool call (default 60s)
+	Timeout time.Duration
+
+	// PageSize is the number of tools per tools/list page (default 100)
+	PageSize int
+
+	// SessionTTL ends HTTP sessions idle for this long (default 1h)
+	SessionTTL time.Duration
+
+	// APIKeys are the accepted bearer tokens over HTTP; empty disables
+	// authentication
+	APIKeys []string
+
+	// AllowedOrigins are the browser origins accepted over HTTP ("*" allows
+	// any). Requests with an Origin header not listed are rejected to
+	// prevent DNS rebinding.
+	AllowedOrigins []string
+
+	// Logger logs requests and failures (default: no logging). Over stdio it
+	// must not write to stdout.
+	Logger logger.Logger
+}
+
+// Server exposes a tools.Registry as an MCP server over stdio or
+// streamable HTTP
+type Server struct {
+	config ServerConfig
+	logger logger.Logger
+
+	mu       sync.Mutex
+	sessions map[string]*serverSession
+}
+
+// serverSession is one client connection
+type serverSession struct {
+	id     string
+	send   func(msg []byte) error // Delivers server-initiated messages
+	stream *httpStream            // GET stream of HTTP sessions
+	done   chan struct{}          // Closed when the session ends
+
+	mu       sync.Mutex
+	inflight map[string]context.CancelFunc
+	lastUsed time.Time
+}
+
+// NewServer creates an MCP server for a registry
+func NewServer(config ServerConfig) (*Server, error) {
+	if config.Registry == nil {
+		return nil, fmt.Errorf("mcp server requires a registry")
+	}
+	if config.Info.Name == "" {
+		config.Info = Implementation{Name: "go-llm-agent", Version: "1.0.0"}
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 60 * time.Second
+	}
+	if config.PageSize <= 0 {
+		config.PageSize = defaultPageSize
+	}
+	if config.SessionTTL <= 0 {
+		config.SessionTTL = time.Hour
+	}
+	if config.Logger == nil {
+		config.Logger = &logger.NoopLogger{}
+	}
+
+	return &Server{
+		config:   config,
+		logger:   config.Logger,
+		sessions: make(map[string]*serverSession),
+	}, nil
+}
+
+// Tools returns the tools the server exposes, sorted by name
+func (s *Server) Tools() []tools.Tool {
+	var served []tools.Tool
+	for _, tool := range s.config.Registry.All() {
+		if s.serves(tool) {
+			served = append(served, tool)
+		}
+	}
+	sort.Slice(served, func(i, j int) bool { return served[i].Name() < served[j].Name() })
+	return served
+}
+
+// serves reports whether a tool passes the configured filters
+func (s *Server) serves(tool tools.Tool) bool {
+	if !tool.IsSafe() && !s.config.AllowUnsafe {
+		return false
+	}
+	if tool.RequiresAuth() && !s.config.AllowAuth {
+		return false
+	}
+	return s.config.Filter == nil || s.config.Filter(tool)
+}
+
+// NotifyToolsChanged tells connected clients to list the tools again; call
+// it after changing the registry
+func (s *Server) NotifyToolsChanged() {
+	data, _ := json.Marshal(message{JSONRPC: "2.0", Method: MethodToolsListChanged})
+
+	s.mu.Lock()
+	sessions := make([]*serverSession, 0, len(s.sessions))
+	for _, session := range s.sessions {
+		sessions = append(sessions, session)
+	}
+	s.mu.Unlock()
+
+	for _, session := range sessions {
+		if err := session.send(data); err != nil {
+			s.logger.Debug("MCP session %s: notification not delivered: %v", session.id, err)
+		}
+	}
+}
+
+// ServeStdio serves one client speaking newline delimited JSON-RPC on r and
+// w (usually os.Stdin and os.Stdout) until r is exhausted or ctx is done.
+// Requests are handled concurrently.
+func (s *Server) ServeStdio(ctx context.Context, r io.Reader, w io.Writer) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var writeMu sync.Mutex
+	write := func(msg []byte) error {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		_, err := w.Write(append(msg, '\n'))
+		return err
+	}
+
+	session := s.addSession(write, nil)
+	defer s.removeSession(session.id)
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	lines := make(chan []byte)
+	readErr := make(chan error, 1)
+	go func() {
+		readErr <- readLines(r, func(line []byte) bool {
+			select {
+			case lines <- line:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-readErr:
+			return err
+		case line := <-lines:
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for _, reply := range s.handle(ctx, session, line) {
+					if err := write(reply); err != nil {
+						s.logger.Error("❌ MCP stdio write failed: %v", err)
+					}
+				}
+			}()
+		}
+	}
+}
+
+// readLines passes each non-empty line of r to yield until yield returns false
+func readLines(r io.Reader, yield func([]byte) bool) error {
+	reader := bufio.NewReaderSize(r, 64*1024)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if line = bytes.TrimSpace(line); len(line) > 0 && !yield(line) {
+			return nil
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) addSession(send func([]byte) error, stream *httpStream) *serverSession {
+	session := &serverSession{
+		id:       newSessionID(),
+		send:     send,
+		stream:   stream,
+		done:     make(chan struct{}),
+		inflight: make(map[string]context.CancelFunc),
+		lastUsed: time.Now(),
+	}
+
+	s.expireSessions()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions[session.id] = session
+	return session
+}
+
+// newSessionID returns a random, unguessable session ID
+func newSessionID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func (s *Server) session(id string) *serverSession {
+	s.mu.Lock()
+	session := s.sessions[id]
+	s.mu.Unlock()
+
+	if session != nil {
+		session.mu.Lock()
+		session.lastUsed = time.Now()
+		session.mu.Unlock()
+	}
+	return session
+}
+
+// expireSessions ends HTTP sessions idle for longer than SessionTTL whose
+// clients went away without deleting them
+func (s *Server) expireSessions() {
+	s.mu.Lock()
+	var expired []string
+	for id, session := range s.sessions {
+		session.mu.Lock()
+		idle := session.stream != nil && !session.stream.isActive() && time.Since(session.lastUsed) > s.config.SessionTTL && len(session.inflight) == 0
+		session.mu.Unlock()
+		if idle {
+			expired = append(expired, id)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, id := range expired {
+		s.removeSession(id)
+	}
+}
+
+func (s *Server) removeSession(id string) {
+	s.mu.Lock()
+	session := s.sessions[id]
+	delete(s.sessions, id)
+	s.mu.Unlock()
+
+	if session != nil {
+		close(session.done)
+		session.mu.Lock()
+		for _, cancel := range session.inflight {
+			cancel()
+		}
+		session.mu.Unlock()
+	}
+}
+
+// handle processes a message or batch and returns the responses to send
+func (s *Server) handle(ctx context.Context, session *serverSession, data []byte) [][]byte {
+	var replies [][]byte
+	err := deliverJSON(data, func(raw []byte) {
+		if reply := s.handleMessage(ctx, session, raw); reply != nil {
+			if encoded, err := json.Marshal(reply); err == nil {
+				replies = append(replies, encoded)
+			}
+		}
+	})
+	if err != nil {
+		encoded, _ := json.Marshal(errorReply(nil, CodeParseError, err.Error()))
+		replies = append(replies, encoded)
+	}
+	return replies
+}
+
+// handleMessage processes one message; notifications and responses from
+// the client yield no reply
+func (s *Server) handleMessage(ctx context.Context, session *serverSession, raw []byte) *message {
+	var msg message
+	if err := json.Unmarshal(raw, &msg); err != nil {
+		return errorReply(nil, CodeParseError, "invalid JSON: "+err.Error())
+	}
+	if msg.JSONRPC != "2.0" {
+		return errorReply(msg.ID, CodeInvalidRequest, "jsonrpc must be \"2.0\"")
+	}
+
+	if msg.isNotification() {
+		if msg.Method == MethodCancelled {
+			var params struct {
+				RequestID json.RawMessage `json:"requestId"`
+			}
+			if json.Unmarshal(msg.Params, &params) == nil {
+				session.cancel(string(params.RequestID))
+			}
+		}
+		return nil
+	}
+	if !msg.isRequest() {
+		return nil // Responses to our pings are not needed
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	key := string(*msg.ID)
+	session.track(key, cancel)
+	defer session.untrack(key)
+
+	result, err := s.dispatch(ctx, msg)
+	if err != nil {
+		if rpcErr, ok := err.(*RPCError); ok {
+			return &message{JSONRPC: "2.0", ID: msg.ID, Error: rpcErr}
+		}
+		return errorReply(msg.ID, CodeInternalError, err.Error())
+	}
+
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		return errorReply(msg.ID, CodeInternalError, "failed to encode result: "+err.Error())
+	}
+	return &message{JSONRPC: "2.0", ID: msg.ID, Result: encoded}
+}
+
+// dispatch runs a request; protocol failures are returned as *RPCError
+func (s *Server) dispatch(ctx context.Context, msg message) (interface{}, error) {
+	switch msg.Method {
+	case MethodInitialize:
+		var params initializeParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, &RPCError{Code: CodeInvalidParams, Message: "invalid initialize params"}
+		}
+		version := params.ProtocolVersion
+		if negotiateVersion(version) != nil {
+			version = ProtocolVersion // Offer ours; the client decides
+		}
+		s.logger.Debug("🔌 MCP client %s %s connected (protocol %s)", params.ClientInfo.Name, params.ClientInfo.Version, version)
+
+		result := initializeResult{
+			ProtocolVersion: version,
+			ServerInfo:      s.config.Info,
+			Instructions:    s.config.Instructions,
+		}
+		result.Capabilities.Tools = &struct {
+			ListChanged bool `json:"listChanged,omitempty"`
+		}{ListChanged: true}
+		return result, nil
+
+	case MethodPing:
+		return struct{}{}, nil
+
+	case MethodToolsList:
+		var params listToolsParams
+		if len(msg.Params) > 0 {
+			if err := json.Unmarshal(msg.Params, &params); err != nil {
+				return nil, &RPCError{Code: CodeInvalidParams, Message: "invalid tools/list params"}
+			}
+		}
+		return s.listTools(params.Cursor)
+
+	case MethodToolsCall:
+		var params callToolParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, &RPCError{Code: CodeInvalidParams, Message: "invalid tools/call params"}
+		}
+		return s.callTool(ctx, params)
+
+	default:
+		return nil, &RPCError{Code: CodeMethodNotFound, Message: "method not found: " + msg.Method}
+	}
+}
+
+// listTools returns the page of tools starting at cursor (an offset)
+func (s *Server) listTools(cursor string) (*listToolsResult, error) {
+	served := s.Tools()
+
+	start := 0
+	if cursor != "" {
+		offset, err := strconv.Atoi(cursor)
+		if err != nil || offset < 0 || offset > len(served) {
+			return nil, &RPCError{Code: CodeInvalidParams, Message: "invalid cursor"}
+		}
+		start = offset
+	}
+	end := min(start+s.config.PageSize, len(served))
+
+	result := &listToolsResult{Tools: make([]Tool, 0, end-start)}
+	for _, tool := range served[start:end] {
+		result.Tools = append(result.Tools, describeTool(tool))
+	}
+	if end < len(served) {
+		result.NextCursor = strconv.Itoa(end)
+	}
+	return result, nil
+}
+
+// describeTool maps a tools.Tool to its MCP description
+func describeTool(tool tools.Tool) Tool {
+	schema, err := json.Marshal(tool.Parameters())
+	if err != nil || tool.Parameters() == nil {
+		schema = json.RawMessage(`{"type":"object"}`)
+	}
+
+	readOnly := tool.IsSafe()
+	destructive := !readOnly
+	return Tool{
+		Name:        tool.Name(),
+		Description: tool.Description(),
+		InputSchema: schema,
+		Annotations: &ToolAnnotations{
+			ReadOnlyHint:    &readOnly,
+			DestructiveHint: &destructive,
+		},
+	}
+}
+
+// callTool runs a tool; tool failures become results with IsError set so
+// the model calling the tool can see them
+func (s *Server) callTool(ctx context.Context, params callToolParams) (*CallToolResult, error) {
+	tool := s.config.Registry.Get(params.Name)
+	if tool == nil || !s.serves(tool) {
+		return nil, &RPCError{Code: CodeInvalidParams, Message: "unknown tool: " + params.Name}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.config.Timeout)
+	defer cancel()
+
+	arguments := params.Arguments
+	if arguments == nil {
+		arguments = map[string]interface{}{}
+	}
+
+	start := time.Now()
+	output, err := s.config.Registry.Execute(ctx, params.Name, arguments)
+	if err != nil {
+		s.logger.Warn("⚠️  MCP tool %s failed after %v: %v", params.Name, time.Since(start), err)
+		return &CallToolResult{Content: []Content{TextContent(err.Error())}, IsError: true}, nil
+	}
+	s.logger.Debug("🔧 MCP tool %s completed in %v", params.Name, time.Since(start))
+
+	return toolResult(output), nil
+}
+
+// toolResult converts a tool's output into a result: strings are sent as
+// text, other values as JSON text plus structured content when they encode
+// to a JSON object
+func toolResult(output interface{}) *CallToolResult {
+	switch v := output.(type) {
+	case nil:
+		return &CallToolResult{Content: []Content{}}
+	case string:
+		return &CallToolResult{Content: []Content{TextContent(v)}}
+	case *CallToolResult:
+		return v
+	}
+
+	encoded, err := json.Marshal(output)
+	if err != nil {
+		return &CallToolResult{Content: []Content{TextContent(fmt.Sprint(output))}}
+	}
+	result := &CallToolResult{Content: []Content{TextContent(string(encoded))}}
+	if len(encoded) > 0 && encoded[0] == '{' {
+		result.StructuredContent = json.RawMessage(encoded)
+	}
+	return result
+}
+
+func errorReply(id *json.RawMessage, code int, text string) *message {
+	if id == nil {
+		null := json.RawMessage("null")
+		id = &null
+	}
+	return &message{JSONRPC: "2.0", ID: id, Error: &RPCError{Code: code, Message: text}}
+}
+
+func (session *serverSession) track(id string, cancel context.CancelFunc) {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	session.inflight[id] = cancel
+}
+
+func (session *serverSession) untrack(id string) {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	delete(session.inflight, id)
+}
+
+func (session *serverSession) cancel(id string) {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	if cancel, ok := session.inflight[id]; ok {
+		cancel()
+	}
+}
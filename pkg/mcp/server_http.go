@@ -0,0 +1,217 @@
+package mcp
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxRequestBytes limits the size of a request body
+const maxRequestBytes = 16 << 20
+
+// httpStream buffers server-initiated messages for a session's GET stream
+type httpStream struct {
+	mu     sync.Mutex
+	ch     chan []byte
+	active bool
+}
+
+func (st *httpStream) isActive() bool {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return st.active
+}
+
+func (st *httpStream) send(msg []byte) error {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if !st.active {
+		return fmt.Errorf("no open stream")
+	}
+	select {
+	case st.ch <- msg:
+		return nil
+	default:
+		return fmt.Errorf("stream buffer full")
+	}
+}
+
+// ServeHTTP implements the streamable HTTP transport on any path: POST
+// carries client messages (answered with JSON), GET opens an SSE stream for
+// server notifications and DELETE ends the session
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(r) {
+		http.Error(w, "invalid or missing API key", http.StatusUnauthorized)
+		return
+	}
+	if origin := r.Header.Get("Origin"); origin != "" && !s.allowedOrigin(origin) {
+		http.Error(w, "origin not allowed", http.StatusForbidden)
+		return
+	}
+	if version := r.Header.Get(headerProtocolVersion); version != "" && negotiateVersion(version) != nil {
+		http.Error(w, "unsupported protocol version "+version, http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		s.handlePost(w, r)
+	case http.MethodGet:
+		s.handleStream(w, r)
+	case http.MethodDelete:
+		id := r.Header.Get(headerSessionID)
+		if s.session(id) == nil {
+			http.Error(w, "unknown session", http.StatusNotFound)
+			return
+		}
+		s.removeSession(id)
+		w.WriteHeader(http.StatusOK)
+	default:
+		w.Header().Set("Allow", "GET, POST, DELETE")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// ListenAndServe serves the MCP endpoint at /mcp on addr
+func (s *Server) ListenAndServe(addr string) error {
+	mux := http.NewServeMux()
+	mux.Handle("/mcp", s)
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	s.logger.Info("🔌 MCP server listening on %s/mcp (%d tools)", addr, len(s.Tools()))
+	return srv.ListenAndServe()
+}
+
+func (s *Server) handlePost(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxRequestBytes))
+	if err != nil {
+		http.Error(w, "failed to read request", http.StatusBadRequest)
+		return
+	}
+	body = bytes.TrimSpace(body)
+
+	var session *serverSession
+	if isInitialize(body) {
+		stream := &httpStream{ch: make(chan []byte, 16)}
+		session = s.addSession(stream.send, stream)
+		w.Header().Set(headerSessionID, session.id)
+	} else {
+		id := r.Header.Get(headerSessionID)
+		if id == "" {
+			http.Error(w, "missing "+headerSessionID+" header", http.StatusBadRequest)
+			return
+		}
+		if session = s.session(id); session == nil {
+			http.Error(w, "unknown session", http.StatusNotFound)
+			return
+		}
+	}
+
+	replies := s.handle(r.Context(), session, body)
+	if len(replies) == 0 {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if len(body) > 0 && body[0] == '[' {
+		w.Write([]byte("[" + string(bytes.Join(replies, []byte(","))) + "]"))
+		return
+	}
+	w.Write(replies[0])
+}
+
+// handleStream serves server-initiated messages of a session as SSE
+func (s *Server) handleStream(w http.ResponseWriter, r *http.Request) {
+	if !strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		http.Error(w, "GET requires Accept: text/event-stream", http.StatusMethodNotAllowed)
+		return
+	}
+	session := s.session(r.Header.Get(headerSessionID))
+	if session == nil || session.stream == nil {
+		http.Error(w, "unknown session", http.StatusNotFound)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	stream := session.stream
+	stream.mu.Lock()
+	if stream.active {
+		stream.mu.Unlock()
+		http.Error(w, "stream already open", http.StatusConflict)
+		return
+	}
+	stream.active = true
+	stream.mu.Unlock()
+	defer func() {
+		stream.mu.Lock()
+		stream.active = false
+		stream.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(30 * time.Second)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-session.done:
+			return
+		case msg := <-stream.ch:
+			fmt.Fprintf(w, "event: message\ndata: %s\n\n", msg)
+			flusher.Flush()
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			flusher.Flush()
+		}
+	}
+}
+
+// isInitialize reports whether body is a single initialize request
+func isInitialize(body []byte) bool {
+	if len(body) == 0 || body[0] != '{' {
+		return false
+	}
+	var msg message
+	return json.Unmarshal(body, &msg) == nil && msg.Method == MethodInitialize && msg.ID != nil
+}
+
+// authorized checks the bearer token against the configured keys
+func (s *Server) authorized(r *http.Request) bool {
+	if len(s.config.APIKeys) == 0 {
+		return true
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return false
+	}
+	for _, key := range s.config.APIKeys {
+		if subtle.ConstantTimeCompare([]byte(strings.TrimSpace(token)), []byte(key)) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) allowedOrigin(origin string) bool {
+	return slices.Contains(s.config.AllowedOrigins, "*") || slices.Contains(s.config.AllowedOrigins, origin)
+}
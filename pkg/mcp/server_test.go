@@ -0,0 +1,319 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/taipm/go-llm-agent/pkg/tools"
+	"github.com/taipm/go-llm-agent/pkg/types"
+)
+
+// testTool is a configurable tools.Tool
+type testTool struct {
+	tools.BaseTool
+	run func(ctx context.Context, params map[string]interface{}) (interface{}, error)
+}
+
+func newTestTool(name string, requiresAuth, safe bool, run func(context.Context, map[string]interface{}) (interface{}, error)) *testTool {
+	return &testTool{
+		BaseTool: tools.NewBaseTool(name, "Test tool "+name, tools.CategoryMath, requiresAuth, safe),
+		run:      run,
+	}
+}
+
+func (t *testTool) Parameters() *types.JSONSchema {
+	return &types.JSONSchema{
+		Type:       "object",
+		Properties: map[string]*types.JSONSchema{"text": {Type: "string", Description: "Input text"}},
+		Required:   []string{"text"},
+	}
+}
+
+func (t *testTool) Execute(ctx context.Context, params map[string]interface{}) (interface{}, error) {
+	return t.run(ctx, params)
+}
+
+func newTestRegistry() *tools.Registry {
+	registry := tools.NewRegistry()
+	registry.MustRegister(newTestTool("upper", false, true, func(_ context.Context, p map[string]interface{}) (interface{}, error) {
+		return strings.ToUpper(p["text"].(string)), nil
+	}))
+	registry.MustRegister(newTestTool("stats", false, true, func(_ context.Context, p map[string]interface{}) (interface{}, error) {
+		return map[string]interface{}{"length": len(p["text"].(string))}, nil
+	}))
+	registry.MustRegister(newTestTool("broken", false, true, func(context.Context, map[string]interface{}) (interface{}, error) {
+		return nil, errors.New("disk on fire")
+	}))
+	registry.MustRegister(newTestTool("delete", false, false, func(context.Context, map[string]interface{}) (interface{}, error) {
+		return "deleted", nil
+	}))
+	registry.MustRegister(newTestTool("mail", true, true, func(context.Context, map[string]interface{}) (interface{}, error) {
+		return "sent", nil
+	}))
+	return registry
+}
+
+func TestServerFiltering(t *testing.T) {
+	tests := []struct {
+		name   string
+		config ServerConfig
+		want   []string
+	}{
+		{"default", ServerConfig{}, []string{"broken", "stats", "upper"}},
+		{"unsafe", ServerConfig{AllowUnsafe: true}, []string{"broken", "delete", "stats", "upper"}},
+		{"auth", ServerConfig{AllowAuth: true}, []string{"broken", "mail", "stats", "upper"}},
+		{"filter", ServerConfig{Filter: func(t tools.Tool) bool { return t.Name() != "broken" }}, []string{"stats", "upper"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.Registry = newTestRegistry()
+			server, err := NewServer(tt.config)
+			if err != nil {
+				t.Fatalf("NewServer failed: %v", err)
+			}
+			var names []string
+			for _, tool := range server.Tools() {
+				names = append(names, tool.Name())
+			}
+			if strings.Join(names, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Expected %v, got %v", tt.want, names)
+			}
+		})
+	}
+
+	if _, err := NewServer(ServerConfig{}); err == nil {
+		t.Error("Expected error without a registry")
+	}
+}
+
+func TestServerHTTPWithClient(t *testing.T) {
+	registry := newTestRegistry()
+	server, err := NewServer(ServerConfig{Registry: registry, PageSize: 2, Instructions: "Test tools"})
+	if err != nil {
+		t.Fatalf("NewServer failed: %v", err)
+	}
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+
+	changed := make(chan []Tool, 1)
+	client := newHTTPClient(t, httpServer.URL, ClientConfig{
+		Name:           "local",
+		OnToolsChanged: func(tools []Tool) { changed <- tools },
+	})
+	ctx := context.Background()
+
+	imported := tools.NewRegistry()
+	if err := client.Register(ctx, imported); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if imported.Count() != 3 {
+		t.Fatalf("Expected 3 tools over two pages, got %v", imported.Names())
+	}
+	if client.Instructions() != "Test tools" {
+		t.Errorf("Expected instructions, got %q", client.Instructions())
+	}
+
+	upper := imported.Get("local__upper")
+	if upper == nil || !upper.IsSafe() || upper.Parameters().Properties["text"] == nil {
+		t.Fatalf("Expected safe tool with text parameter, got %+v", upper)
+	}
+
+	result, err := imported.Execute(ctx, "local__upper", map[string]interface{}{"text": "hi"})
+	if err != nil || result != "HI" {
+		t.Errorf("Expected HI, got %v (%v)", result, err)
+	}
+
+	result, err = imported.Execute(ctx, "local__stats", map[string]interface{}{"text": "four"})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if length, _ := result.(map[string]interface{})["length"].(float64); length != 4 {
+		t.Errorf("Expected structured length 4, got %v", result)
+	}
+
+	// Tool errors are results with isError, not protocol errors
+	call, err := client.CallTool(ctx, "broken", map[string]interface{}{"text": "x"})
+	if err != nil {
+		t.Fatalf("Expected error result, got protocol error %v", err)
+	}
+	if !call.IsError || call.Text() != "disk on fire" {
+		t.Errorf("Expected isError result with message, got %+v", call)
+	}
+
+	// Filtered tools are unknown
+	_, err = client.CallTool(ctx, "delete", nil)
+	var rpcErr *RPCError
+	if !errors.As(err, &rpcErr) || rpcErr.Code != CodeInvalidParams {
+		t.Errorf("Expected invalid params error for filtered tool, got %v", err)
+	}
+
+	// Wait for the client's notification stream before changing the registry
+	deadline := time.Now().Add(5 * time.Second)
+	for !server.streaming() && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	registry.Unregister("stats")
+	server.NotifyToolsChanged()
+
+	select {
+	case list := <-changed:
+		if len(list) != 2 {
+			t.Errorf("Expected 2 tools after change, got %d", len(list))
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for tool list refresh")
+	}
+	if imported.Has("local__stats") {
+		t.Error("Expected local__stats to be unregistered")
+	}
+
+	client.Close()
+	if n := server.sessionCount(); n != 0 {
+		t.Errorf("Expected session to be deleted on close, got %d", n)
+	}
+}
+
+func TestServerHTTPErrors(t *testing.T) {
+	server, _ := NewServer(ServerConfig{Registry: newTestRegistry(), APIKeys: []string{"secret"}})
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	post := func(body string, headers map[string]string) *http.Response {
+		req, _ := http.NewRequest(http.MethodPost, httpServer.URL, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer secret")
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+
+	tests := []struct {
+		name    string
+		body    string
+		headers map[string]string
+		want    int
+	}{
+		{"no auth", `{}`, map[string]string{"Authorization": "Bearer wrong"}, http.StatusUnauthorized},
+		{"bad origin", `{}`, map[string]string{"Origin": "http://evil.example"}, http.StatusForbidden},
+		{"no session", `{"jsonrpc":"2.0","id":1,"method":"ping"}`, nil, http.StatusBadRequest},
+		{"unknown session", `{"jsonrpc":"2.0","id":1,"method":"ping"}`, map[string]string{headerSessionID: "nope"}, http.StatusNotFound},
+		{"bad version", `{}`, map[string]string{headerProtocolVersion: "1999-01-01"}, http.StatusBadRequest},
+		{"initialize", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","capabilities":{},"clientInfo":{"name":"t","version":"1"}}}`, nil, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := post(tt.body, tt.headers)
+			if resp.StatusCode != tt.want {
+				t.Errorf("Expected status %d, got %d", tt.want, resp.StatusCode)
+			}
+			if tt.want == http.StatusOK && resp.Header.Get(headerSessionID) == "" {
+				t.Error("Expected session ID on initialize")
+			}
+		})
+	}
+}
+
+func TestServerStdio(t *testing.T) {
+	server, _ := NewServer(ServerConfig{Registry: newTestRegistry()})
+
+	clientIn, serverOut := io.Pipe()
+	serverIn, clientOut := io.Pipe()
+	done := make(chan error, 1)
+	go func() { done <- server.ServeStdio(context.Background(), serverIn, serverOut) }()
+
+	responses := bufio.NewScanner(clientIn)
+	send := func(line string) map[string]interface{} {
+		t.Helper()
+		if _, err := io.WriteString(clientOut, line+"\n"); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+		if !responses.Scan() {
+			t.Fatalf("No response to %s", line)
+		}
+		var reply map[string]interface{}
+		if err := json.Unmarshal(responses.Bytes(), &reply); err != nil {
+			t.Fatalf("Invalid response %s: %v", responses.Text(), err)
+		}
+		return reply
+	}
+
+	reply := send(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05","capabilities":{},"clientInfo":{"name":"t","version":"1"}}}`)
+	result := reply["result"].(map[string]interface{})
+	if result["protocolVersion"] != "2024-11-05" {
+		t.Errorf("Expected negotiated version 2024-11-05, got %v", result["protocolVersion"])
+	}
+
+	reply = send(`{"jsonrpc":"2.0","id":"a","method":"tools/call","params":{"name":"upper","arguments":{"text":"go"}}}`)
+	if reply["id"] != "a" {
+		t.Errorf("Expected id a, got %v", reply["id"])
+	}
+	content := reply["result"].(map[string]interface{})["content"].([]interface{})
+	if text := content[0].(map[string]interface{})["text"]; text != "GO" {
+		t.Errorf("Expected GO, got %v", text)
+	}
+
+	reply = send(`{"jsonrpc":"2.0","id":2,"method":"resources/list"}`)
+	if code := reply["error"].(map[string]interface{})["code"].(float64); code != CodeMethodNotFound {
+		t.Errorf("Expected method not found, got %v", code)
+	}
+
+	reply = send(`not json`)
+	if code := reply["error"].(map[string]interface{})["code"].(float64); code != CodeParseError {
+		t.Errorf("Expected parse error, got %v", code)
+	}
+
+	clientOut.Close()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Expected clean shutdown, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("ServeStdio did not return after stdin closed")
+	}
+}
+
+func TestToolResult(t *testing.T) {
+	if r := toolResult("plain"); r.Text() != "plain" || r.StructuredContent != nil {
+		t.Errorf("Expected text result, got %+v", r)
+	}
+	if r := toolResult([]int{1, 2}); r.Text() != "[1,2]" || r.StructuredContent != nil {
+		t.Errorf("Expected JSON text without structured content, got %+v", r)
+	}
+	r := toolResult(struct {
+		A int `json:"a"`
+	}{A: 1})
+	if r.Text() != `{"a":1}` || r.StructuredContent == nil {
+		t.Errorf("Expected structured content for objects, got %+v", r)
+	}
+}
+
+func (s *Server) sessionCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.sessions)
+}
+
+func (s *Server) streaming() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, session := range s.sessions {
+		if session.stream != nil && session.stream.isActive() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,239 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/taipm/go-llm-agent/pkg/tools"
+	"github.com/taipm/go-llm-agent/pkg/types"
+)
+
+// NamespaceSeparator joins the server name and the tool name
+const NamespaceSeparator = "__"
+
+// maxToolNameLength is the longest tool name accepted by LLM providers
+const maxToolNameLength = 64
+
+var invalidNameChars = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// RemoteTool adapts a tool of an MCP server into a tools.Tool
+type RemoteTool struct {
+	client *Client
+	name   string
+	tool   Tool
+	params *types.JSONSchema
+}
+
+func (c *Client) newRemoteTool(t Tool) (*RemoteTool, error) {
+	params, err := ConvertSchema(t.InputSchema)
+	if err != nil {
+		return nil, err
+	}
+	return &RemoteTool{
+		client: c,
+		name:   ToolName(c.config.Name, t.Name),
+		tool:   t,
+		params: params,
+	}, nil
+}
+
+// ToolName returns the registered name of tool of server, e.g. "docs__search".
+// Characters providers reject are replaced with "_" and long names are
+// truncated.
+func ToolName(server, tool string) string {
+	name := tool
+	if server != "" {
+		name = server + NamespaceSeparator + tool
+	}
+	name = invalidNameChars.ReplaceAllString(name, "_")
+	if len(name) > maxToolNameLength {
+		name = name[:maxToolNameLength]
+	}
+	return name
+}
+
+// Name implements tools.Tool
+func (t *RemoteTool) Name() string { return t.name }
+
+// RemoteName returns the tool's name on the server
+func (t *RemoteTool) RemoteName() string { return t.tool.Name }
+
+// Definition returns the tool as described by the server
+func (t *RemoteTool) Definition() Tool { return t.tool }
+
+// Description implements tools.Tool
+func (t *RemoteTool) Description() string {
+	if t.tool.Description != "" {
+		return t.tool.Description
+	}
+	if t.tool.Title != "" {
+		return t.tool.Title
+	}
+	return t.tool.Name
+}
+
+// Parameters implements tools.Tool
+func (t *RemoteTool) Parameters() *types.JSONSchema { return t.params }
+
+// Category implements tools.Tool
+func (t *RemoteTool) Category() tools.ToolCategory { return tools.CategoryMCP }
+
+// RequiresAuth implements tools.Tool
+func (t *RemoteTool) RequiresAuth() bool { return false }
+
+// IsSafe implements tools.Tool: only tools the server marks read-only are safe
+func (t *RemoteTool) IsSafe() bool {
+	a := t.tool.Annotations
+	return a != nil && a.ReadOnlyHint != nil && *a.ReadOnlyHint
+}
+
+// ValidatesArguments implements tools.SelfValidating: the server checks the
+// arguments against the full input schema, which Parameters only approximates
+// (type unions and anyOf/oneOf keep their first branch)
+func (t *RemoteTool) ValidatesArguments() bool { return true }
+
+// Execute implements tools.Tool. It returns the structured content of the
+// result when the server sends one, otherwise its text.
+func (t *RemoteTool) Execute(ctx context.Context, params map[string]interface{}) (interface{}, error) {
+	result, err := t.client.CallTool(ctx, t.tool.Name, params)
+	if err != nil {
+		return nil, fmt.Errorf("MCP tool %s failed: %w", t.name, err)
+	}
+	if result.IsError {
+		return nil, fmt.Errorf("MCP tool %s returned an error: %s", t.name, result.Text())
+	}
+	if result.StructuredContent != nil {
+		return result.StructuredContent, nil
+	}
+	return result.Text(), nil
+}
+
+// equal reports whether two adapters describe the same remote tool
+func (t *RemoteTool) equal(other *RemoteTool) bool {
+	a, errA := json.Marshal(t.tool)
+	b, errB := json.Marshal(other.tool)
+	return errA == nil && errB == nil && string(a) == string(b)
+}
+
+// Text joins the text blocks of the result; other blocks are summarized
+func (r *CallToolResult) Text() string {
+	parts := make([]string, 0, len(r.Content))
+	for _, c := range r.Content {
+		switch c.Type {
+		case "text":
+			parts = append(parts, c.Text)
+		case "resource_link":
+			parts = append(parts, fmt.Sprintf("[resource %s]", c.URI))
+		case "resource":
+			var res struct {
+				URI  string `json:"uri"`
+				Text string `json:"text"`
+			}
+			if json.Unmarshal(c.Resource, &res) == nil && res.Text != "" {
+				parts = append(parts, res.Text)
+			} else {
+				parts = append(parts, fmt.Sprintf("[resource %s]", res.URI))
+			}
+		default:
+			parts = append(parts, strings.TrimSpace(fmt.Sprintf("[%s %s]", c.Type, c.MimeType)))
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// registration tracks the tools a client registered in a registry
+type registration struct {
+	registry *tools.Registry
+	tools    map[string]*RemoteTool
+}
+
+// Register lists the server's tools and registers them in registry. The
+// registry is kept in sync when the server's tool list changes.
+func (c *Client) Register(ctx context.Context, registry *tools.Registry) error {
+	list, err := c.Tools(ctx)
+	if err != nil {
+		return err
+	}
+
+	reg := &registration{registry: registry, tools: make(map[string]*RemoteTool, len(list))}
+	for _, t := range list {
+		if err := registry.Register(t); err != nil {
+			for name := range reg.tools {
+				registry.Unregister(name)
+			}
+			return fmt.Errorf("failed to register MCP tool: %w", err)
+		}
+		reg.tools[t.Name()] = t.(*RemoteTool)
+	}
+
+	c.mu.Lock()
+	c.registries = append(c.registries, reg)
+	c.mu.Unlock()
+
+	c.logger.Info("🔌 MCP %s: registered %d tools", c.config.Name, len(list))
+	return nil
+}
+
+// refreshTools lists the tools again and syncs the registries
+func (c *Client) refreshTools() {
+	c.refreshMu.Lock()
+	defer c.refreshMu.Unlock()
+
+	c.mu.Lock()
+	watched := len(c.registries) > 0 || c.config.OnToolsChanged != nil
+	c.mu.Unlock()
+	if !watched {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.config.Timeout)
+	defer cancel()
+
+	list, err := c.Tools(ctx)
+	if err != nil {
+		c.logger.Warn("⚠️  MCP %s: failed to refresh tools: %v", c.config.Name, err)
+		return
+	}
+
+	latest := make(map[string]*RemoteTool, len(list))
+	for _, t := range list {
+		latest[t.Name()] = t.(*RemoteTool)
+	}
+
+	c.mu.Lock()
+	registries := c.registries
+	definitions := c.tools
+	c.mu.Unlock()
+
+	for _, reg := range registries {
+		reg.sync(latest)
+	}
+	if c.config.OnToolsChanged != nil {
+		c.config.OnToolsChanged(definitions)
+	}
+}
+
+// sync updates the registry to hold exactly the latest tools
+func (reg *registration) sync(latest map[string]*RemoteTool) {
+	for name := range reg.tools {
+		if _, ok := latest[name]; !ok {
+			reg.registry.Unregister(name)
+			delete(reg.tools, name)
+		}
+	}
+	for name, t := range latest {
+		if old, ok := reg.tools[name]; ok {
+			if old.equal(t) {
+				continue
+			}
+			reg.registry.Unregister(name)
+		}
+		if err := reg.registry.Register(t); err != nil {
+			continue // Name taken by a tool we don't own
+		}
+		reg.tools[name] = t
+	}
+}
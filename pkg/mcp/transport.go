@@ -0,0 +1,163 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// Transport carries JSON-RPC messages between a client and a server.
+// A transport is started once; reconnecting creates a new one.
+type Transport interface {
+	// Start opens the connection. Messages from the peer are passed to
+	// onMessage; onClose is called once when the connection ends without
+	// Close having been called.
+	Start(ctx context.Context, onMessage func([]byte), onClose func(error)) error
+
+	// Send delivers one JSON-RPC message
+	Send(ctx context.Context, msg []byte) error
+
+	// Close ends the connection
+	Close() error
+}
+
+// StdioConfig starts an MCP server as a subprocess speaking newline
+// delimited JSON-RPC on stdin/stdout
+type StdioConfig struct {
+	Command string
+	Args    []string
+	Env     []string  // Extra environment variables (KEY=value), added to the current environment
+	Dir     string    // Working directory
+	Stderr  io.Writer // Server logs (default: discarded)
+}
+
+// StdioTransport runs an MCP server as a subprocess
+type StdioTransport struct {
+	config StdioConfig
+
+	mu      sync.Mutex // Guards writes and state
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	closed  bool
+	exited  chan struct{}
+	waitErr error
+}
+
+// NewStdioTransport creates a stdio transport; the process starts on Start
+func NewStdioTransport(config StdioConfig) *StdioTransport {
+	return &StdioTransport{config: config}
+}
+
+// Start implements Transport
+func (t *StdioTransport) Start(ctx context.Context, onMessage func([]byte), onClose func(error)) error {
+	if t.config.Command == "" {
+		return fmt.Errorf("stdio transport requires a command")
+	}
+
+	cmd := exec.Command(t.config.Command, t.config.Args...)
+	cmd.Dir = t.config.Dir
+	cmd.Env = append(os.Environ(), t.config.Env...)
+	cmd.Stderr = t.config.Stderr
+	if cmd.Stderr == nil {
+		cmd.Stderr = io.Discard
+	}
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("failed to open stdin: %w", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to open stdout: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start MCP server %s: %w", t.config.Command, err)
+	}
+
+	t.mu.Lock()
+	t.cmd = cmd
+	t.stdin = stdin
+	t.exited = make(chan struct{})
+	t.mu.Unlock()
+
+	go func() {
+		reader := bufio.NewReaderSize(stdout, 64*1024)
+		var readErr error
+		for {
+			line, err := reader.ReadBytes('\n')
+			if line = bytes.TrimSpace(line); len(line) > 0 {
+				onMessage(line)
+			}
+			if err != nil {
+				if !errors.Is(err, io.EOF) {
+					readErr = err
+				}
+				break
+			}
+		}
+
+		waitErr := cmd.Wait()
+		t.mu.Lock()
+		t.waitErr = waitErr
+		closed := t.closed
+		close(t.exited)
+		t.mu.Unlock()
+
+		if !closed {
+			if readErr == nil {
+				readErr = waitErr
+			}
+			if readErr == nil {
+				readErr = io.EOF
+			}
+			onClose(fmt.Errorf("MCP server %s exited: %w", t.config.Command, readErr))
+		}
+	}()
+
+	return nil
+}
+
+// Send implements Transport
+func (t *StdioTransport) Send(ctx context.Context, msg []byte) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closed || t.stdin == nil {
+		return ErrClosed
+	}
+	if _, err := t.stdin.Write(append(msg, '\n')); err != nil {
+		return fmt.Errorf("%w: %v", ErrClosed, err)
+	}
+	return nil
+}
+
+// Close implements Transport: it closes stdin and kills the process if it
+// does not exit within two seconds
+func (t *StdioTransport) Close() error {
+	t.mu.Lock()
+	if t.closed || t.cmd == nil {
+		t.closed = true
+		t.mu.Unlock()
+		return nil
+	}
+	t.closed = true
+	t.stdin.Close()
+	exited := t.exited
+	cmd := t.cmd
+	t.mu.Unlock()
+
+	select {
+	case <-exited:
+	case <-time.After(2 * time.Second):
+		cmd.Process.Kill()
+		<-exited
+	}
+	return nil
+}
@@ -0,0 +1,303 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HTTP headers of the streamable HTTP transport
+const (
+	headerSessionID       = "Mcp-Session-Id"
+	headerProtocolVersion = "Mcp-Protocol-Version"
+)
+
+// HTTPConfig connects to an MCP server over the streamable HTTP transport
+type HTTPConfig struct {
+	URL     string            // MCP endpoint, e.g. "https://example.com/mcp"
+	Headers map[string]string // Extra headers (e.g. Authorization)
+	Client  *http.Client      // Default: http.DefaultClient
+}
+
+// HTTPTransport speaks the streamable HTTP transport: every message is
+// POSTed to the endpoint and answered with JSON or an SSE stream, and a GET
+// stream (when the server offers one) carries server notifications
+type HTTPTransport struct {
+	config HTTPConfig
+
+	mu        sync.Mutex
+	sessionID string
+	version   string
+	onMessage func([]byte)
+	onClose   func(error)
+	cancel    context.CancelFunc // Stops the GET stream
+	listening bool
+	closed    bool
+}
+
+// NewHTTPTransport creates a streamable HTTP transport
+func NewHTTPTransport(config HTTPConfig) *HTTPTransport {
+	if config.Client == nil {
+		config.Client = http.DefaultClient
+	}
+	return &HTTPTransport{config: config}
+}
+
+// Start implements Transport
+func (t *HTTPTransport) Start(ctx context.Context, onMessage func([]byte), onClose func(error)) error {
+	if t.config.URL == "" {
+		return fmt.Errorf("HTTP transport requires a URL")
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.onMessage = onMessage
+	t.onClose = onClose
+	return nil
+}
+
+// setProtocolVersion records the negotiated version sent on later requests
+func (t *HTTPTransport) setProtocolVersion(version string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.version = version
+}
+
+// Send implements Transport
+func (t *HTTPTransport) Send(ctx context.Context, msg []byte) error {
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		return ErrClosed
+	}
+	sessionID, version := t.sessionID, t.version
+	t.mu.Unlock()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.config.URL, bytes.NewReader(msg))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	t.setHeaders(req, sessionID, version)
+
+	resp, err := t.config.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach MCP server: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound && sessionID != "" {
+		// The server dropped our session; the client must initialize again
+		t.fail(fmt.Errorf("MCP session %s expired", sessionID))
+		return ErrClosed
+	}
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("MCP server error (status %d): %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	if id := resp.Header.Get(headerSessionID); id != "" {
+		t.mu.Lock()
+		t.sessionID = id
+		t.mu.Unlock()
+	}
+	t.listen()
+
+	if resp.StatusCode == http.StatusAccepted {
+		return nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	switch mediaType {
+	case "text/event-stream":
+		return readSSE(resp.Body, t.deliver)
+	case "application/json":
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("failed to read response: %w", err)
+		}
+		return deliverJSON(body, t.deliver)
+	default:
+		return nil
+	}
+}
+
+func (t *HTTPTransport) setHeaders(req *http.Request, sessionID, version string) {
+	for k, v := range t.config.Headers {
+		req.Header.Set(k, v)
+	}
+	if sessionID != "" {
+		req.Header.Set(headerSessionID, sessionID)
+	}
+	if version != "" {
+		req.Header.Set(headerProtocolVersion, version)
+	}
+}
+
+func (t *HTTPTransport) deliver(msg []byte) {
+	t.mu.Lock()
+	onMessage := t.onMessage
+	t.mu.Unlock()
+	if onMessage != nil {
+		onMessage(msg)
+	}
+}
+
+// fail reports the end of the connection once
+func (t *HTTPTransport) fail(err error) {
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		return
+	}
+	t.closed = true
+	if t.cancel != nil {
+		t.cancel()
+	}
+	onClose := t.onClose
+	t.mu.Unlock()
+
+	if onClose != nil {
+		onClose(err)
+	}
+}
+
+// listen opens the GET stream for server-initiated messages (once)
+func (t *HTTPTransport) listen() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.listening || t.closed || t.version == "" {
+		return // Not before initialization has completed
+	}
+	t.listening = true
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.cancel = cancel
+	go t.listenLoop(ctx)
+}
+
+func (t *HTTPTransport) listenLoop(ctx context.Context) {
+	delay := time.Second
+	for ctx.Err() == nil {
+		t.mu.Lock()
+		sessionID, version := t.sessionID, t.version
+		t.mu.Unlock()
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, t.config.URL, nil)
+		if err != nil {
+			return
+		}
+		req.Header.Set("Accept", "text/event-stream")
+		t.setHeaders(req, sessionID, version)
+
+		resp, err := t.config.Client.Do(req)
+		if err == nil {
+			switch {
+			case resp.StatusCode == http.StatusMethodNotAllowed:
+				// The server has no notification stream
+				resp.Body.Close()
+				return
+			case resp.StatusCode == http.StatusNotFound && sessionID != "":
+				resp.Body.Close()
+				t.fail(fmt.Errorf("MCP session %s expired", sessionID))
+				return
+			case resp.StatusCode == http.StatusOK:
+				delay = time.Second
+				readSSE(resp.Body, t.deliver)
+			}
+			resp.Body.Close()
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, 30*time.Second)
+	}
+}
+
+// Close implements Transport; it ends the server session when there is one
+func (t *HTTPTransport) Close() error {
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		return nil
+	}
+	t.closed = true
+	if t.cancel != nil {
+		t.cancel()
+	}
+	sessionID, version := t.sessionID, t.version
+	t.mu.Unlock()
+
+	if sessionID != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		req, err := http.NewRequestWithContext(ctx, http.MethodDelete, t.config.URL, nil)
+		if err == nil {
+			t.setHeaders(req, sessionID, version)
+			if resp, err := t.config.Client.Do(req); err == nil {
+				resp.Body.Close()
+			}
+		}
+	}
+	return nil
+}
+
+// readSSE passes the data of each server-sent event to deliver
+func readSSE(r io.Reader, deliver func([]byte)) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 64<<20)
+
+	var data bytes.Buffer
+	flush := func() {
+		if data.Len() > 0 {
+			deliverJSON(bytes.Clone(data.Bytes()), deliver)
+			data.Reset()
+		}
+	}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			flush()
+		case strings.HasPrefix(line, "data:"):
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	flush()
+	return scanner.Err()
+}
+
+// deliverJSON passes a message, or each message of a batch, to deliver
+func deliverJSON(body []byte, deliver func([]byte)) error {
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 {
+		return nil
+	}
+	if body[0] != '[' {
+		deliver(body)
+		return nil
+	}
+	var batch []json.RawMessage
+	if err := json.Unmarshal(body, &batch); err != nil {
+		return fmt.Errorf("invalid JSON-RPC batch: %w", err)
+	}
+	for _, msg := range batch {
+		deliver(msg)
+	}
+	return nil
+}
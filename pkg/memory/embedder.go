@@ -7,6 +7,7 @@ import (
 	"io"
 	"net/http"
 	"strings"
+	"sync/atomic"
 )
 
 // Embedder generates vector embeddings from text
@@ -15,11 +16,100 @@ type Embedder interface {
 	Dimensions() int
 }
 
+// BatchEmbedder is implemented by embedders that embed many texts per request
+type BatchEmbedder interface {
+	Embedder
+	EmbedBatch(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// EmbedBatch embeds texts with a single batch call when e supports it,
+// falling back to one Embed call per text. Results are in input order.
+func EmbedBatch(ctx context.Context, e Embedder, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return [][]float32{}, nil
+	}
+	if batch, ok := e.(BatchEmbedder); ok {
+		return batch.EmbedBatch(ctx, texts)
+	}
+
+	embeddings := make([][]float32, len(texts))
+	for i, text := range texts {
+		emb, err := e.Embed(ctx, text)
+		if err != nil {
+			return nil, fmt.Errorf("failed to embed text %d: %w", i, err)
+		}
+		embeddings[i] = emb
+	}
+	return embeddings, nil
+}
+
+// DetectDimensions returns e.Dimensions(), embedding a probe text first when
+// the size is not known yet (embedders learn it from their first response)
+func DetectDimensions(ctx context.Context, e Embedder) (int, error) {
+	if dims := e.Dimensions(); dims > 0 {
+		return dims, nil
+	}
+	emb, err := e.Embed(ctx, "dimension probe")
+	if err != nil {
+		return 0, fmt.Errorf("failed to detect embedding dimensions: %w", err)
+	}
+	return len(emb), nil
+}
+
+// dimensions tracks an embedder's vector size: a guess from the model name
+// until the first response reports the real size
+type dimensions struct {
+	guess    int
+	detected atomic.Int64
+}
+
+func (d *dimensions) get() int {
+	if n := d.detected.Load(); n > 0 {
+		return int(n)
+	}
+	return d.guess
+}
+
+func (d *dimensions) observe(embeddings ...[]float32) {
+	for _, emb := range embeddings {
+		if len(emb) > 0 {
+			d.detected.Store(int64(len(emb)))
+			return
+		}
+	}
+}
+
+// knownDimensions maps embedding models (without tag) to their vector size
+var knownDimensions = map[string]int{
+	"nomic-embed-text":       768,
+	"mxbai-embed-large":      1024,
+	"all-minilm":             384,
+	"snowflake-arctic-embed": 1024,
+	"bge-m3":                 1024,
+	"text-embedding-3-small": 1536,
+	"text-embedding-3-large": 3072,
+	"text-embedding-ada-002": 1536,
+	"text-embedding-004":     768,
+	"gemini-embedding-001":   3072,
+}
+
+// guessDimensions looks up a model's vector size (0 when unknown)
+func guessDimensions(model string) int {
+	model = strings.TrimPrefix(model, "models/")
+	if i := strings.Index(model, ":"); i >= 0 {
+		model = model[:i]
+	}
+	return knownDimensions[model]
+}
+
+// maxBatchSize caps the number of texts sent in one HTTP request
+const maxBatchSize = 256
+
 // OllamaEmbedder uses Ollama for embeddings
 type OllamaEmbedder struct {
 	baseURL string
 	model   string
-	dims    int
+	dims    dimensions
 }
 
 // NewOllamaEmbedder creates an embedder using Ollama
@@ -31,15 +121,10 @@ func NewOllamaEmbedder(baseURL, model string) *OllamaEmbedder {
 		model = "nomic-embed-text:latest" // Default embedding model
 	}
 
-	dims := 768 // nomic-embed-text dimensions
-	if model == "mxbai-embed-large" {
-		dims = 1024
-	}
-
 	return &OllamaEmbedder{
 		baseURL: baseURL,
 		model:   model,
-		dims:    dims,
+		dims:    dimensions{guess: guessDimensions(model)},
 	}
 }
 
@@ -51,52 +136,63 @@ func (e *OllamaEmbedder) Embed(ctx context.Context, text string) ([]float32, err
 		"prompt": text,
 	}
 
-	jsonData, err := json.Marshal(payload)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	req, err := http.NewRequestWithContext(ctx, "POST", url, strings.NewReader(string(jsonData)))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to call Ollama: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("Ollama error (status %d): %s", resp.StatusCode, string(body))
-	}
-
 	var result struct {
 		Embedding []float32 `json:"embedding"`
 	}
-
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	if err := postJSON(ctx, url, "", "Ollama", payload, &result); err != nil {
+		return nil, err
 	}
 
 	if len(result.Embedding) == 0 {
 		return nil, fmt.Errorf("empty embedding returned")
 	}
 
+	e.dims.observe(result.Embedding)
 	return result.Embedding, nil
 }
 
+// EmbedBatch implements BatchEmbedder using Ollama's /api/embed endpoint
+func (e *OllamaEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	url := fmt.Sprintf("%s/api/embed", e.baseURL)
+
+	embeddings := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += maxBatchSize {
+		chunk := texts[start:min(start+maxBatchSize, len(texts))]
+
+		var result struct {
+			Embeddings [][]float32 `json:"embeddings"`
+		}
+		payload := map[string]interface{}{"model": e.model, "input": chunk}
+		if err := postJSON(ctx, url, "", "Ollama", payload, &result); err != nil {
+			return nil, err
+		}
+		if len(result.Embeddings) != len(chunk) {
+			return nil, fmt.Errorf("expected %d embeddings, got %d", len(chunk), len(result.Embeddings))
+		}
+		embeddings = append(embeddings, result.Embeddings...)
+	}
+
+	e.dims.observe(embeddings...)
+	return embeddings, nil
+}
+
+// Dimensions returns the vector size, detected from the first response
+// (0 for unknown models before the first call)
 func (e *OllamaEmbedder) Dimensions() int {
-	return e.dims
+	return e.dims.get()
+}
+
+// Model returns the embedding model name
+func (e *OllamaEmbedder) Model() string {
+	return e.model
 }
 
 // OpenAIEmbedder uses OpenAI for embeddings
 type OpenAIEmbedder struct {
-	apiKey string
-	model  string
-	dims   int
+	apiKey  string
+	model   string
+	baseURL string
+	dims    dimensions
 }
 
 // NewOpenAIEmbedder creates an embedder using OpenAI
@@ -105,67 +201,105 @@ func NewOpenAIEmbedder(apiKey, model string) *OpenAIEmbedder {
 		model = "text-embedding-3-small" // Default model
 	}
 
-	dims := 1536 // text-embedding-3-small dimensions
-	if model == "text-embedding-3-large" {
-		dims = 3072
-	}
-
 	return &OpenAIEmbedder{
-		apiKey: apiKey,
-		model:  model,
-		dims:   dims,
+		apiKey:  apiKey,
+		model:   model,
+		baseURL: "https://api.openai.com/v1",
+		dims:    dimensions{guess: guessDimensions(model)},
 	}
 }
 
+// WithBaseURL points the embedder at an OpenAI-compatible API
+func (e *OpenAIEmbedder) WithBaseURL(baseURL string) *OpenAIEmbedder {
+	e.baseURL = strings.TrimSuffix(baseURL, "/")
+	return e
+}
+
 func (e *OpenAIEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
-	url := "https://api.openai.com/v1/embeddings"
+	embeddings, err := e.EmbedBatch(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+	return embeddings[0], nil
+}
 
-	payload := map[string]interface{}{
-		"model": e.model,
-		"input": text,
+// EmbedBatch implements BatchEmbedder
+func (e *OpenAIEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	url := e.baseURL + "/embeddings"
+
+	embeddings := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += maxBatchSize {
+		chunk := texts[start:min(start+maxBatchSize, len(texts))]
+
+		var result struct {
+			Data []struct {
+				Index     int       `json:"index"`
+				Embedding []float32 `json:"embedding"`
+			} `json:"data"`
+		}
+		payload := map[string]interface{}{"model": e.model, "input": chunk}
+		if err := postJSON(ctx, url, e.apiKey, "OpenAI", payload, &result); err != nil {
+			return nil, err
+		}
+
+		batch := make([][]float32, len(chunk))
+		for _, d := range result.Data {
+			if d.Index >= 0 && d.Index < len(batch) {
+				batch[d.Index] = d.Embedding
+			}
+		}
+		for _, emb := range batch {
+			if len(emb) == 0 {
+				return nil, fmt.Errorf("empty embedding returned")
+			}
+		}
+		embeddings = append(embeddings, batch...)
 	}
 
+	e.dims.observe(embeddings...)
+	return embeddings, nil
+}
+
+// Dimensions returns the vector size, detected from the first response
+// (0 for unknown models before the first call)
+func (e *OpenAIEmbedder) Dimensions() int {
+	return e.dims.get()
+}
+
+// Model returns the embedding model name
+func (e *OpenAIEmbedder) Model() string {
+	return e.model
+}
+
+// postJSON sends payload to url and decodes the JSON response into result
+func postJSON(ctx context.Context, url, apiKey, service string, payload, result interface{}) error {
 	jsonData, err := json.Marshal(payload)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
+		return fmt.Errorf("failed to marshal request: %w", err)
 	}
 
 	req, err := http.NewRequestWithContext(ctx, "POST", url, strings.NewReader(string(jsonData)))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return fmt.Errorf("failed to create request: %w", err)
 	}
-
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", e.apiKey))
+	if apiKey != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", apiKey))
+	}
 
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to call OpenAI: %w", err)
+		return fmt.Errorf("failed to call %s: %w", service, err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("OpenAI error (status %d): %s", resp.StatusCode, string(body))
+		return fmt.Errorf("%s error (status %d): %s", service, resp.StatusCode, string(body))
 	}
 
-	var result struct {
-		Data []struct {
-			Embedding []float32 `json:"embedding"`
-		} `json:"data"`
-	}
-
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
-	}
-
-	if len(result.Data) == 0 || len(result.Data[0].Embedding) == 0 {
-		return nil, fmt.Errorf("empty embedding returned")
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
 	}
-
-	return result.Data[0].Embedding, nil
-}
-
-func (e *OpenAIEmbedder) Dimensions() int {
-	return e.dims
+	return nil
 }
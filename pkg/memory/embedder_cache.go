@@ -0,0 +1,220 @@
+package memory
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// CachedEmbedder memoizes another embedder in an LRU cache and, optionally,
+// a directory on disk. Entries are keyed by a hash of the model name and the
+// text, so several models can share one cache directory.
+type CachedEmbedder struct {
+	next  Embedder
+	model string
+	dir   string
+	size  int
+
+	mu    sync.Mutex
+	lru   *list.List               // Front = most recently used
+	items map[string]*list.Element // key -> element holding *cacheEntry
+	stats CacheStats
+}
+
+// CacheConfig holds configuration for CachedEmbedder
+type CacheConfig struct {
+	Size  int    // Entries kept in memory (default 10000)
+	Dir   string // Optional directory for a persistent cache
+	Model string // Cache namespace (default: the embedder's Model(), or its type)
+}
+
+// CacheStats reports cache effectiveness
+type CacheStats struct {
+	Hits     int64 // Served from memory
+	DiskHits int64 // Served from disk
+	Misses   int64 // Embedded by the wrapped embedder
+	Entries  int   // Entries in memory
+}
+
+type cacheEntry struct {
+	key       string
+	embedding []float32
+}
+
+// NewCachedEmbedder wraps next with a cache
+func NewCachedEmbedder(next Embedder, config CacheConfig) (*CachedEmbedder, error) {
+	if config.Size <= 0 {
+		config.Size = 10000
+	}
+	if config.Model == "" {
+		if named, ok := next.(interface{ Model() string }); ok {
+			config.Model = named.Model()
+		} else {
+			config.Model = fmt.Sprintf("%T", next)
+		}
+	}
+	if config.Dir != "" {
+		if err := os.MkdirAll(config.Dir, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create cache directory: %w", err)
+		}
+	}
+
+	return &CachedEmbedder{
+		next:  next,
+		model: config.Model,
+		dir:   config.Dir,
+		size:  config.Size,
+		lru:   list.New(),
+		items: make(map[string]*list.Element),
+	}, nil
+}
+
+func (c *CachedEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	key := c.key(text)
+	if emb, ok := c.lookup(key); ok {
+		return emb, nil
+	}
+
+	emb, err := c.next.Embed(ctx, text)
+	if err != nil {
+		return nil, err
+	}
+	c.store(key, emb)
+	return emb, nil
+}
+
+// EmbedBatch implements BatchEmbedder; only cache misses reach the wrapped embedder
+func (c *CachedEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	embeddings := make([][]float32, len(texts))
+	keys := make([]string, len(texts))
+	var missing []int
+	var missingTexts []string
+
+	for i, text := range texts {
+		keys[i] = c.key(text)
+		if emb, ok := c.lookup(keys[i]); ok {
+			embeddings[i] = emb
+			continue
+		}
+		missing = append(missing, i)
+		missingTexts = append(missingTexts, text)
+	}
+
+	if len(missing) > 0 {
+		fresh, err := EmbedBatch(ctx, c.next, missingTexts)
+		if err != nil {
+			return nil, err
+		}
+		for j, i := range missing {
+			embeddings[i] = fresh[j]
+			c.store(keys[i], fresh[j])
+		}
+	}
+
+	return embeddings, nil
+}
+
+func (c *CachedEmbedder) Dimensions() int {
+	return c.next.Dimensions()
+}
+
+// Model returns the cache namespace (the wrapped model name by default)
+func (c *CachedEmbedder) Model() string {
+	return c.model
+}
+
+// Stats returns hit and miss counters
+func (c *CachedEmbedder) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.stats
+	stats.Entries = c.lru.Len()
+	return stats
+}
+
+// key hashes the model and text
+func (c *CachedEmbedder) key(text string) string {
+	sum := sha256.Sum256([]byte(c.model + "\x00" + text))
+	return hex.EncodeToString(sum[:])
+}
+
+// lookup checks memory, then disk
+func (c *CachedEmbedder) lookup(key string) ([]float32, bool) {
+	c.mu.Lock()
+	if el, ok := c.items[key]; ok {
+		c.lru.MoveToFront(el)
+		c.stats.Hits++
+		emb := el.Value.(*cacheEntry).embedding
+		c.mu.Unlock()
+		return emb, true
+	}
+	c.mu.Unlock()
+
+	if c.dir != "" {
+		if data, err := os.ReadFile(c.path(key)); err == nil && len(data) > 0 && len(data)%4 == 0 {
+			emb := decodeEmbedding(data)
+			c.remember(key, emb)
+			c.mu.Lock()
+			c.stats.DiskHits++
+			c.mu.Unlock()
+			return emb, true
+		}
+	}
+
+	c.mu.Lock()
+	c.stats.Misses++
+	c.mu.Unlock()
+	return nil, false
+}
+
+// store keeps a fresh embedding in memory and on disk (best effort)
+func (c *CachedEmbedder) store(key string, emb []float32) {
+	c.remember(key, emb)
+	if c.dir == "" {
+		return
+	}
+
+	path := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), key+".tmp*")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(encodeEmbedding(emb))
+	if closeErr := tmp.Close(); err == nil && closeErr == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+}
+
+// remember adds an entry to the LRU, evicting the least recently used
+func (c *CachedEmbedder) remember(key string, emb []float32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		el.Value.(*cacheEntry).embedding = emb
+		c.lru.MoveToFront(el)
+		return
+	}
+	c.items[key] = c.lru.PushFront(&cacheEntry{key: key, embedding: emb})
+	for c.lru.Len() > c.size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.items, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// path shards cache files by the first two hex digits of the key
+func (c *CachedEmbedder) path(key string) string {
+	return filepath.Join(c.dir, key[:2], key+".bin")
+}
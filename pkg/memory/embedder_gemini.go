@@ -0,0 +1,119 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/genai"
+)
+
+// GeminiEmbedder uses the Gemini API (or Vertex AI) for embeddings
+type GeminiEmbedder struct {
+	client     *genai.Client
+	model      string
+	outputDims int32
+	taskType   string
+	dims       dimensions
+}
+
+// GeminiEmbedderConfig holds configuration for GeminiEmbedder
+type GeminiEmbedderConfig struct {
+	APIKey     string             // Gemini API key (ignored when Client is set)
+	Model      string             // Embedding model (default "gemini-embedding-001")
+	Dimensions int                // Optional output size for models that support truncation
+	TaskType   string             // Optional task type, e.g. "RETRIEVAL_DOCUMENT" or "SEMANTIC_SIMILARITY"
+	Client     *genai.Client      // Use an existing client (e.g. configured for Vertex AI)
+	HTTP       *genai.HTTPOptions // Optional HTTP options (base URL, headers) for a new client
+}
+
+// NewGeminiEmbedder creates an embedder using Gemini
+func NewGeminiEmbedder(ctx context.Context, config GeminiEmbedderConfig) (*GeminiEmbedder, error) {
+	if config.Model == "" {
+		config.Model = "gemini-embedding-001" // Default embedding model
+	}
+
+	client := config.Client
+	if client == nil {
+		clientConfig := &genai.ClientConfig{
+			APIKey:  config.APIKey,
+			Backend: genai.BackendGeminiAPI,
+		}
+		if config.HTTP != nil {
+			clientConfig.HTTPOptions = *config.HTTP
+		}
+		var err error
+		client, err = genai.NewClient(ctx, clientConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Gemini client: %w", err)
+		}
+	}
+
+	guess := guessDimensions(config.Model)
+	if config.Dimensions > 0 {
+		guess = config.Dimensions
+	}
+
+	return &GeminiEmbedder{
+		client:     client,
+		model:      config.Model,
+		outputDims: int32(config.Dimensions),
+		taskType:   config.TaskType,
+		dims:       dimensions{guess: guess},
+	}, nil
+}
+
+func (e *GeminiEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	embeddings, err := e.EmbedBatch(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+	return embeddings[0], nil
+}
+
+// EmbedBatch implements BatchEmbedder
+func (e *GeminiEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	config := &genai.EmbedContentConfig{TaskType: e.taskType}
+	if e.outputDims > 0 {
+		config.OutputDimensionality = &e.outputDims
+	}
+
+	embeddings := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += maxBatchSize {
+		chunk := texts[start:min(start+maxBatchSize, len(texts))]
+
+		contents := make([]*genai.Content, len(chunk))
+		for i, text := range chunk {
+			contents[i] = genai.NewContentFromText(text, genai.RoleUser)
+		}
+
+		resp, err := e.client.Models.EmbedContent(ctx, e.model, contents, config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to call Gemini: %w", err)
+		}
+		if len(resp.Embeddings) != len(chunk) {
+			return nil, fmt.Errorf("expected %d embeddings, got %d", len(chunk), len(resp.Embeddings))
+		}
+		for _, emb := range resp.Embeddings {
+			if emb == nil || len(emb.Values) == 0 {
+				return nil, fmt.Errorf("empty embedding returned")
+			}
+			embeddings = append(embeddings, emb.Values)
+		}
+	}
+
+	e.dims.observe(embeddings...)
+	return embeddings, nil
+}
+
+// Dimensions returns the vector size, detected from the first response
+func (e *GeminiEmbedder) Dimensions() int {
+	return e.dims.get()
+}
+
+// Model returns the embedding model name (with the output size when truncated)
+func (e *GeminiEmbedder) Model() string {
+	if e.outputDims > 0 {
+		return fmt.Sprintf("%s/%d", e.model, e.outputDims)
+	}
+	return e.model
+}
@@ -0,0 +1,79 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+)
+
+// HashingEmbedder maps text to vectors with the hashing trick: every keyword
+// term (see Tokenize) and character trigram is hashed to a signed bucket. It
+// needs no model or network and is deterministic, so it suits tests and
+// offline deployments. Similarity reflects shared words, not meaning.
+type HashingEmbedder struct {
+	dims int
+}
+
+// NewHashingEmbedder creates a hashing embedder (dims defaults to 256)
+func NewHashingEmbedder(dims int) *HashingEmbedder {
+	if dims <= 0 {
+		dims = 256
+	}
+	return &HashingEmbedder{dims: dims}
+}
+
+func (e *HashingEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	vec := make([]float32, e.dims)
+	for _, term := range Tokenize(text) {
+		e.add(vec, "w:"+term, 1)
+
+		// Trigrams make near spellings ("timeout"/"timeouts") overlap
+		runes := []rune(" " + term + " ")
+		for i := 0; i+3 <= len(runes); i++ {
+			e.add(vec, "t:"+string(runes[i:i+3]), 0.5)
+		}
+	}
+
+	var norm float64
+	for _, v := range vec {
+		norm += float64(v) * float64(v)
+	}
+	if norm > 0 {
+		scale := float32(1 / math.Sqrt(norm))
+		for i := range vec {
+			vec[i] *= scale
+		}
+	}
+	return vec, nil
+}
+
+// EmbedBatch implements BatchEmbedder
+func (e *HashingEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	embeddings := make([][]float32, len(texts))
+	for i, text := range texts {
+		embeddings[i], _ = e.Embed(ctx, text)
+	}
+	return embeddings, nil
+}
+
+func (e *HashingEmbedder) Dimensions() int {
+	return e.dims
+}
+
+// Model returns a name identifying the embedding space
+func (e *HashingEmbedder) Model() string {
+	return fmt.Sprintf("hashing-%d", e.dims)
+}
+
+// add hashes feature into a bucket with a hash-derived sign
+func (e *HashingEmbedder) add(vec []float32, feature string, weight float32) {
+	h := fnv.New64a()
+	h.Write([]byte(feature))
+	sum := h.Sum64()
+
+	if sum>>63 == 1 {
+		weight = -weight
+	}
+	vec[sum%uint64(e.dims)] += weight
+}
@@ -0,0 +1,251 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/genai"
+)
+
+// fakeEmbedding returns a 3-dim vector derived from the text length
+func fakeEmbedding(text string) []float32 {
+	return []float32{float32(len(text)), 1, 0}
+}
+
+func TestOllamaEmbedder_Batch(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		var req struct {
+			Input  []string `json:"input"`
+			Prompt string   `json:"prompt"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+
+		switch r.URL.Path {
+		case "/api/embed":
+			embeddings := make([][]float32, len(req.Input))
+			for i, text := range req.Input {
+				embeddings[i] = fakeEmbedding(text)
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"embeddings": embeddings})
+		case "/api/embeddings":
+			json.NewEncoder(w).Encode(map[string]interface{}{"embedding": fakeEmbedding(req.Prompt)})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	e := NewOllamaEmbedder(srv.URL, "custom-embedder")
+	if e.Dimensions() != 0 {
+		t.Errorf("Expected unknown dimensions before the first call, got %d", e.Dimensions())
+	}
+
+	embeddings, err := EmbedBatch(context.Background(), e, []string{"a", "bbb"})
+	if err != nil {
+		t.Fatalf("EmbedBatch failed: %v", err)
+	}
+	if requests != 1 || len(embeddings) != 2 || embeddings[1][0] != 3 {
+		t.Errorf("Expected one request with ordered results, got %d requests and %v", requests, embeddings)
+	}
+	if e.Dimensions() != 3 {
+		t.Errorf("Expected detected dimensions 3, got %d", e.Dimensions())
+	}
+
+	if emb, err := e.Embed(context.Background(), "cc"); err != nil || emb[0] != 2 {
+		t.Errorf("Expected single embedding, got %v (%v)", emb, err)
+	}
+	if NewOllamaEmbedder(srv.URL, "nomic-embed-text:v1.5").Dimensions() != 768 {
+		t.Error("Expected known model dimensions to be guessed")
+	}
+}
+
+func TestOpenAIEmbedder_Batch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/embeddings" || r.Header.Get("Authorization") != "Bearer key" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		var req struct {
+			Input []string `json:"input"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+
+		// Return results out of order; the embedder must sort by index
+		data := make([]map[string]interface{}, 0, len(req.Input))
+		for i := len(req.Input) - 1; i >= 0; i-- {
+			data = append(data, map[string]interface{}{"index": i, "embedding": fakeEmbedding(req.Input[i])})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+	}))
+	defer srv.Close()
+
+	e := NewOpenAIEmbedder("key", "local-model").WithBaseURL(srv.URL + "/v1/")
+	embeddings, err := e.EmbedBatch(context.Background(), []string{"a", "bb", "ccc"})
+	if err != nil {
+		t.Fatalf("EmbedBatch failed: %v", err)
+	}
+	for i, want := range []float32{1, 2, 3} {
+		if embeddings[i][0] != want {
+			t.Errorf("Expected embedding %d to start with %v, got %v", i, want, embeddings[i])
+		}
+	}
+	if e.Dimensions() != 3 {
+		t.Errorf("Expected detected dimensions 3, got %d", e.Dimensions())
+	}
+
+	_, err = NewOpenAIEmbedder("wrong", "").WithBaseURL(srv.URL+"/v1").Embed(context.Background(), "x")
+	if err == nil || !strings.Contains(err.Error(), "status 400") {
+		t.Errorf("Expected HTTP error, got %v", err)
+	}
+}
+
+func TestGeminiEmbedder_Batch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, ":batchEmbedContents") {
+			http.NotFound(w, r)
+			return
+		}
+		var req struct {
+			Requests []json.RawMessage `json:"requests"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+
+		embeddings := make([]map[string]interface{}, len(req.Requests))
+		for i := range req.Requests {
+			embeddings[i] = map[string]interface{}{"values": []float32{float32(i), 0.5}}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"embeddings": embeddings})
+	}))
+	defer srv.Close()
+
+	e, err := NewGeminiEmbedder(context.Background(), GeminiEmbedderConfig{
+		APIKey:     "key",
+		Dimensions: 2,
+		HTTP:       &genai.HTTPOptions{BaseURL: srv.URL},
+	})
+	if err != nil {
+		t.Fatalf("NewGeminiEmbedder failed: %v", err)
+	}
+
+	embeddings, err := e.EmbedBatch(context.Background(), []string{"a", "b", "c"})
+	if err != nil {
+		t.Fatalf("EmbedBatch failed: %v", err)
+	}
+	if len(embeddings) != 3 || embeddings[2][0] != 2 {
+		t.Errorf("Expected 3 ordered embeddings, got %v", embeddings)
+	}
+	if e.Dimensions() != 2 || e.Model() != "gemini-embedding-001/2" {
+		t.Errorf("Expected 2 dimensions for gemini-embedding-001/2, got %d for %s", e.Dimensions(), e.Model())
+	}
+}
+
+func TestHashingEmbedder(t *testing.T) {
+	ctx := context.Background()
+	e := NewHashingEmbedder(0)
+	if e.Dimensions() != 256 {
+		t.Errorf("Expected default 256 dimensions, got %d", e.Dimensions())
+	}
+
+	a, _ := e.Embed(ctx, "database connection timeout")
+	b, _ := e.Embed(ctx, "database connection timeout")
+	if len(a) != 256 || similarity(a, b) < 0.999 {
+		t.Error("Expected identical texts to give identical unit vectors")
+	}
+
+	near, _ := e.Embed(ctx, "timeouts on the database")
+	far, _ := e.Embed(ctx, "pancake recipe with blueberries")
+	if similarity(a, near) <= similarity(a, far) {
+		t.Error("Expected texts sharing words to be more similar")
+	}
+
+	empty, _ := e.Embed(ctx, "")
+	for _, v := range empty {
+		if v != 0 {
+			t.Fatal("Expected a zero vector for empty text")
+		}
+	}
+}
+
+func TestCachedEmbedder_LRU(t *testing.T) {
+	ctx := context.Background()
+	inner := &countingEmbedder{keywordEmbedder: keywordEmbedder{vocab: []string{"a", "b", "c"}}}
+	cache, err := NewCachedEmbedder(inner, CacheConfig{Size: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cache.Embed(ctx, "a")
+	cache.Embed(ctx, "b")
+	cache.Embed(ctx, "a") // Hit; "b" becomes least recently used
+	cache.Embed(ctx, "c") // Evicts "b"
+	cache.Embed(ctx, "a") // Hit
+	cache.Embed(ctx, "b") // Miss again
+	if inner.calls != 4 {
+		t.Errorf("Expected 4 inner calls, got %d", inner.calls)
+	}
+	if stats := cache.Stats(); stats.Hits != 2 || stats.Misses != 4 || stats.Entries != 2 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+
+	// Batches only embed the misses (fallback to Embed for non-batch embedders)
+	inner.calls = 0
+	embeddings, err := cache.EmbedBatch(ctx, []string{"a", "b", "ab", "abc"})
+	if err != nil || len(embeddings) != 4 {
+		t.Fatalf("EmbedBatch failed: %v", err)
+	}
+	if inner.calls != 2 {
+		t.Errorf("Expected 2 inner calls for the misses, got %d", inner.calls)
+	}
+	if embeddings[3][2] != 1 || embeddings[0][1] != 0 {
+		t.Errorf("Expected results in input order, got %v", embeddings)
+	}
+}
+
+func TestCachedEmbedder_Disk(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	inner := &countingEmbedder{keywordEmbedder: keywordEmbedder{vocab: []string{"disk", "cache"}}}
+
+	first, _ := NewCachedEmbedder(inner, CacheConfig{Dir: dir, Model: "kw"})
+	want, _ := first.Embed(ctx, "disk cache")
+
+	second, _ := NewCachedEmbedder(inner, CacheConfig{Dir: dir, Model: "kw"})
+	got, err := second.Embed(ctx, "disk cache")
+	if err != nil || inner.calls != 1 {
+		t.Fatalf("Expected a disk hit, got %d inner calls (%v)", inner.calls, err)
+	}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("Expected %v from disk, got %v", want, got)
+	}
+	if second.Stats().DiskHits != 1 {
+		t.Errorf("Expected 1 disk hit, got %+v", second.Stats())
+	}
+
+	// A different model namespace must not share entries
+	other, _ := NewCachedEmbedder(inner, CacheConfig{Dir: dir, Model: "other"})
+	other.Embed(ctx, "disk cache")
+	if inner.calls != 2 {
+		t.Errorf("Expected a miss for another model, got %d inner calls", inner.calls)
+	}
+}
+
+func TestDetectDimensions(t *testing.T) {
+	ctx := context.Background()
+	if dims, _ := DetectDimensions(ctx, NewHashingEmbedder(64)); dims != 64 {
+		t.Errorf("Expected 64, got %d", dims)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"embedding": []float32{1, 2, 3, 4, 5}})
+	}))
+	defer srv.Close()
+	if dims, err := DetectDimensions(ctx, NewOllamaEmbedder(srv.URL, "unknown")); err != nil || dims != 5 {
+		t.Errorf("Expected 5 probed dimensions, got %d (%v)", dims, err)
+	}
+}
@@ -0,0 +1,277 @@
+package memory
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/taipm/go-llm-agent/pkg/types"
+)
+
+// Portable memory format (JSONL)
+//
+// An export is a UTF-8 text file with one JSON object per line. The first
+// line is a header:
+//
+//	{"format":"go-llm-agent/memory","version":1,"exported_at":"2025-01-27T10:00:00Z","records":2,"dimensions":768}
+//
+// Every following line is a Record, oldest first:
+//
+//	{"role":"user","content":"deploy failed with ERR-504","metadata":{"category":"factual"},"category":"factual","timestamp":"2025-01-27T09:58:00Z"}
+//	{"role":"assistant","content":"","tool_calls":[{"id":"c1","type":"function","function":{"name":"web_fetch","arguments":{"url":"https://status.example.com"}}}]}
+//
+// Only "role" is required. "category", "importance" and "priority" repeat the
+// metadata keys of the same name so other tools can read them without knowing
+// the metadata layout. "embedding" is present when exported with
+// IncludeEmbeddings. The header is optional on import, so hand-written
+// fixtures can contain records only.
+
+// ExportFormat identifies the portable memory format in the header line
+const ExportFormat = "go-llm-agent/memory"
+
+// ExportVersion is the version of the portable memory format
+const ExportVersion = 1
+
+// ExportHeader is the first line of an export
+type ExportHeader struct {
+	Format     string    `json:"format"`
+	Version    int       `json:"version"`
+	ExportedAt time.Time `json:"exported_at"`
+	Records    int       `json:"records"`
+	Dimensions int       `json:"dimensions,omitempty"` // Embedding size (0 without embeddings)
+}
+
+// Record is a message with the storage details needed to restore it
+type Record struct {
+	Role           types.Role             `json:"role"`
+	Content        string                 `json:"content"`
+	ToolCalls      []types.ToolCall       `json:"tool_calls,omitempty"`
+	ToolID         string                 `json:"tool_call_id,omitempty"`
+	Metadata       map[string]interface{} `json:"metadata,omitempty"`
+	Category       string                 `json:"category,omitempty"`
+	Importance     float64                `json:"importance,omitempty"`
+	Priority       int                    `json:"priority,omitempty"`
+	ConversationID string                 `json:"conversation_id,omitempty"`
+	Timestamp      *time.Time             `json:"timestamp,omitempty"`
+	Archived       bool                   `json:"archived,omitempty"`
+	Embedding      []float32              `json:"embedding,omitempty"`
+}
+
+// RecordSource is implemented by memories that can list every stored record
+// with the details GetHistory drops (timestamps, embeddings, archived state)
+type RecordSource interface {
+	Records(ctx context.Context) ([]Record, error)
+}
+
+// RecordSink is implemented by memories that can restore a record verbatim
+// (keeping its timestamp, conversation and archived state)
+type RecordSink interface {
+	ImportRecord(ctx context.Context, record Record) error
+}
+
+// ExportOptions controls what is written by Export functions
+type ExportOptions struct {
+	IncludeEmbeddings bool // Write stored vectors (large; skip to re-embed on import)
+	IncludeArchived   bool // Write archived messages too
+}
+
+// ImportOptions controls how records are restored
+type ImportOptions struct {
+	IgnoreEmbeddings bool // Drop stored vectors so the target embedder recomputes them
+}
+
+// NewRecord builds a record from a message, copying the indexed metadata fields
+func NewRecord(message types.Message) Record {
+	category, importance, priority := classifyMetadata(message.Metadata)
+	return Record{
+		Role:       message.Role,
+		Content:    message.Content,
+		ToolCalls:  message.ToolCalls,
+		ToolID:     message.ToolID,
+		Metadata:   message.Metadata,
+		Category:   category,
+		Importance: importance,
+		Priority:   priority,
+	}
+}
+
+// Message returns the record as a message. Category, importance and priority
+// are written back to metadata when the metadata does not already hold them.
+func (r Record) Message() types.Message {
+	msg := types.Message{
+		Role:      r.Role,
+		Content:   r.Content,
+		ToolCalls: r.ToolCalls,
+		ToolID:    r.ToolID,
+	}
+
+	if len(r.Metadata) > 0 || r.Category != "" || r.Importance != 0 || r.Priority != 0 {
+		msg.Metadata = make(map[string]interface{}, len(r.Metadata)+3)
+		for k, v := range r.Metadata {
+			msg.Metadata[k] = v
+		}
+		setDefault := func(key string, value interface{}, present bool) {
+			if _, ok := msg.Metadata[key]; !ok && present {
+				msg.Metadata[key] = value
+			}
+		}
+		setDefault("category", r.Category, r.Category != "")
+		setDefault("importance", r.Importance, r.Importance != 0)
+		setDefault("priority", r.Priority, r.Priority != 0)
+	}
+
+	return msg
+}
+
+// Export writes every record of mem to w in the portable JSONL format and
+// returns the number of records written. Memories implementing RecordSource
+// export full detail; others export their GetHistory(0) messages.
+func Export(ctx context.Context, mem types.Memory, w io.Writer, opts ExportOptions) (int, error) {
+	var records []Record
+	if source, ok := mem.(RecordSource); ok {
+		all, err := source.Records(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read records: %w", err)
+		}
+		records = all
+	} else {
+		messages, err := mem.GetHistory(0)
+		if err != nil {
+			return 0, fmt.Errorf("failed to read history: %w", err)
+		}
+		for _, msg := range messages {
+			records = append(records, NewRecord(msg))
+		}
+	}
+
+	header := ExportHeader{Format: ExportFormat, Version: ExportVersion, ExportedAt: time.Now().UTC()}
+	kept := records[:0:0]
+	for _, rec := range records {
+		if rec.Archived && !opts.IncludeArchived {
+			continue
+		}
+		if !opts.IncludeEmbeddings {
+			rec.Embedding = nil
+		} else if len(rec.Embedding) > 0 {
+			header.Dimensions = len(rec.Embedding)
+		}
+		kept = append(kept, rec)
+	}
+	header.Records = len(kept)
+
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	if err := enc.Encode(header); err != nil {
+		return 0, fmt.Errorf("failed to write header: %w", err)
+	}
+	for i, rec := range kept {
+		if err := enc.Encode(rec); err != nil {
+			return i, fmt.Errorf("failed to write record %d: %w", i+1, err)
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		return 0, fmt.Errorf("failed to write export: %w", err)
+	}
+
+	return len(kept), nil
+}
+
+// Import reads a portable JSONL export from r into mem and returns the number
+// of records imported. Memories implementing RecordSink restore records
+// verbatim; AdvancedMemory keeps stored embeddings; any other memory receives
+// the messages through Add.
+func Import(ctx context.Context, mem types.Memory, r io.Reader, opts ImportOptions) (int, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024) // Embeddings make long lines
+
+	sink, isSink := mem.(RecordSink)
+	advanced, isAdvanced := mem.(types.AdvancedMemory)
+
+	count, line := 0, 0
+	for scanner.Scan() {
+		line++
+		data := scanner.Bytes()
+		if len(bytes.TrimSpace(data)) == 0 {
+			continue
+		}
+
+		if line == 1 {
+			var header ExportHeader
+			if err := json.Unmarshal(data, &header); err == nil && header.Format != "" {
+				if header.Format != ExportFormat {
+					return 0, fmt.Errorf("unsupported export format %q", header.Format)
+				}
+				if header.Version > ExportVersion {
+					return 0, fmt.Errorf("unsupported export version %d", header.Version)
+				}
+				continue
+			}
+		}
+
+		var rec Record
+		if err := json.Unmarshal(data, &rec); err != nil {
+			return count, fmt.Errorf("line %d: invalid record: %w", line, err)
+		}
+		if rec.Role == "" {
+			return count, fmt.Errorf("line %d: record has no role", line)
+		}
+		if opts.IgnoreEmbeddings {
+			rec.Embedding = nil
+		}
+
+		var err error
+		switch {
+		case isSink:
+			err = sink.ImportRecord(ctx, rec)
+		case isAdvanced:
+			err = advanced.AddWithEmbedding(ctx, rec.Message(), rec.Embedding)
+		default:
+			err = mem.Add(rec.Message())
+		}
+		if err != nil {
+			return count, fmt.Errorf("line %d: failed to import record: %w", line, err)
+		}
+		count++
+	}
+	if err := scanner.Err(); err != nil {
+		return count, fmt.Errorf("failed to read export: %w", err)
+	}
+
+	return count, nil
+}
+
+// ExportFile writes mem to a new file at path; existing files are not overwritten
+func ExportFile(ctx context.Context, mem types.Memory, path string, opts ExportOptions) (int, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		if os.IsExist(err) {
+			return 0, fmt.Errorf("export target already exists: %s", path)
+		}
+		return 0, fmt.Errorf("failed to create export: %w", err)
+	}
+
+	n, err := Export(ctx, mem, f, opts)
+	if closeErr := f.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to write export: %w", closeErr)
+	}
+	if err != nil {
+		os.Remove(path)
+		return 0, err
+	}
+	return n, nil
+}
+
+// ImportFile reads a portable JSONL export from path into mem
+func ImportFile(ctx context.Context, mem types.Memory, path string, opts ImportOptions) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open import: %w", err)
+	}
+	defer f.Close()
+	return Import(ctx, mem, f, opts)
+}
@@ -0,0 +1,211 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/taipm/go-llm-agent/pkg/types"
+)
+
+// countingEmbedder wraps keywordEmbedder and counts Embed calls
+type countingEmbedder struct {
+	keywordEmbedder
+	calls int
+}
+
+func (e *countingEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	e.calls++
+	return e.keywordEmbedder.Embed(ctx, text)
+}
+
+func sampleMessages() []types.Message {
+	call := types.ToolCall{ID: "c1", Type: "function", Function: types.FunctionCall{
+		Name: "web_fetch", Arguments: map[string]interface{}{"url": "https://status.example.com"},
+	}}
+	return []types.Message{
+		{Role: types.RoleUser, Content: "deploy failed with ERR-504", Metadata: map[string]interface{}{"category": "factual", "importance": 0.9}},
+		{Role: types.RoleAssistant, ToolCalls: []types.ToolCall{call}},
+		{Role: types.RoleTool, ToolID: "c1", Content: "gateway degraded"},
+	}
+}
+
+func TestExportImport_BufferToLocal(t *testing.T) {
+	ctx := context.Background()
+	source := NewBuffer(10)
+	for _, msg := range sampleMessages() {
+		source.Add(msg)
+	}
+
+	var buf bytes.Buffer
+	n, err := Export(ctx, source, &buf, ExportOptions{})
+	if err != nil || n != 3 {
+		t.Fatalf("Expected 3 records exported, got %d (%v)", n, err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 4 || !strings.Contains(lines[0], `"format":"go-llm-agent/memory"`) || !strings.Contains(lines[1], `"category":"factual"`) {
+		t.Fatalf("Unexpected export:\n%s", buf.String())
+	}
+
+	target := newTestLocal(t, LocalVectorMemoryConfig{})
+	if n, err := Import(ctx, target, &buf, ImportOptions{}); err != nil || n != 3 {
+		t.Fatalf("Expected 3 records imported, got %d (%v)", n, err)
+	}
+
+	history, _ := target.GetHistory(0)
+	if len(history) != 3 {
+		t.Fatalf("Expected 3 messages, got %+v", history)
+	}
+	if history[0].Metadata["category"] != "factual" || history[0].Metadata["importance"] != 0.9 {
+		t.Errorf("Expected metadata round trip, got %+v", history[0].Metadata)
+	}
+	if got := history[1].ToolCalls; len(got) != 1 || got[0].Function.Arguments["url"] != "https://status.example.com" {
+		t.Errorf("Expected tool call round trip, got %+v", got)
+	}
+	if history[2].ToolID != "c1" || history[2].Content != "gateway degraded" {
+		t.Errorf("Expected tool result round trip, got %+v", history[2])
+	}
+}
+
+func TestExportImport_LocalFullFidelity(t *testing.T) {
+	ctx := context.Background()
+	embedder := &countingEmbedder{keywordEmbedder: keywordEmbedder{vocab: []string{"deploy", "gateway"}}}
+	source := newTestLocal(t, LocalVectorMemoryConfig{Embedder: embedder})
+	for _, msg := range sampleMessages() {
+		source.Add(msg)
+	}
+	time.Sleep(5 * time.Millisecond)
+	source.Archive(ctx, time.Millisecond)
+	source.Add(types.Message{Role: types.RoleUser, Content: "new deploy"})
+
+	path := filepath.Join(t.TempDir(), "memory.jsonl")
+	if err := source.Export(ctx, path); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if err := source.Export(ctx, path); err == nil {
+		t.Error("Expected Export to refuse overwriting an existing file")
+	}
+
+	embedder.calls = 0
+	target := newTestLocal(t, LocalVectorMemoryConfig{Embedder: embedder})
+	if n, err := ImportFile(ctx, target, path, ImportOptions{}); err != nil || n != 4 {
+		t.Fatalf("Expected 4 records imported, got %d (%v)", n, err)
+	}
+	if embedder.calls != 0 {
+		t.Errorf("Expected stored embeddings to be reused, got %d Embed calls", embedder.calls)
+	}
+
+	want, _ := source.Records(ctx)
+	got, _ := target.Records(ctx)
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d records, got %d", len(want), len(got))
+	}
+	for i := range want {
+		if !got[i].Timestamp.Equal(*want[i].Timestamp) || got[i].Archived != want[i].Archived || !slices.Equal(got[i].Embedding, want[i].Embedding) {
+			t.Errorf("Record %d differs:\nwant %+v\ngot  %+v", i, want[i], got[i])
+		}
+	}
+	if target.Size() != 1 {
+		t.Errorf("Expected archived records to stay archived, got size %d", target.Size())
+	}
+
+	// Re-embedding on import
+	embedder.calls = 0
+	reembedded := newTestLocal(t, LocalVectorMemoryConfig{Embedder: embedder})
+	ImportFile(ctx, reembedded, path, ImportOptions{IgnoreEmbeddings: true})
+	if embedder.calls != 3 {
+		t.Errorf("Expected the 3 messages with content to be re-embedded, got %d calls", embedder.calls)
+	}
+}
+
+func TestExport_SkipsArchivedAndEmbeddingsByDefault(t *testing.T) {
+	ctx := context.Background()
+	mem := newTestLocal(t, LocalVectorMemoryConfig{Embedder: &keywordEmbedder{vocab: []string{"old", "new"}}})
+	mem.Add(types.Message{Role: types.RoleUser, Content: "old"})
+	time.Sleep(5 * time.Millisecond)
+	mem.Archive(ctx, time.Millisecond)
+	mem.Add(types.Message{Role: types.RoleUser, Content: "new"})
+
+	var buf bytes.Buffer
+	if n, _ := Export(ctx, mem, &buf, ExportOptions{}); n != 1 {
+		t.Errorf("Expected only the live record, got %d", n)
+	}
+	if strings.Contains(buf.String(), "embedding") || strings.Contains(buf.String(), `"dimensions"`) {
+		t.Errorf("Expected no embeddings, got:\n%s", buf.String())
+	}
+}
+
+func TestImport_Fixtures(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		input   string
+		want    int
+		wantErr string
+	}{
+		{"records without header", `{"role":"user","content":"hi","category":"user","priority":4}` + "\n\n" + `{"role":"assistant","content":"hello"}`, 2, ""},
+		{"header only", `{"format":"go-llm-agent/memory","version":1,"records":0}`, 0, ""},
+		{"foreign format", `{"format":"other","version":1}`, 0, "unsupported export format"},
+		{"newer version", `{"format":"go-llm-agent/memory","version":99}`, 0, "unsupported export version"},
+		{"missing role", `{"role":"user","content":"ok"}` + "\n" + `{"content":"no role"}`, 1, "line 2: record has no role"},
+		{"invalid json", `{"role":`, 0, "line 1: invalid record"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mem := NewBuffer(10)
+			n, err := Import(ctx, mem, strings.NewReader(tt.input), ImportOptions{})
+			if n != tt.want {
+				t.Errorf("Expected %d records, got %d", tt.want, n)
+			}
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	mem := NewBuffer(10)
+	Import(ctx, mem, strings.NewReader(tests[0].input), ImportOptions{})
+	history, _ := mem.GetHistory(0)
+	if history[0].Metadata["category"] != "user" || history[0].Metadata["priority"] != 4 {
+		t.Errorf("Expected top-level fields to be copied into metadata, got %+v", history[0].Metadata)
+	}
+	if history[1].Metadata != nil {
+		t.Errorf("Expected no metadata for a plain record, got %+v", history[1].Metadata)
+	}
+}
+
+func TestExportImport_SQLite(t *testing.T) {
+	ctx := context.Background()
+	source := newTestSQLite(t, nil)
+	for _, msg := range sampleMessages() {
+		source.Add(msg)
+	}
+	source.WithConversation("other").AddWithEmbedding(ctx, types.Message{Role: types.RoleUser, Content: "vector"}, []float32{1, 0})
+
+	var buf bytes.Buffer
+	if n, err := Export(ctx, source, &buf, ExportOptions{IncludeEmbeddings: true}); err != nil || n != 4 {
+		t.Fatalf("Expected 4 records, got %d (%v)", n, err)
+	}
+
+	target := newTestSQLite(t, nil)
+	if _, err := Import(ctx, target, &buf, ImportOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	want, _ := source.Records(ctx)
+	got, _ := target.Records(ctx)
+	for i := range want {
+		if got[i].ConversationID != want[i].ConversationID || !got[i].Timestamp.Equal(*want[i].Timestamp) ||
+			!slices.Equal(got[i].Embedding, want[i].Embedding) || got[i].Content != want[i].Content {
+			t.Errorf("Record %d differs:\nwant %+v\ngot  %+v", i, want[i], got[i])
+		}
+	}
+}
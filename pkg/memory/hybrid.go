@@ -0,0 +1,266 @@
+package memory
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/taipm/go-llm-agent/pkg/types"
+)
+
+// HybridConfig tunes hybrid (keyword + vector) search
+type HybridConfig struct {
+	VectorWeight  float64 // Weight of the vector ranking in the fused score (default 1.0, negative disables)
+	KeywordWeight float64 // Weight of the BM25 ranking in the fused score (default 1.0, negative disables)
+	RRFK          float64 // Reciprocal rank fusion constant (default 60)
+	Candidates    int     // Results taken from each ranking before fusion (default 4*limit, min 20)
+	BM25          BM25Config
+}
+
+// withDefaults fills unset fields
+func (c HybridConfig) withDefaults() HybridConfig {
+	if c.VectorWeight == 0 {
+		c.VectorWeight = 1.0
+	}
+	if c.KeywordWeight == 0 {
+		c.KeywordWeight = 1.0
+	}
+	if c.RRFK <= 0 {
+		c.RRFK = 60
+	}
+	return c
+}
+
+// candidates returns how many results to take from each ranking
+func (c HybridConfig) candidates(limit int) int {
+	if c.Candidates > 0 {
+		return max(c.Candidates, limit)
+	}
+	return max(4*limit, 20)
+}
+
+// HybridResult is a recalled message with the scores that explain why
+type HybridResult struct {
+	Message      types.Message
+	Score        float64 // Fused reciprocal rank score (higher is better)
+	VectorScore  float64 // Similarity to the query (0 when not a vector match)
+	VectorRank   int     // 1-based rank among vector matches (0 when not a vector match)
+	KeywordScore float64 // BM25 score (0 when not a keyword match)
+	KeywordRank  int     // 1-based rank among keyword matches (0 when not a keyword match)
+}
+
+// fuseRRF merges vector and keyword rankings with weighted reciprocal rank
+// fusion: score = wv/(k+rank_v) + wk/(k+rank_k). It returns IDs with their
+// partial scores, best first; Message is left for the caller to fill.
+func fuseRRF(vector, keyword []IndexHit, config HybridConfig) ([]string, map[string]*HybridResult) {
+	config = config.withDefaults()
+	fused := make(map[string]*HybridResult)
+	var order []string
+
+	get := func(id string) *HybridResult {
+		r, ok := fused[id]
+		if !ok {
+			r = &HybridResult{}
+			fused[id] = r
+			order = append(order, id)
+		}
+		return r
+	}
+
+	if config.VectorWeight > 0 {
+		for i, hit := range vector {
+			r := get(hit.ID)
+			r.VectorScore, r.VectorRank = hit.Score, i+1
+			r.Score += config.VectorWeight / (config.RRFK + float64(i+1))
+		}
+	}
+	if config.KeywordWeight > 0 {
+		for i, hit := range keyword {
+			r := get(hit.ID)
+			r.KeywordScore, r.KeywordRank = hit.Score, i+1
+			r.Score += config.KeywordWeight / (config.RRFK + float64(i+1))
+		}
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		return fused[order[i]].Score > fused[order[j]].Score
+	})
+	return order, fused
+}
+
+// buildHybridResults fuses the rankings and attaches messages, keeping limit
+func buildHybridResults(vector, keyword []IndexHit, messages map[string]types.Message, config HybridConfig, limit int) []HybridResult {
+	order, fused := fuseRRF(vector, keyword, config)
+	results := make([]HybridResult, 0, min(limit, len(order)))
+	for _, id := range order {
+		if len(results) >= limit {
+			break
+		}
+		r := fused[id]
+		r.Message = messages[id]
+		results = append(results, *r)
+	}
+	return results
+}
+
+// hybridMessages strips scores from hybrid results
+func hybridMessages(results []HybridResult) []types.Message {
+	messages := make([]types.Message, len(results))
+	for i, r := range results {
+		messages[i] = r.Message
+	}
+	return messages
+}
+
+// ===========================
+// BM25 keyword index
+// ===========================
+
+// BM25Config holds the BM25 ranking parameters
+type BM25Config struct {
+	K1 float64 // Term frequency saturation (default 1.2)
+	B  float64 // Length normalization (default 0.75)
+}
+
+// BM25Index is an in-process inverted index scored with Okapi BM25.
+// It is not safe for concurrent use.
+type BM25Index struct {
+	config   BM25Config
+	postings map[string]map[string]int // term -> doc -> term frequency
+	terms    map[string][]string       // doc -> distinct terms (for Remove)
+	lengths  map[string]int            // doc -> number of terms
+	total    int                       // Sum of lengths
+}
+
+// NewBM25Index creates an empty keyword index
+func NewBM25Index(config BM25Config) *BM25Index {
+	if config.K1 <= 0 {
+		config.K1 = 1.2
+	}
+	if config.B <= 0 || config.B > 1 {
+		config.B = 0.75
+	}
+	return &BM25Index{
+		config:   config,
+		postings: make(map[string]map[string]int),
+		terms:    make(map[string][]string),
+		lengths:  make(map[string]int),
+	}
+}
+
+// Add indexes text under id, replacing any previous text
+func (b *BM25Index) Add(id, text string) {
+	b.Remove(id)
+
+	terms := Tokenize(text)
+	if len(terms) == 0 {
+		return
+	}
+	for _, term := range terms {
+		docs, ok := b.postings[term]
+		if !ok {
+			docs = make(map[string]int)
+			b.postings[term] = docs
+		}
+		if docs[id] == 0 {
+			b.terms[id] = append(b.terms[id], term)
+		}
+		docs[id]++
+	}
+	b.lengths[id] = len(terms)
+	b.total += len(terms)
+}
+
+// Remove deletes id from the index
+func (b *BM25Index) Remove(id string) {
+	length, ok := b.lengths[id]
+	if !ok {
+		return
+	}
+	for _, term := range b.terms[id] {
+		docs := b.postings[term]
+		delete(docs, id)
+		if len(docs) == 0 {
+			delete(b.postings, term)
+		}
+	}
+	delete(b.terms, id)
+	delete(b.lengths, id)
+	b.total -= length
+}
+
+// Len returns the number of indexed documents
+func (b *BM25Index) Len() int {
+	return len(b.lengths)
+}
+
+// Search returns up to k documents ranked by BM25, accepted by filter (nil = all)
+func (b *BM25Index) Search(query string, k int, filter func(id string) bool) []IndexHit {
+	n := len(b.lengths)
+	if n == 0 {
+		return nil
+	}
+	avgLength := float64(b.total) / float64(n)
+
+	scores := make(map[string]float64)
+	seen := make(map[string]bool)
+	for _, term := range Tokenize(query) {
+		if seen[term] {
+			continue
+		}
+		seen[term] = true
+
+		docs := b.postings[term]
+		if len(docs) == 0 {
+			continue
+		}
+		df := float64(len(docs))
+		idf := math.Log(1 + (float64(n)-df+0.5)/(df+0.5))
+		for id, tf := range docs {
+			if filter != nil && !filter(id) {
+				continue
+			}
+			norm := 1 - b.config.B + b.config.B*float64(b.lengths[id])/avgLength
+			f := float64(tf)
+			scores[id] += idf * f * (b.config.K1 + 1) / (f + b.config.K1*norm)
+		}
+	}
+
+	hits := make([]IndexHit, 0, len(scores))
+	for id, score := range scores {
+		hits = append(hits, IndexHit{ID: id, Score: score})
+	}
+	return topHits(hits, k)
+}
+
+// Tokenize lowercases text and splits it into keyword terms. Identifiers
+// such as "web_search", "ERR-504" or "db-1.prod.local" are kept whole and
+// also split into their parts, so both exact and partial queries match.
+func Tokenize(text string) []string {
+	var terms []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !isJoiner(r)
+	}) {
+		word = strings.TrimFunc(word, isJoiner)
+		if word == "" {
+			continue
+		}
+		terms = append(terms, word)
+
+		parts := strings.FieldsFunc(word, isJoiner)
+		if len(parts) > 1 {
+			terms = append(terms, parts...)
+		}
+	}
+	return terms
+}
+
+// isJoiner reports runes that join identifier parts
+func isJoiner(r rune) bool {
+	switch r {
+	case '_', '-', '.', ':', '/':
+		return true
+	}
+	return false
+}
@@ -0,0 +1,141 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/taipm/go-llm-agent/pkg/types"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"Hello, World!", []string{"hello", "world"}},
+		{"call web_search now", []string{"call", "web_search", "web", "search", "now"}},
+		{"ERR-504 from db-1.prod.local.", []string{"err-504", "err", "504", "from", "db-1.prod.local", "db", "1", "prod", "local"}},
+		{"  --  ", nil},
+	}
+	for _, tt := range tests {
+		if got := Tokenize(tt.text); !slices.Equal(got, tt.want) {
+			t.Errorf("Tokenize(%q): expected %v, got %v", tt.text, tt.want, got)
+		}
+	}
+}
+
+func TestBM25Index_Search(t *testing.T) {
+	index := NewBM25Index(BM25Config{})
+	index.Add("a", "the server returned ERR-504 gateway timeout")
+	index.Add("b", "the server is healthy and the server is fast")
+	index.Add("c", "the weather is nice")
+	index.Add("d", "")
+
+	if index.Len() != 3 {
+		t.Errorf("Expected empty documents to be skipped, got %d", index.Len())
+	}
+
+	hits := index.Search("ERR-504", 5, nil)
+	if len(hits) != 1 || hits[0].ID != "a" {
+		t.Errorf("Expected exact identifier match, got %+v", hits)
+	}
+
+	// Rare terms outweigh common ones
+	hits = index.Search("server timeout", 5, nil)
+	if len(hits) != 2 || hits[0].ID != "a" || hits[0].Score <= hits[1].Score {
+		t.Errorf("Expected a (rare term) before b, got %+v", hits)
+	}
+
+	hits = index.Search("server", 5, func(id string) bool { return id != "a" })
+	if len(hits) != 1 || hits[0].ID != "b" {
+		t.Errorf("Expected filter to exclude a, got %+v", hits)
+	}
+
+	index.Add("a", "replaced text")
+	index.Remove("b")
+	if hits := index.Search("server", 5, nil); len(hits) != 0 {
+		t.Errorf("Expected replaced and removed documents to be gone, got %+v", hits)
+	}
+	if hits := index.Search("replaced", 5, nil); len(hits) != 1 || hits[0].ID != "a" {
+		t.Errorf("Expected replaced text to be indexed, got %+v", hits)
+	}
+}
+
+func TestFuseRRF(t *testing.T) {
+	vector := []IndexHit{{ID: "v1", Score: 0.9}, {ID: "both", Score: 0.8}}
+	keyword := []IndexHit{{ID: "k1", Score: 5}, {ID: "both", Score: 3}}
+
+	tests := []struct {
+		name   string
+		config HybridConfig
+		want   []string
+	}{
+		{"equal weights rank shared hit first", HybridConfig{}, []string{"both", "v1", "k1"}},
+		{"keyword weight dominates", HybridConfig{VectorWeight: 0.1, KeywordWeight: 2}, []string{"both", "k1", "v1"}},
+		{"vector only", HybridConfig{KeywordWeight: -1}, []string{"v1", "both"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order, fused := fuseRRF(vector, keyword, tt.config)
+			if !slices.Equal(order, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, order)
+			}
+			if r := fused["both"]; r.VectorRank != 2 || r.VectorScore != 0.8 {
+				t.Errorf("Expected vector rank and score to be kept, got %+v", r)
+			}
+		})
+	}
+
+	order, fused := fuseRRF(vector, keyword, HybridConfig{})
+	want := 1 / 61.0 // v1: rank 1 in the vector list only
+	if got := fused[order[1]].Score; got != want {
+		t.Errorf("Expected RRF score %v, got %v", want, got)
+	}
+}
+
+func TestLocalVectorMemory_HybridSearchWithScores(t *testing.T) {
+	mem := newTestLocal(t, LocalVectorMemoryConfig{
+		Embedder: &keywordEmbedder{vocab: []string{"gateway", "weather"}},
+	})
+	ctx := context.Background()
+
+	mem.Add(types.Message{Role: types.RoleTool, Content: "upstream failed with ERR-504"})
+	mem.Add(types.Message{Role: types.RoleAssistant, Content: "the gateway is slow"})
+	mem.Add(types.Message{Role: types.RoleAssistant, Content: "the weather is sunny"})
+
+	// The embedder knows nothing about error codes: only BM25 finds it
+	results, err := mem.HybridSearchWithScores(ctx, "gateway ERR-504", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected keyword and vector matches, got %+v", results)
+	}
+
+	byContent := make(map[string]HybridResult)
+	for _, r := range results {
+		byContent[r.Message.Content] = r
+	}
+	code := byContent["upstream failed with ERR-504"]
+	if code.KeywordRank == 0 || code.VectorRank != 0 || code.KeywordScore <= 0 {
+		t.Errorf("Expected error code to be a keyword-only match, got %+v", code)
+	}
+	gateway := byContent["the gateway is slow"]
+	if gateway.VectorRank != 1 || gateway.VectorScore < 0.999 || gateway.KeywordRank == 0 {
+		t.Errorf("Expected gateway message to match both ways, got %+v", gateway)
+	}
+	if results[0].Message.Content != "the gateway is slow" {
+		t.Errorf("Expected the double match first, got %+v", results[0])
+	}
+
+	// Turning off keywords leaves only the vector ranking
+	vectorOnly := newTestLocal(t, LocalVectorMemoryConfig{
+		Embedder: &keywordEmbedder{vocab: []string{"gateway", "weather"}},
+		Hybrid:   HybridConfig{KeywordWeight: -1},
+	})
+	vectorOnly.Add(types.Message{Role: types.RoleTool, Content: "upstream failed with ERR-504"})
+	if results, _ := vectorOnly.HybridSearchWithScores(ctx, "ERR-504", 3); len(results) != 0 {
+		t.Errorf("Expected no results with keywords disabled, got %+v", results)
+	}
+}
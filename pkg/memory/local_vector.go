@@ -0,0 +1,682 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/taipm/go-llm-agent/pkg/types"
+)
+
+// LocalVectorMemory implements AdvancedMemory with an in-process vector index.
+// It needs no server: messages and embeddings live in memory and can be
+// persisted to a single JSON file, so semantic memory works offline.
+type LocalVectorMemory struct {
+	mu       sync.RWMutex
+	config   LocalVectorMemoryConfig
+	embedder Embedder
+	index    VectorIndex
+	keywords *BM25Index
+	path     string
+	autoSave bool
+	dims     int
+
+	records []*localRecord          // Insertion order (oldest first)
+	byID    map[string]*localRecord // Lookup for index hits
+	removed int                     // Index removals since the index was last rebuilt
+
+	dirty     bool        // Changes not yet written by AutoSave
+	saveTimer *time.Timer // Pending AutoSave write
+	saveErr   error       // Error from the last background AutoSave write
+}
+
+// LocalVectorMemoryConfig holds configuration for LocalVectorMemory
+type LocalVectorMemoryConfig struct {
+	Embedder Embedder     // Optional: enables semantic search (messages are stored without vectors otherwise)
+	Index    IndexType    // IndexFlat (default, exact) or IndexHNSW (approximate, for large memories)
+	Metric   Metric       // MetricCosine (default) or MetricDot
+	HNSW     HNSWConfig   // HNSW tuning (IndexHNSW only)
+	Path     string       // Optional persistence file, loaded on creation and written by Save/Close
+	AutoSave bool         // Write Path after changes (see SaveDelay)
+	Hybrid   HybridConfig // Keyword/vector fusion used by HybridSearch
+
+	// HistorySize caps GetHistory(0) to the most recent messages, like the
+	// size of a BufferMemory (0 = all). Searches still cover every message.
+	HistorySize int
+
+	// MaxRecords caps the stored records (0 = unlimited). Beyond it the
+	// oldest records, archived or not, are evicted from the store and index.
+	MaxRecords int
+
+	// SaveDelay coalesces AutoSave writes: changes made within the delay are
+	// written together (default 1s, negative writes on every change).
+	// Save and Close flush pending changes.
+	SaveDelay time.Duration
+}
+
+// Filter restricts a search by payload. The key "role" matches the message
+// role and any other key (such as "category") the metadata value of the same
+// name. Values are compared by their string form.
+type Filter map[string]interface{}
+
+// SearchResult is a message with its similarity to the query
+type SearchResult struct {
+	Message types.Message
+	Score   float64
+}
+
+// localRecord is a stored message and its payload
+type localRecord struct {
+	ID        string        `json:"id"`
+	Message   types.Message `json:"message"`
+	Embedding []float32     `json:"embedding,omitempty"`
+	CreatedAt time.Time     `json:"created_at"`
+	Archived  bool          `json:"archived,omitempty"`
+}
+
+// localSnapshot is the persistence file format
+type localSnapshot struct {
+	Version    int            `json:"version"`
+	Metric     Metric         `json:"metric"`
+	Dimensions int            `json:"dimensions"`
+	Records    []*localRecord `json:"records"`
+}
+
+const localSnapshotVersion = 1
+
+// localDefaultSaveDelay is the default AutoSave debounce
+const localDefaultSaveDelay = time.Second
+
+// localMinRebuild is the number of index removals below which the index is
+// never rebuilt (HNSW keeps removed vectors as tombstones until then)
+const localMinRebuild = 1000
+
+// NewLocalVectorMemory creates an in-process vector memory, loading Path if it exists
+func NewLocalVectorMemory(config LocalVectorMemoryConfig) (*LocalVectorMemory, error) {
+	if config.Index == "" {
+		config.Index = IndexFlat
+	}
+	if config.Metric == "" {
+		config.Metric = MetricCosine
+	}
+	if config.SaveDelay == 0 {
+		config.SaveDelay = localDefaultSaveDelay
+	}
+	if config.Index != IndexFlat && config.Index != IndexHNSW {
+		return nil, fmt.Errorf("unknown index type: %s", config.Index)
+	}
+	if config.Metric != MetricCosine && config.Metric != MetricDot {
+		return nil, fmt.Errorf("unknown metric: %s", config.Metric)
+	}
+
+	l := &LocalVectorMemory{
+		config:   config,
+		embedder: config.Embedder,
+		index:    NewVectorIndex(config.Index, config.Metric, config.HNSW),
+		keywords: NewBM25Index(config.Hybrid.BM25),
+		path:     config.Path,
+		autoSave: config.AutoSave && config.Path != "",
+		byID:     make(map[string]*localRecord),
+	}
+
+	if config.Path != "" {
+		if err := l.load(config.Path); err != nil {
+			return nil, err
+		}
+		l.evict()
+	}
+
+	return l, nil
+}
+
+// WithConversation returns a separate memory for one conversation with the
+// same configuration and embedder. With a Path it persists to a sibling file
+// named after the conversation (memory.json -> memory.<id>.json), written on
+// every change so that a memory reopened for the conversation is up to date.
+func (l *LocalVectorMemory) WithConversation(conversationID string) (*LocalVectorMemory, error) {
+	config := l.config
+	if config.Path != "" {
+		ext := filepath.Ext(config.Path)
+		config.Path = strings.TrimSuffix(config.Path, ext) + "." + url.PathEscape(conversationID) + ext
+		config.SaveDelay = -1
+	}
+	return NewLocalVectorMemory(config)
+}
+
+// Add implements types.Memory interface
+func (l *LocalVectorMemory) Add(message types.Message) error {
+	return l.AddWithEmbedding(context.Background(), message, nil)
+}
+
+// AddWithEmbedding implements types.AdvancedMemory interface.
+// Without a pre-computed embedding one is generated if an embedder is set;
+// if that fails the message is stored without an embedding.
+func (l *LocalVectorMemory) AddWithEmbedding(ctx context.Context, message types.Message, embedding []float32) error {
+	return l.add(ctx, &localRecord{Message: message, Embedding: embedding, CreatedAt: time.Now()})
+}
+
+// add embeds (if needed) and stores a new record
+func (l *LocalVectorMemory) add(ctx context.Context, rec *localRecord) error {
+	if rec.Embedding == nil && l.embedder != nil && strings.TrimSpace(rec.Message.Content) != "" {
+		if emb, err := l.embedder.Embed(ctx, rec.Message.Content); err == nil {
+			rec.Embedding = emb
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(rec.Embedding) > 0 {
+		if l.dims == 0 {
+			l.dims = len(rec.Embedding)
+		} else if len(rec.Embedding) != l.dims {
+			return fmt.Errorf("embedding has %d dimensions, expected %d", len(rec.Embedding), l.dims)
+		}
+	}
+
+	rec.ID = uuid.New().String()
+	l.insert(rec)
+	l.evict()
+
+	return l.changed()
+}
+
+// GetHistory implements types.Memory interface
+func (l *LocalVectorMemory) GetHistory(limit int) ([]types.Message, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	if limit <= 0 {
+		limit = l.config.HistorySize
+	}
+
+	messages := make([]types.Message, 0)
+	for i := len(l.records) - 1; i >= 0; i-- {
+		if limit > 0 && len(messages) >= limit {
+			break
+		}
+		if !l.records[i].Archived {
+			messages = append(messages, l.records[i].Message)
+		}
+	}
+
+	// Oldest first
+	for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+		messages[i], messages[j] = messages[j], messages[i]
+	}
+	return messages, nil
+}
+
+// Clear implements types.Memory interface
+func (l *LocalVectorMemory) Clear() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.index = NewVectorIndex(l.config.Index, l.config.Metric, l.config.HNSW)
+	l.keywords = NewBM25Index(l.config.Hybrid.BM25)
+	l.records = nil
+	l.byID = make(map[string]*localRecord)
+	l.removed = 0
+	l.dims = 0
+
+	return l.changed()
+}
+
+// Size implements types.Memory interface
+func (l *LocalVectorMemory) Size() int {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	count := 0
+	for _, rec := range l.records {
+		if !rec.Archived {
+			count++
+		}
+	}
+	return count
+}
+
+// SearchSemantic implements types.AdvancedMemory interface
+func (l *LocalVectorMemory) SearchSemantic(ctx context.Context, query string, limit int) ([]types.Message, error) {
+	results, err := l.Search(ctx, query, limit, nil)
+	if err != nil {
+		return nil, err
+	}
+	return resultMessages(results), nil
+}
+
+// Search embeds the query and returns the closest messages matching filter
+func (l *LocalVectorMemory) Search(ctx context.Context, query string, limit int, filter Filter) ([]SearchResult, error) {
+	if l.embedder == nil {
+		return nil, fmt.Errorf("semantic search requires an embedder")
+	}
+
+	queryEmbedding, err := l.embedder.Embed(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate query embedding: %w", err)
+	}
+
+	return l.SearchVector(queryEmbedding, limit, filter), nil
+}
+
+// SearchVector returns the messages closest to a pre-computed vector
+func (l *LocalVectorMemory) SearchVector(vector []float32, limit int, filter Filter) []SearchResult {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	var accept func(id string) bool
+	if len(filter) > 0 {
+		accept = func(id string) bool {
+			return filter.Matches(l.byID[id].Message)
+		}
+	}
+
+	hits := l.index.Search(vector, limit, accept)
+	results := make([]SearchResult, 0, len(hits))
+	for _, hit := range hits {
+		results = append(results, SearchResult{Message: l.byID[hit.ID].Message, Score: hit.Score})
+	}
+	return results
+}
+
+// GetByCategory implements types.AdvancedMemory interface (most recent first)
+func (l *LocalVectorMemory) GetByCategory(ctx context.Context, category types.MessageCategory, limit int) ([]types.Message, error) {
+	filter := Filter{"category": category}
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	messages := make([]types.Message, 0)
+	for i := len(l.records) - 1; i >= 0 && len(messages) < limit; i-- {
+		if rec := l.records[i]; !rec.Archived && filter.Matches(rec.Message) {
+			messages = append(messages, rec.Message)
+		}
+	}
+	return messages, nil
+}
+
+// GetMostImportant implements types.AdvancedMemory interface
+func (l *LocalVectorMemory) GetMostImportant(ctx context.Context, limit int) ([]types.Message, error) {
+	l.mu.RLock()
+	live := l.live()
+	l.mu.RUnlock()
+
+	// Newest first among equal importance
+	for i, j := 0, len(live)-1; i < j; i, j = i+1, j-1 {
+		live[i], live[j] = live[j], live[i]
+	}
+	importance := func(rec *localRecord) float64 {
+		_, imp, _ := classifyMetadata(rec.Message.Metadata)
+		return imp
+	}
+	sort.SliceStable(live, func(i, j int) bool {
+		return importance(live[i]) > importance(live[j])
+	})
+
+	messages := make([]types.Message, 0, limit)
+	for i := 0; i < len(live) && i < limit; i++ {
+		messages = append(messages, live[i].Message)
+	}
+	return messages, nil
+}
+
+// HybridSearch implements types.AdvancedMemory interface by fusing BM25
+// keyword matches with vector matches (see HybridSearchWithScores)
+func (l *LocalVectorMemory) HybridSearch(ctx context.Context, query string, limit int) ([]types.Message, error) {
+	results, err := l.HybridSearchWithScores(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	return hybridMessages(results), nil
+}
+
+// HybridSearchWithScores ranks messages by weighted reciprocal rank fusion of
+// vector similarity and BM25 keyword relevance. Without an embedder (or if the
+// query cannot be embedded) results are ranked by keywords alone.
+func (l *LocalVectorMemory) HybridSearchWithScores(ctx context.Context, query string, limit int) ([]HybridResult, error) {
+	var queryEmbedding []float32
+	if l.embedder != nil {
+		if emb, err := l.embedder.Embed(ctx, query); err == nil {
+			queryEmbedding = emb
+		}
+	}
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	candidates := l.config.Hybrid.candidates(limit)
+	var vectorHits []IndexHit
+	if queryEmbedding != nil {
+		for _, hit := range l.index.Search(queryEmbedding, candidates, nil) {
+			if hit.Score > 0 {
+				vectorHits = append(vectorHits, hit)
+			}
+		}
+	}
+	keywordHits := l.keywords.Search(query, candidates, nil)
+
+	messages := make(map[string]types.Message, len(vectorHits)+len(keywordHits))
+	for _, hits := range [][]IndexHit{vectorHits, keywordHits} {
+		for _, hit := range hits {
+			messages[hit.ID] = l.byID[hit.ID].Message
+		}
+	}
+
+	return buildHybridResults(vectorHits, keywordHits, messages, l.config.Hybrid, limit), nil
+}
+
+// GetStats implements types.AdvancedMemory interface
+func (l *LocalVectorMemory) GetStats(ctx context.Context) (*types.MemoryStats, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	stats := &types.MemoryStats{VectorCount: l.index.Len()}
+	for _, rec := range l.live() {
+		stats.TotalMessages++
+		category, _, _ := classifyMetadata(rec.Message.Metadata)
+		switch types.MessageCategory(category) {
+		case types.CategoryReasoning:
+			stats.TotalReActSteps++
+		case types.CategoryPlanning:
+			stats.TotalPlans++
+		case types.CategoryReflection:
+			stats.TotalReflections++
+		}
+		if stats.OldestMessage.IsZero() || rec.CreatedAt.Before(stats.OldestMessage) {
+			stats.OldestMessage = rec.CreatedAt
+		}
+		if rec.CreatedAt.After(stats.NewestMessage) {
+			stats.NewestMessage = rec.CreatedAt
+		}
+	}
+
+	if l.path != "" {
+		if info, err := os.Stat(l.path); err == nil {
+			stats.DatabaseSize = info.Size()
+		}
+	}
+
+	return stats, nil
+}
+
+// Archive implements types.AdvancedMemory interface.
+// Archived messages are kept (and persisted) but excluded from history and
+// search. Messages with PriorityCritical are never archived.
+func (l *LocalVectorMemory) Archive(ctx context.Context, olderThan time.Duration) error {
+	cutoff := time.Now().Add(-olderThan)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, rec := range l.records {
+		_, _, priority := classifyMetadata(rec.Message.Metadata)
+		if !rec.Archived && rec.CreatedAt.Before(cutoff) && priority < int(types.PriorityCritical) {
+			rec.Archived = true
+			l.remove(rec)
+		}
+	}
+	l.compact()
+
+	return l.changed()
+}
+
+// Export implements types.AdvancedMemory interface by writing all records,
+// including archived ones and embeddings, in the portable JSONL format
+func (l *LocalVectorMemory) Export(ctx context.Context, path string) error {
+	_, err := ExportFile(ctx, l, path, ExportOptions{IncludeEmbeddings: true, IncludeArchived: true})
+	return err
+}
+
+// Records implements RecordSource
+func (l *LocalVectorMemory) Records(ctx context.Context) ([]Record, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	records := make([]Record, 0, len(l.records))
+	for _, rec := range l.records {
+		r := NewRecord(rec.Message)
+		created := rec.CreatedAt
+		r.Timestamp = &created
+		r.Archived = rec.Archived
+		r.Embedding = rec.Embedding
+		records = append(records, r)
+	}
+	return records, nil
+}
+
+// ImportRecord implements RecordSink, keeping the timestamp and archived state
+func (l *LocalVectorMemory) ImportRecord(ctx context.Context, record Record) error {
+	created := time.Now()
+	if record.Timestamp != nil {
+		created = *record.Timestamp
+	}
+	return l.add(ctx, &localRecord{
+		Message:   record.Message(),
+		Embedding: record.Embedding,
+		CreatedAt: created,
+		Archived:  record.Archived,
+	})
+}
+
+// Save writes the memory to its persistence file, including pending AutoSave changes
+func (l *LocalVectorMemory) Save() error {
+	if l.path == "" {
+		return fmt.Errorf("no persistence path configured")
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.flush()
+}
+
+// Close saves the memory if a persistence path is configured
+func (l *LocalVectorMemory) Close() error {
+	if l.path == "" {
+		return nil
+	}
+	return l.Save()
+}
+
+// Matches reports whether a message satisfies every filter condition
+func (f Filter) Matches(message types.Message) bool {
+	for key, want := range f {
+		var got interface{}
+		switch key {
+		case "role":
+			got = message.Role
+		default:
+			v, ok := message.Metadata[key]
+			if !ok {
+				return false
+			}
+			got = v
+		}
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			return false
+		}
+	}
+	return true
+}
+
+// insert adds a record to the store and index (caller holds the lock)
+func (l *LocalVectorMemory) insert(rec *localRecord) {
+	l.records = append(l.records, rec)
+	l.byID[rec.ID] = rec
+	if rec.Archived {
+		return
+	}
+	if len(rec.Embedding) > 0 {
+		l.index.Add(rec.ID, rec.Embedding)
+	}
+	l.keywords.Add(rec.ID, rec.Message.Content)
+}
+
+// remove drops a record from the index and keyword index (caller holds the lock)
+func (l *LocalVectorMemory) remove(rec *localRecord) {
+	if len(rec.Embedding) > 0 {
+		l.index.Remove(rec.ID)
+		l.removed++
+	}
+	l.keywords.Remove(rec.ID)
+}
+
+// evict drops the oldest records beyond MaxRecords (caller holds the lock)
+func (l *LocalVectorMemory) evict() {
+	excess := len(l.records) - l.config.MaxRecords
+	if l.config.MaxRecords <= 0 || excess <= 0 {
+		return
+	}
+
+	for _, rec := range l.records[:excess] {
+		delete(l.byID, rec.ID)
+		if !rec.Archived {
+			l.remove(rec)
+		}
+	}
+	// Copy so the evicted records can be collected
+	l.records = append([]*localRecord(nil), l.records[excess:]...)
+	l.compact()
+}
+
+// compact rebuilds the vector index once removals outnumber the vectors it
+// still holds, so HNSW tombstones do not grow without bound (caller holds the lock)
+func (l *LocalVectorMemory) compact() {
+	if l.removed < localMinRebuild || l.removed <= l.index.Len() {
+		return
+	}
+
+	l.index = NewVectorIndex(l.config.Index, l.config.Metric, l.config.HNSW)
+	for _, rec := range l.records {
+		if !rec.Archived && len(rec.Embedding) > 0 {
+			l.index.Add(rec.ID, rec.Embedding)
+		}
+	}
+	l.removed = 0
+}
+
+// live returns the records that are not archived (caller holds the lock)
+func (l *LocalVectorMemory) live() []*localRecord {
+	live := make([]*localRecord, 0, len(l.records))
+	for _, rec := range l.records {
+		if !rec.Archived {
+			live = append(live, rec)
+		}
+	}
+	return live
+}
+
+// changed schedules an AutoSave write after SaveDelay, or writes at once if
+// the delay is negative. It returns the error of a failed background write.
+// (caller holds the lock)
+func (l *LocalVectorMemory) changed() error {
+	if !l.autoSave {
+		return nil
+	}
+	if l.config.SaveDelay < 0 {
+		return l.writeSnapshot(l.path)
+	}
+
+	l.dirty = true
+	if l.saveTimer == nil {
+		var timer *time.Timer
+		timer = time.AfterFunc(l.config.SaveDelay, func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			if l.saveTimer == timer { // Not already flushed by Save
+				l.saveErr = l.flush()
+			}
+		})
+		l.saveTimer = timer
+	}
+
+	err := l.saveErr
+	l.saveErr = nil
+	return err
+}
+
+// flush writes the snapshot and cancels any pending AutoSave write (caller holds the lock)
+func (l *LocalVectorMemory) flush() error {
+	if l.saveTimer != nil {
+		l.saveTimer.Stop()
+		l.saveTimer = nil
+	}
+	if err := l.writeSnapshot(l.path); err != nil {
+		return err
+	}
+	l.dirty = false
+	l.saveErr = nil
+	return nil
+}
+
+// writeSnapshot atomically writes all records to path (caller holds the lock)
+func (l *LocalVectorMemory) writeSnapshot(path string) error {
+	data, err := json.Marshal(localSnapshot{
+		Version:    localSnapshotVersion,
+		Metric:     l.config.Metric,
+		Dimensions: l.dims,
+		Records:    l.records,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to serialize memory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("failed to write memory: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write memory: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write memory: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write memory: %w", err)
+	}
+	return nil
+}
+
+// load reads a persistence file and rebuilds the index; a missing file is not an error
+func (l *LocalVectorMemory) load(path string) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read memory: %w", err)
+	}
+
+	var snapshot localSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return fmt.Errorf("failed to parse memory file %s: %w", path, err)
+	}
+	if snapshot.Version != localSnapshotVersion {
+		return fmt.Errorf("unsupported memory file version %d", snapshot.Version)
+	}
+
+	l.dims = snapshot.Dimensions
+	for _, rec := range snapshot.Records {
+		l.insert(rec)
+	}
+	return nil
+}
+
+// resultMessages strips scores from search results
+func resultMessages(results []SearchResult) []types.Message {
+	messages := make([]types.Message, len(results))
+	for i, r := range results {
+		messages[i] = r.Message
+	}
+	return messages
+}
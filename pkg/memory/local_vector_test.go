@@ -0,0 +1,342 @@
+package memory
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/taipm/go-llm-agent/pkg/types"
+)
+
+func newTestLocal(t *testing.T, config LocalVectorMemoryConfig) *LocalVectorMemory {
+	t.Helper()
+	mem, err := NewLocalVectorMemory(config)
+	if err != nil {
+		t.Fatalf("NewLocalVectorMemory failed: %v", err)
+	}
+	return mem
+}
+
+func contents(messages []types.Message) string {
+	var out []string
+	for _, msg := range messages {
+		out = append(out, msg.Content)
+	}
+	return strings.Join(out, ",")
+}
+
+func TestLocalVectorMemory_History(t *testing.T) {
+	mem := newTestLocal(t, LocalVectorMemoryConfig{})
+
+	mem.Add(types.Message{Role: types.RoleUser, Content: "one"})
+	mem.Add(types.Message{Role: types.RoleAssistant, Content: "two"})
+	mem.Add(types.Message{Role: types.RoleUser, Content: "three"})
+
+	if mem.Size() != 3 {
+		t.Errorf("Expected size 3, got %d", mem.Size())
+	}
+	all, _ := mem.GetHistory(0)
+	if contents(all) != "one,two,three" {
+		t.Errorf("Expected oldest first, got %q", contents(all))
+	}
+	recent, _ := mem.GetHistory(2)
+	if contents(recent) != "two,three" {
+		t.Errorf("Expected last 2 messages, got %q", contents(recent))
+	}
+
+	mem.Clear()
+	if mem.Size() != 0 {
+		t.Errorf("Expected empty memory after Clear, got %d", mem.Size())
+	}
+}
+
+func TestLocalVectorMemory_HistorySize(t *testing.T) {
+	mem := newTestLocal(t, LocalVectorMemoryConfig{HistorySize: 2})
+
+	for _, content := range []string{"one", "two", "three"} {
+		mem.Add(types.Message{Role: types.RoleUser, Content: content})
+	}
+
+	if history, _ := mem.GetHistory(0); contents(history) != "two,three" {
+		t.Errorf("Expected the 2 most recent messages, got %q", contents(history))
+	}
+	if history, _ := mem.GetHistory(3); contents(history) != "one,two,three" {
+		t.Errorf("Expected an explicit limit to override HistorySize, got %q", contents(history))
+	}
+	if mem.Size() != 3 {
+		t.Errorf("Expected all messages to be kept, got %d", mem.Size())
+	}
+}
+
+func TestLocalVectorMemory_MaxRecords(t *testing.T) {
+	ctx := context.Background()
+	mem := newTestLocal(t, LocalVectorMemoryConfig{Index: IndexHNSW, MaxRecords: 3})
+
+	for i, content := range []string{"one", "two", "three", "four", "five"} {
+		mem.AddWithEmbedding(ctx, types.Message{Role: types.RoleUser, Content: content}, []float32{1, float32(i)})
+	}
+
+	if history, _ := mem.GetHistory(0); contents(history) != "three,four,five" {
+		t.Errorf("Expected the oldest records to be evicted, got %q", contents(history))
+	}
+	if got := mem.index.Len(); got != 3 {
+		t.Errorf("Expected 3 indexed vectors, got %d", got)
+	}
+	if results := mem.SearchVector([]float32{1, 0}, 5, nil); len(results) != 3 {
+		t.Errorf("Expected evicted records to be excluded from search, got %q", contents(resultMessages(results)))
+	}
+	if hits, _ := mem.HybridSearch(ctx, "one", 5); len(hits) != 0 {
+		t.Errorf("Expected evicted records to be excluded from keyword search, got %q", contents(hits))
+	}
+
+	// Tombstones are dropped once removals outnumber live vectors
+	for i := 0; i < localMinRebuild+10; i++ {
+		mem.AddWithEmbedding(ctx, types.Message{Role: types.RoleUser, Content: "filler"}, []float32{1, float32(i)})
+	}
+	if nodes := len(mem.index.(*HNSWIndex).nodes); nodes > localMinRebuild {
+		t.Errorf("Expected the HNSW index to be rebuilt, got %d nodes", nodes)
+	}
+	if mem.Size() != 3 {
+		t.Errorf("Expected 3 records, got %d", mem.Size())
+	}
+}
+
+func TestLocalVectorMemory_AutoSaveDelay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "memory.json")
+	mem := newTestLocal(t, LocalVectorMemoryConfig{Path: path, AutoSave: true, SaveDelay: 50 * time.Millisecond})
+
+	for _, content := range []string{"one", "two", "three"} {
+		mem.Add(types.Message{Role: types.RoleUser, Content: content})
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("Expected the write to be delayed, got %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := os.Stat(path); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected AutoSave to write the file after SaveDelay")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	reopened := newTestLocal(t, LocalVectorMemoryConfig{Path: path})
+	if history, _ := reopened.GetHistory(0); contents(history) != "one,two,three" {
+		t.Errorf("Expected all changes in one write, got %q", contents(history))
+	}
+}
+
+func TestLocalVectorMemory_SemanticSearch(t *testing.T) {
+	for _, index := range []IndexType{IndexFlat, IndexHNSW} {
+		t.Run(string(index), func(t *testing.T) {
+			mem := newTestLocal(t, LocalVectorMemoryConfig{
+				Embedder: &keywordEmbedder{vocab: []string{"golang", "python", "weather"}},
+				Index:    index,
+			})
+			ctx := context.Background()
+
+			mem.Add(types.Message{Role: types.RoleUser, Content: "I love golang and golang tooling"})
+			mem.Add(types.Message{Role: types.RoleUser, Content: "python is nice", Metadata: map[string]interface{}{"category": types.CategoryFactual}})
+			mem.Add(types.Message{Role: types.RoleAssistant, Content: "the weather is sunny"})
+			mem.AddWithEmbedding(ctx, types.Message{Role: types.RoleUser, Content: "precomputed"}, []float32{0, 0, 1})
+
+			results, err := mem.Search(ctx, "weather", 2, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(results) != 2 || results[0].Score < 0.999 || results[1].Score < 0.999 {
+				t.Errorf("Expected two exact weather matches, got %+v", results)
+			}
+
+			filtered, _ := mem.Search(ctx, "weather", 5, Filter{"role": types.RoleUser})
+			if len(filtered) == 0 || filtered[0].Message.Content != "precomputed" {
+				t.Errorf("Expected role filter to keep the user weather message first, got %+v", filtered)
+			}
+			for _, r := range filtered {
+				if r.Message.Role != types.RoleUser {
+					t.Errorf("Expected only user messages, got %+v", r.Message)
+				}
+			}
+
+			byCategory, _ := mem.Search(ctx, "weather", 5, Filter{"category": "factual"})
+			if len(byCategory) != 1 || byCategory[0].Message.Content != "python is nice" {
+				t.Errorf("Expected metadata filter to match one message, got %+v", byCategory)
+			}
+
+			hybrid, _ := mem.HybridSearch(ctx, "nice", 3)
+			if !strings.Contains(contents(hybrid), "python is nice") {
+				t.Errorf("Expected keyword match in hybrid results, got %q", contents(hybrid))
+			}
+
+			if err := mem.AddWithEmbedding(ctx, types.Message{Content: "bad"}, []float32{1}); err == nil {
+				t.Error("Expected dimension mismatch error")
+			}
+		})
+	}
+}
+
+func TestLocalVectorMemory_NoEmbedder(t *testing.T) {
+	mem := newTestLocal(t, LocalVectorMemoryConfig{})
+	ctx := context.Background()
+	mem.Add(types.Message{Role: types.RoleUser, Content: "plain text"})
+
+	if _, err := mem.SearchSemantic(ctx, "plain", 1); err == nil {
+		t.Error("Expected semantic search to require an embedder")
+	}
+	hybrid, err := mem.HybridSearch(ctx, "plain", 1)
+	if err != nil || contents(hybrid) != "plain text" {
+		t.Errorf("Expected keyword-only hybrid search, got %q (%v)", contents(hybrid), err)
+	}
+}
+
+func TestLocalVectorMemory_CategoryImportanceAndStats(t *testing.T) {
+	mem := newTestLocal(t, LocalVectorMemoryConfig{})
+	ctx := context.Background()
+
+	mem.Add(types.Message{Role: types.RoleAssistant, Content: "step 1", Metadata: map[string]interface{}{"category": types.CategoryReasoning, "importance": 0.2}})
+	mem.Add(types.Message{Role: types.RoleAssistant, Content: "plan", Metadata: map[string]interface{}{"category": types.CategoryPlanning, "importance": 0.9}})
+	mem.Add(types.Message{Role: types.RoleAssistant, Content: "step 2", Metadata: map[string]interface{}{"category": types.CategoryReasoning, "importance": 0.5}})
+
+	reasoning, _ := mem.GetByCategory(ctx, types.CategoryReasoning, 10)
+	if contents(reasoning) != "step 2,step 1" {
+		t.Errorf("Expected reasoning messages most recent first, got %q", contents(reasoning))
+	}
+
+	important, _ := mem.GetMostImportant(ctx, 2)
+	if contents(important) != "plan,step 2" {
+		t.Errorf("Expected messages by importance, got %q", contents(important))
+	}
+
+	stats, _ := mem.GetStats(ctx)
+	if stats.TotalMessages != 3 || stats.TotalReActSteps != 2 || stats.TotalPlans != 1 || stats.VectorCount != 0 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+}
+
+func TestLocalVectorMemory_Persistence(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "memory.json")
+	embedder := &keywordEmbedder{vocab: []string{"golang", "python"}}
+
+	mem := newTestLocal(t, LocalVectorMemoryConfig{Embedder: embedder, Path: path, AutoSave: true})
+	call := types.ToolCall{ID: "c1", Type: "function", Function: types.FunctionCall{
+		Name: "run", Arguments: map[string]interface{}{"lang": "go"},
+	}}
+	mem.Add(types.Message{Role: types.RoleUser, Content: "golang rocks"})
+	mem.Add(types.Message{Role: types.RoleAssistant, ToolCalls: []types.ToolCall{call}})
+	mem.Add(types.Message{Role: types.RoleTool, ToolID: "c1", Content: "python output", Metadata: map[string]interface{}{"priority": types.PriorityCritical}})
+
+	// Close flushes the coalesced AutoSave write; reopen with an HNSW index
+	if err := mem.Close(); err != nil {
+		t.Fatal(err)
+	}
+	reopened := newTestLocal(t, LocalVectorMemoryConfig{Embedder: embedder, Path: path, Index: IndexHNSW})
+	history, _ := reopened.GetHistory(0)
+	if len(history) != 3 || history[1].ToolCalls[0].Function.Arguments["lang"] != "go" || history[2].ToolID != "c1" {
+		t.Fatalf("Expected messages to survive reopening, got %+v", history)
+	}
+
+	results, err := reopened.SearchSemantic(ctx, "python", 1)
+	if err != nil || contents(results) != "python output" {
+		t.Errorf("Expected index to be rebuilt on load, got %q (%v)", contents(results), err)
+	}
+
+	// Archive everything except the critical message, then check it persists
+	time.Sleep(5 * time.Millisecond)
+	if err := reopened.Archive(ctx, time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if err := reopened.Close(); err != nil {
+		t.Fatal(err)
+	}
+	archived := newTestLocal(t, LocalVectorMemoryConfig{Embedder: embedder, Path: path})
+	if history, _ := archived.GetHistory(0); contents(history) != "python output" {
+		t.Errorf("Expected only the critical message after archive, got %q", contents(history))
+	}
+	if results, _ := archived.SearchSemantic(ctx, "golang", 5); strings.Contains(contents(results), "golang") {
+		t.Errorf("Expected archived messages to be excluded from search, got %q", contents(results))
+	}
+
+	exportPath := filepath.Join(t.TempDir(), "export.json")
+	if err := archived.Export(ctx, exportPath); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	data, _ := os.ReadFile(exportPath)
+	if !strings.Contains(string(data), "golang rocks") {
+		t.Error("Expected archived messages in the export")
+	}
+	if err := archived.Export(ctx, exportPath); err == nil {
+		t.Error("Expected Export to refuse overwriting an existing file")
+	}
+}
+
+func TestLocalVectorMemory_WithConversation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "memory.json")
+	base := newTestLocal(t, LocalVectorMemoryConfig{Path: path, AutoSave: true, HistorySize: 5})
+
+	conv, err := base.WithConversation("a/b")
+	if err != nil {
+		t.Fatalf("WithConversation failed: %v", err)
+	}
+	conv.Add(types.Message{Role: types.RoleUser, Content: "hello"})
+
+	if base.Size() != 0 {
+		t.Errorf("Expected the base memory to stay empty, got %d", base.Size())
+	}
+	if conv.config.HistorySize != 5 {
+		t.Errorf("Expected the configuration to be kept, got %+v", conv.config)
+	}
+
+	// Written at once to its own file
+	reopened, _ := base.WithConversation("a/b")
+	if history, _ := reopened.GetHistory(0); contents(history) != "hello" {
+		t.Errorf("Expected the conversation to be reloaded, got %q", contents(history))
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(path), "memory.a%2Fb.json")); err != nil {
+		t.Errorf("Expected a sibling file for the conversation: %v", err)
+	}
+}
+
+func TestLocalVectorMemory_InvalidConfig(t *testing.T) {
+	if _, err := NewLocalVectorMemory(LocalVectorMemoryConfig{Metric: "euclid"}); err == nil {
+		t.Error("Expected error for unknown metric")
+	}
+
+	path := filepath.Join(t.TempDir(), "broken.json")
+	os.WriteFile(path, []byte("not json"), 0o644)
+	if _, err := NewLocalVectorMemory(LocalVectorMemoryConfig{Path: path}); err == nil {
+		t.Error("Expected error for corrupt persistence file")
+	}
+}
+
+func TestFilter_Matches(t *testing.T) {
+	msg := types.Message{Role: types.RoleUser, Metadata: map[string]interface{}{"category": types.CategoryFactual, "priority": 3.0}}
+	tests := []struct {
+		filter Filter
+		want   bool
+	}{
+		{Filter{}, true},
+		{Filter{"role": "user"}, true},
+		{Filter{"role": types.RoleAssistant}, false},
+		{Filter{"category": "factual", "priority": 3}, true},
+		{Filter{"missing": "x"}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.filter.Matches(msg); got != tt.want {
+			t.Errorf("Filter %v: expected %v, got %v", tt.filter, tt.want, got)
+		}
+	}
+	if !Filter(nil).Matches(msg) {
+		t.Error("Expected nil filter to match")
+	}
+}
+
+func TestLocalVectorMemory_ImplementsAdvancedMemory(t *testing.T) {
+	var _ types.AdvancedMemory = (*LocalVectorMemory)(nil)
+}
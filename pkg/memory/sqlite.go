@@ -0,0 +1,590 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/taipm/go-llm-agent/pkg/types"
+	_ "modernc.org/sqlite" // Pure-Go driver registered as "sqlite"
+)
+
+// SQLiteMemory implements AdvancedMemory on a SQLite database.
+// Messages survive restarts without a server; embeddings are stored alongside
+// messages and searched by brute force, which suits up to ~100k messages.
+//
+// The memory uses database/sql with the pure-Go modernc.org/sqlite driver,
+// which this package registers as "sqlite"; set DriverName to use another.
+type SQLiteMemory struct {
+	db             *sql.DB
+	path           string
+	conversationID string
+	embedder       Embedder
+	ownsDB         bool
+}
+
+// SQLiteMemoryConfig holds configuration for SQLiteMemory
+type SQLiteMemoryConfig struct {
+	Path           string   // Database file (default "agent_memory.db", ":memory:" for in-memory)
+	DriverName     string   // database/sql driver name (default "sqlite", registered by modernc.org/sqlite)
+	DB             *sql.DB  // Use an already opened database instead of Path/DriverName
+	ConversationID string   // Conversation used by Add/GetHistory/Clear/Size (default "default")
+	Embedder       Embedder // Optional: enables semantic search
+}
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS messages (
+	id              INTEGER PRIMARY KEY AUTOINCREMENT,
+	conversation_id TEXT    NOT NULL,
+	role            TEXT    NOT NULL,
+	content         TEXT    NOT NULL DEFAULT '',
+	tool_calls      TEXT,
+	tool_id         TEXT    NOT NULL DEFAULT '',
+	metadata        TEXT,
+	category        TEXT    NOT NULL DEFAULT '',
+	importance      REAL    NOT NULL DEFAULT 0,
+	priority        INTEGER NOT NULL DEFAULT 0,
+	created_at      INTEGER NOT NULL,
+	archived        INTEGER NOT NULL DEFAULT 0,
+	embedding       BLOB
+);
+CREATE INDEX IF NOT EXISTS idx_messages_conversation ON messages(conversation_id, archived, id);
+CREATE INDEX IF NOT EXISTS idx_messages_category ON messages(category, archived);
+CREATE INDEX IF NOT EXISTS idx_messages_importance ON messages(importance);
+CREATE INDEX IF NOT EXISTS idx_messages_created ON messages(created_at);
+`
+
+// messageColumns are the columns read by scanMessage
+const messageColumns = "role, content, tool_calls, tool_id, metadata"
+
+// NewSQLiteMemory opens (or creates) a SQLite memory
+func NewSQLiteMemory(ctx context.Context, config SQLiteMemoryConfig) (*SQLiteMemory, error) {
+	if config.Path == "" {
+		config.Path = "agent_memory.db"
+	}
+	if config.DriverName == "" {
+		config.DriverName = "sqlite"
+	}
+	if config.ConversationID == "" {
+		config.ConversationID = "default"
+	}
+
+	db := config.DB
+	ownsDB := false
+	if db == nil {
+		var err error
+		db, err = sql.Open(config.DriverName, config.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open SQLite database: %w", err)
+		}
+		// SQLite serializes writes; a single connection also keeps ":memory:" databases shared
+		db.SetMaxOpenConns(1)
+		ownsDB = true
+	}
+
+	if _, err := db.ExecContext(ctx, sqliteSchema); err != nil {
+		if ownsDB {
+			db.Close()
+		}
+		return nil, fmt.Errorf("failed to create schema: %w", err)
+	}
+
+	return &SQLiteMemory{
+		db:             db,
+		path:           config.Path,
+		conversationID: config.ConversationID,
+		embedder:       config.Embedder,
+		ownsDB:         ownsDB,
+	}, nil
+}
+
+// ConversationID returns the conversation this memory reads and writes
+func (s *SQLiteMemory) ConversationID() string {
+	return s.conversationID
+}
+
+// WithConversation returns a view of the same database scoped to another conversation
+func (s *SQLiteMemory) WithConversation(conversationID string) *SQLiteMemory {
+	view := *s
+	view.conversationID = conversationID
+	view.ownsDB = false
+	return &view
+}
+
+// Add implements types.Memory interface
+func (s *SQLiteMemory) Add(message types.Message) error {
+	return s.AddWithEmbedding(context.Background(), message, nil)
+}
+
+// AddWithEmbedding implements types.AdvancedMemory interface.
+// Without a pre-computed embedding one is generated if an embedder is set;
+// if that fails the message is stored without an embedding.
+func (s *SQLiteMemory) AddWithEmbedding(ctx context.Context, message types.Message, embedding []float32) error {
+	return s.insert(ctx, s.conversationID, message, embedding, time.Now(), false)
+}
+
+// insert stores a message, generating its embedding if needed
+func (s *SQLiteMemory) insert(ctx context.Context, conversationID string, message types.Message, embedding []float32, createdAt time.Time, archived bool) error {
+	if embedding == nil && s.embedder != nil && strings.TrimSpace(message.Content) != "" {
+		if emb, err := s.embedder.Embed(ctx, message.Content); err == nil {
+			embedding = emb
+		}
+	}
+
+	var toolCalls, metadata []byte
+	var err error
+	if len(message.ToolCalls) > 0 {
+		if toolCalls, err = json.Marshal(message.ToolCalls); err != nil {
+			return fmt.Errorf("failed to marshal tool calls: %w", err)
+		}
+	}
+	if len(message.Metadata) > 0 {
+		if metadata, err = json.Marshal(message.Metadata); err != nil {
+			return fmt.Errorf("failed to marshal metadata: %w", err)
+		}
+	}
+
+	category, importance, priority := classifyMetadata(message.Metadata)
+
+	_, err = s.db.ExecContext(ctx,
+		`INSERT INTO messages (conversation_id, role, content, tool_calls, tool_id, metadata, category, importance, priority, created_at, archived, embedding)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		conversationID, string(message.Role), message.Content, nullString(toolCalls), message.ToolID,
+		nullString(metadata), category, importance, priority, createdAt.UnixNano(), archived, encodeEmbedding(embedding),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert message: %w", err)
+	}
+
+	return nil
+}
+
+// GetHistory implements types.Memory interface
+func (s *SQLiteMemory) GetHistory(limit int) ([]types.Message, error) {
+	ctx := context.Background()
+
+	query := `SELECT ` + messageColumns + ` FROM messages
+		WHERE conversation_id = ? AND archived = 0 ORDER BY id DESC`
+	args := []interface{}{s.conversationID}
+	if limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, limit)
+	}
+
+	messages, err := s.queryMessages(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Oldest first
+	for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+		messages[i], messages[j] = messages[j], messages[i]
+	}
+	return messages, nil
+}
+
+// Clear implements types.Memory interface (current conversation only)
+func (s *SQLiteMemory) Clear() error {
+	_, err := s.db.Exec(`DELETE FROM messages WHERE conversation_id = ?`, s.conversationID)
+	if err != nil {
+		return fmt.Errorf("failed to clear messages: %w", err)
+	}
+	return nil
+}
+
+// Size implements types.Memory interface (current conversation only)
+func (s *SQLiteMemory) Size() int {
+	var count int
+	if err := s.db.QueryRow(`SELECT COUNT(*) FROM messages WHERE conversation_id = ? AND archived = 0`, s.conversationID).Scan(&count); err != nil {
+		return 0
+	}
+	return count
+}
+
+// SearchSemantic implements types.AdvancedMemory interface.
+// It searches all conversations by cosine similarity to the query.
+func (s *SQLiteMemory) SearchSemantic(ctx context.Context, query string, limit int) ([]types.Message, error) {
+	if s.embedder == nil {
+		return nil, fmt.Errorf("semantic search requires an embedder")
+	}
+
+	queryEmbedding, err := s.embedder.Embed(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate query embedding: %w", err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT `+messageColumns+`, embedding FROM messages
+		WHERE archived = 0 AND embedding IS NOT NULL`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search: %w", err)
+	}
+	defer rows.Close()
+
+	type scoredMessage struct {
+		msg   types.Message
+		score float64
+	}
+
+	var scored []scoredMessage
+	for rows.Next() {
+		var blob []byte
+		msg, err := scanMessage(rows, &blob)
+		if err != nil {
+			return nil, err
+		}
+		score := cosineSimilarity(queryEmbedding, decodeEmbedding(blob))
+		scored = append(scored, scoredMessage{msg: msg, score: score})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to search: %w", err)
+	}
+
+	sort.SliceStable(scored, func(i, j int) bool {
+		return scored[i].score > scored[j].score
+	})
+
+	messages := make([]types.Message, 0, limit)
+	for i := 0; i < len(scored) && i < limit; i++ {
+		messages = append(messages, scored[i].msg)
+	}
+	return messages, nil
+}
+
+// GetByCategory implements types.AdvancedMemory interface (most recent first)
+func (s *SQLiteMemory) GetByCategory(ctx context.Context, category types.MessageCategory, limit int) ([]types.Message, error) {
+	return s.queryMessages(ctx, `SELECT `+messageColumns+` FROM messages
+		WHERE category = ? AND archived = 0 ORDER BY id DESC LIMIT ?`, string(category), limit)
+}
+
+// GetMostImportant implements types.AdvancedMemory interface
+func (s *SQLiteMemory) GetMostImportant(ctx context.Context, limit int) ([]types.Message, error) {
+	return s.queryMessages(ctx, `SELECT `+messageColumns+` FROM messages
+		WHERE archived = 0 ORDER BY importance DESC, id DESC LIMIT ?`, limit)
+}
+
+// HybridSearch implements types.AdvancedMemory interface.
+// Semantic matches come first, followed by messages containing the query words.
+func (s *SQLiteMemory) HybridSearch(ctx context.Context, query string, limit int) ([]types.Message, error) {
+	var results []types.Message
+	if s.embedder != nil {
+		semantic, err := s.SearchSemantic(ctx, query, limit)
+		if err != nil {
+			return nil, err
+		}
+		results = semantic
+	}
+
+	keyword, err := s.searchKeyword(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	for _, msg := range results {
+		seen[string(msg.Role)+":"+msg.Content] = true
+	}
+	for _, msg := range keyword {
+		if len(results) >= limit {
+			break
+		}
+		if key := string(msg.Role) + ":" + msg.Content; !seen[key] {
+			seen[key] = true
+			results = append(results, msg)
+		}
+	}
+
+	return results, nil
+}
+
+// searchKeyword returns messages containing all query words (most recent first)
+func (s *SQLiteMemory) searchKeyword(ctx context.Context, query string, limit int) ([]types.Message, error) {
+	words := strings.Fields(strings.ToLower(query))
+	if len(words) == 0 {
+		return nil, nil
+	}
+
+	conditions := make([]string, len(words))
+	args := make([]interface{}, 0, len(words)+1)
+	for i, word := range words {
+		conditions[i] = `LOWER(content) LIKE ? ESCAPE '\'`
+		args = append(args, "%"+escapeLike(word)+"%")
+	}
+	args = append(args, limit)
+
+	return s.queryMessages(ctx, `SELECT `+messageColumns+` FROM messages
+		WHERE archived = 0 AND `+strings.Join(conditions, " AND ")+` ORDER BY id DESC LIMIT ?`, args...)
+}
+
+// GetStats implements types.AdvancedMemory interface
+func (s *SQLiteMemory) GetStats(ctx context.Context) (*types.MemoryStats, error) {
+	stats := &types.MemoryStats{}
+
+	var oldest, newest sql.NullInt64
+	err := s.db.QueryRowContext(ctx, `SELECT
+			COUNT(*),
+			COUNT(embedding),
+			SUM(CASE WHEN category = ? THEN 1 ELSE 0 END),
+			SUM(CASE WHEN category = ? THEN 1 ELSE 0 END),
+			SUM(CASE WHEN category = ? THEN 1 ELSE 0 END),
+			MIN(created_at),
+			MAX(created_at)
+		FROM messages WHERE archived = 0`,
+		string(types.CategoryReasoning), string(types.CategoryPlanning), string(types.CategoryReflection),
+	).Scan(&stats.TotalMessages, &stats.VectorCount, nullInt(&stats.TotalReActSteps), nullInt(&stats.TotalPlans),
+		nullInt(&stats.TotalReflections), &oldest, &newest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stats: %w", err)
+	}
+
+	if oldest.Valid {
+		stats.OldestMessage = time.Unix(0, oldest.Int64)
+	}
+	if newest.Valid {
+		stats.NewestMessage = time.Unix(0, newest.Int64)
+	}
+	if info, err := os.Stat(s.path); err == nil {
+		stats.DatabaseSize = info.Size()
+	}
+
+	return stats, nil
+}
+
+// Archive implements types.AdvancedMemory interface.
+// Archived messages are kept in the database (and in Export) but excluded
+// from history and search. Messages with PriorityCritical are never archived.
+func (s *SQLiteMemory) Archive(ctx context.Context, olderThan time.Duration) error {
+	cutoff := time.Now().Add(-olderThan).UnixNano()
+	_, err := s.db.ExecContext(ctx, `UPDATE messages SET archived = 1
+		WHERE archived = 0 AND created_at < ? AND priority < ?`, cutoff, int(types.PriorityCritical))
+	if err != nil {
+		return fmt.Errorf("failed to archive messages: %w", err)
+	}
+	return nil
+}
+
+// Export implements types.AdvancedMemory interface by writing the messages of
+// every conversation, including archived ones and embeddings, to path in the
+// portable JSONL format (see ExportFile). Use Backup for a database copy.
+func (s *SQLiteMemory) Export(ctx context.Context, path string) error {
+	_, err := ExportFile(ctx, s, path, ExportOptions{IncludeEmbeddings: true, IncludeArchived: true})
+	return err
+}
+
+// Backup writes a consistent copy of the database to path
+func (s *SQLiteMemory) Backup(ctx context.Context, path string) error {
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("backup target already exists: %s", path)
+	}
+	if _, err := s.db.ExecContext(ctx, `VACUUM INTO ?`, path); err != nil {
+		return fmt.Errorf("failed to back up database: %w", err)
+	}
+	return nil
+}
+
+// Records implements RecordSource with the messages of every conversation
+func (s *SQLiteMemory) Records(ctx context.Context) ([]Record, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+messageColumns+`, conversation_id, created_at, archived, embedding
+		FROM messages ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query messages: %w", err)
+	}
+	defer rows.Close()
+
+	records := make([]Record, 0)
+	for rows.Next() {
+		var conversationID string
+		var createdAt int64
+		var archived bool
+		var blob []byte
+		msg, err := scanMessage(rows, &conversationID, &createdAt, &archived, &blob)
+		if err != nil {
+			return nil, err
+		}
+
+		rec := NewRecord(msg)
+		created := time.Unix(0, createdAt)
+		rec.ConversationID = conversationID
+		rec.Timestamp = &created
+		rec.Archived = archived
+		if blob != nil {
+			rec.Embedding = decodeEmbedding(blob)
+		}
+		records = append(records, rec)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read messages: %w", err)
+	}
+	return records, nil
+}
+
+// ImportRecord implements RecordSink. Records keep their conversation (the
+// current one when unset), timestamp and archived state.
+func (s *SQLiteMemory) ImportRecord(ctx context.Context, record Record) error {
+	conversationID := record.ConversationID
+	if conversationID == "" {
+		conversationID = s.conversationID
+	}
+	created := time.Now()
+	if record.Timestamp != nil {
+		created = *record.Timestamp
+	}
+	return s.insert(ctx, conversationID, record.Message(), record.Embedding, created, record.Archived)
+}
+
+// Close closes the database if it was opened by NewSQLiteMemory
+func (s *SQLiteMemory) Close() error {
+	if s.ownsDB && s.db != nil {
+		return s.db.Close()
+	}
+	return nil
+}
+
+// queryMessages runs a query selecting messageColumns
+func (s *SQLiteMemory) queryMessages(ctx context.Context, query string, args ...interface{}) ([]types.Message, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query messages: %w", err)
+	}
+	defer rows.Close()
+
+	messages := make([]types.Message, 0)
+	for rows.Next() {
+		msg, err := scanMessage(rows)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, msg)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read messages: %w", err)
+	}
+	return messages, nil
+}
+
+// scanMessage reads messageColumns (plus any extra destinations) from a row
+func scanMessage(rows *sql.Rows, extra ...interface{}) (types.Message, error) {
+	var msg types.Message
+	var role string
+	var toolCalls, metadata sql.NullString
+
+	dest := append([]interface{}{&role, &msg.Content, &toolCalls, &msg.ToolID, &metadata}, extra...)
+	if err := rows.Scan(dest...); err != nil {
+		return msg, fmt.Errorf("failed to scan message: %w", err)
+	}
+
+	msg.Role = types.Role(role)
+	if toolCalls.Valid {
+		if err := json.Unmarshal([]byte(toolCalls.String), &msg.ToolCalls); err != nil {
+			return msg, fmt.Errorf("failed to unmarshal tool calls: %w", err)
+		}
+	}
+	if metadata.Valid {
+		if err := json.Unmarshal([]byte(metadata.String), &msg.Metadata); err != nil {
+			return msg, fmt.Errorf("failed to unmarshal metadata: %w", err)
+		}
+	}
+	return msg, nil
+}
+
+// classifyMetadata extracts the indexed category, importance and priority
+func classifyMetadata(metadata map[string]interface{}) (string, float64, int) {
+	category := ""
+	if c, ok := metadata["category"]; ok && c != nil {
+		category = fmt.Sprint(c)
+	}
+
+	importance := 0.0
+	switch v := metadata["importance"].(type) {
+	case float64:
+		importance = v
+	case float32:
+		importance = float64(v)
+	case int:
+		importance = float64(v)
+	}
+
+	priority := 0
+	switch v := metadata["priority"].(type) {
+	case types.MemoryPriority:
+		priority = int(v)
+	case int:
+		priority = v
+	case float64:
+		priority = int(v)
+	}
+
+	return category, importance, priority
+}
+
+// encodeEmbedding stores a vector as little-endian float32s
+func encodeEmbedding(embedding []float32) []byte {
+	if embedding == nil {
+		return nil
+	}
+	buf := make([]byte, 4*len(embedding))
+	for i, v := range embedding {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(v))
+	}
+	return buf
+}
+
+func decodeEmbedding(buf []byte) []float32 {
+	embedding := make([]float32, len(buf)/4)
+	for i := range embedding {
+		embedding[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[4*i:]))
+	}
+	return embedding
+}
+
+// cosineSimilarity returns the cosine of the angle between a and b (0 if undefined)
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+func nullString(b []byte) interface{} {
+	if b == nil {
+		return nil
+	}
+	return string(b)
+}
+
+// nullIntScanner scans a nullable integer (SUM over no rows) into an int
+type nullIntScanner struct{ dest *int }
+
+func (n nullIntScanner) Scan(src interface{}) error {
+	var v sql.NullInt64
+	if err := v.Scan(src); err != nil {
+		return err
+	}
+	*n.dest = int(v.Int64)
+	return nil
+}
+
+func nullInt(dest *int) sql.Scanner {
+	return nullIntScanner{dest: dest}
+}
+
+func escapeLike(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, "%", `\%`)
+	return strings.ReplaceAll(s, "_", `\_`)
+}
@@ -0,0 +1,282 @@
+package memory
+
+import (
+	"context"
+	"math"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/taipm/go-llm-agent/pkg/types"
+)
+
+// keywordEmbedder embeds text as counts of a fixed vocabulary
+type keywordEmbedder struct{ vocab []string }
+
+func (e *keywordEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	vec := make([]float32, len(e.vocab))
+	lower := strings.ToLower(text)
+	for i, word := range e.vocab {
+		vec[i] = float32(strings.Count(lower, word))
+	}
+	return vec, nil
+}
+
+func (e *keywordEmbedder) Dimensions() int { return len(e.vocab) }
+
+// newTestSQLite opens a SQLite memory in a temp dir
+func newTestSQLite(t *testing.T, embedder Embedder) *SQLiteMemory {
+	t.Helper()
+
+	mem, err := NewSQLiteMemory(context.Background(), SQLiteMemoryConfig{
+		Path:     filepath.Join(t.TempDir(), "memory.db"),
+		Embedder: embedder,
+	})
+	if err != nil {
+		t.Fatalf("NewSQLiteMemory failed: %v", err)
+	}
+	t.Cleanup(func() { mem.Close() })
+	return mem
+}
+
+func TestEmbeddingEncoding(t *testing.T) {
+	in := []float32{0, 1.5, -2.25, float32(math.Pi)}
+	out := decodeEmbedding(encodeEmbedding(in))
+	if !slices.Equal(in, out) {
+		t.Errorf("Expected %v, got %v", in, out)
+	}
+	if encodeEmbedding(nil) != nil {
+		t.Error("Expected nil embedding to encode as NULL")
+	}
+}
+
+func TestCosineSimilarity(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b []float32
+		want float64
+	}{
+		{"identical", []float32{1, 2}, []float32{1, 2}, 1},
+		{"orthogonal", []float32{1, 0}, []float32{0, 1}, 0},
+		{"opposite", []float32{1, 0}, []float32{-1, 0}, -1},
+		{"length mismatch", []float32{1}, []float32{1, 0}, 0},
+		{"zero vector", []float32{0, 0}, []float32{1, 0}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cosineSimilarity(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestClassifyMetadata(t *testing.T) {
+	category, importance, priority := classifyMetadata(map[string]interface{}{
+		"category":   types.CategoryReasoning,
+		"importance": 0.8,
+		"priority":   types.PriorityCritical,
+	})
+	if category != "reasoning" || importance != 0.8 || priority != int(types.PriorityCritical) {
+		t.Errorf("Unexpected classification: %q %v %d", category, importance, priority)
+	}
+
+	// Values decoded from JSON
+	_, _, priority = classifyMetadata(map[string]interface{}{"priority": float64(3)})
+	if priority != 3 {
+		t.Errorf("Expected priority 3, got %d", priority)
+	}
+}
+
+func TestSQLiteMemory_History(t *testing.T) {
+	mem := newTestSQLite(t, nil)
+
+	call := types.ToolCall{ID: "c1", Type: "function", Function: types.FunctionCall{
+		Name: "weather", Arguments: map[string]interface{}{"city": "Hanoi"},
+	}}
+	mem.Add(types.Message{Role: types.RoleUser, Content: "weather?"})
+	mem.Add(types.Message{Role: types.RoleAssistant, ToolCalls: []types.ToolCall{call}})
+	mem.Add(types.Message{Role: types.RoleTool, ToolID: "c1", Content: "30C", Metadata: map[string]interface{}{"source": "api"}})
+
+	if mem.Size() != 3 {
+		t.Errorf("Expected size 3, got %d", mem.Size())
+	}
+
+	history, err := mem.GetHistory(0)
+	if err != nil {
+		t.Fatalf("GetHistory failed: %v", err)
+	}
+	if len(history) != 3 || history[0].Content != "weather?" {
+		t.Fatalf("Expected 3 messages oldest first, got %+v", history)
+	}
+	if got := history[1].ToolCalls; len(got) != 1 || got[0].Function.Arguments["city"] != "Hanoi" {
+		t.Errorf("Expected tool call round trip, got %+v", got)
+	}
+	if history[2].ToolID != "c1" || history[2].Metadata["source"] != "api" {
+		t.Errorf("Expected tool id and metadata round trip, got %+v", history[2])
+	}
+
+	recent, _ := mem.GetHistory(2)
+	if len(recent) != 2 || recent[1].Content != "30C" {
+		t.Errorf("Expected last 2 messages, got %+v", recent)
+	}
+}
+
+func TestSQLiteMemory_Conversations(t *testing.T) {
+	mem := newTestSQLite(t, nil)
+	other := mem.WithConversation("other")
+
+	mem.Add(types.Message{Role: types.RoleUser, Content: "first"})
+	other.Add(types.Message{Role: types.RoleUser, Content: "second"})
+
+	if mem.Size() != 1 || other.Size() != 1 {
+		t.Errorf("Expected conversations to be isolated, got %d / %d", mem.Size(), other.Size())
+	}
+
+	other.Clear()
+	if mem.Size() != 1 || other.Size() != 0 {
+		t.Errorf("Expected Clear to only affect its conversation, got %d / %d", mem.Size(), other.Size())
+	}
+}
+
+func TestSQLiteMemory_Persistence(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "memory.db")
+
+	mem, err := NewSQLiteMemory(ctx, SQLiteMemoryConfig{Path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mem.Add(types.Message{Role: types.RoleUser, Content: "remember me"})
+	mem.Close()
+
+	reopened, err := NewSQLiteMemory(ctx, SQLiteMemoryConfig{Path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+
+	history, _ := reopened.GetHistory(0)
+	if len(history) != 1 || history[0].Content != "remember me" {
+		t.Errorf("Expected message to survive reopening, got %+v", history)
+	}
+}
+
+func TestSQLiteMemory_CategoryAndImportance(t *testing.T) {
+	mem := newTestSQLite(t, nil)
+	ctx := context.Background()
+
+	mem.Add(types.Message{Role: types.RoleAssistant, Content: "step 1", Metadata: map[string]interface{}{"category": types.CategoryReasoning, "importance": 0.2}})
+	mem.Add(types.Message{Role: types.RoleAssistant, Content: "plan", Metadata: map[string]interface{}{"category": types.CategoryPlanning, "importance": 0.9}})
+	mem.Add(types.Message{Role: types.RoleAssistant, Content: "step 2", Metadata: map[string]interface{}{"category": types.CategoryReasoning, "importance": 0.5}})
+
+	reasoning, err := mem.GetByCategory(ctx, types.CategoryReasoning, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reasoning) != 2 || reasoning[0].Content != "step 2" {
+		t.Errorf("Expected 2 reasoning messages, most recent first, got %+v", reasoning)
+	}
+
+	important, _ := mem.GetMostImportant(ctx, 2)
+	if len(important) != 2 || important[0].Content != "plan" || important[1].Content != "step 2" {
+		t.Errorf("Expected messages by importance, got %+v", important)
+	}
+
+	stats, err := mem.GetStats(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.TotalMessages != 3 || stats.TotalReActSteps != 2 || stats.TotalPlans != 1 || stats.DatabaseSize == 0 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+}
+
+func TestSQLiteMemory_SemanticAndHybridSearch(t *testing.T) {
+	mem := newTestSQLite(t, &keywordEmbedder{vocab: []string{"golang", "python", "weather"}})
+	ctx := context.Background()
+
+	mem.Add(types.Message{Role: types.RoleUser, Content: "I love golang and golang tooling"})
+	mem.Add(types.Message{Role: types.RoleUser, Content: "python is nice"})
+	mem.Add(types.Message{Role: types.RoleUser, Content: "the weather is sunny"})
+	mem.AddWithEmbedding(ctx, types.Message{Role: types.RoleUser, Content: "precomputed"}, []float32{0, 0, 1})
+
+	results, err := mem.SearchSemantic(ctx, "weather", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var contents []string
+	for _, msg := range results {
+		contents = append(contents, msg.Content)
+	}
+	slices.Sort(contents)
+	if strings.Join(contents, ",") != "precomputed,the weather is sunny" {
+		t.Errorf("Expected the two weather messages, got %v", contents)
+	}
+
+	hybrid, err := mem.HybridSearch(ctx, "nice", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, msg := range hybrid {
+		found = found || msg.Content == "python is nice"
+	}
+	if !found || len(hybrid) > 3 {
+		t.Errorf("Expected keyword match in hybrid results, got %+v", hybrid)
+	}
+}
+
+func TestSQLiteMemory_ArchiveAndExport(t *testing.T) {
+	mem := newTestSQLite(t, nil)
+	ctx := context.Background()
+
+	mem.Add(types.Message{Role: types.RoleUser, Content: "old"})
+	mem.Add(types.Message{Role: types.RoleUser, Content: "keep", Metadata: map[string]interface{}{"priority": types.PriorityCritical}})
+	time.Sleep(5 * time.Millisecond)
+
+	if err := mem.Archive(ctx, time.Millisecond); err != nil {
+		t.Fatalf("Archive failed: %v", err)
+	}
+	history, _ := mem.GetHistory(0)
+	if len(history) != 1 || history[0].Content != "keep" {
+		t.Errorf("Expected only the critical message to remain, got %+v", history)
+	}
+
+	exportPath := filepath.Join(t.TempDir(), "export.jsonl")
+	if err := mem.Export(ctx, exportPath); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	imported := NewBuffer(10)
+	if n, err := ImportFile(ctx, imported, exportPath, ImportOptions{}); err != nil || n != 2 {
+		t.Errorf("Expected archived messages in the JSONL export, imported %d (%v)", n, err)
+	}
+	if err := mem.Export(ctx, exportPath); err == nil {
+		t.Error("Expected Export to refuse overwriting an existing file")
+	}
+
+	path := filepath.Join(t.TempDir(), "backup.db")
+	if err := mem.Backup(ctx, path); err != nil {
+		t.Fatalf("Backup failed: %v", err)
+	}
+	backup, err := NewSQLiteMemory(ctx, SQLiteMemoryConfig{Path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer backup.Close()
+
+	var total int
+	backup.db.QueryRow(`SELECT COUNT(*) FROM messages`).Scan(&total)
+	if total != 2 {
+		t.Errorf("Expected archived messages in the backup, got %d rows", total)
+	}
+	if err := mem.Backup(ctx, path); err == nil {
+		t.Error("Expected Backup to refuse overwriting an existing file")
+	}
+}
+
+func TestSQLiteMemory_ImplementsAdvancedMemory(t *testing.T) {
+	var _ types.AdvancedMemory = (*SQLiteMemory)(nil)
+}
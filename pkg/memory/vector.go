@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -20,6 +21,18 @@ type VectorMemory struct {
 	embedder       Embedder
 	cache          *BufferMemory // Hot cache for recent messages
 	dims           int
+
+	// In-process BM25 index over stored points for HybridSearch. Existing
+	// points are indexed in the background; messages are read from Qdrant.
+	mu             sync.RWMutex
+	hybrid         HybridConfig
+	keywords       *BM25Index
+	timestamps     map[string]int64 // Point ID -> Unix seconds, matching the point payload
+	generation     int              // Bumped by Clear to discard a running index build
+	archivedBefore int64            // Points older than this are not indexed
+	stopLoad       context.CancelFunc
+	loaded         chan struct{} // Closed when the background index build ends
+	loadErr        error
 }
 
 // VectorMemoryConfig holds configuration for VectorMemory
@@ -28,6 +41,7 @@ type VectorMemoryConfig struct {
 	CollectionName string
 	Embedder       Embedder
 	CacheSize      int
+	Hybrid         HybridConfig // Keyword/vector fusion used by HybridSearch
 }
 
 // NewVectorMemory creates a new vector memory with Qdrant
@@ -54,12 +68,21 @@ func NewVectorMemory(ctx context.Context, config VectorMemoryConfig) (*VectorMem
 		return nil, fmt.Errorf("failed to connect to Qdrant: %w", err)
 	}
 
+	// Unknown models report their size after the first embedding
+	dims, err := DetectDimensions(ctx, config.Embedder)
+	if err != nil {
+		return nil, err
+	}
+
 	vm := &VectorMemory{
 		client:         client,
 		collectionName: config.CollectionName,
 		embedder:       config.Embedder,
 		cache:          NewBuffer(config.CacheSize),
-		dims:           config.Embedder.Dimensions(),
+		dims:           dims,
+		hybrid:         config.Hybrid,
+		keywords:       NewBM25Index(config.Hybrid.BM25),
+		timestamps:     make(map[string]int64),
 	}
 
 	// Create collection if it doesn't exist
@@ -67,6 +90,10 @@ func NewVectorMemory(ctx context.Context, config VectorMemoryConfig) (*VectorMem
 		return nil, fmt.Errorf("failed to setup collection: %w", err)
 	}
 
+	// Rebuild the keyword index from existing points without blocking: large
+	// collections take longer than callers wait for the constructor
+	vm.startKeywordIndex()
+
 	return vm, nil
 }
 
@@ -106,6 +133,11 @@ func (v *VectorMemory) Add(message types.Message) error {
 
 // AddWithEmbedding implements types.AdvancedMemory interface
 func (v *VectorMemory) AddWithEmbedding(ctx context.Context, message types.Message, embedding []float32) error {
+	return v.addAt(ctx, message, embedding, time.Now().Unix())
+}
+
+// addAt caches a message and stores it in Qdrant with the given timestamp
+func (v *VectorMemory) addAt(ctx context.Context, message types.Message, embedding []float32, timestamp int64) error {
 	// Add to hot cache
 	v.cache.Add(message)
 
@@ -128,7 +160,7 @@ func (v *VectorMemory) AddWithEmbedding(ctx context.Context, message types.Messa
 	payload := map[string]interface{}{
 		"role":       string(message.Role),
 		"content":    message.Content,
-		"timestamp":  time.Now().Unix(),
+		"timestamp":  timestamp,
 		"tool_calls": message.ToolCalls,
 		"tool_id":    message.ToolID,
 	}
@@ -170,6 +202,10 @@ func (v *VectorMemory) AddWithEmbedding(ctx context.Context, message types.Messa
 		return fmt.Errorf("failed to upsert point: %w", err)
 	}
 
+	v.mu.Lock()
+	v.indexKeywordsLocked(pointID, message.Content, timestamp)
+	v.mu.Unlock()
+
 	return nil
 }
 
@@ -222,8 +258,13 @@ func (v *VectorMemory) GetHistoryWithContext(ctx context.Context, query string,
 func (v *VectorMemory) Clear() error {
 	ctx := context.Background()
 
-	// Clear cache
+	// Clear cache and keyword index
 	v.cache.Clear()
+	v.mu.Lock()
+	v.keywords = NewBM25Index(v.hybrid.BM25)
+	v.timestamps = make(map[string]int64)
+	v.generation++
+	v.mu.Unlock()
 
 	// Delete and recreate collection
 	err := v.client.DeleteCollection(ctx, v.collectionName)
@@ -412,10 +453,86 @@ func (v *VectorMemory) GetMostImportant(ctx context.Context, limit int) ([]types
 	return messages, nil
 }
 
-// HybridSearch implements types.AdvancedMemory interface
+// HybridSearch implements types.AdvancedMemory interface by fusing BM25
+// keyword matches with vector matches (see HybridSearchWithScores)
 func (v *VectorMemory) HybridSearch(ctx context.Context, query string, limit int) ([]types.Message, error) {
-	// For now, use semantic search (can be enhanced with BM25 later)
-	return v.SearchSemantic(ctx, query, limit)
+	results, err := v.HybridSearchWithScores(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	return hybridMessages(results), nil
+}
+
+// HybridSearchWithScores ranks messages by weighted reciprocal rank fusion of
+// vector similarity and BM25 keyword relevance. Keyword matching catches exact
+// identifiers (error codes, hostnames, tool names) that embeddings miss. If the
+// query cannot be embedded, results are ranked by keywords alone. Until the
+// keyword index of existing points is built (see WaitKeywordIndex), keyword
+// matches only cover the points indexed so far.
+func (v *VectorMemory) HybridSearchWithScores(ctx context.Context, query string, limit int) ([]HybridResult, error) {
+	candidates := v.hybrid.candidates(limit)
+	messages := make(map[string]types.Message)
+
+	var vectorHits []IndexHit
+	if queryEmbedding, err := v.embedder.Embed(ctx, query); err == nil {
+		points, err := v.client.Query(ctx, &qdrant.QueryPoints{
+			CollectionName: v.collectionName,
+			Query:          qdrant.NewQuery(queryEmbedding...),
+			Limit:          qdrant.PtrOf(uint64(candidates)),
+			WithPayload:    qdrant.NewWithPayload(true),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to search: %w", err)
+		}
+		for _, point := range points {
+			if point.Score <= 0 {
+				continue
+			}
+			msg, err := v.pointToMessage(point)
+			if err != nil {
+				continue
+			}
+			id := point.GetId().GetUuid()
+			messages[id] = msg
+			vectorHits = append(vectorHits, IndexHit{ID: id, Score: float64(point.Score)})
+		}
+	}
+
+	v.mu.RLock()
+	keywordHits := v.keywords.Search(query, candidates, nil)
+	v.mu.RUnlock()
+
+	// Keyword-only hits are read from Qdrant
+	var missing []*qdrant.PointId
+	for _, hit := range keywordHits {
+		if _, ok := messages[hit.ID]; !ok {
+			missing = append(missing, qdrant.NewID(hit.ID))
+		}
+	}
+	if len(missing) > 0 {
+		points, err := v.client.Get(ctx, &qdrant.GetPoints{
+			CollectionName: v.collectionName,
+			Ids:            missing,
+			WithPayload:    qdrant.NewWithPayload(true),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get keyword matches: %w", err)
+		}
+		for _, point := range points {
+			if msg, err := v.retrievedPointToMessage(point); err == nil {
+				messages[point.GetId().GetUuid()] = msg
+			}
+		}
+		found := keywordHits[:0]
+		for _, hit := range keywordHits {
+			if _, ok := messages[hit.ID]; ok {
+				found = append(found, hit)
+			}
+		}
+		keywordHits = found
+	}
+
+	return buildHybridResults(vectorHits, keywordHits, messages, v.hybrid, limit), nil
 }
 
 // GetStats implements types.AdvancedMemory interface
@@ -456,24 +573,192 @@ func (v *VectorMemory) Archive(ctx context.Context, olderThan time.Duration) err
 			},
 		},
 	})
+	if err != nil {
+		return err
+	}
 
-	return err
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.archivedBefore = max(v.archivedBefore, cutoff)
+	for id, timestamp := range v.timestamps {
+		if timestamp < cutoff {
+			v.keywords.Remove(id)
+			delete(v.timestamps, id)
+		}
+	}
+
+	return nil
 }
 
-// Export implements types.AdvancedMemory interface
+// Export implements types.AdvancedMemory interface by writing every point,
+// with its embedding, to path in the portable JSONL format (see ExportFile)
 func (v *VectorMemory) Export(ctx context.Context, path string) error {
-	// Create snapshot
-	snapshotResult, err := v.client.CreateSnapshot(ctx, v.collectionName)
-	if err != nil {
-		return fmt.Errorf("failed to create snapshot: %w", err)
+	_, err := ExportFile(ctx, v, path, ExportOptions{IncludeEmbeddings: true})
+	return err
+}
+
+// Records implements RecordSource by scrolling through the collection
+func (v *VectorMemory) Records(ctx context.Context) ([]Record, error) {
+	var records []Record
+	var offset *qdrant.PointId
+	for {
+		points, next, err := v.client.ScrollAndOffset(ctx, &qdrant.ScrollPoints{
+			CollectionName: v.collectionName,
+			Offset:         offset,
+			Limit:          qdrant.PtrOf(uint32(256)),
+			WithPayload:    qdrant.NewWithPayload(true),
+			WithVectors:    qdrant.NewWithVectors(true),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to scroll points: %w", err)
+		}
+
+		for _, point := range points {
+			msg, err := v.retrievedPointToMessage(point)
+			if err != nil {
+				continue
+			}
+			records = append(records, pointRecord(msg, point.GetVectors().GetVector()))
+		}
+
+		if next == nil {
+			break
+		}
+		offset = next
 	}
 
-	// Snapshot name is returned
-	_ = snapshotResult.GetName()
+	// Oldest first
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Timestamp.Before(*records[j].Timestamp)
+	})
+	return records, nil
+}
 
-	// Note: Actual file download would require additional Qdrant API calls
-	// This is a simplified version
-	return nil
+// ImportRecord implements RecordSink. The record keeps its timestamp;
+// archived records are skipped because Archive deletes points from Qdrant.
+func (v *VectorMemory) ImportRecord(ctx context.Context, record Record) error {
+	if record.Archived {
+		return nil
+	}
+	timestamp := time.Now().Unix()
+	if record.Timestamp != nil {
+		timestamp = record.Timestamp.Unix()
+	}
+	return v.addAt(ctx, record.Message(), record.Embedding, timestamp)
+}
+
+// pointRecord turns a stored point back into a record, moving the payload
+// bookkeeping fields out of the metadata
+func pointRecord(msg types.Message, vector *qdrant.VectorOutput) Record {
+	ts := time.Unix(0, 0)
+	switch t := msg.Metadata["timestamp"].(type) {
+	case int64:
+		ts = time.Unix(t, 0)
+	case float64:
+		ts = time.Unix(int64(t), 0)
+	}
+	if id, ok := msg.Metadata["tool_id"].(string); ok {
+		msg.ToolID = id
+	}
+	for _, key := range []string{"timestamp", "tool_id", "tool_calls"} {
+		delete(msg.Metadata, key)
+	}
+	if len(msg.Metadata) == 0 {
+		msg.Metadata = nil
+	}
+
+	rec := NewRecord(msg)
+	rec.Timestamp = &ts
+	rec.Embedding = vector.GetDense().GetData()
+	if len(rec.Embedding) == 0 {
+		rec.Embedding = vector.GetData()
+	}
+	return rec
+}
+
+// indexKeywordsLocked adds a stored point to the keyword index
+func (v *VectorMemory) indexKeywordsLocked(id, content string, timestamp int64) {
+	if timestamp < v.archivedBefore {
+		return
+	}
+	v.keywords.Add(id, content)
+	v.timestamps[id] = timestamp
+}
+
+// startKeywordIndex indexes the existing points in the background
+func (v *VectorMemory) startKeywordIndex() {
+	ctx, cancel := context.WithCancel(context.Background())
+	v.stopLoad = cancel
+	v.loaded = make(chan struct{})
+
+	v.mu.RLock()
+	generation := v.generation
+	v.mu.RUnlock()
+
+	go func() {
+		defer close(v.loaded)
+		if err := v.loadKeywordIndex(ctx, generation); err != nil {
+			v.mu.Lock()
+			v.loadErr = fmt.Errorf("failed to build keyword index: %w", err)
+			v.mu.Unlock()
+		}
+	}()
+}
+
+// WaitKeywordIndex blocks until the keyword index covers the points that
+// existed when the memory was created, and returns the error of its build
+func (v *VectorMemory) WaitKeywordIndex(ctx context.Context) error {
+	select {
+	case <-v.loaded:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return v.loadErr
+}
+
+// loadKeywordIndex scrolls through the collection and indexes every point;
+// it stops once Clear started a new generation of the index
+func (v *VectorMemory) loadKeywordIndex(ctx context.Context, generation int) error {
+	var offset *qdrant.PointId
+	for {
+		points, next, err := v.client.ScrollAndOffset(ctx, &qdrant.ScrollPoints{
+			CollectionName: v.collectionName,
+			Offset:         offset,
+			Limit:          qdrant.PtrOf(uint32(256)),
+			WithPayload:    qdrant.NewWithPayload(true),
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, point := range points {
+			msg, err := v.retrievedPointToMessage(point)
+			if err != nil {
+				continue
+			}
+			timestamp := int64(0)
+			switch ts := msg.Metadata["timestamp"].(type) {
+			case int64:
+				timestamp = ts
+			case float64:
+				timestamp = int64(ts)
+			}
+			v.mu.Lock()
+			if v.generation != generation {
+				v.mu.Unlock()
+				return nil
+			}
+			v.indexKeywordsLocked(point.GetId().GetUuid(), msg.Content, timestamp)
+			v.mu.Unlock()
+		}
+
+		if next == nil {
+			return nil
+		}
+		offset = next
+	}
 }
 
 // pointToMessage converts Qdrant point to Message
@@ -560,8 +845,12 @@ func extractValue(val *qdrant.Value) interface{} {
 	}
 }
 
-// Close closes the Qdrant client connection
+// Close stops the keyword index build and closes the Qdrant client connection
 func (v *VectorMemory) Close() error {
+	if v.stopLoad != nil {
+		v.stopLoad()
+		<-v.loaded
+	}
 	if v.client != nil {
 		return v.client.Close()
 	}
@@ -0,0 +1,442 @@
+package memory
+
+import (
+	"container/heap"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// Metric selects how vectors are compared
+type Metric string
+
+const (
+	MetricCosine Metric = "cosine" // Cosine similarity (vectors are normalized on insert)
+	MetricDot    Metric = "dot"    // Raw dot product
+)
+
+// IndexType selects the vector index implementation
+type IndexType string
+
+const (
+	IndexFlat IndexType = "flat" // Exact brute-force search
+	IndexHNSW IndexType = "hnsw" // Approximate search with a navigable small-world graph
+)
+
+// IndexHit is a search result from a VectorIndex
+type IndexHit struct {
+	ID    string
+	Score float64 // Similarity: higher is closer
+}
+
+// VectorIndex is an in-process nearest-neighbour index keyed by string IDs.
+// Implementations are not safe for concurrent use.
+type VectorIndex interface {
+	// Add inserts or replaces the vector for id
+	Add(id string, vector []float32)
+
+	// Remove deletes id from the index
+	Remove(id string)
+
+	// Search returns up to k nearest vectors accepted by filter (nil = all)
+	Search(query []float32, k int, filter func(id string) bool) []IndexHit
+
+	// Len returns the number of vectors in the index
+	Len() int
+}
+
+// NewVectorIndex creates an index of the given type
+func NewVectorIndex(indexType IndexType, metric Metric, hnsw HNSWConfig) VectorIndex {
+	if indexType == IndexHNSW {
+		return NewHNSWIndex(metric, hnsw)
+	}
+	return NewFlatIndex(metric)
+}
+
+// similarity compares two prepared vectors
+func similarity(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return math.Inf(-1)
+	}
+	var dot float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+	}
+	return dot
+}
+
+// prepare copies the vector, normalizing it for cosine similarity
+func prepare(metric Metric, vector []float32) []float32 {
+	out := make([]float32, len(vector))
+	copy(out, vector)
+	if metric == MetricDot {
+		return out
+	}
+
+	var norm float64
+	for _, v := range out {
+		norm += float64(v) * float64(v)
+	}
+	if norm == 0 {
+		return out
+	}
+	norm = math.Sqrt(norm)
+	for i := range out {
+		out[i] = float32(float64(out[i]) / norm)
+	}
+	return out
+}
+
+// ===========================
+// Flat index
+// ===========================
+
+// FlatIndex performs exact search by comparing the query with every vector
+type FlatIndex struct {
+	metric  Metric
+	vectors map[string][]float32
+}
+
+// NewFlatIndex creates an exact brute-force index
+func NewFlatIndex(metric Metric) *FlatIndex {
+	return &FlatIndex{metric: metric, vectors: make(map[string][]float32)}
+}
+
+// Add implements VectorIndex
+func (f *FlatIndex) Add(id string, vector []float32) {
+	f.vectors[id] = prepare(f.metric, vector)
+}
+
+// Remove implements VectorIndex
+func (f *FlatIndex) Remove(id string) {
+	delete(f.vectors, id)
+}
+
+// Len implements VectorIndex
+func (f *FlatIndex) Len() int {
+	return len(f.vectors)
+}
+
+// Search implements VectorIndex
+func (f *FlatIndex) Search(query []float32, k int, filter func(id string) bool) []IndexHit {
+	q := prepare(f.metric, query)
+	hits := make([]IndexHit, 0, len(f.vectors))
+	for id, vec := range f.vectors {
+		if filter != nil && !filter(id) {
+			continue
+		}
+		hits = append(hits, IndexHit{ID: id, Score: similarity(q, vec)})
+	}
+	return topHits(hits, k)
+}
+
+// topHits sorts hits by score (then ID for determinism) and keeps k
+func topHits(hits []IndexHit, k int) []IndexHit {
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID < hits[j].ID
+	})
+	if k >= 0 && len(hits) > k {
+		hits = hits[:k]
+	}
+	return hits
+}
+
+// ===========================
+// HNSW index
+// ===========================
+
+// HNSWConfig tunes the HNSW graph
+type HNSWConfig struct {
+	M              int   // Links per node on upper layers (default 16, layer 0 uses 2*M)
+	EfConstruction int   // Candidate list size while inserting (default 200)
+	EfSearch       int   // Candidate list size while searching (default 64)
+	Seed           int64 // Random seed for level assignment (default 42)
+}
+
+// HNSWIndex is an approximate nearest-neighbour index (Malkov & Yashunin).
+// Removed vectors are tombstoned and skipped; re-adding an ID replaces it.
+type HNSWIndex struct {
+	metric Metric
+	config HNSWConfig
+	rng    *rand.Rand
+	levelM float64
+
+	nodes    []*hnswNode
+	ids      map[string]int // id -> live node
+	entry    int            // Entry point node (-1 when empty)
+	maxLevel int
+}
+
+type hnswNode struct {
+	id      string
+	vector  []float32
+	links   [][]int // Neighbours per layer
+	deleted bool
+}
+
+// NewHNSWIndex creates an HNSW index
+func NewHNSWIndex(metric Metric, config HNSWConfig) *HNSWIndex {
+	if config.M <= 0 {
+		config.M = 16
+	}
+	if config.EfConstruction <= 0 {
+		config.EfConstruction = 200
+	}
+	if config.EfSearch <= 0 {
+		config.EfSearch = 64
+	}
+	if config.Seed == 0 {
+		config.Seed = 42
+	}
+	return &HNSWIndex{
+		metric: metric,
+		config: config,
+		rng:    rand.New(rand.NewSource(config.Seed)),
+		levelM: 1 / math.Log(float64(config.M)),
+		ids:    make(map[string]int),
+		entry:  -1,
+	}
+}
+
+// Len implements VectorIndex
+func (h *HNSWIndex) Len() int {
+	return len(h.ids)
+}
+
+// Remove implements VectorIndex
+func (h *HNSWIndex) Remove(id string) {
+	if n, ok := h.ids[id]; ok {
+		h.nodes[n].deleted = true
+		delete(h.ids, id)
+	}
+}
+
+// Add implements VectorIndex
+func (h *HNSWIndex) Add(id string, vector []float32) {
+	h.Remove(id)
+
+	level := int(math.Floor(-math.Log(1-h.rng.Float64()) * h.levelM))
+	node := &hnswNode{id: id, vector: prepare(h.metric, vector), links: make([][]int, level+1)}
+	n := len(h.nodes)
+	h.nodes = append(h.nodes, node)
+	h.ids[id] = n
+
+	if h.entry < 0 {
+		h.entry, h.maxLevel = n, level
+		return
+	}
+
+	// Greedy descent through the layers above the new node's level
+	current := h.entry
+	for l := h.maxLevel; l > level; l-- {
+		current = h.greedy(node.vector, current, l)
+	}
+
+	// Connect the node on each of its layers
+	entries := []int{current}
+	for l := min(level, h.maxLevel); l >= 0; l-- {
+		candidates := h.searchLayer(node.vector, entries, h.config.EfConstruction, l)
+		neighbours := h.closest(candidates, h.maxLinks(l))
+		node.links[l] = neighbours
+		for _, nb := range neighbours {
+			h.link(nb, n, l)
+		}
+		entries = candidates
+	}
+
+	if level > h.maxLevel {
+		h.entry, h.maxLevel = n, level
+	}
+}
+
+// hnswExactScanLimit is the index size up to which Search falls back to an
+// exact scan when the graph walk finds fewer than k matching vectors
+const hnswExactScanLimit = 1000
+
+// hnswMaxWidening bounds how far Search widens the candidate list (as a
+// multiple of ef) before giving up on finding k matching vectors
+const hnswMaxWidening = 16
+
+// Search implements VectorIndex. Restrictive filters (or many tombstones)
+// can leave fewer than k matches among the ef candidates; Search then
+// retries with a candidate list widened up to 16 times. Indexes of up to
+// 1000 vectors finally fall back to an exact scan; larger indexes return the
+// matches found, so a filter matching very few vectors may miss some. Each
+// widening round costs about as much as a search with that ef.
+func (h *HNSWIndex) Search(query []float32, k int, filter func(id string) bool) []IndexHit {
+	if h.entry < 0 || k <= 0 {
+		return nil
+	}
+	q := prepare(h.metric, query)
+
+	current := h.entry
+	for l := h.maxLevel; l > 0; l-- {
+		current = h.greedy(q, current, l)
+	}
+
+	ef := max(h.config.EfSearch, k)
+	maxEf := min(ef*hnswMaxWidening, len(h.nodes))
+	hits := h.collect(q, current, ef, filter)
+	for len(hits) < k && len(hits) < h.Len() && ef < maxEf {
+		ef = min(ef*4, maxEf)
+		hits = h.collect(q, current, ef, filter)
+	}
+
+	if len(hits) < k && len(hits) < h.Len() && len(h.nodes) <= hnswExactScanLimit {
+		hits = hits[:0]
+		for id, n := range h.ids {
+			if filter == nil || filter(id) {
+				hits = append(hits, IndexHit{ID: id, Score: similarity(q, h.nodes[n].vector)})
+			}
+		}
+	}
+
+	return topHits(hits, k)
+}
+
+// collect searches layer 0 from entry with candidate list size ef and
+// returns the live candidates accepted by filter
+func (h *HNSWIndex) collect(q []float32, entry, ef int, filter func(id string) bool) []IndexHit {
+	candidates := h.searchLayer(q, []int{entry}, ef, 0)
+	hits := make([]IndexHit, 0, len(candidates))
+	for _, c := range candidates {
+		node := h.nodes[c]
+		if node.deleted || (filter != nil && !filter(node.id)) {
+			continue
+		}
+		hits = append(hits, IndexHit{ID: node.id, Score: similarity(q, node.vector)})
+	}
+	return hits
+}
+
+func (h *HNSWIndex) maxLinks(level int) int {
+	if level == 0 {
+		return 2 * h.config.M
+	}
+	return h.config.M
+}
+
+func (h *HNSWIndex) distance(q []float32, n int) float64 {
+	return -similarity(q, h.nodes[n].vector)
+}
+
+// greedy walks layer l towards q and returns the closest node found
+func (h *HNSWIndex) greedy(q []float32, start, l int) int {
+	current, best := start, h.distance(q, start)
+	for changed := true; changed; {
+		changed = false
+		for _, nb := range h.linksAt(current, l) {
+			if d := h.distance(q, nb); d < best {
+				current, best, changed = nb, d, true
+			}
+		}
+	}
+	return current
+}
+
+// searchLayer returns up to ef nodes closest to q on layer l, closest first
+func (h *HNSWIndex) searchLayer(q []float32, entries []int, ef, l int) []int {
+	visited := make(map[int]bool, ef*4)
+	candidates := &distHeap{}       // Min-heap: closest unexplored first
+	results := &distHeap{max: true} // Max-heap: furthest kept result on top
+	for _, e := range entries {
+		if visited[e] {
+			continue
+		}
+		visited[e] = true
+		d := h.distance(q, e)
+		heap.Push(candidates, distItem{e, d})
+		heap.Push(results, distItem{e, d})
+		if results.Len() > ef {
+			heap.Pop(results)
+		}
+	}
+
+	for candidates.Len() > 0 {
+		c := heap.Pop(candidates).(distItem)
+		if results.Len() >= ef && c.dist > results.items[0].dist {
+			break
+		}
+		for _, nb := range h.linksAt(c.node, l) {
+			if visited[nb] {
+				continue
+			}
+			visited[nb] = true
+			d := h.distance(q, nb)
+			if results.Len() < ef || d < results.items[0].dist {
+				heap.Push(candidates, distItem{nb, d})
+				heap.Push(results, distItem{nb, d})
+				if results.Len() > ef {
+					heap.Pop(results)
+				}
+			}
+		}
+	}
+
+	out := make([]int, results.Len())
+	for i := len(out) - 1; i >= 0; i-- {
+		out[i] = heap.Pop(results).(distItem).node
+	}
+	return out
+}
+
+// closest returns the first n nodes of an already sorted candidate list
+func (h *HNSWIndex) closest(sorted []int, n int) []int {
+	if len(sorted) > n {
+		sorted = sorted[:n]
+	}
+	return append([]int(nil), sorted...)
+}
+
+// link adds a connection from node a to b on layer l, pruning a's links
+func (h *HNSWIndex) link(a, b, l int) {
+	node := h.nodes[a]
+	node.links[l] = append(node.links[l], b)
+	if len(node.links[l]) <= h.maxLinks(l) {
+		return
+	}
+
+	links := node.links[l]
+	sort.Slice(links, func(i, j int) bool {
+		return -similarity(node.vector, h.nodes[links[i]].vector) < -similarity(node.vector, h.nodes[links[j]].vector)
+	})
+	node.links[l] = links[:h.maxLinks(l)]
+}
+
+func (h *HNSWIndex) linksAt(n, l int) []int {
+	if l < len(h.nodes[n].links) {
+		return h.nodes[n].links[l]
+	}
+	return nil
+}
+
+// distItem is a node with its distance to the query
+type distItem struct {
+	node int
+	dist float64
+}
+
+// distHeap is a min-heap by distance, or a max-heap when max is set
+type distHeap struct {
+	items []distItem
+	max   bool
+}
+
+func (d *distHeap) Len() int { return len(d.items) }
+func (d *distHeap) Less(i, j int) bool {
+	if d.max {
+		return d.items[i].dist > d.items[j].dist
+	}
+	return d.items[i].dist < d.items[j].dist
+}
+func (d *distHeap) Swap(i, j int)      { d.items[i], d.items[j] = d.items[j], d.items[i] }
+func (d *distHeap) Push(x interface{}) { d.items = append(d.items, x.(distItem)) }
+func (d *distHeap) Pop() interface{} {
+	old := d.items
+	item := old[len(old)-1]
+	d.items = old[:len(old)-1]
+	return item
+}
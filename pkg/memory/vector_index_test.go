@@ -0,0 +1,168 @@
+package memory
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+func randomVectors(n, dims int, seed int64) map[string][]float32 {
+	rng := rand.New(rand.NewSource(seed))
+	vectors := make(map[string][]float32, n)
+	for i := 0; i < n; i++ {
+		vec := make([]float32, dims)
+		for d := range vec {
+			vec[d] = rng.Float32()*2 - 1
+		}
+		vectors[fmt.Sprintf("v%d", i)] = vec
+	}
+	return vectors
+}
+
+func TestFlatIndex_Search(t *testing.T) {
+	tests := []struct {
+		name   string
+		metric Metric
+		want   string
+	}{
+		// Cosine ignores magnitude: "long" points the same way as the query
+		{"cosine", MetricCosine, "same"},
+		// Dot product rewards magnitude
+		{"dot", MetricDot, "long"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index := NewFlatIndex(tt.metric)
+			index.Add("same", []float32{1, 0})
+			index.Add("long", []float32{5, 4})
+			index.Add("other", []float32{0, 1})
+
+			hits := index.Search([]float32{1, 0}, 1, nil)
+			if len(hits) != 1 || hits[0].ID != tt.want {
+				t.Errorf("Expected %s, got %+v", tt.want, hits)
+			}
+		})
+	}
+}
+
+func TestFlatIndex_FilterAndRemove(t *testing.T) {
+	index := NewFlatIndex(MetricCosine)
+	index.Add("a", []float32{1, 0})
+	index.Add("b", []float32{0.9, 0.1})
+	index.Add("c", []float32{0, 1})
+
+	hits := index.Search([]float32{1, 0}, 2, func(id string) bool { return id != "a" })
+	if len(hits) != 2 || hits[0].ID != "b" || hits[1].ID != "c" {
+		t.Errorf("Expected filtered results b,c, got %+v", hits)
+	}
+
+	index.Remove("b")
+	if index.Len() != 2 {
+		t.Errorf("Expected 2 vectors after remove, got %d", index.Len())
+	}
+	if hits := index.Search([]float32{1, 0}, 3, nil); len(hits) != 2 || hits[0].ID != "a" {
+		t.Errorf("Expected removed vector to be gone, got %+v", hits)
+	}
+}
+
+func TestHNSWIndex_RecallMatchesFlat(t *testing.T) {
+	vectors := randomVectors(1000, 16, 1)
+	flat := NewFlatIndex(MetricCosine)
+	hnsw := NewHNSWIndex(MetricCosine, HNSWConfig{M: 8, EfConstruction: 100})
+	for id, vec := range vectors {
+		flat.Add(id, vec)
+		hnsw.Add(id, vec)
+	}
+
+	const k = 10
+	found, total := 0, 0
+	for _, query := range randomVectors(50, 16, 2) {
+		want := make(map[string]bool)
+		for _, hit := range flat.Search(query, k, nil) {
+			want[hit.ID] = true
+		}
+		for _, hit := range hnsw.Search(query, k, nil) {
+			if want[hit.ID] {
+				found++
+			}
+		}
+		total += k
+	}
+
+	if recall := float64(found) / float64(total); recall < 0.9 {
+		t.Errorf("Expected recall@%d >= 0.9, got %.2f", k, recall)
+	}
+}
+
+func TestHNSWIndex_FilterRemoveAndReplace(t *testing.T) {
+	index := NewHNSWIndex(MetricCosine, HNSWConfig{})
+	for id, vec := range randomVectors(200, 8, 3) {
+		index.Add(id, vec)
+	}
+	query := []float32{1, 1, 1, 1, 1, 1, 1, 1}
+
+	// A filter accepting a single ID must still find it
+	hits := index.Search(query, 5, func(id string) bool { return id == "v42" })
+	if len(hits) != 1 || hits[0].ID != "v42" {
+		t.Errorf("Expected only v42, got %+v", hits)
+	}
+
+	index.Remove("v42")
+	if index.Len() != 199 {
+		t.Errorf("Expected 199 vectors, got %d", index.Len())
+	}
+	for _, hit := range index.Search(query, 200, nil) {
+		if hit.ID == "v42" {
+			t.Fatal("Expected removed vector to be excluded")
+		}
+	}
+
+	// Re-adding replaces the vector
+	index.Add("v7", query)
+	if hits := index.Search(query, 1, nil); len(hits) != 1 || hits[0].ID != "v7" || hits[0].Score < 0.999 {
+		t.Errorf("Expected replaced v7 as exact match, got %+v", hits)
+	}
+	if index.Len() != 199 {
+		t.Errorf("Expected replacement to keep the count, got %d", index.Len())
+	}
+}
+
+func TestHNSWIndex_Empty(t *testing.T) {
+	index := NewHNSWIndex(MetricDot, HNSWConfig{})
+	if hits := index.Search([]float32{1}, 3, nil); len(hits) != 0 {
+		t.Errorf("Expected no hits, got %+v", hits)
+	}
+}
+
+func TestHNSWIndex_RestrictiveFilterOnLargeIndex(t *testing.T) {
+	index := NewHNSWIndex(MetricCosine, HNSWConfig{M: 8, EfConstruction: 64})
+	vectors := randomVectors(3000, 8, 4)
+	for id, vec := range vectors {
+		index.Add(id, vec)
+	}
+	query := []float32{1, 1, 1, 1, 1, 1, 1, 1}
+
+	// One vector in 20 matches: widening the candidate list finds k of them
+	// without scanning the whole index
+	calls := 0
+	hits := index.Search(query, 10, func(id string) bool {
+		calls++
+		var n int
+		fmt.Sscanf(id, "v%d", &n)
+		return n%20 == 0
+	})
+	if len(hits) != 10 {
+		t.Errorf("Expected 10 filtered hits, got %d", len(hits))
+	}
+	for _, hit := range hits {
+		var n int
+		fmt.Sscanf(hit.ID, "v%d", &n)
+		if n%20 != 0 {
+			t.Errorf("Expected only filtered IDs, got %s", hit.ID)
+		}
+	}
+	if calls >= len(vectors) {
+		t.Errorf("Expected no exact scan, filter was called %d times", calls)
+	}
+}
@@ -0,0 +1,311 @@
+package anthropic
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/taipm/go-llm-agent/pkg/types"
+)
+
+const (
+	// DefaultBaseURL is the public Anthropic API endpoint
+	DefaultBaseURL = "https://api.anthropic.com"
+
+	// apiVersion is the Messages API version sent in the anthropic-version header
+	apiVersion = "2023-06-01"
+
+	// defaultMaxTokens is used when ChatOptions.MaxTokens is not set
+	// (max_tokens is mandatory for the Messages API)
+	defaultMaxTokens = 4096
+)
+
+// Provider implements the LLMProvider interface for Anthropic's Messages API
+type Provider struct {
+	apiKey  string
+	baseURL string
+	model   string
+	client  *http.Client
+}
+
+// New creates a new Anthropic provider
+// apiKey: Anthropic API key (get from https://console.anthropic.com)
+// model: Model name (e.g., "claude-sonnet-4-5", "claude-haiku-4-5")
+func New(apiKey, model string) *Provider {
+	return NewWithBaseURL(apiKey, model, DefaultBaseURL)
+}
+
+// NewWithBaseURL creates a new Anthropic provider with custom base URL
+// Useful for proxies or Anthropic-compatible gateways
+func NewWithBaseURL(apiKey, model, baseURL string) *Provider {
+	return &Provider{
+		apiKey:  apiKey,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		model:   model,
+		client: &http.Client{
+			Timeout: 120 * time.Second,
+		},
+	}
+}
+
+// WithHTTPClient sets a custom HTTP client
+func (p *Provider) WithHTTPClient(client *http.Client) *Provider {
+	p.client = client
+	return p
+}
+
+// Chat implements the LLMProvider interface
+func (p *Provider) Chat(ctx context.Context, messages []types.Message, options *types.ChatOptions) (*types.Response, error) {
+	reqBody := p.buildRequest(messages, options, false)
+
+	resp, err := p.send(ctx, "Chat", reqBody)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Parse response
+	var antResp anthropicResponse
+	if err := json.NewDecoder(resp.Body).Decode(&antResp); err != nil {
+		return nil, &ProviderError{
+			Op:  "Chat",
+			Err: fmt.Errorf("failed to decode response: %w", err),
+		}
+	}
+
+	return fromAnthropicResponse(&antResp), nil
+}
+
+// Stream implements streaming chat completion using server-sent events
+func (p *Provider) Stream(ctx context.Context, messages []types.Message, options *types.ChatOptions, handler types.StreamHandler) error {
+	reqBody := p.buildRequest(messages, options, true)
+
+	resp, err := p.send(ctx, "Stream", reqBody)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// Accumulate tool_use blocks by content block index
+	type pendingToolCall struct {
+		id    string
+		name  string
+		input strings.Builder
+	}
+	pending := make(map[int]*pendingToolCall)
+
+	var model string
+	var usage anthropicUsage
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		// We only need the data lines; the event type is repeated inside the payload
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "" {
+			continue
+		}
+
+		var event streamEvent
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			return &ProviderError{
+				Op:  "Stream",
+				Err: fmt.Errorf("failed to decode event: %w", err),
+			}
+		}
+
+		switch event.Type {
+		case "message_start":
+			model = event.Message.Model
+			usage.InputTokens = event.Message.Usage.InputTokens
+			usage.OutputTokens = event.Message.Usage.OutputTokens
+
+		case "content_block_start":
+			if event.ContentBlock.Type == "tool_use" {
+				pending[event.Index] = &pendingToolCall{
+					id:   event.ContentBlock.ID,
+					name: event.ContentBlock.Name,
+				}
+			}
+
+		case "content_block_delta":
+			switch event.Delta.Type {
+			case "text_delta":
+				if event.Delta.Text == "" {
+					continue
+				}
+				if err := handler(types.StreamChunk{Content: event.Delta.Text}); err != nil {
+					return err
+				}
+			case "input_json_delta":
+				if tc := pending[event.Index]; tc != nil {
+					tc.input.WriteString(event.Delta.PartialJSON)
+				}
+			}
+
+		case "message_delta":
+			if event.Usage.OutputTokens > 0 {
+				usage.OutputTokens = event.Usage.OutputTokens
+			}
+
+		case "message_stop":
+			// Build final tool calls array in block order
+			indexes := make([]int, 0, len(pending))
+			for idx := range pending {
+				indexes = append(indexes, idx)
+			}
+			sort.Ints(indexes)
+
+			var finalToolCalls []types.ToolCall
+			for _, idx := range indexes {
+				tc := pending[idx]
+				args, err := parseToolInput(tc.input.String())
+				if err != nil {
+					return &ProviderError{Op: "Stream", Err: err}
+				}
+				finalToolCalls = append(finalToolCalls, types.ToolCall{
+					ID:   tc.id,
+					Type: "function",
+					Function: types.FunctionCall{
+						Name:      tc.name,
+						Arguments: args,
+					},
+				})
+			}
+
+			// Send done event
+			return handler(types.StreamChunk{
+				ToolCalls: finalToolCalls,
+				Done:      true,
+				Metadata:  toMetadata(model, usage),
+			})
+
+		case "error":
+			return &ProviderError{
+				Op:      "Stream",
+				Type:    event.Error.Type,
+				Message: event.Error.Message,
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return &ProviderError{
+			Op:  "Stream",
+			Err: err,
+		}
+	}
+
+	return &ProviderError{
+		Op:  "Stream",
+		Err: fmt.Errorf("stream ended without message_stop"),
+	}
+}
+
+// streamEvent represents a server-sent event from the Messages API
+type streamEvent struct {
+	Type         string                `json:"type"`
+	Index        int                   `json:"index"`
+	Message      anthropicResponse     `json:"message"`
+	ContentBlock anthropicContentBlock `json:"content_block"`
+	Delta        struct {
+		Type        string `json:"type"`
+		Text        string `json:"text"`
+		PartialJSON string `json:"partial_json"`
+		StopReason  string `json:"stop_reason"`
+	} `json:"delta"`
+	Usage anthropicUsage `json:"usage"`
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// send marshals the request, posts it to /v1/messages and checks the status code
+func (p *Provider) send(ctx context.Context, op string, reqBody anthropicRequest) (*http.Response, error) {
+	// Marshal request
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, &ProviderError{Op: op, Err: fmt.Errorf("failed to marshal request: %w", err)}
+	}
+
+	// Create HTTP request
+	url := fmt.Sprintf("%s/v1/messages", p.baseURL)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, &ProviderError{Op: op, Err: fmt.Errorf("failed to create request: %w", err)}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-api-key", p.apiKey)
+	req.Header.Set("anthropic-version", apiVersion)
+	if reqBody.Stream {
+		req.Header.Set("Accept", "text/event-stream")
+	}
+
+	// Send request
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, &ProviderError{Op: op, Err: fmt.Errorf("failed to send request: %w", err)}
+	}
+
+	// Check status code
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+
+		provErr := &ProviderError{
+			Op:         op,
+			StatusCode: resp.StatusCode,
+			Header:     resp.Header,
+		}
+
+		var errResp anthropicErrorResponse
+		if err := json.Unmarshal(body, &errResp); err == nil && errResp.Error.Message != "" {
+			provErr.Type = errResp.Error.Type
+			provErr.Message = errResp.Error.Message
+		} else {
+			provErr.Message = string(body)
+		}
+
+		return nil, provErr
+	}
+
+	return resp, nil
+}
+
+// ProviderError wraps Anthropic API errors
+type ProviderError struct {
+	Op         string      // Operation that failed
+	StatusCode int         // HTTP status code (0 if the request never completed)
+	Type       string      // Anthropic error type (e.g., "rate_limit_error", "overloaded_error")
+	Message    string      // Error message returned by the API
+	Header     http.Header // Response headers (e.g., Retry-After)
+	Err        error       // Underlying error
+}
+
+func (e *ProviderError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("anthropic %s: %v", e.Op, e.Err)
+	}
+	if e.StatusCode != 0 {
+		return fmt.Sprintf("anthropic %s: API error (status %d, %s): %s", e.Op, e.StatusCode, e.Type, e.Message)
+	}
+	return fmt.Sprintf("anthropic %s: %s: %s", e.Op, e.Type, e.Message)
+}
+
+func (e *ProviderError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,301 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/taipm/go-llm-agent/pkg/types"
+)
+
+const (
+	testAPIKey = "sk-ant-test"
+	testModel  = "claude-haiku-4-5"
+)
+
+// newTestServer starts a stand-in for the Messages API that records the last request
+func newTestServer(t *testing.T, handler func(w http.ResponseWriter, req anthropicRequest)) (*httptest.Server, *http.Header) {
+	t.Helper()
+
+	var lastHeader http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/messages" {
+			t.Errorf("Expected path /v1/messages, got %s", r.URL.Path)
+		}
+		lastHeader = r.Header.Clone()
+
+		var req anthropicRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("Failed to decode request: %v", err)
+		}
+		handler(w, req)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &lastHeader
+}
+
+func TestNew(t *testing.T) {
+	provider := New(testAPIKey, testModel)
+
+	if provider.baseURL != DefaultBaseURL {
+		t.Errorf("Expected baseURL %s, got %s", DefaultBaseURL, provider.baseURL)
+	}
+	if provider.model != testModel {
+		t.Errorf("Expected model %s, got %s", testModel, provider.model)
+	}
+	if provider.client == nil {
+		t.Error("Expected HTTP client to be initialized")
+	}
+}
+
+func TestChat(t *testing.T) {
+	server, header := newTestServer(t, func(w http.ResponseWriter, req anthropicRequest) {
+		if req.System != "You are terse." {
+			t.Errorf("Expected top-level system prompt, got %q", req.System)
+		}
+		if len(req.Messages) != 1 || req.Messages[0].Role != "user" {
+			t.Fatalf("Expected single user message, got %+v", req.Messages)
+		}
+		if req.MaxTokens != defaultMaxTokens {
+			t.Errorf("Expected default max_tokens %d, got %d", defaultMaxTokens, req.MaxTokens)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{
+			"id": "msg_1", "type": "message", "role": "assistant", "model": "claude-haiku-4-5",
+			"content": [{"type": "text", "text": "Hello"}],
+			"stop_reason": "end_turn",
+			"usage": {"input_tokens": 12, "output_tokens": 3}
+		}`)
+	})
+
+	provider := NewWithBaseURL(testAPIKey, testModel, server.URL)
+	response, err := provider.Chat(context.Background(), []types.Message{
+		{Role: types.RoleUser, Content: "Say hello"},
+	}, &types.ChatOptions{SystemPrompt: "You are terse."})
+	if err != nil {
+		t.Fatalf("Chat failed: %v", err)
+	}
+
+	if (*header).Get("x-api-key") != testAPIKey {
+		t.Errorf("Expected x-api-key header to be set")
+	}
+	if (*header).Get("anthropic-version") != apiVersion {
+		t.Errorf("Expected anthropic-version %s, got %s", apiVersion, (*header).Get("anthropic-version"))
+	}
+
+	if response.Content != "Hello" {
+		t.Errorf("Expected content 'Hello', got %q", response.Content)
+	}
+	if response.Metadata == nil {
+		t.Fatal("Expected metadata to be present")
+	}
+	if response.Metadata.PromptTokens != 12 || response.Metadata.CompletionTokens != 3 || response.Metadata.TotalTokens != 15 {
+		t.Errorf("Unexpected usage: %+v", response.Metadata)
+	}
+	if response.Metadata.Model != testModel {
+		t.Errorf("Expected model %s, got %s", testModel, response.Metadata.Model)
+	}
+}
+
+func TestChatToolUse(t *testing.T) {
+	server, _ := newTestServer(t, func(w http.ResponseWriter, req anthropicRequest) {
+		if len(req.Tools) != 1 || req.Tools[0].Name != "math_calculate" {
+			t.Fatalf("Expected math_calculate tool, got %+v", req.Tools)
+		}
+		if req.Tools[0].InputSchema == nil || req.Tools[0].InputSchema.Type != "object" {
+			t.Errorf("Expected input_schema to be forwarded")
+		}
+
+		fmt.Fprint(w, `{
+			"id": "msg_2", "type": "message", "role": "assistant", "model": "claude-haiku-4-5",
+			"content": [
+				{"type": "text", "text": "Let me calculate."},
+				{"type": "tool_use", "id": "toolu_01", "name": "math_calculate", "input": {"expression": "2+2"}}
+			],
+			"stop_reason": "tool_use",
+			"usage": {"input_tokens": 20, "output_tokens": 10}
+		}`)
+	})
+
+	provider := NewWithBaseURL(testAPIKey, testModel, server.URL)
+	response, err := provider.Chat(context.Background(), []types.Message{
+		{Role: types.RoleUser, Content: "What is 2+2?"},
+	}, &types.ChatOptions{
+		Tools: []types.ToolDefinition{{
+			Type: "function",
+			Function: types.FunctionDefinition{
+				Name:        "math_calculate",
+				Description: "Evaluate an expression",
+				Parameters: &types.JSONSchema{
+					Type: "object",
+					Properties: map[string]*types.JSONSchema{
+						"expression": {Type: "string"},
+					},
+					Required: []string{"expression"},
+				},
+			},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("Chat failed: %v", err)
+	}
+
+	if len(response.ToolCalls) != 1 {
+		t.Fatalf("Expected 1 tool call, got %d", len(response.ToolCalls))
+	}
+	tc := response.ToolCalls[0]
+	if tc.ID != "toolu_01" || tc.Type != "function" || tc.Function.Name != "math_calculate" {
+		t.Errorf("Unexpected tool call: %+v", tc)
+	}
+	if tc.Function.Arguments["expression"] != "2+2" {
+		t.Errorf("Expected expression argument '2+2', got %v", tc.Function.Arguments["expression"])
+	}
+}
+
+func TestToAnthropicMessages(t *testing.T) {
+	messages := []types.Message{
+		{Role: types.RoleSystem, Content: "Be precise."},
+		{Role: types.RoleUser, Content: "Look up two hosts"},
+		{
+			Role:    types.RoleAssistant,
+			Content: "",
+			ToolCalls: []types.ToolCall{
+				{ID: "toolu_a", Type: "function", Function: types.FunctionCall{Name: "network_dns_lookup", Arguments: map[string]interface{}{"domain": "a.com"}}},
+				{ID: "toolu_b", Type: "function", Function: types.FunctionCall{Name: "network_dns_lookup", Arguments: map[string]interface{}{"domain": "b.com"}}},
+			},
+		},
+		{Role: types.RoleTool, Content: "1.1.1.1", ToolID: "toolu_a"},
+		{Role: types.RoleTool, Content: "2.2.2.2", ToolID: "toolu_b"},
+	}
+
+	result, system := toAnthropicMessages(messages)
+
+	if system != "Be precise." {
+		t.Errorf("Expected system prompt to be hoisted, got %q", system)
+	}
+	if len(result) != 3 {
+		t.Fatalf("Expected 3 messages (user, assistant, user), got %d", len(result))
+	}
+	if result[1].Role != "assistant" || len(result[1].Content) != 2 || result[1].Content[0].Type != "tool_use" {
+		t.Errorf("Unexpected assistant message: %+v", result[1])
+	}
+
+	// Both tool results must be merged into a single user message
+	last := result[2]
+	if last.Role != "user" || len(last.Content) != 2 {
+		t.Fatalf("Expected merged tool results, got %+v", last)
+	}
+	if last.Content[0].Type != "tool_result" || last.Content[0].ToolUseID != "toolu_a" || last.Content[1].ToolUseID != "toolu_b" {
+		t.Errorf("Unexpected tool results: %+v", last.Content)
+	}
+}
+
+func TestChatAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		fmt.Fprint(w, `{"type": "error", "error": {"type": "rate_limit_error", "message": "slow down"}}`)
+	}))
+	defer server.Close()
+
+	provider := NewWithBaseURL(testAPIKey, testModel, server.URL)
+	_, err := provider.Chat(context.Background(), []types.Message{
+		{Role: types.RoleUser, Content: "hi"},
+	}, nil)
+	if err == nil {
+		t.Fatal("Expected error for 429 response")
+	}
+
+	var provErr *ProviderError
+	if !errors.As(err, &provErr) {
+		t.Fatalf("Expected *ProviderError, got %T", err)
+	}
+	if provErr.StatusCode != http.StatusTooManyRequests || provErr.Type != "rate_limit_error" || provErr.Message != "slow down" {
+		t.Errorf("Unexpected error fields: %+v", provErr)
+	}
+}
+
+func TestStream(t *testing.T) {
+	events := []string{
+		`{"type":"message_start","message":{"id":"msg_3","type":"message","role":"assistant","model":"claude-haiku-4-5","content":[],"usage":{"input_tokens":25,"output_tokens":1}}}`,
+		`{"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`,
+		`{"type":"ping"}`,
+		`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Checking"}}`,
+		`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":" now."}}`,
+		`{"type":"content_block_stop","index":0}`,
+		`{"type":"content_block_start","index":1,"content_block":{"type":"tool_use","id":"toolu_02","name":"web_fetch","input":{}}}`,
+		`{"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"{\"url\": \"https://"}}`,
+		`{"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"example.com\"}"}}`,
+		`{"type":"content_block_stop","index":1}`,
+		`{"type":"message_delta","delta":{"stop_reason":"tool_use"},"usage":{"output_tokens":30}}`,
+		`{"type":"message_stop"}`,
+	}
+
+	server, _ := newTestServer(t, func(w http.ResponseWriter, req anthropicRequest) {
+		if !req.Stream {
+			t.Error("Expected stream=true in request")
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, ev := range events {
+			var typed struct {
+				Type string `json:"type"`
+			}
+			json.Unmarshal([]byte(ev), &typed)
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", typed.Type, ev)
+		}
+	})
+
+	provider := NewWithBaseURL(testAPIKey, testModel, server.URL)
+
+	var content strings.Builder
+	var final types.StreamChunk
+	err := provider.Stream(context.Background(), []types.Message{
+		{Role: types.RoleUser, Content: "Fetch example.com"},
+	}, nil, func(chunk types.StreamChunk) error {
+		content.WriteString(chunk.Content)
+		if chunk.Done {
+			final = chunk
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Stream failed: %v", err)
+	}
+
+	if content.String() != "Checking now." {
+		t.Errorf("Expected streamed content 'Checking now.', got %q", content.String())
+	}
+	if !final.Done {
+		t.Fatal("Expected final chunk with Done=true")
+	}
+	if len(final.ToolCalls) != 1 || final.ToolCalls[0].Function.Arguments["url"] != "https://example.com" {
+		t.Errorf("Unexpected tool calls: %+v", final.ToolCalls)
+	}
+	if final.Metadata == nil || final.Metadata.PromptTokens != 25 || final.Metadata.CompletionTokens != 30 {
+		t.Errorf("Unexpected metadata: %+v", final.Metadata)
+	}
+}
+
+func TestStreamHandlerError(t *testing.T) {
+	server, _ := newTestServer(t, func(w http.ResponseWriter, req anthropicRequest) {
+		fmt.Fprint(w, "data: {\"type\":\"content_block_delta\",\"index\":0,\"delta\":{\"type\":\"text_delta\",\"text\":\"Hi\"}}\n\n")
+	})
+
+	provider := NewWithBaseURL(testAPIKey, testModel, server.URL)
+	handlerErr := errors.New("stop")
+
+	err := provider.Stream(context.Background(), []types.Message{
+		{Role: types.RoleUser, Content: "hi"},
+	}, nil, func(chunk types.StreamChunk) error {
+		return handlerErr
+	})
+	if !errors.Is(err, handlerErr) {
+		t.Errorf("Expected handler error to be returned, got %v", err)
+	}
+}
@@ -0,0 +1,272 @@
+package anthropic
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/taipm/go-llm-agent/pkg/types"
+)
+
+// anthropicContentBlock represents a content block in Anthropic's format
+// A block is one of: text, tool_use (assistant) or tool_result (user)
+type anthropicContentBlock struct {
+	Type string `json:"type"`
+
+	// text block
+	Text string `json:"text,omitempty"`
+
+	// tool_use block
+	ID    string                 `json:"id,omitempty"`
+	Name  string                 `json:"name,omitempty"`
+	Input map[string]interface{} `json:"input,omitempty"`
+
+	// tool_result block
+	ToolUseID string `json:"tool_use_id,omitempty"`
+	Content   string `json:"content,omitempty"`
+}
+
+// anthropicMessage represents a message in Anthropic's format
+type anthropicMessage struct {
+	Role    string                  `json:"role"`
+	Content []anthropicContentBlock `json:"content"`
+}
+
+// anthropicTool represents a tool definition in Anthropic's format
+type anthropicTool struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	InputSchema *types.JSONSchema `json:"input_schema"`
+}
+
+// anthropicRequest represents the request body for the Messages API
+type anthropicRequest struct {
+	Model         string             `json:"model"`
+	Messages      []anthropicMessage `json:"messages"`
+	System        string             `json:"system,omitempty"`
+	MaxTokens     int                `json:"max_tokens"`
+	Temperature   *float64           `json:"temperature,omitempty"`
+	TopP          *float64           `json:"top_p,omitempty"`
+	StopSequences []string           `json:"stop_sequences,omitempty"`
+	Tools         []anthropicTool    `json:"tools,omitempty"`
+	Stream        bool               `json:"stream,omitempty"`
+}
+
+// anthropicUsage represents token usage reported by the Messages API
+type anthropicUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+// anthropicResponse represents the response from the Messages API
+type anthropicResponse struct {
+	ID         string                  `json:"id"`
+	Type       string                  `json:"type"`
+	Role       string                  `json:"role"`
+	Model      string                  `json:"model"`
+	Content    []anthropicContentBlock `json:"content"`
+	StopReason string                  `json:"stop_reason"`
+	Usage      anthropicUsage          `json:"usage"`
+}
+
+// anthropicErrorResponse represents an error body returned by the API
+type anthropicErrorResponse struct {
+	Type  string `json:"type"`
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// buildRequest converts agent messages and options into an Anthropic request
+func (p *Provider) buildRequest(messages []types.Message, options *types.ChatOptions, stream bool) anthropicRequest {
+	antMessages, system := toAnthropicMessages(messages)
+
+	reqBody := anthropicRequest{
+		Model:     p.model,
+		Messages:  antMessages,
+		MaxTokens: defaultMaxTokens,
+		Stream:    stream,
+	}
+
+	// Anthropic takes the system prompt as a top-level field, not a message
+	if options != nil && options.SystemPrompt != "" {
+		if system != "" {
+			system = options.SystemPrompt + "\n\n" + system
+		} else {
+			system = options.SystemPrompt
+		}
+	}
+	reqBody.System = system
+
+	// Apply options if provided
+	if options != nil {
+		if options.MaxTokens > 0 {
+			reqBody.MaxTokens = options.MaxTokens
+		}
+		if options.Temperature > 0 {
+			temp := options.Temperature
+			reqBody.Temperature = &temp
+		}
+		if options.TopP > 0 {
+			topP := options.TopP
+			reqBody.TopP = &topP
+		}
+		if len(options.Stop) > 0 {
+			reqBody.StopSequences = options.Stop
+		}
+		if len(options.Tools) > 0 {
+			reqBody.Tools = toAnthropicTools(options.Tools)
+		}
+	}
+
+	return reqBody
+}
+
+// toAnthropicMessages converts agent messages to Anthropic format
+// Returns the messages and the concatenated system prompt found in the history
+func toAnthropicMessages(messages []types.Message) ([]anthropicMessage, string) {
+	result := make([]anthropicMessage, 0, len(messages))
+	var systemParts []string
+
+	for _, msg := range messages {
+		var role string
+		var blocks []anthropicContentBlock
+
+		switch msg.Role {
+		case types.RoleSystem:
+			// System messages are hoisted to the top-level system field
+			if msg.Content != "" {
+				systemParts = append(systemParts, msg.Content)
+			}
+			continue
+
+		case types.RoleUser:
+			role = "user"
+			if msg.Content != "" {
+				blocks = append(blocks, anthropicContentBlock{Type: "text", Text: msg.Content})
+			}
+
+		case types.RoleAssistant:
+			role = "assistant"
+			if msg.Content != "" {
+				blocks = append(blocks, anthropicContentBlock{Type: "text", Text: msg.Content})
+			}
+			for _, tc := range msg.ToolCalls {
+				input := tc.Function.Arguments
+				if input == nil {
+					input = make(map[string]interface{})
+				}
+				blocks = append(blocks, anthropicContentBlock{
+					Type:  "tool_use",
+					ID:    tc.ID,
+					Name:  tc.Function.Name,
+					Input: input,
+				})
+			}
+
+		case types.RoleTool:
+			// Tool results are sent back as user content blocks
+			role = "user"
+			blocks = append(blocks, anthropicContentBlock{
+				Type:      "tool_result",
+				ToolUseID: msg.ToolID,
+				Content:   msg.Content,
+			})
+
+		default:
+			continue
+		}
+
+		if len(blocks) == 0 {
+			continue
+		}
+
+		// Anthropic requires alternating roles, so merge consecutive messages
+		// (e.g. several tool results following one assistant turn)
+		if n := len(result); n > 0 && result[n-1].Role == role {
+			result[n-1].Content = append(result[n-1].Content, blocks...)
+			continue
+		}
+
+		result = append(result, anthropicMessage{
+			Role:    role,
+			Content: blocks,
+		})
+	}
+
+	return result, strings.Join(systemParts, "\n\n")
+}
+
+// toAnthropicTools converts our tool definitions to Anthropic format
+func toAnthropicTools(tools []types.ToolDefinition) []anthropicTool {
+	result := make([]anthropicTool, 0, len(tools))
+
+	for _, tool := range tools {
+		schema := tool.Function.Parameters
+		if schema == nil {
+			// input_schema is mandatory for Anthropic tools
+			schema = &types.JSONSchema{Type: "object"}
+		}
+
+		result = append(result, anthropicTool{
+			Name:        tool.Function.Name,
+			Description: tool.Function.Description,
+			InputSchema: schema,
+		})
+	}
+
+	return result
+}
+
+// fromAnthropicResponse converts an Anthropic response to our Response type
+func fromAnthropicResponse(resp *anthropicResponse) *types.Response {
+	response := &types.Response{}
+
+	for _, block := range resp.Content {
+		switch block.Type {
+		case "text":
+			response.Content += block.Text
+
+		case "tool_use":
+			args := block.Input
+			if args == nil {
+				args = make(map[string]interface{})
+			}
+			response.ToolCalls = append(response.ToolCalls, types.ToolCall{
+				ID:   block.ID,
+				Type: "function",
+				Function: types.FunctionCall{
+					Name:      block.Name,
+					Arguments: args,
+				},
+			})
+		}
+	}
+
+	response.Metadata = toMetadata(resp.Model, resp.Usage)
+
+	return response
+}
+
+// toMetadata converts Anthropic usage into our Metadata type
+func toMetadata(model string, usage anthropicUsage) *types.Metadata {
+	return &types.Metadata{
+		Model:            model,
+		PromptTokens:     usage.InputTokens,
+		CompletionTokens: usage.OutputTokens,
+		TotalTokens:      usage.InputTokens + usage.OutputTokens,
+	}
+}
+
+// parseToolInput parses accumulated input_json_delta fragments
+func parseToolInput(partial string) (map[string]interface{}, error) {
+	args := make(map[string]interface{})
+	if strings.TrimSpace(partial) == "" {
+		return args, nil
+	}
+	if err := json.Unmarshal([]byte(partial), &args); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal tool input: %w", err)
+	}
+	return args, nil
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/taipm/go-llm-agent/pkg/provider/anthropic"
 	"github.com/taipm/go-llm-agent/pkg/provider/gemini"
 	"github.com/taipm/go-llm-agent/pkg/provider/ollama"
 	"github.com/taipm/go-llm-agent/pkg/provider/openai"
@@ -21,18 +22,20 @@ const (
 	ProviderOpenAI ProviderType = "openai"
 	// ProviderGemini is the Google Gemini provider type
 	ProviderGemini ProviderType = "gemini"
+	// ProviderAnthropic is the Anthropic (Claude) provider type
+	ProviderAnthropic ProviderType = "anthropic"
 )
 
 // Config holds the configuration for creating a provider
 type Config struct {
-	// Type specifies which provider to use (ollama, openai, gemini)
+	// Type specifies which provider to use (ollama, openai, gemini, anthropic)
 	Type ProviderType
 
-	// APIKey is required for OpenAI and Gemini providers
+	// APIKey is required for OpenAI, Gemini and Anthropic providers
 	APIKey string
 
 	// BaseURL is required for Ollama (e.g., "http://localhost:11434")
-	// Optional for OpenAI (for Azure OpenAI or custom endpoints)
+	// Optional for OpenAI (for Azure OpenAI or custom endpoints) and Anthropic (for proxies)
 	BaseURL string
 
 	// Model specifies the model to use (e.g., "llama3.2", "gpt-4o", "gemini-2.5-flash")
@@ -72,6 +75,12 @@ func New(config Config) (types.LLMProvider, error) {
 		// Gemini API
 		return gemini.New(ctx, config.APIKey, config.Model)
 
+	case ProviderAnthropic:
+		if config.BaseURL != "" {
+			return anthropic.NewWithBaseURL(config.APIKey, config.Model, config.BaseURL), nil
+		}
+		return anthropic.New(config.APIKey, config.Model), nil
+
 	default:
 		return nil, fmt.Errorf("unsupported provider type: %s", config.Type)
 	}
@@ -79,7 +88,7 @@ func New(config Config) (types.LLMProvider, error) {
 
 // FromEnv creates a provider based on environment variables
 // Environment variables:
-//   - LLM_PROVIDER: ollama, openai, or gemini
+//   - LLM_PROVIDER: ollama, openai, gemini, or anthropic
 //   - LLM_MODEL: model name
 //   - OLLAMA_BASE_URL: Ollama base URL (default: http://localhost:11434)
 //   - OPENAI_API_KEY: OpenAI API key
@@ -87,6 +96,8 @@ func New(config Config) (types.LLMProvider, error) {
 //   - GEMINI_API_KEY: Gemini API key
 //   - GEMINI_PROJECT_ID: Gemini Vertex AI project ID
 //   - GEMINI_LOCATION: Gemini Vertex AI location
+//   - ANTHROPIC_API_KEY: Anthropic API key
+//   - ANTHROPIC_BASE_URL: Optional Anthropic base URL
 func FromEnv() (types.LLMProvider, error) {
 	providerType := os.Getenv("LLM_PROVIDER")
 	if providerType == "" {
@@ -119,8 +130,12 @@ func FromEnv() (types.LLMProvider, error) {
 		config.ProjectID = os.Getenv("GEMINI_PROJECT_ID")   // Optional (for Vertex AI)
 		config.Location = os.Getenv("GEMINI_LOCATION")       // Optional (for Vertex AI)
 
+	case ProviderAnthropic:
+		config.APIKey = os.Getenv("ANTHROPIC_API_KEY")
+		config.BaseURL = os.Getenv("ANTHROPIC_BASE_URL") // Optional
+
 	default:
-		return nil, fmt.Errorf("unsupported LLM_PROVIDER: %s (must be ollama, openai, or gemini)", providerType)
+		return nil, fmt.Errorf("unsupported LLM_PROVIDER: %s (must be ollama, openai, gemini, or anthropic)", providerType)
 	}
 
 	return New(config)
@@ -159,6 +174,11 @@ func validateConfig(config Config) error {
 			}
 		}
 
+	case ProviderAnthropic:
+		if config.APIKey == "" {
+			return fmt.Errorf("API key is required for Anthropic provider")
+		}
+
 	default:
 		return fmt.Errorf("invalid provider type: %s", config.Type)
 	}
@@ -48,6 +48,23 @@ func TestNew(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "anthropic provider",
+			config: Config{
+				Type:   ProviderAnthropic,
+				APIKey: "sk-ant-test",
+				Model:  "claude-haiku-4-5",
+			},
+			wantErr: false,
+		},
+		{
+			name: "anthropic missing API key",
+			config: Config{
+				Type:  ProviderAnthropic,
+				Model: "claude-haiku-4-5",
+			},
+			wantErr: true,
+		},
 		// Skip vertex AI test - requires GCP credentials
 		// {
 		// 	name: "gemini vertex AI provider",
@@ -175,6 +192,15 @@ func TestFromEnv(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "anthropic from env",
+			envVars: map[string]string{
+				"LLM_PROVIDER":      "anthropic",
+				"LLM_MODEL":         "claude-haiku-4-5",
+				"ANTHROPIC_API_KEY": "sk-ant-test",
+			},
+			wantErr: false,
+		},
 		// Skip vertex AI test - requires GCP credentials
 		// {
 		// 	name: "gemini vertex AI from env",
@@ -242,6 +268,8 @@ func clearEnvVars() {
 	os.Unsetenv("GEMINI_API_KEY")
 	os.Unsetenv("GEMINI_PROJECT_ID")
 	os.Unsetenv("GEMINI_LOCATION")
+	os.Unsetenv("ANTHROPIC_API_KEY")
+	os.Unsetenv("ANTHROPIC_BASE_URL")
 }
 
 func TestValidateConfig(t *testing.T) {
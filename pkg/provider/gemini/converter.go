@@ -147,9 +147,26 @@ func toGeminiSchema(jsonSchema *types.JSONSchema) *genai.Schema {
 		schema.Enum = enumStrings
 	}
 
+	// Bounds
+	schema.Minimum = jsonSchema.Minimum
+	schema.Maximum = jsonSchema.Maximum
+	schema.MinLength = toInt64(jsonSchema.MinLength)
+	schema.MaxLength = toInt64(jsonSchema.MaxLength)
+	schema.MinItems = toInt64(jsonSchema.MinItems)
+	schema.MaxItems = toInt64(jsonSchema.MaxItems)
+
 	return schema
 }
 
+// toInt64 converts an optional bound to Gemini's representation
+func toInt64(v *int) *int64 {
+	if v == nil {
+		return nil
+	}
+	n := int64(*v)
+	return &n
+}
+
 // fromGeminiResponse converts Gemini response to our Response format
 func fromGeminiResponse(geminiResp *genai.GenerateContentResponse) (*types.Response, error) {
 	if len(geminiResp.Candidates) == 0 {
@@ -0,0 +1,166 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/taipm/go-llm-agent/pkg/types"
+)
+
+// Middleware decorates an LLMProvider with additional behaviour
+// (retries, rate limiting, timeouts, ...)
+type Middleware func(types.LLMProvider) types.LLMProvider
+
+// Wrap applies middlewares to a provider and returns the decorated provider.
+// The first middleware is the outermost one, so
+//
+//	provider.Wrap(p, provider.WithRetry(cfg), provider.WithRateLimit(5, 1), provider.WithTimeout(30*time.Second))
+//
+// retries calls that each wait for the rate limiter and get their own timeout.
+func Wrap(p types.LLMProvider, middlewares ...Middleware) types.LLMProvider {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		if middlewares[i] != nil {
+			p = middlewares[i](p)
+		}
+	}
+	return p
+}
+
+// WithTimeout bounds every Chat and Stream call with the given timeout.
+// For Stream the timeout covers the whole stream, not each chunk.
+func WithTimeout(timeout time.Duration) Middleware {
+	return func(next types.LLMProvider) types.LLMProvider {
+		if timeout <= 0 {
+			return next
+		}
+		return &timeoutProvider{next: next, timeout: timeout}
+	}
+}
+
+// timeoutProvider applies a per-call deadline
+type timeoutProvider struct {
+	next    types.LLMProvider
+	timeout time.Duration
+}
+
+// Chat implements the LLMProvider interface
+func (p *timeoutProvider) Chat(ctx context.Context, messages []types.Message, options *types.ChatOptions) (*types.Response, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+	return p.next.Chat(ctx, messages, options)
+}
+
+// Stream implements the LLMProvider interface
+func (p *timeoutProvider) Stream(ctx context.Context, messages []types.Message, options *types.ChatOptions, handler types.StreamHandler) error {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+	return p.next.Stream(ctx, messages, options, handler)
+}
+
+// WithRateLimit limits calls to requestsPerSecond using a token bucket that
+// allows bursts of up to burst calls. Callers block until a token is available
+// or their context is done.
+func WithRateLimit(requestsPerSecond float64, burst int) Middleware {
+	return func(next types.LLMProvider) types.LLMProvider {
+		if requestsPerSecond <= 0 {
+			return next
+		}
+		if burst < 1 {
+			burst = 1
+		}
+		return &rateLimitProvider{
+			next:    next,
+			limiter: newTokenBucket(requestsPerSecond, burst),
+		}
+	}
+}
+
+// rateLimitProvider waits for the limiter before each call
+type rateLimitProvider struct {
+	next    types.LLMProvider
+	limiter *tokenBucket
+}
+
+// Chat implements the LLMProvider interface
+func (p *rateLimitProvider) Chat(ctx context.Context, messages []types.Message, options *types.ChatOptions) (*types.Response, error) {
+	if err := p.limiter.wait(ctx); err != nil {
+		return nil, err
+	}
+	return p.next.Chat(ctx, messages, options)
+}
+
+// Stream implements the LLMProvider interface
+func (p *rateLimitProvider) Stream(ctx context.Context, messages []types.Message, options *types.ChatOptions, handler types.StreamHandler) error {
+	if err := p.limiter.wait(ctx); err != nil {
+		return err
+	}
+	return p.next.Stream(ctx, messages, options, handler)
+}
+
+// tokenBucket is a minimal token bucket rate limiter
+type tokenBucket struct {
+	mu       sync.Mutex
+	rate     float64 // tokens per second
+	capacity float64
+	tokens   float64
+	last     time.Time
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	return &tokenBucket{
+		rate:     rate,
+		capacity: float64(burst),
+		tokens:   float64(burst),
+		last:     time.Now(),
+	}
+}
+
+// reserve takes a token and returns how long the caller must wait before using it
+func (b *tokenBucket) reserve() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.capacity {
+		b.tokens = b.capacity
+	}
+	b.last = now
+
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// cancel returns a token taken by reserve when the caller gave up waiting
+func (b *tokenBucket) cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens++
+	if b.tokens > b.capacity {
+		b.tokens = b.capacity
+	}
+}
+
+// wait blocks until a token is available or ctx is done
+func (b *tokenBucket) wait(ctx context.Context) error {
+	delay := b.reserve()
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		b.cancel()
+		return fmt.Errorf("rate limit wait: %w", ctx.Err())
+	}
+}
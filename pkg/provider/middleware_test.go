@@ -0,0 +1,343 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/genai"
+
+	"github.com/taipm/go-llm-agent/pkg/provider/anthropic"
+	"github.com/taipm/go-llm-agent/pkg/provider/gemini"
+	"github.com/taipm/go-llm-agent/pkg/provider/ollama"
+	"github.com/taipm/go-llm-agent/pkg/types"
+)
+
+// scriptedProvider returns the scripted errors in order, then succeeds
+type scriptedProvider struct {
+	mu       sync.Mutex
+	errs     []error
+	calls    int
+	chunks   []string // streamed before the scripted error
+	deadline bool     // whether the last call's context had a deadline
+}
+
+func (p *scriptedProvider) next() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls++
+	if len(p.errs) == 0 {
+		return nil
+	}
+	err := p.errs[0]
+	p.errs = p.errs[1:]
+	return err
+}
+
+func (p *scriptedProvider) Chat(ctx context.Context, messages []types.Message, options *types.ChatOptions) (*types.Response, error) {
+	_, p.deadline = ctx.Deadline()
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	return &types.Response{Content: "ok"}, nil
+}
+
+func (p *scriptedProvider) Stream(ctx context.Context, messages []types.Message, options *types.ChatOptions, handler types.StreamHandler) error {
+	for _, c := range p.chunks {
+		if err := handler(types.StreamChunk{Content: c}); err != nil {
+			return err
+		}
+	}
+	if err := p.next(); err != nil {
+		return err
+	}
+	return handler(types.StreamChunk{Done: true})
+}
+
+func fastRetry(maxRetries int) RetryConfig {
+	return RetryConfig{
+		MaxRetries:     maxRetries,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     5 * time.Millisecond,
+		Multiplier:     2,
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"canceled", context.Canceled, false},
+		{"deadline", context.DeadlineExceeded, true},
+		{"ollama 429", &ollama.ProviderError{StatusCode: 429}, true},
+		{"ollama 503", &ollama.ProviderError{StatusCode: 503}, true},
+		{"ollama 404", &ollama.ProviderError{StatusCode: 404}, false},
+		{"anthropic 529", &anthropic.ProviderError{Op: "Chat", StatusCode: 529}, true},
+		{"anthropic 400", &anthropic.ProviderError{Op: "Chat", StatusCode: 400}, false},
+		{"anthropic overloaded event", &anthropic.ProviderError{Op: "Stream", Type: "overloaded_error"}, true},
+		{"gemini 429", &gemini.ProviderError{Provider: "gemini", Original: genai.APIError{Code: 429}}, true},
+		{"gemini 400", &gemini.ProviderError{Provider: "gemini", Original: genai.APIError{Code: 400}}, false},
+		{"wrapped 500", fmt.Errorf("call failed: %w", &ollama.ProviderError{StatusCode: 500}), true},
+		{"501", &ollama.ProviderError{StatusCode: 501}, false},
+		{"plain error", errors.New("boom"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryable(tt.err); got != tt.want {
+				t.Errorf("IsRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	header := http.Header{}
+	header.Set("Retry-After", "2")
+	if d, ok := RetryAfter(&ollama.ProviderError{StatusCode: 429, Header: header}); !ok || d != 2*time.Second {
+		t.Errorf("Expected 2s from Retry-After header, got %v (ok=%v)", d, ok)
+	}
+
+	header = http.Header{}
+	header.Set("retry-after-ms", "150")
+	if d, ok := RetryAfter(&anthropic.ProviderError{StatusCode: 429, Header: header}); !ok || d != 150*time.Millisecond {
+		t.Errorf("Expected 150ms from retry-after-ms header, got %v (ok=%v)", d, ok)
+	}
+
+	gemErr := &gemini.ProviderError{
+		Provider: "gemini",
+		Original: genai.APIError{
+			Code: 429,
+			Details: []map[string]any{
+				{"@type": "type.googleapis.com/google.rpc.RetryInfo", "retryDelay": "3s"},
+			},
+		},
+	}
+	if d, ok := RetryAfter(gemErr); !ok || d != 3*time.Second {
+		t.Errorf("Expected 3s from Gemini RetryInfo, got %v (ok=%v)", d, ok)
+	}
+
+	if _, ok := RetryAfter(errors.New("boom")); ok {
+		t.Error("Expected no Retry-After for plain error")
+	}
+}
+
+func TestWithRetryChat(t *testing.T) {
+	mock := &scriptedProvider{errs: []error{
+		&ollama.ProviderError{StatusCode: 503},
+		&ollama.ProviderError{StatusCode: 429},
+	}}
+
+	var retries int
+	config := fastRetry(3)
+	config.OnRetry = func(attempt int, err error, delay time.Duration) { retries++ }
+
+	p := Wrap(mock, WithRetry(config))
+	resp, err := p.Chat(context.Background(), nil, nil)
+	if err != nil {
+		t.Fatalf("Expected success after retries, got %v", err)
+	}
+	if resp.Content != "ok" {
+		t.Errorf("Expected content 'ok', got '%s'", resp.Content)
+	}
+	if mock.calls != 3 {
+		t.Errorf("Expected 3 calls, got %d", mock.calls)
+	}
+	if retries != 2 {
+		t.Errorf("Expected OnRetry to be called 2 times, got %d", retries)
+	}
+}
+
+func TestWithRetryNonRetryable(t *testing.T) {
+	mock := &scriptedProvider{errs: []error{&ollama.ProviderError{StatusCode: 400}}}
+
+	p := Wrap(mock, WithRetry(fastRetry(3)))
+	if _, err := p.Chat(context.Background(), nil, nil); err == nil {
+		t.Fatal("Expected error")
+	}
+	if mock.calls != 1 {
+		t.Errorf("Expected 1 call, got %d", mock.calls)
+	}
+}
+
+func TestWithRetryExhausted(t *testing.T) {
+	mock := &scriptedProvider{errs: []error{
+		&ollama.ProviderError{StatusCode: 500},
+		&ollama.ProviderError{StatusCode: 500},
+		&ollama.ProviderError{StatusCode: 500},
+	}}
+
+	p := Wrap(mock, WithRetry(fastRetry(2)))
+	_, err := p.Chat(context.Background(), nil, nil)
+	if StatusCode(err) != 500 {
+		t.Errorf("Expected last error with status 500, got %v", err)
+	}
+	if mock.calls != 3 {
+		t.Errorf("Expected 3 calls, got %d", mock.calls)
+	}
+}
+
+func TestWithRetryHonoursRetryAfter(t *testing.T) {
+	header := http.Header{}
+	header.Set("retry-after-ms", "30")
+	mock := &scriptedProvider{errs: []error{&ollama.ProviderError{StatusCode: 429, Header: header}}}
+
+	var delay time.Duration
+	config := fastRetry(1)
+	config.OnRetry = func(attempt int, err error, d time.Duration) { delay = d }
+
+	start := time.Now()
+	if _, err := Wrap(mock, WithRetry(config)).Chat(context.Background(), nil, nil); err != nil {
+		t.Fatalf("Expected success, got %v", err)
+	}
+	if delay != 30*time.Millisecond {
+		t.Errorf("Expected Retry-After delay 30ms, got %v", delay)
+	}
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Errorf("Expected to wait at least 30ms, waited %v", elapsed)
+	}
+
+	// Retry-After longer than MaxRetryAfter fails fast
+	header = http.Header{}
+	header.Set("Retry-After", "3600")
+	mock = &scriptedProvider{errs: []error{&ollama.ProviderError{StatusCode: 429, Header: header}}}
+	config = fastRetry(1)
+	config.MaxRetryAfter = time.Second
+	if _, err := Wrap(mock, WithRetry(config)).Chat(context.Background(), nil, nil); err == nil {
+		t.Error("Expected error when Retry-After exceeds MaxRetryAfter")
+	}
+	if mock.calls != 1 {
+		t.Errorf("Expected 1 call, got %d", mock.calls)
+	}
+}
+
+func TestWithRetryStream(t *testing.T) {
+	// Failure before any chunk is retried
+	mock := &scriptedProvider{errs: []error{&ollama.ProviderError{StatusCode: 503}}}
+	var chunks int
+	err := Wrap(mock, WithRetry(fastRetry(2))).Stream(context.Background(), nil, nil, func(chunk types.StreamChunk) error {
+		chunks++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Expected success, got %v", err)
+	}
+	if mock.calls != 2 {
+		t.Errorf("Expected 2 calls, got %d", mock.calls)
+	}
+	if chunks != 1 {
+		t.Errorf("Expected 1 chunk, got %d", chunks)
+	}
+
+	// Failure after output reached the handler is not retried
+	mock = &scriptedProvider{
+		errs:   []error{&ollama.ProviderError{StatusCode: 503}},
+		chunks: []string{"partial"},
+	}
+	err = Wrap(mock, WithRetry(fastRetry(2))).Stream(context.Background(), nil, nil, func(chunk types.StreamChunk) error {
+		return nil
+	})
+	if err == nil {
+		t.Fatal("Expected error after partial stream")
+	}
+	if mock.calls != 1 {
+		t.Errorf("Expected 1 call, got %d", mock.calls)
+	}
+}
+
+func TestWithRetryContextCanceled(t *testing.T) {
+	mock := &scriptedProvider{errs: []error{&ollama.ProviderError{StatusCode: 503}}}
+	config := fastRetry(3)
+	config.InitialBackoff = time.Hour
+	config.MaxBackoff = time.Hour
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := Wrap(mock, WithRetry(config)).Chat(ctx, nil, nil); err == nil {
+		t.Fatal("Expected error")
+	}
+	if time.Since(start) > time.Second {
+		t.Error("Expected retry wait to stop when context is done")
+	}
+}
+
+func TestWithTimeout(t *testing.T) {
+	mock := &scriptedProvider{}
+	if _, err := Wrap(mock, WithTimeout(time.Second)).Chat(context.Background(), nil, nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !mock.deadline {
+		t.Error("Expected context passed to provider to have a deadline")
+	}
+
+	// Zero timeout leaves the provider untouched
+	if p := Wrap(mock, WithTimeout(0)); p != types.LLMProvider(mock) {
+		t.Error("Expected WithTimeout(0) to return the provider unchanged")
+	}
+}
+
+func TestWithRateLimit(t *testing.T) {
+	mock := &scriptedProvider{}
+	p := Wrap(mock, WithRateLimit(50, 1)) // one call every 20ms
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if _, err := p.Chat(context.Background(), nil, nil); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 35*time.Millisecond {
+		t.Errorf("Expected rate limiting to delay calls, took %v", elapsed)
+	}
+
+	// Waiting respects context cancellation
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	slow := Wrap(mock, WithRateLimit(0.001, 1))
+	slow.Chat(context.Background(), nil, nil) // consume the burst token
+	if _, err := slow.Chat(ctx, nil, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestWrapOrder(t *testing.T) {
+	var order []string
+	tag := func(name string) Middleware {
+		return func(next types.LLMProvider) types.LLMProvider {
+			return &orderProvider{next: next, name: name, order: &order}
+		}
+	}
+
+	p := Wrap(&scriptedProvider{}, tag("outer"), nil, tag("inner"))
+	if _, err := p.Chat(context.Background(), nil, nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(order) != 2 || order[0] != "outer" || order[1] != "inner" {
+		t.Errorf("Expected [outer inner], got %v", order)
+	}
+}
+
+type orderProvider struct {
+	next  types.LLMProvider
+	name  string
+	order *[]string
+}
+
+func (p *orderProvider) Chat(ctx context.Context, messages []types.Message, options *types.ChatOptions) (*types.Response, error) {
+	*p.order = append(*p.order, p.name)
+	return p.next.Chat(ctx, messages, options)
+}
+
+func (p *orderProvider) Stream(ctx context.Context, messages []types.Message, options *types.ChatOptions, handler types.StreamHandler) error {
+	*p.order = append(*p.order, p.name)
+	return p.next.Stream(ctx, messages, options, handler)
+}
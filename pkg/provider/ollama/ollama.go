@@ -187,7 +187,11 @@ func (p *Provider) Chat(ctx context.Context, messages []types.Message, options *
 	// Check status code
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("ollama API error (status %d): %s", resp.StatusCode, string(body))
+		return nil, &ProviderError{
+			StatusCode: resp.StatusCode,
+			Message:    string(body),
+			Header:     resp.Header,
+		}
 	}
 
 	// Parse response
@@ -323,7 +327,11 @@ func (p *Provider) Stream(ctx context.Context, messages []types.Message, options
 	// Check status code
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("ollama API error (status %d): %s", resp.StatusCode, string(body))
+		return &ProviderError{
+			StatusCode: resp.StatusCode,
+			Message:    string(body),
+			Header:     resp.Header,
+		}
 	}
 
 	// Read streaming response line by line
@@ -382,3 +390,14 @@ func (p *Provider) Stream(ctx context.Context, messages []types.Message, options
 
 	return nil
 }
+
+// ProviderError is returned when the Ollama API responds with a non-200 status
+type ProviderError struct {
+	StatusCode int         // HTTP status code
+	Message    string      // Response body
+	Header     http.Header // Response headers (e.g., Retry-After)
+}
+
+func (e *ProviderError) Error() string {
+	return fmt.Sprintf("ollama API error (status %d): %s", e.StatusCode, e.Message)
+}
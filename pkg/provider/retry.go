@@ -0,0 +1,300 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	openaisdk "github.com/openai/openai-go/v3"
+	"google.golang.org/genai"
+
+	"github.com/taipm/go-llm-agent/pkg/provider/anthropic"
+	"github.com/taipm/go-llm-agent/pkg/provider/ollama"
+	"github.com/taipm/go-llm-agent/pkg/types"
+)
+
+// RetryConfig configures the retry middleware
+type RetryConfig struct {
+	// MaxRetries is the number of retries after the first attempt
+	MaxRetries int
+
+	// InitialBackoff is the delay before the first retry
+	InitialBackoff time.Duration
+
+	// MaxBackoff caps the exponential backoff delay
+	MaxBackoff time.Duration
+
+	// Multiplier grows the backoff after each retry (e.g., 2.0 doubles it)
+	Multiplier float64
+
+	// Jitter randomly shortens each delay by up to this fraction (0.0 - 1.0)
+	Jitter float64
+
+	// MaxRetryAfter is the longest server-requested Retry-After delay we are
+	// willing to wait; longer requests fail immediately. Zero means no limit.
+	MaxRetryAfter time.Duration
+
+	// ShouldRetry decides whether an error is retryable (default: IsRetryable)
+	ShouldRetry func(err error) bool
+
+	// OnRetry is called before sleeping for the next attempt (optional)
+	OnRetry func(attempt int, err error, delay time.Duration)
+}
+
+// DefaultRetryConfig returns sensible retry defaults
+func DefaultRetryConfig() RetryConfig {
+	return RetryConfig{
+		MaxRetries:     3,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     30 * time.Second,
+		Multiplier:     2.0,
+		Jitter:         0.2,
+		MaxRetryAfter:  time.Minute,
+	}
+}
+
+// WithRetry retries transient provider failures with exponential backoff.
+// Server-supplied Retry-After delays take precedence over the computed backoff.
+// Stream calls are only retried while no chunk has reached the handler,
+// so callers never see duplicated output.
+func WithRetry(config RetryConfig) Middleware {
+	defaults := DefaultRetryConfig()
+	if config.MaxRetries < 0 {
+		config.MaxRetries = 0
+	}
+	if config.InitialBackoff <= 0 {
+		config.InitialBackoff = defaults.InitialBackoff
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = defaults.MaxBackoff
+	}
+	if config.Multiplier < 1 {
+		config.Multiplier = defaults.Multiplier
+	}
+	if config.ShouldRetry == nil {
+		config.ShouldRetry = IsRetryable
+	}
+
+	return func(next types.LLMProvider) types.LLMProvider {
+		return &retryProvider{next: next, config: config}
+	}
+}
+
+// retryProvider re-issues failed calls according to its RetryConfig
+type retryProvider struct {
+	next   types.LLMProvider
+	config RetryConfig
+}
+
+// Chat implements the LLMProvider interface
+func (p *retryProvider) Chat(ctx context.Context, messages []types.Message, options *types.ChatOptions) (*types.Response, error) {
+	var resp *types.Response
+	err := p.do(ctx, func() (bool, error) {
+		var err error
+		resp, err = p.next.Chat(ctx, messages, options)
+		return true, err
+	})
+	return resp, err
+}
+
+// Stream implements the LLMProvider interface
+func (p *retryProvider) Stream(ctx context.Context, messages []types.Message, options *types.ChatOptions, handler types.StreamHandler) error {
+	return p.do(ctx, func() (bool, error) {
+		delivered := false
+		err := p.next.Stream(ctx, messages, options, func(chunk types.StreamChunk) error {
+			delivered = true
+			return handler(chunk)
+		})
+		// Once the handler has seen output, replaying the stream would duplicate it
+		return !delivered, err
+	})
+}
+
+// do runs attempt until it succeeds, the error is not retryable,
+// retries are exhausted or ctx is done
+func (p *retryProvider) do(ctx context.Context, attempt func() (retryable bool, err error)) error {
+	for n := 0; ; n++ {
+		retryable, err := attempt()
+		if err == nil {
+			return nil
+		}
+		if !retryable || n >= p.config.MaxRetries || ctx.Err() != nil || !p.config.ShouldRetry(err) {
+			return err
+		}
+
+		delay := p.backoff(n)
+		if retryAfter, ok := RetryAfter(err); ok {
+			if p.config.MaxRetryAfter > 0 && retryAfter > p.config.MaxRetryAfter {
+				return err
+			}
+			delay = retryAfter
+		}
+
+		if p.config.OnRetry != nil {
+			p.config.OnRetry(n+1, err, delay)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		}
+	}
+}
+
+// backoff returns the exponential delay before retry number n (0-based)
+func (p *retryProvider) backoff(n int) time.Duration {
+	delay := float64(p.config.InitialBackoff) * math.Pow(p.config.Multiplier, float64(n))
+	if delay > float64(p.config.MaxBackoff) {
+		delay = float64(p.config.MaxBackoff)
+	}
+	if p.config.Jitter > 0 {
+		delay -= delay * p.config.Jitter * rand.Float64()
+	}
+	return time.Duration(delay)
+}
+
+// IsRetryable reports whether err is a transient provider failure:
+// rate limiting (429), timeouts, server errors (5xx) or network failures.
+// Cancellation of the caller's context is never retryable.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	// A per-attempt deadline (e.g. from WithTimeout) expired
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	if code := StatusCode(err); code != 0 {
+		switch code {
+		case http.StatusRequestTimeout, http.StatusConflict, http.StatusTooEarly, http.StatusTooManyRequests:
+			return true
+		case http.StatusNotImplemented, http.StatusHTTPVersionNotSupported:
+			return false
+		}
+		return code >= 500
+	}
+
+	// Anthropic reports overload mid-stream as an error event without a status
+	var antErr *anthropic.ProviderError
+	if errors.As(err, &antErr) {
+		switch antErr.Type {
+		case "overloaded_error", "rate_limit_error", "api_error":
+			return true
+		}
+	}
+
+	// Network-level failures
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	return errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET)
+}
+
+// StatusCode extracts the HTTP status code from a provider error.
+// Returns 0 when the error does not carry one.
+func StatusCode(err error) int {
+	var oaiErr *openaisdk.Error
+	if errors.As(err, &oaiErr) {
+		return oaiErr.StatusCode
+	}
+
+	var gemErr genai.APIError
+	if errors.As(err, &gemErr) {
+		return gemErr.Code
+	}
+
+	var antErr *anthropic.ProviderError
+	if errors.As(err, &antErr) {
+		return antErr.StatusCode
+	}
+
+	var ollamaErr *ollama.ProviderError
+	if errors.As(err, &ollamaErr) {
+		return ollamaErr.StatusCode
+	}
+
+	return 0
+}
+
+// RetryAfter extracts the server-requested retry delay from a provider error,
+// using the Retry-After header (OpenAI, Anthropic, Ollama) or the
+// RetryInfo error detail (Gemini).
+func RetryAfter(err error) (time.Duration, bool) {
+	var oaiErr *openaisdk.Error
+	if errors.As(err, &oaiErr) && oaiErr.Response != nil {
+		return parseRetryAfter(oaiErr.Response.Header)
+	}
+
+	var antErr *anthropic.ProviderError
+	if errors.As(err, &antErr) {
+		return parseRetryAfter(antErr.Header)
+	}
+
+	var ollamaErr *ollama.ProviderError
+	if errors.As(err, &ollamaErr) {
+		return parseRetryAfter(ollamaErr.Header)
+	}
+
+	var gemErr genai.APIError
+	if errors.As(err, &gemErr) {
+		for _, detail := range gemErr.Details {
+			typ, _ := detail["@type"].(string)
+			if !strings.HasSuffix(typ, "RetryInfo") {
+				continue
+			}
+			if delay, ok := detail["retryDelay"].(string); ok {
+				if d, err := time.ParseDuration(delay); err == nil && d >= 0 {
+					return d, true
+				}
+			}
+		}
+	}
+
+	return 0, false
+}
+
+// parseRetryAfter reads retry-after-ms or Retry-After (seconds or HTTP date)
+func parseRetryAfter(header http.Header) (time.Duration, bool) {
+	if header == nil {
+		return 0, false
+	}
+
+	if ms := header.Get("retry-after-ms"); ms != "" {
+		if v, err := strconv.ParseFloat(ms, 64); err == nil && v >= 0 {
+			return time.Duration(v * float64(time.Millisecond)), true
+		}
+	}
+
+	value := strings.TrimSpace(header.Get("Retry-After"))
+	if value == "" {
+		return 0, false
+	}
+	if secs, err := strconv.ParseFloat(value, 64); err == nil && secs >= 0 {
+		return time.Duration(secs * float64(time.Second)), true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		d := time.Until(t)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+
+	return 0, false
+}
@@ -0,0 +1,383 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/taipm/go-llm-agent/pkg/types"
+)
+
+// ErrNoBackendAvailable is returned when every backend's circuit breaker is open
+var ErrNoBackendAvailable = errors.New("no backend available: all circuit breakers are open")
+
+// errStreamHandler marks errors returned by the caller's StreamHandler
+var errStreamHandler = errors.New("stream handler failed")
+
+// handlerError wraps a StreamHandler error so that route neither blames the
+// backend for it nor fails over
+type handlerError struct {
+	err error
+}
+
+func (e *handlerError) Error() string        { return e.err.Error() }
+func (e *handlerError) Unwrap() error        { return e.err }
+func (e *handlerError) Is(target error) bool { return target == errStreamHandler }
+
+// RoutingStrategy decides the order in which backends are tried
+type RoutingStrategy string
+
+const (
+	// StrategyFailover tries backends in the configured order
+	StrategyFailover RoutingStrategy = "failover"
+	// StrategyWeighted picks backends at random proportionally to their weight,
+	// then fails over to the remaining ones
+	StrategyWeighted RoutingStrategy = "weighted"
+)
+
+// Backend describes one provider behind a Router
+type Backend struct {
+	// Name identifies the backend in metadata and health reports
+	// (default: "<type>/<model>")
+	Name string
+
+	// Config is passed to New to create the provider
+	Config Config
+
+	// Provider is used as-is instead of Config when set
+	// (e.g. a provider already decorated with Wrap)
+	Provider types.LLMProvider
+
+	// Weight is the relative share of traffic for StrategyWeighted (default: 1)
+	Weight int
+
+	// Timeout bounds each call to this backend (optional)
+	Timeout time.Duration
+}
+
+// RouterConfig configures a Router
+type RouterConfig struct {
+	// Backends in order of preference
+	Backends []Backend
+
+	// Strategy selects failover (default) or weighted load balancing
+	Strategy RoutingStrategy
+
+	// FailureThreshold is the number of consecutive failures that opens
+	// a backend's circuit breaker (default: 3)
+	FailureThreshold int
+
+	// Cooldown is how long an open circuit rejects traffic before a
+	// single trial request is allowed through (default: 30s)
+	Cooldown time.Duration
+
+	// ShouldFailover decides whether an error moves on to the next backend
+	// (default: every error except cancellation of the caller's context)
+	ShouldFailover func(err error) bool
+}
+
+// BackendHealth is a snapshot of a backend's circuit breaker
+type BackendHealth struct {
+	Name                string
+	Healthy             bool      // false while the circuit is open
+	ConsecutiveFailures int       // failures since the last success
+	OpenUntil           time.Time // when an open circuit allows a trial request
+	Requests            int       // total requests routed to this backend
+	Failures            int       // total failed requests
+	LastError           string    // most recent error message
+}
+
+// Router is an LLMProvider that spreads requests over several backends,
+// failing over when one errors or times out and tracking backend health
+// with a circuit breaker.
+type Router struct {
+	backends         []*routerBackend
+	strategy         RoutingStrategy
+	failureThreshold int
+	cooldown         time.Duration
+	shouldFailover   func(error) bool
+}
+
+// routerBackend holds a backend provider and its circuit breaker state
+type routerBackend struct {
+	name     string
+	provider types.LLMProvider
+	weight   int
+
+	mu                  sync.Mutex
+	consecutiveFailures int
+	openUntil           time.Time
+	trialInFlight       bool
+	requests            int
+	failures            int
+	lastError           string
+}
+
+// NewRouter creates a router from an ordered list of backends
+func NewRouter(config RouterConfig) (*Router, error) {
+	if len(config.Backends) == 0 {
+		return nil, fmt.Errorf("router requires at least one backend")
+	}
+
+	strategy := config.Strategy
+	switch strategy {
+	case "":
+		strategy = StrategyFailover
+	case StrategyFailover, StrategyWeighted:
+	default:
+		return nil, fmt.Errorf("unsupported routing strategy: %s", strategy)
+	}
+
+	r := &Router{
+		strategy:         strategy,
+		failureThreshold: config.FailureThreshold,
+		cooldown:         config.Cooldown,
+		shouldFailover:   config.ShouldFailover,
+	}
+	if r.failureThreshold <= 0 {
+		r.failureThreshold = 3
+	}
+	if r.cooldown <= 0 {
+		r.cooldown = 30 * time.Second
+	}
+
+	names := make(map[string]bool)
+	for i, b := range config.Backends {
+		p := b.Provider
+		if p == nil {
+			var err error
+			p, err = New(b.Config)
+			if err != nil {
+				return nil, fmt.Errorf("backend %d: %w", i, err)
+			}
+		}
+		if b.Timeout > 0 {
+			p = Wrap(p, WithTimeout(b.Timeout))
+		}
+
+		name := b.Name
+		if name == "" {
+			name = fmt.Sprintf("%s/%s", b.Config.Type, b.Config.Model)
+		}
+		if names[name] {
+			return nil, fmt.Errorf("duplicate backend name: %s", name)
+		}
+		names[name] = true
+
+		weight := b.Weight
+		if weight <= 0 {
+			weight = 1
+		}
+
+		r.backends = append(r.backends, &routerBackend{
+			name:     name,
+			provider: p,
+			weight:   weight,
+		})
+	}
+
+	return r, nil
+}
+
+// Chat implements the LLMProvider interface
+func (r *Router) Chat(ctx context.Context, messages []types.Message, options *types.ChatOptions) (*types.Response, error) {
+	var resp *types.Response
+	err := r.route(ctx, func(b *routerBackend) (bool, error) {
+		var err error
+		resp, err = b.provider.Chat(ctx, messages, options)
+		if err != nil {
+			return true, err
+		}
+		if resp.Metadata == nil {
+			resp.Metadata = &types.Metadata{}
+		}
+		resp.Metadata.Backend = b.name
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Stream implements the LLMProvider interface.
+// Failover only happens while no chunk has reached the handler.
+func (r *Router) Stream(ctx context.Context, messages []types.Message, options *types.ChatOptions, handler types.StreamHandler) error {
+	return r.route(ctx, func(b *routerBackend) (bool, error) {
+		delivered := false
+		var handlerErr error
+		err := b.provider.Stream(ctx, messages, options, func(chunk types.StreamChunk) error {
+			delivered = true
+			if chunk.Done {
+				if chunk.Metadata == nil {
+					chunk.Metadata = &types.Metadata{}
+				}
+				chunk.Metadata.Backend = b.name
+			}
+			if err := handler(chunk); err != nil {
+				handlerErr = err
+				return err
+			}
+			return nil
+		})
+		// Providers may wrap (or reformat) the handler's error; report the original
+		if err != nil && handlerErr != nil {
+			err = &handlerError{err: handlerErr}
+		}
+		return !delivered, err
+	})
+}
+
+// Health returns a snapshot of every backend's circuit breaker
+func (r *Router) Health() []BackendHealth {
+	now := time.Now()
+	result := make([]BackendHealth, 0, len(r.backends))
+	for _, b := range r.backends {
+		b.mu.Lock()
+		result = append(result, BackendHealth{
+			Name:                b.name,
+			Healthy:             !now.Before(b.openUntil),
+			ConsecutiveFailures: b.consecutiveFailures,
+			OpenUntil:           b.openUntil,
+			Requests:            b.requests,
+			Failures:            b.failures,
+			LastError:           b.lastError,
+		})
+		b.mu.Unlock()
+	}
+	return result
+}
+
+// route tries backends in strategy order until one succeeds.
+// call returns whether failing over is still allowed, and the call's error.
+func (r *Router) route(ctx context.Context, call func(b *routerBackend) (bool, error)) error {
+	var errs []error
+	tried := 0
+
+	for _, b := range r.order() {
+		if !b.acquire() {
+			continue
+		}
+		tried++
+
+		canFailover, err := call(b)
+		if err == nil {
+			b.success()
+			return nil
+		}
+
+		// The caller gave up; don't blame the backend
+		if ctx.Err() != nil {
+			b.release()
+			return err
+		}
+
+		// The caller's stream handler failed; the backend worked
+		if errors.Is(err, errStreamHandler) {
+			b.release()
+			return errors.Unwrap(err)
+		}
+
+		b.failure(err, r.failureThreshold, r.cooldown)
+		errs = append(errs, fmt.Errorf("%s: %w", b.name, err))
+
+		if !canFailover || !r.failover(err) {
+			return err
+		}
+	}
+
+	if tried == 0 {
+		return ErrNoBackendAvailable
+	}
+	return fmt.Errorf("all backends failed: %w", errors.Join(errs...))
+}
+
+// failover reports whether err should move on to the next backend
+func (r *Router) failover(err error) bool {
+	if r.shouldFailover != nil {
+		return r.shouldFailover(err)
+	}
+	return !errors.Is(err, context.Canceled)
+}
+
+// order returns the backends in the order they should be tried
+func (r *Router) order() []*routerBackend {
+	if r.strategy != StrategyWeighted {
+		return r.backends
+	}
+
+	// Weighted random permutation (sampling without replacement)
+	remaining := append([]*routerBackend(nil), r.backends...)
+	ordered := make([]*routerBackend, 0, len(remaining))
+	for len(remaining) > 0 {
+		total := 0
+		for _, b := range remaining {
+			total += b.weight
+		}
+		pick := rand.IntN(total)
+		for i, b := range remaining {
+			if pick < b.weight {
+				ordered = append(ordered, b)
+				remaining = append(remaining[:i], remaining[i+1:]...)
+				break
+			}
+			pick -= b.weight
+		}
+	}
+	return ordered
+}
+
+// acquire reports whether the backend may take a request.
+// An open circuit lets a single trial request through once its cooldown expires.
+func (b *routerBackend) acquire() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.openUntil.IsZero() {
+		b.requests++
+		return true
+	}
+	if time.Now().Before(b.openUntil) || b.trialInFlight {
+		return false
+	}
+
+	// Half-open: allow one trial request
+	b.trialInFlight = true
+	b.requests++
+	return true
+}
+
+// release undoes acquire for a request that was abandoned by the caller
+func (b *routerBackend) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trialInFlight = false
+}
+
+// success closes the circuit
+func (b *routerBackend) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.consecutiveFailures = 0
+	b.openUntil = time.Time{}
+	b.trialInFlight = false
+}
+
+// failure records an error and opens the circuit once the threshold is reached
+// (or immediately when a half-open trial fails)
+func (b *routerBackend) failure(err error, threshold int, cooldown time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.consecutiveFailures++
+	b.lastError = err.Error()
+
+	if b.trialInFlight || b.consecutiveFailures >= threshold {
+		b.openUntil = time.Now().Add(cooldown)
+	}
+	b.trialInFlight = false
+}
@@ -0,0 +1,303 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/taipm/go-llm-agent/pkg/provider/ollama"
+	"github.com/taipm/go-llm-agent/pkg/types"
+)
+
+func TestNewRouterValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		config RouterConfig
+	}{
+		{"no backends", RouterConfig{}},
+		{"invalid backend config", RouterConfig{Backends: []Backend{{Config: Config{Type: ProviderOpenAI, Model: "gpt-4o"}}}}},
+		{"duplicate names", RouterConfig{Backends: []Backend{
+			{Name: "a", Provider: &scriptedProvider{}},
+			{Name: "a", Provider: &scriptedProvider{}},
+		}}},
+		{"unknown strategy", RouterConfig{
+			Strategy: "round-robin",
+			Backends: []Backend{{Name: "a", Provider: &scriptedProvider{}}},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewRouter(tt.config); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+
+	// Backends built from Config get a default name
+	r, err := NewRouter(RouterConfig{Backends: []Backend{
+		{Config: Config{Type: ProviderOllama, BaseURL: "http://localhost:11434", Model: "llama3.2"}},
+	}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if name := r.Health()[0].Name; name != "ollama/llama3.2" {
+		t.Errorf("Expected default name 'ollama/llama3.2', got '%s'", name)
+	}
+}
+
+func TestRouterFailover(t *testing.T) {
+	primary := &scriptedProvider{errs: []error{&ollama.ProviderError{StatusCode: 503}}}
+	secondary := &scriptedProvider{}
+
+	r, err := NewRouter(RouterConfig{Backends: []Backend{
+		{Name: "gemini", Provider: primary},
+		{Name: "openai", Provider: secondary},
+	}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	resp, err := r.Chat(context.Background(), nil, nil)
+	if err != nil {
+		t.Fatalf("Expected failover to succeed, got %v", err)
+	}
+	if resp.Metadata == nil || resp.Metadata.Backend != "openai" {
+		t.Errorf("Expected backend 'openai' in metadata, got %+v", resp.Metadata)
+	}
+	if primary.calls != 1 || secondary.calls != 1 {
+		t.Errorf("Expected one call per backend, got %d and %d", primary.calls, secondary.calls)
+	}
+
+	// Primary is healthy again and serves the next request
+	resp, err = r.Chat(context.Background(), nil, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if resp.Metadata.Backend != "gemini" {
+		t.Errorf("Expected backend 'gemini', got '%s'", resp.Metadata.Backend)
+	}
+}
+
+func TestRouterAllBackendsFail(t *testing.T) {
+	errA := &ollama.ProviderError{StatusCode: 500}
+	errB := errors.New("connection refused")
+
+	r, _ := NewRouter(RouterConfig{Backends: []Backend{
+		{Name: "a", Provider: &scriptedProvider{errs: []error{errA}}},
+		{Name: "b", Provider: &scriptedProvider{errs: []error{errB}}},
+	}})
+
+	_, err := r.Chat(context.Background(), nil, nil)
+	if err == nil {
+		t.Fatal("Expected error")
+	}
+	if !errors.Is(err, errB) || StatusCode(err) != 500 {
+		t.Errorf("Expected joined backend errors, got %v", err)
+	}
+}
+
+func TestRouterShouldFailover(t *testing.T) {
+	secondary := &scriptedProvider{}
+	r, _ := NewRouter(RouterConfig{
+		Backends: []Backend{
+			{Name: "a", Provider: &scriptedProvider{errs: []error{&ollama.ProviderError{StatusCode: 400}}}},
+			{Name: "b", Provider: secondary},
+		},
+		ShouldFailover: IsRetryable,
+	})
+
+	if _, err := r.Chat(context.Background(), nil, nil); StatusCode(err) != 400 {
+		t.Errorf("Expected the 400 error to be returned, got %v", err)
+	}
+	if secondary.calls != 0 {
+		t.Errorf("Expected no failover for non-retryable error, got %d calls", secondary.calls)
+	}
+}
+
+func TestRouterCircuitBreaker(t *testing.T) {
+	failing := &scriptedProvider{errs: []error{
+		errors.New("down"), errors.New("down"), errors.New("down"),
+	}}
+	backup := &scriptedProvider{}
+
+	r, _ := NewRouter(RouterConfig{
+		Backends: []Backend{
+			{Name: "primary", Provider: failing},
+			{Name: "backup", Provider: backup},
+		},
+		FailureThreshold: 2,
+		Cooldown:         30 * time.Millisecond,
+	})
+
+	for i := 0; i < 2; i++ {
+		if _, err := r.Chat(context.Background(), nil, nil); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	health := r.Health()
+	if health[0].Healthy || health[0].ConsecutiveFailures != 2 {
+		t.Errorf("Expected primary circuit to be open after 2 failures, got %+v", health[0])
+	}
+
+	// Open circuit is skipped
+	if _, err := r.Chat(context.Background(), nil, nil); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if failing.calls != 2 {
+		t.Errorf("Expected open circuit to skip primary, got %d calls", failing.calls)
+	}
+
+	// After cooldown a failed trial re-opens the circuit immediately
+	time.Sleep(40 * time.Millisecond)
+	r.Chat(context.Background(), nil, nil)
+	if failing.calls != 3 {
+		t.Errorf("Expected one trial call after cooldown, got %d calls", failing.calls)
+	}
+	if r.Health()[0].Healthy {
+		t.Error("Expected failed trial to re-open the circuit")
+	}
+
+	// A successful trial closes it
+	time.Sleep(40 * time.Millisecond)
+	resp, err := r.Chat(context.Background(), nil, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if resp.Metadata.Backend != "primary" {
+		t.Errorf("Expected recovered primary to serve, got '%s'", resp.Metadata.Backend)
+	}
+	if h := r.Health()[0]; !h.Healthy || h.ConsecutiveFailures != 0 {
+		t.Errorf("Expected primary to be healthy, got %+v", h)
+	}
+}
+
+func TestRouterNoBackendAvailable(t *testing.T) {
+	r, _ := NewRouter(RouterConfig{
+		Backends:         []Backend{{Name: "a", Provider: &scriptedProvider{errs: []error{errors.New("down")}}}},
+		FailureThreshold: 1,
+		Cooldown:         time.Hour,
+	})
+
+	r.Chat(context.Background(), nil, nil)
+	if _, err := r.Chat(context.Background(), nil, nil); !errors.Is(err, ErrNoBackendAvailable) {
+		t.Errorf("Expected ErrNoBackendAvailable, got %v", err)
+	}
+}
+
+func TestRouterWeighted(t *testing.T) {
+	heavy := &scriptedProvider{}
+	light := &scriptedProvider{}
+
+	r, _ := NewRouter(RouterConfig{
+		Strategy: StrategyWeighted,
+		Backends: []Backend{
+			{Name: "heavy", Provider: heavy, Weight: 3},
+			{Name: "light", Provider: light, Weight: 1},
+		},
+	})
+
+	const n = 2000
+	for i := 0; i < n; i++ {
+		if _, err := r.Chat(context.Background(), nil, nil); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	share := float64(heavy.calls) / n
+	if share < 0.68 || share > 0.82 {
+		t.Errorf("Expected heavy backend to serve ~75%% of requests, got %.2f", share)
+	}
+	if heavy.calls+light.calls != n {
+		t.Errorf("Expected %d calls in total, got %d", n, heavy.calls+light.calls)
+	}
+}
+
+func TestRouterStream(t *testing.T) {
+	r, _ := NewRouter(RouterConfig{Backends: []Backend{
+		{Name: "a", Provider: &scriptedProvider{errs: []error{errors.New("down")}}},
+		{Name: "b", Provider: &scriptedProvider{}},
+	}})
+
+	var final types.StreamChunk
+	err := r.Stream(context.Background(), nil, nil, func(chunk types.StreamChunk) error {
+		if chunk.Done {
+			final = chunk
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Expected failover to succeed, got %v", err)
+	}
+	if final.Metadata == nil || final.Metadata.Backend != "b" {
+		t.Errorf("Expected backend 'b' on final chunk, got %+v", final.Metadata)
+	}
+
+	// No failover once output has been delivered
+	second := &scriptedProvider{}
+	r, _ = NewRouter(RouterConfig{Backends: []Backend{
+		{Name: "a", Provider: &scriptedProvider{errs: []error{errors.New("down")}, chunks: []string{"partial"}}},
+		{Name: "b", Provider: second},
+	}})
+	if err := r.Stream(context.Background(), nil, nil, func(types.StreamChunk) error { return nil }); err == nil {
+		t.Error("Expected error after partial stream")
+	}
+	if second.calls != 0 {
+		t.Errorf("Expected no failover after partial stream, got %d calls", second.calls)
+	}
+}
+
+func TestRouterStreamHandlerError(t *testing.T) {
+	primary := &scriptedProvider{chunks: []string{"partial"}}
+	second := &scriptedProvider{}
+	r, _ := NewRouter(RouterConfig{
+		Backends: []Backend{
+			{Name: "a", Provider: primary},
+			{Name: "b", Provider: second},
+		},
+		FailureThreshold: 1,
+	})
+
+	stop := errors.New("client went away")
+	err := r.Stream(context.Background(), nil, nil, func(types.StreamChunk) error { return stop })
+	if err != stop {
+		t.Errorf("Expected the handler's error, got %v", err)
+	}
+	if second.calls != 0 {
+		t.Errorf("Expected no failover on a handler error, got %d calls", second.calls)
+	}
+	if h := r.Health()[0]; !h.Healthy || h.Failures != 0 {
+		t.Errorf("Expected the backend not to be blamed, got %+v", h)
+	}
+}
+
+func TestRouterBackendTimeout(t *testing.T) {
+	slow := &blockingProvider{}
+	r, _ := NewRouter(RouterConfig{Backends: []Backend{
+		{Name: "slow", Provider: slow, Timeout: 10 * time.Millisecond},
+		{Name: "fast", Provider: &scriptedProvider{}},
+	}})
+
+	resp, err := r.Chat(context.Background(), nil, nil)
+	if err != nil {
+		t.Fatalf("Expected failover after timeout, got %v", err)
+	}
+	if resp.Metadata.Backend != "fast" {
+		t.Errorf("Expected backend 'fast', got '%s'", resp.Metadata.Backend)
+	}
+}
+
+// blockingProvider blocks until the context is done
+type blockingProvider struct{}
+
+func (p *blockingProvider) Chat(ctx context.Context, messages []types.Message, options *types.ChatOptions) (*types.Response, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (p *blockingProvider) Stream(ctx context.Context, messages []types.Message, options *types.ChatOptions, handler types.StreamHandler) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
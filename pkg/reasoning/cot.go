@@ -22,6 +22,9 @@ type CoTAgent struct {
 	verbose  bool
 	logger   logger.Logger
 
+	// Self-consistency sampling (nil: a single chain)
+	consistency *SelfConsistencyConfig
+
 	// Current reasoning chain
 	chain *types.CoTChain
 }
@@ -74,36 +77,20 @@ func (c *CoTAgent) Think(ctx context.Context, question string) (string, error) {
 		c.logger.Debug("📝 Question: %s", question)
 	}
 
-	// Build CoT prompt
-	prompt := c.buildCoTPrompt(question)
-
-	// Get LLM response
-	messages := []types.Message{
-		{Role: "user", Content: prompt},
-	}
-
-	if c.logger != nil {
-		c.logger.Debug("🤖 Calling LLM for CoT reasoning...")
+	var steps []types.CoTStep
+	var finalAnswer string
+	var err error
+	if c.consistency != nil {
+		// Sample several chains and vote on the answer
+		steps, finalAnswer, err = c.selfConsistentChain(ctx, question)
+	} else {
+		if c.logger != nil {
+			c.logger.Debug("🤖 Calling LLM for CoT reasoning...")
+		}
+		steps, finalAnswer, err = c.sampleChain(ctx, c.buildCoTPrompt(question), nil)
 	}
-
-	response, err := c.provider.Chat(ctx, messages, nil)
 	if err != nil {
-		return "", fmt.Errorf("LLM call failed: %w", err)
-	}
-
-	// Parse CoT steps
-	steps, finalAnswer, err := c.parseCoTResponse(response.Content)
-	if err != nil {
-		return "", fmt.Errorf("failed to parse CoT response: %w", err)
-	}
-
-	// Validate steps
-	if len(steps) == 0 {
-		return "", fmt.Errorf("no reasoning steps found in response")
-	}
-
-	if len(steps) > c.maxSteps {
-		return "", fmt.Errorf("exceeded max steps (%d > %d)", len(steps), c.maxSteps)
+		return "", err
 	}
 
 	// Log reasoning steps
@@ -116,6 +103,9 @@ func (c *CoTAgent) Think(ctx context.Context, question string) (string, error) {
 			}
 		}
 		c.logger.Info("✅ Final Answer: %s", finalAnswer)
+		if c.consistency != nil {
+			c.logger.Info("🗳️  Agreement: %.2f (%d chains)", c.chain.Confidence, c.chain.Samples)
+		}
 	}
 
 	// Update chain
@@ -134,6 +124,36 @@ func (c *CoTAgent) Think(ctx context.Context, question string) (string, error) {
 	return finalAnswer, nil
 }
 
+// sampleChain asks the LLM for one reasoning chain and parses it
+func (c *CoTAgent) sampleChain(ctx context.Context, prompt string, options *types.ChatOptions) ([]types.CoTStep, string, error) {
+	// Get LLM response
+	messages := []types.Message{
+		{Role: "user", Content: prompt},
+	}
+
+	response, err := c.provider.Chat(ctx, messages, options)
+	if err != nil {
+		return nil, "", fmt.Errorf("LLM call failed: %w", err)
+	}
+
+	// Parse CoT steps
+	steps, finalAnswer, err := c.parseCoTResponse(response.Content)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to parse CoT response: %w", err)
+	}
+
+	// Validate steps
+	if len(steps) == 0 {
+		return nil, "", fmt.Errorf("no reasoning steps found in response")
+	}
+
+	if len(steps) > c.maxSteps {
+		return nil, "", fmt.Errorf("exceeded max steps (%d > %d)", len(steps), c.maxSteps)
+	}
+
+	return steps, finalAnswer, nil
+}
+
 // buildCoTPrompt creates a prompt that encourages step-by-step reasoning
 func (c *CoTAgent) buildCoTPrompt(question string) string {
 	var sb strings.Builder
@@ -0,0 +1,181 @@
+package reasoning
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/taipm/go-llm-agent/pkg/types"
+)
+
+// SelfConsistencyConfig controls self-consistency sampling: the CoTAgent
+// samples several chains and answers with the answer most of them reach.
+// Reference: https://arxiv.org/abs/2203.11171
+type SelfConsistencyConfig struct {
+	Samples      int                        // Chains sampled per question (default: 5)
+	Temperature  float64                    // Sampling temperature (default: 0.8)
+	MaxParallel  int                        // Chains sampled at once (default: 1, sequential)
+	MinAgreement float64                    // Agreement below which LowAgreement reports true (default: 0.6)
+	Normalize    func(answer string) string // Maps answers to vote keys (default: NormalizeAnswer)
+}
+
+// DefaultSelfConsistencyConfig returns the default self-consistency configuration
+func DefaultSelfConsistencyConfig() SelfConsistencyConfig {
+	return SelfConsistencyConfig{
+		Samples:      5,
+		Temperature:  0.8,
+		MaxParallel:  1,
+		MinAgreement: 0.6,
+		Normalize:    NormalizeAnswer,
+	}
+}
+
+// WithSelfConsistency makes Think sample several chains and vote on their
+// answers; zero fields of config take their defaults. Pass nil to go back
+// to a single chain.
+func (c *CoTAgent) WithSelfConsistency(config *SelfConsistencyConfig) *CoTAgent {
+	if config == nil {
+		c.consistency = nil
+		return c
+	}
+
+	cfg := *config
+	defaults := DefaultSelfConsistencyConfig()
+	if cfg.Samples <= 0 {
+		cfg.Samples = defaults.Samples
+	}
+	if cfg.Temperature <= 0 {
+		cfg.Temperature = defaults.Temperature
+	}
+	if cfg.MaxParallel <= 0 {
+		cfg.MaxParallel = defaults.MaxParallel
+	}
+	if cfg.MinAgreement <= 0 {
+		cfg.MinAgreement = defaults.MinAgreement
+	}
+	if cfg.Normalize == nil {
+		cfg.Normalize = defaults.Normalize
+	}
+	c.consistency = &cfg
+	return c
+}
+
+// LowAgreement reports whether the last self-consistent chain reached less
+// agreement than MinAgreement, i.e. its answer should be verified further
+func (c *CoTAgent) LowAgreement() bool {
+	return c.consistency != nil && c.chain != nil && c.chain.Confidence < c.consistency.MinAgreement
+}
+
+// cotSample is one sampled reasoning chain
+type cotSample struct {
+	steps  []types.CoTStep
+	answer string
+	err    error
+}
+
+// selfConsistentChain samples chains, clusters their answers and returns the
+// chain of the winning answer. The agreement (votes of the winning answer
+// over all samples, so failed chains count against it) is recorded as the
+// chain confidence.
+func (c *CoTAgent) selfConsistentChain(ctx context.Context, question string) ([]types.CoTStep, string, error) {
+	config := c.consistency
+	prompt := c.buildCoTPrompt(question)
+
+	if c.logger != nil {
+		c.logger.Debug("🤖 Sampling %d CoT chains (temperature %.2f)...", config.Samples, config.Temperature)
+	}
+
+	samples := make([]cotSample, config.Samples)
+	sem := make(chan struct{}, config.MaxParallel)
+	var wg sync.WaitGroup
+	for i := range samples {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(sample *cotSample) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			options := &types.ChatOptions{Temperature: config.Temperature}
+			sample.steps, sample.answer, sample.err = c.sampleChain(ctx, prompt, options)
+		}(&samples[i])
+	}
+	wg.Wait()
+
+	// Cluster answers by their normalized form, in sampling order
+	type cluster struct {
+		first int // Index of the first sample with this answer
+		votes int
+	}
+	clusters := make(map[string]*cluster)
+	order := make([]*cluster, 0)
+	var firstErr error
+	for i, sample := range samples {
+		if sample.err == nil && strings.TrimSpace(sample.answer) == "" {
+			sample.err = fmt.Errorf("no answer found in response")
+		}
+		if sample.err != nil {
+			if firstErr == nil {
+				firstErr = sample.err
+			}
+			if c.logger != nil {
+				c.logger.Debug("   Chain %d failed: %v", i+1, sample.err)
+			}
+			continue
+		}
+
+		key := config.Normalize(sample.answer)
+		if cl, ok := clusters[key]; ok {
+			cl.votes++
+			continue
+		}
+		cl := &cluster{first: i, votes: 1}
+		clusters[key] = cl
+		order = append(order, cl)
+	}
+
+	if len(order) == 0 {
+		return nil, "", fmt.Errorf("no sampled chain produced an answer: %w", firstErr)
+	}
+
+	// Most votes first; ties go to the answer reached first
+	sort.SliceStable(order, func(i, j int) bool {
+		return order[i].votes > order[j].votes
+	})
+
+	c.chain.Samples = config.Samples
+	c.chain.Candidates = make([]types.CoTCandidate, 0, len(order))
+	for _, cl := range order {
+		c.chain.Candidates = append(c.chain.Candidates, types.CoTCandidate{
+			Answer: samples[cl.first].answer,
+			Votes:  cl.votes,
+		})
+	}
+	c.chain.Confidence = float64(order[0].votes) / float64(config.Samples)
+
+	winner := samples[order[0].first]
+	return winner.steps, winner.answer, nil
+}
+
+var answerNumberPattern = regexp.MustCompile(`-?\d[\d,]*(?:\.\d+)?`)
+
+// NormalizeAnswer maps an answer to its vote key. An answer with a single
+// number is keyed by that number ("9 apples are left" and "9" agree);
+// other answers by their lowercase words without punctuation.
+func NormalizeAnswer(answer string) string {
+	answer = strings.ToLower(strings.TrimSpace(answer))
+
+	if numbers := answerNumberPattern.FindAllString(answer, -1); len(numbers) == 1 {
+		if f, err := strconv.ParseFloat(strings.ReplaceAll(numbers[0], ",", ""), 64); err == nil {
+			return strconv.FormatFloat(f, 'f', -1, 64)
+		}
+	}
+
+	words := strings.FieldsFunc(answer, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(words, " ")
+}
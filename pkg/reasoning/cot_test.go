@@ -2,8 +2,11 @@ package reasoning
 
 import (
 	"context"
+	"fmt"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/taipm/go-llm-agent/pkg/types"
 )
@@ -305,3 +308,137 @@ Answer: Too many steps`
 		t.Errorf("Expected 'exceeded max steps' error, got: %v", err)
 	}
 }
+
+// samplingProvider answers CoT prompts with queued responses and is safe
+// for concurrent use
+type samplingProvider struct {
+	mu           sync.Mutex
+	responses    []string
+	calls        int
+	temperatures []float64
+	delay        time.Duration
+	running      int
+	maxRunning   int
+}
+
+func (p *samplingProvider) Chat(ctx context.Context, messages []types.Message, options *types.ChatOptions) (*types.Response, error) {
+	p.mu.Lock()
+	i := p.calls
+	p.calls++
+	if options != nil {
+		p.temperatures = append(p.temperatures, options.Temperature)
+	}
+	p.running++
+	if p.running > p.maxRunning {
+		p.maxRunning = p.running
+	}
+	p.mu.Unlock()
+
+	time.Sleep(p.delay)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.running--
+	if i >= len(p.responses) {
+		return nil, fmt.Errorf("no response %d", i)
+	}
+	return &types.Response{Content: p.responses[i]}, nil
+}
+
+func (p *samplingProvider) Stream(ctx context.Context, messages []types.Message, options *types.ChatOptions, handler types.StreamHandler) error {
+	return nil
+}
+
+func TestCoTSelfConsistencyVotes(t *testing.T) {
+	provider := &samplingProvider{responses: []string{
+		"Step 1: 15 * 23 = 345\nStep 2: 345 + 47 = 390\n\nAnswer: 390",
+		"Step 1: 15 * 23 = 345\nStep 2: 345 + 47 = 392\n\nAnswer: 392",
+		"Step 1: 345 + 47\n\nAnswer: The result is 392.",
+		"Step 1: Guess", // No answer: counts against agreement
+		"Step 1: Add\n\nAnswer: 392.0",
+	}}
+	agent := NewCoTAgent(provider, nil, 5).
+		WithSelfConsistency(&SelfConsistencyConfig{Samples: 5, MinAgreement: 0.7})
+
+	answer, err := agent.Think(context.Background(), "What is 15 * 23 + 47?")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// The first chain that reached the winning answer is kept
+	if answer != "392" {
+		t.Errorf("Expected majority answer '392', got '%s'", answer)
+	}
+	chain := agent.GetChain()
+	if len(chain.Steps) != 2 || !strings.Contains(chain.Steps[1].Description, "392") {
+		t.Errorf("Expected steps of the winning chain, got %+v", chain.Steps)
+	}
+	if chain.Samples != 5 || chain.Confidence != 0.6 {
+		t.Errorf("Expected agreement 0.6 over 5 samples, got %.2f over %d", chain.Confidence, chain.Samples)
+	}
+	if len(chain.Candidates) != 2 || chain.Candidates[0].Votes != 3 || chain.Candidates[1].Answer != "390" {
+		t.Errorf("Expected candidates 392 (3 votes) and 390, got %+v", chain.Candidates)
+	}
+	if !agent.LowAgreement() {
+		t.Error("Expected low agreement below MinAgreement 0.7")
+	}
+	for _, temp := range provider.temperatures {
+		if temp != 0.8 {
+			t.Errorf("Expected default sampling temperature 0.8, got %.2f", temp)
+		}
+	}
+}
+
+func TestCoTSelfConsistencyParallel(t *testing.T) {
+	responses := make([]string, 6)
+	for i := range responses {
+		responses[i] = "Step 1: Think\n\nAnswer: Paris"
+	}
+	provider := &samplingProvider{responses: responses, delay: 20 * time.Millisecond}
+	agent := NewCoTAgent(provider, nil, 5).
+		WithSelfConsistency(&SelfConsistencyConfig{Samples: 6, MaxParallel: 3, Temperature: 1.0})
+
+	answer, err := agent.Think(context.Background(), "What is the capital of France?")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if answer != "Paris" || agent.GetChain().Confidence != 1.0 || agent.LowAgreement() {
+		t.Errorf("Expected unanimous 'Paris', got '%s' (%.2f)", answer, agent.GetChain().Confidence)
+	}
+	if provider.calls != 6 {
+		t.Errorf("Expected 6 samples, got %d", provider.calls)
+	}
+	if provider.maxRunning < 2 || provider.maxRunning > 3 {
+		t.Errorf("Expected up to 3 concurrent samples, got %d", provider.maxRunning)
+	}
+}
+
+func TestCoTSelfConsistencyAllFail(t *testing.T) {
+	agent := NewCoTAgent(&samplingProvider{}, nil, 5).
+		WithSelfConsistency(&SelfConsistencyConfig{Samples: 2})
+
+	if _, err := agent.Think(context.Background(), "Question"); err == nil || !strings.Contains(err.Error(), "no sampled chain") {
+		t.Errorf("Expected error when no chain answers, got %v", err)
+	}
+}
+
+func TestNormalizeAnswer(t *testing.T) {
+	tests := []struct {
+		answer   string
+		expected string
+	}{
+		{"392", "392"},
+		{"The answer is 1,200.00 dollars", "1200"},
+		{"9 apples are left.", "9"},
+		{"-3.5", "-3.5"},
+		{"Yes!", "yes"},
+		{"  The Eiffel Tower, Paris ", "the eiffel tower paris"},
+		{"15 + 23 = 38", "15 23 38"},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeAnswer(tt.answer); got != tt.expected {
+			t.Errorf("NormalizeAnswer(%q): expected %q, got %q", tt.answer, tt.expected, got)
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package reasoning
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/taipm/go-llm-agent/pkg/types"
+)
+
+// PlanStore persists plans so that interrupted executions can be resumed.
+// Implementations must be safe for concurrent use.
+type PlanStore interface {
+	// Save inserts or replaces a plan (keyed by ID)
+	Save(ctx context.Context, plan *types.Plan) error
+
+	// Load returns the plan with the given ID
+	Load(ctx context.Context, id string) (*types.Plan, error)
+
+	// List returns all stored plans, newest first
+	List(ctx context.Context) ([]*types.Plan, error)
+
+	// Delete removes a plan; deleting an unknown plan is not an error
+	Delete(ctx context.Context, id string) error
+}
+
+// ErrPlanNotFound is returned when a plan ID is unknown
+var ErrPlanNotFound = fmt.Errorf("plan not found")
+
+// InMemoryPlanStore keeps plans in process memory. Plans are copied on Save
+// and Load, so callers never share state with the store.
+type InMemoryPlanStore struct {
+	mu    sync.RWMutex
+	plans map[string][]byte
+}
+
+// NewInMemoryPlanStore creates an empty in-memory plan store
+func NewInMemoryPlanStore() *InMemoryPlanStore {
+	return &InMemoryPlanStore{plans: make(map[string][]byte)}
+}
+
+// Save implements PlanStore
+func (s *InMemoryPlanStore) Save(ctx context.Context, plan *types.Plan) error {
+	data, err := json.Marshal(plan)
+	if err != nil {
+		return fmt.Errorf("failed to serialize plan: %w", err)
+	}
+	s.mu.Lock()
+	s.plans[plan.ID] = data
+	s.mu.Unlock()
+	return nil
+}
+
+// Load implements PlanStore
+func (s *InMemoryPlanStore) Load(ctx context.Context, id string) (*types.Plan, error) {
+	s.mu.RLock()
+	data, ok := s.plans[id]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrPlanNotFound, id)
+	}
+	return decodePlan(data)
+}
+
+// List implements PlanStore
+func (s *InMemoryPlanStore) List(ctx context.Context) ([]*types.Plan, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	plans := make([]*types.Plan, 0, len(s.plans))
+	for _, data := range s.plans {
+		plan, err := decodePlan(data)
+		if err != nil {
+			return nil, err
+		}
+		plans = append(plans, plan)
+	}
+	sortPlans(plans)
+	return plans, nil
+}
+
+// Delete implements PlanStore
+func (s *InMemoryPlanStore) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	delete(s.plans, id)
+	s.mu.Unlock()
+	return nil
+}
+
+// FilePlanStore stores each plan as a JSON file (<id>.json) in a directory.
+// Files are replaced atomically, so a crash never leaves a truncated plan.
+type FilePlanStore struct {
+	mu  sync.Mutex
+	dir string
+}
+
+// NewFilePlanStore creates dir if needed and stores plans in it
+func NewFilePlanStore(dir string) (*FilePlanStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create plan directory: %w", err)
+	}
+	return &FilePlanStore{dir: dir}, nil
+}
+
+// Save implements PlanStore
+func (s *FilePlanStore) Save(ctx context.Context, plan *types.Plan) error {
+	path, err := s.path(plan.ID)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize plan: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tmp, err := os.CreateTemp(s.dir, ".plan-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to save plan: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save plan: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to save plan: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to save plan: %w", err)
+	}
+	return nil
+}
+
+// Load implements PlanStore
+func (s *FilePlanStore) Load(ctx context.Context, id string) (*types.Plan, error) {
+	path, err := s.path(id)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: %s", ErrPlanNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load plan: %w", err)
+	}
+	return decodePlan(data)
+}
+
+// List implements PlanStore
+func (s *FilePlanStore) List(ctx context.Context) ([]*types.Plan, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list plans: %w", err)
+	}
+
+	plans := make([]*types.Plan, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") || filepath.Ext(name) != ".json" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load plan: %w", err)
+		}
+		plan, err := decodePlan(data)
+		if err != nil {
+			continue // Skip files that are not plans
+		}
+		plans = append(plans, plan)
+	}
+	sortPlans(plans)
+	return plans, nil
+}
+
+// Delete implements PlanStore
+func (s *FilePlanStore) Delete(ctx context.Context, id string) error {
+	path, err := s.path(id)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete plan: %w", err)
+	}
+	return nil
+}
+
+// path returns the file of a plan, rejecting IDs that would escape dir
+func (s *FilePlanStore) path(id string) (string, error) {
+	if id == "" || id != filepath.Base(id) || strings.HasPrefix(id, ".") || strings.ContainsAny(id, `/\`) {
+		return "", fmt.Errorf("invalid plan ID %q", id)
+	}
+	return filepath.Join(s.dir, id+".json"), nil
+}
+
+func decodePlan(data []byte) (*types.Plan, error) {
+	var plan types.Plan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("failed to parse plan: %w", err)
+	}
+	return &plan, nil
+}
+
+// sortPlans orders plans newest first
+func sortPlans(plans []*types.Plan) {
+	sort.Slice(plans, func(i, j int) bool {
+		if !plans[i].CreatedAt.Equal(plans[j].CreatedAt) {
+			return plans[i].CreatedAt.After(plans[j].CreatedAt)
+		}
+		return plans[i].ID < plans[j].ID
+	})
+}
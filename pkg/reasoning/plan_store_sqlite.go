@@ -0,0 +1,104 @@
+package reasoning
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/taipm/go-llm-agent/pkg/types"
+)
+
+// SQLitePlanStore stores plans in a SQLite table. It uses database/sql;
+// register a driver such as modernc.org/sqlite.
+type SQLitePlanStore struct {
+	db *sql.DB
+}
+
+const planSchema = `
+CREATE TABLE IF NOT EXISTS plans (
+	id         TEXT    PRIMARY KEY,
+	goal       TEXT    NOT NULL DEFAULT '',
+	status     TEXT    NOT NULL DEFAULT '',
+	created_at INTEGER NOT NULL,
+	updated_at INTEGER NOT NULL,
+	data       TEXT    NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_plans_created ON plans(created_at);
+CREATE INDEX IF NOT EXISTS idx_plans_status ON plans(status);
+`
+
+// NewSQLitePlanStore creates the plans table in db if needed
+func NewSQLitePlanStore(ctx context.Context, db *sql.DB) (*SQLitePlanStore, error) {
+	if _, err := db.ExecContext(ctx, planSchema); err != nil {
+		return nil, fmt.Errorf("failed to create plans table: %w", err)
+	}
+	return &SQLitePlanStore{db: db}, nil
+}
+
+// Save implements PlanStore
+func (s *SQLitePlanStore) Save(ctx context.Context, plan *types.Plan) error {
+	data, err := json.Marshal(plan)
+	if err != nil {
+		return fmt.Errorf("failed to serialize plan: %w", err)
+	}
+
+	_, err = s.db.ExecContext(ctx,
+		`INSERT OR REPLACE INTO plans (id, goal, status, created_at, updated_at, data)
+		 VALUES (?, ?, ?, ?, ?, ?)`,
+		plan.ID, plan.Goal, string(plan.Status), plan.CreatedAt.UnixNano(), time.Now().UnixNano(), string(data),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save plan: %w", err)
+	}
+	return nil
+}
+
+// Load implements PlanStore
+func (s *SQLitePlanStore) Load(ctx context.Context, id string) (*types.Plan, error) {
+	var data string
+	err := s.db.QueryRowContext(ctx, `SELECT data FROM plans WHERE id = ?`, id).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s", ErrPlanNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load plan: %w", err)
+	}
+	return decodePlan([]byte(data))
+}
+
+// List implements PlanStore
+func (s *SQLitePlanStore) List(ctx context.Context) ([]*types.Plan, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT data FROM plans ORDER BY created_at DESC, id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query plans: %w", err)
+	}
+	defer rows.Close()
+
+	plans := make([]*types.Plan, 0)
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to scan plan: %w", err)
+		}
+		plan, err := decodePlan([]byte(data))
+		if err != nil {
+			continue // Skip invalid entries
+		}
+		plans = append(plans, plan)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read plans: %w", err)
+	}
+	return plans, nil
+}
+
+// Delete implements PlanStore
+func (s *SQLitePlanStore) Delete(ctx context.Context, id string) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM plans WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("failed to delete plan: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package reasoning
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/taipm/go-llm-agent/pkg/types"
+	_ "modernc.org/sqlite"
+)
+
+func TestPlanStores(t *testing.T) {
+	fileStore, err := NewFilePlanStore(filepath.Join(t.TempDir(), "plans"))
+	if err != nil {
+		t.Fatalf("NewFilePlanStore failed: %v", err)
+	}
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "plans.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	sqliteStore, err := NewSQLitePlanStore(context.Background(), db)
+	if err != nil {
+		t.Fatalf("NewSQLitePlanStore failed: %v", err)
+	}
+	stores := map[string]PlanStore{
+		"memory": NewInMemoryPlanStore(),
+		"file":   fileStore,
+		"sqlite": sqliteStore,
+	}
+
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			created := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+			older := newTestPlan(step("a"), step("b", "a"))
+			older.ID = "older"
+			older.CreatedAt = created
+			older.Steps[0].Status = types.PlanStatusCompleted
+			older.Steps[0].Result = "42"
+			older.Steps[1].Status = types.PlanStatusFailed
+			older.Steps[1].Error = "timeout"
+
+			newer := newTestPlan(step("x"))
+			newer.ID = "newer"
+			newer.CreatedAt = created.Add(time.Hour)
+
+			for _, plan := range []*types.Plan{older, newer} {
+				if err := store.Save(ctx, plan); err != nil {
+					t.Fatalf("Save failed: %v", err)
+				}
+			}
+
+			// Later changes to the plan must not leak into the store
+			older.Steps[0].Result = "changed"
+
+			loaded, err := store.Load(ctx, "older")
+			if err != nil {
+				t.Fatalf("Load failed: %v", err)
+			}
+			if loaded.Steps[0].Result != "42" || loaded.Steps[1].Error != "timeout" || !loaded.CreatedAt.Equal(created) {
+				t.Errorf("Expected saved plan, got %+v", loaded)
+			}
+			if loaded.Steps[1].Dependencies[0] != "a" {
+				t.Errorf("Expected dependencies to round-trip, got %v", loaded.Steps[1].Dependencies)
+			}
+
+			plans, err := store.List(ctx)
+			if err != nil {
+				t.Fatalf("List failed: %v", err)
+			}
+			if len(plans) != 2 || plans[0].ID != "newer" || plans[1].ID != "older" {
+				t.Errorf("Expected newest first, got %d plans", len(plans))
+			}
+
+			if err := store.Delete(ctx, "older"); err != nil {
+				t.Fatalf("Delete failed: %v", err)
+			}
+			if err := store.Delete(ctx, "older"); err != nil {
+				t.Errorf("Expected deleting a missing plan to succeed, got %v", err)
+			}
+			if _, err := store.Load(ctx, "older"); !errors.Is(err, ErrPlanNotFound) {
+				t.Errorf("Expected ErrPlanNotFound, got %v", err)
+			}
+		})
+	}
+}
+
+func TestFilePlanStoreRejectsUnsafeIDs(t *testing.T) {
+	dir := t.TempDir()
+	store, _ := NewFilePlanStore(dir)
+	for _, id := range []string{"", "../escape", "a/b", `a\b`, ".hidden"} {
+		plan := &types.Plan{ID: id}
+		if err := store.Save(context.Background(), plan); err == nil {
+			t.Errorf("Expected error for plan ID %q", id)
+		}
+	}
+
+	// Stray files are ignored by List
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("hi"), 0o644)
+	os.WriteFile(filepath.Join(dir, "broken.json"), []byte("{"), 0o644)
+	plans, err := store.List(context.Background())
+	if err != nil || len(plans) != 0 {
+		t.Errorf("Expected no plans, got %d (%v)", len(plans), err)
+	}
+}
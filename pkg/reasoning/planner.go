@@ -18,6 +18,7 @@ type Planner struct {
 	memory   types.Memory
 	logger   logger.Logger
 	verbose  bool
+	store    PlanStore
 }
 
 // NewPlanner creates a new task planner
@@ -30,7 +31,16 @@ func NewPlanner(provider types.LLMProvider, memory types.Memory, log logger.Logg
 	}
 }
 
-// DecomposeGoal breaks a complex goal into sequential sub-tasks
+// WithStore checkpoints plans to store: after decomposition, after every
+// finished step and when execution ends. Stored plans can be resumed with
+// ResumePlan.
+func (p *Planner) WithStore(store PlanStore) *Planner {
+	p.store = store
+	return p
+}
+
+// DecomposeGoal breaks a complex goal into sub-tasks with dependencies. Plans
+// with unknown dependencies or dependency cycles are rejected.
 func (p *Planner) DecomposeGoal(ctx context.Context, goal string) (*types.Plan, error) {
 	p.logger.Info("🎯 Decomposing goal into tasks...")
 	p.logger.Debug("📝 Goal: %s", goal)
@@ -44,7 +54,7 @@ Requirements:
 1. Create 3-7 concrete, actionable steps
 2. Each step should be specific and measurable
 3. Steps should be in logical execution order
-4. Identify dependencies between steps (which steps must complete before others)
+4. Identify dependencies between steps (which steps must complete before others); steps without dependencies between them run in parallel
 5. Make steps atomic (each should accomplish one clear thing)
 
 Respond in JSON format:
@@ -80,40 +90,9 @@ Only return valid JSON, no additional text.`, goal)
 		return nil, fmt.Errorf("failed to decompose goal: %w", err)
 	}
 
-	// Parse JSON response
-	var planData struct {
-		Steps []struct {
-			ID           string   `json:"id"`
-			Description  string   `json:"description"`
-			Dependencies []string `json:"dependencies"`
-		} `json:"steps"`
-	}
-
-	// Clean response (remove markdown code blocks if present)
-	content := strings.TrimSpace(response.Content)
-	content = strings.TrimPrefix(content, "```json")
-	content = strings.TrimPrefix(content, "```")
-	content = strings.TrimSuffix(content, "```")
-	content = strings.TrimSpace(content)
-
-	if err := json.Unmarshal([]byte(content), &planData); err != nil {
-		p.logger.Warn("Failed to parse JSON, attempting alternative parsing...")
-		// Try to extract JSON from response
-		startIdx := strings.Index(content, "{")
-		endIdx := strings.LastIndex(content, "}")
-		if startIdx >= 0 && endIdx > startIdx {
-			content = content[startIdx : endIdx+1]
-			if err := json.Unmarshal([]byte(content), &planData); err != nil {
-				return nil, fmt.Errorf("failed to parse plan JSON: %w\nResponse: %s", err, response.Content)
-			}
-		} else {
-			return nil, fmt.Errorf("failed to find JSON in response: %s", response.Content)
-		}
-	}
-
-	// Validate plan
-	if len(planData.Steps) == 0 {
-		return nil, fmt.Errorf("plan has no steps")
+	steps, err := parsePlanSteps(response.Content)
+	if err != nil {
+		return nil, err
 	}
 
 	// Create Plan structure
@@ -122,18 +101,14 @@ Only return valid JSON, no additional text.`, goal)
 		Goal:      goal,
 		Status:    types.PlanStatusPending,
 		CreatedAt: time.Now(),
-		Steps:     make([]types.PlanStep, 0, len(planData.Steps)),
+		Steps:     steps,
 	}
 
-	// Convert to PlanStep
-	for _, step := range planData.Steps {
-		plan.Steps = append(plan.Steps, types.PlanStep{
-			ID:           step.ID,
-			Description:  step.Description,
-			Dependencies: step.Dependencies,
-			Status:       types.PlanStatusPending,
-		})
+	// Reject plans that could never finish
+	if err := ValidatePlan(plan); err != nil {
+		return nil, fmt.Errorf("invalid plan: %w", err)
 	}
+	p.checkpoint(ctx, plan)
 
 	// Log plan
 	if p.verbose {
@@ -161,52 +136,192 @@ Only return valid JSON, no additional text.`, goal)
 	return plan, nil
 }
 
-// ExecutePlan executes a plan step-by-step with dependency tracking
+// StepExecutor runs the task described by a plan step. The context carries
+// a StepInput with the results of the step's dependencies.
+type StepExecutor func(ctx context.Context, task string) (interface{}, error)
+
+// StepInput describes the step an executor is running
+type StepInput struct {
+	Goal         string           // Goal of the plan
+	Step         types.PlanStep   // Step being executed
+	Dependencies []types.PlanStep // Completed dependencies with their results
+}
+
+type stepInputKey struct{}
+
+// StepInputFromContext returns the StepInput passed to a StepExecutor
+func StepInputFromContext(ctx context.Context) (*StepInput, bool) {
+	input, ok := ctx.Value(stepInputKey{}).(*StepInput)
+	return input, ok
+}
+
+// Prompt returns the step description preceded by the goal and the results
+// of the step's dependencies, or just the description for steps without
+// dependencies
+func (in *StepInput) Prompt() string {
+	if len(in.Dependencies) == 0 {
+		return in.Step.Description
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Overall goal: %s\n\nResults of previous steps:\n", in.Goal))
+	for _, dep := range in.Dependencies {
+		sb.WriteString(fmt.Sprintf("- %s: %v\n", dep.Description, dep.Result))
+	}
+	sb.WriteString(fmt.Sprintf("\nCurrent step: %s", in.Step.Description))
+	return sb.String()
+}
+
+// Replanner revises a plan after steps failed. It returns the steps that
+// replace every pending step; they may depend on completed steps and on each
+// other. Planner.Replan asks the LLM.
+type Replanner func(ctx context.Context, plan *types.Plan) ([]types.PlanStep, error)
+
+// RetryPolicy controls how often a failed step is retried
+type RetryPolicy struct {
+	MaxAttempts int              // Attempts per step including the first (<= 1: no retries)
+	Backoff     time.Duration    // Delay before the first retry, doubled for each further retry
+	MaxBackoff  time.Duration    // Upper bound for the delay (0: unbounded)
+	Retryable   func(error) bool // Errors worth retrying (nil: all)
+}
+
+// ExecutionConfig controls how ExecutePlanWithConfig runs a plan
+type ExecutionConfig struct {
+	MaxParallel int                    // Steps running at once (default: 4, 1 runs steps in plan order)
+	Retry       RetryPolicy            // Retry policy for every step
+	StepRetry   map[string]RetryPolicy // Per-step overrides by step ID
+	MaxReplans  int                    // Plan revisions after failures (0: the first failure fails the plan)
+	Replan      Replanner              // Revises the plan (default: Planner.Replan)
+}
+
+// DefaultExecutionConfig returns the configuration used by ExecutePlan
+func DefaultExecutionConfig() ExecutionConfig {
+	return ExecutionConfig{
+		MaxParallel: 4,
+		Retry:       RetryPolicy{MaxAttempts: 1},
+	}
+}
+
+// ExecutePlan executes a plan with DefaultExecutionConfig
 func (p *Planner) ExecutePlan(ctx context.Context, plan *types.Plan, executor func(context.Context, string) (interface{}, error)) error {
+	return p.ExecutePlanWithConfig(ctx, plan, executor, DefaultExecutionConfig())
+}
+
+// stepOutcome is reported by a worker when a step finishes
+type stepOutcome struct {
+	step     *types.PlanStep
+	result   interface{}
+	attempts int
+	err      error
+}
+
+// ExecutePlanWithConfig executes a plan as a dependency graph: every step
+// whose dependencies completed runs concurrently, up to MaxParallel at a time.
+// Failed steps are retried according to the retry policy. When a step still
+// fails, no new steps are started; once running steps have finished the plan
+// is either revised by the replanner (up to MaxReplans times) or marked as
+// failed.
+func (p *Planner) ExecutePlanWithConfig(ctx context.Context, plan *types.Plan, executor StepExecutor, config ExecutionConfig) error {
 	if plan == nil {
 		return fmt.Errorf("plan is nil")
 	}
+	if err := ValidatePlan(plan); err != nil {
+		return fmt.Errorf("invalid plan: %w", err)
+	}
+	if config.MaxParallel <= 0 {
+		config.MaxParallel = DefaultExecutionConfig().MaxParallel
+	}
+	if config.Replan == nil {
+		config.Replan = p.Replan
+	}
 
 	p.logger.Info("▶️  Starting plan execution: %s", plan.Goal)
 	plan.Status = types.PlanStatusInProgress
-	plan.StartedAt = time.Now()
+	if plan.StartedAt.IsZero() {
+		plan.StartedAt = time.Now()
+	}
+	p.checkpoint(ctx, plan)
+	defer p.checkpoint(ctx, plan)
 
-	// Track completed steps
-	completed := make(map[string]bool)
+	outcomes := make(chan stepOutcome)
+	running := 0
+	replans := 0
+	var failure error
 
-	// Execute steps in order, respecting dependencies
 	for {
-		// Find next executable step
-		nextStep := p.findNextStep(plan, completed)
-		if nextStep == nil {
-			// No more executable steps
-			break
-		}
+		// Start every executable step while workers are free
+		for failure == nil && ctx.Err() == nil && running < config.MaxParallel {
+			step := p.findNextStep(plan)
+			if step == nil {
+				break
+			}
 
-		// Execute step
-		p.logger.Info("🔄 Executing step: %s", nextStep.Description)
-		nextStep.Status = types.PlanStatusInProgress
-		nextStep.StartedAt = time.Now()
+			p.logger.Info("🔄 Executing step: %s", step.Description)
+			step.Status = types.PlanStatusInProgress
+			step.StartedAt = time.Now()
+			running++
 
-		result, err := executor(ctx, nextStep.Description)
+			policy := config.Retry
+			if override, ok := config.StepRetry[step.ID]; ok {
+				policy = override
+			}
+			input := &StepInput{Goal: plan.Goal, Step: *step, Dependencies: dependencies(plan, step)}
+			go func(step *types.PlanStep, input *StepInput) {
+				stepCtx := context.WithValue(ctx, stepInputKey{}, input)
+				result, attempts, err := p.runStep(stepCtx, step.ID, input.Step.Description, executor, policy)
+				outcomes <- stepOutcome{step: step, result: result, attempts: attempts, err: err}
+			}(step, input)
+		}
 
-		nextStep.CompletedAt = time.Now()
+		if running == 0 {
+			if failure == nil || ctx.Err() != nil || replans >= config.MaxReplans {
+				break
+			}
 
-		if err != nil {
-			nextStep.Status = types.PlanStatusFailed
-			nextStep.Error = err
-			p.logger.Error("❌ Step failed: %v", err)
+			// Nothing is running any more, so the plan can be revised safely
+			replans++
+			p.logger.Info("🔁 Replanning after failure (%d/%d)...", replans, config.MaxReplans)
+			if err := p.applyReplan(ctx, plan, config.Replan); err != nil {
+				p.logger.Error("❌ Replanning failed: %v", err)
+				break
+			}
+			p.checkpoint(ctx, plan)
+			failure = nil
+			continue
+		}
 
-			// Mark plan as failed
-			plan.Status = types.PlanStatusFailed
-			return fmt.Errorf("step %s failed: %w", nextStep.ID, err)
+		out := <-outcomes
+		running--
+
+		step := out.step
+		step.CompletedAt = time.Now()
+		step.Attempts = out.attempts
+		if out.err != nil {
+			step.Status = types.PlanStatusFailed
+			step.Error = out.err.Error()
+			p.logger.Error("❌ Step failed: %v", out.err)
+			if failure == nil {
+				failure = fmt.Errorf("step %s failed: %w", step.ID, out.err)
+			}
+			p.checkpoint(ctx, plan)
+			continue
 		}
 
-		nextStep.Status = types.PlanStatusCompleted
-		nextStep.Result = result
-		completed[nextStep.ID] = true
+		step.Status = types.PlanStatusCompleted
+		step.Result = out.result
+		step.Error = ""
+		p.logger.Info("✅ Step completed: %s", step.Description)
+		p.checkpoint(ctx, plan)
+	}
 
-		p.logger.Info("✅ Step completed: %s", nextStep.Description)
+	if failure == nil {
+		if err := ctx.Err(); err != nil {
+			failure = err
+		}
+	}
+	if failure != nil {
+		plan.Status = types.PlanStatusFailed
+		return failure
 	}
 
 	// Check if all steps completed
@@ -236,8 +351,105 @@ func (p *Planner) ExecutePlan(ctx context.Context, plan *types.Plan, executor fu
 	return nil
 }
 
-// findNextStep finds the next step that can be executed (all dependencies met)
-func (p *Planner) findNextStep(plan *types.Plan, completed map[string]bool) *types.PlanStep {
+// ResumePlan loads a plan from the store and executes its unfinished steps.
+// Completed steps are skipped and keep their results; steps that failed or
+// were interrupted run again.
+func (p *Planner) ResumePlan(ctx context.Context, planID string, executor StepExecutor, config ExecutionConfig) (*types.Plan, error) {
+	if p.store == nil {
+		return nil, fmt.Errorf("plan store not configured")
+	}
+	plan, err := p.store.Load(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+	if plan.Status == types.PlanStatusCompleted {
+		return plan, nil
+	}
+
+	completed := 0
+	for i := range plan.Steps {
+		step := &plan.Steps[i]
+		switch step.Status {
+		case types.PlanStatusCompleted:
+			completed++
+		case types.PlanStatusInProgress, types.PlanStatusFailed:
+			step.Status = types.PlanStatusPending
+			step.Error = ""
+		}
+	}
+	p.logger.Info("⏯️  Resuming plan %s: %d of %d steps already completed", plan.ID, completed, len(plan.Steps))
+
+	return plan, p.ExecutePlanWithConfig(ctx, plan, executor, config)
+}
+
+// checkpoint saves the plan to the store, if any. Failures are logged
+// rather than aborting the execution; the save survives cancellation of ctx
+// so that interrupted plans are recorded.
+func (p *Planner) checkpoint(ctx context.Context, plan *types.Plan) {
+	if p.store == nil {
+		return
+	}
+	if err := p.store.Save(context.WithoutCancel(ctx), plan); err != nil {
+		p.logger.Warn("⚠️  Failed to checkpoint plan %s: %v", plan.ID, err)
+	}
+}
+
+// dependencies returns copies of the steps that step depends on
+func dependencies(plan *types.Plan, step *types.PlanStep) []types.PlanStep {
+	if len(step.Dependencies) == 0 {
+		return nil
+	}
+	deps := make([]types.PlanStep, 0, len(step.Dependencies))
+	for _, id := range step.Dependencies {
+		for _, candidate := range plan.Steps {
+			if candidate.ID == id {
+				deps = append(deps, candidate)
+				break
+			}
+		}
+	}
+	return deps
+}
+
+// runStep executes a step, retrying failures according to policy. It
+// returns the number of attempts made.
+func (p *Planner) runStep(ctx context.Context, id, task string, executor StepExecutor, policy RetryPolicy) (interface{}, int, error) {
+	delay := policy.Backoff
+	for attempt := 1; ; attempt++ {
+		result, err := executor(ctx, task)
+		if err == nil || attempt >= policy.MaxAttempts || ctx.Err() != nil {
+			return result, attempt, err
+		}
+		if policy.Retryable != nil && !policy.Retryable(err) {
+			return result, attempt, err
+		}
+
+		p.logger.Warn("⚠️  Step %s failed (attempt %d/%d), retrying: %v", id, attempt, policy.MaxAttempts, err)
+		if delay > 0 {
+			timer := time.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return result, attempt, err
+			case <-timer.C:
+			}
+			delay *= 2
+			if policy.MaxBackoff > 0 && delay > policy.MaxBackoff {
+				delay = policy.MaxBackoff
+			}
+		}
+	}
+}
+
+// findNextStep finds the next pending step whose dependencies all completed
+func (p *Planner) findNextStep(plan *types.Plan) *types.PlanStep {
+	completed := make(map[string]bool, len(plan.Steps))
+	for _, step := range plan.Steps {
+		if step.Status == types.PlanStatusCompleted {
+			completed[step.ID] = true
+		}
+	}
+
 	for i := range plan.Steps {
 		step := &plan.Steps[i]
 
@@ -263,6 +475,232 @@ func (p *Planner) findNextStep(plan *types.Plan, completed map[string]bool) *typ
 	return nil
 }
 
+// ValidatePlan checks that step IDs are unique and that dependencies refer
+// to existing steps without forming a cycle
+func ValidatePlan(plan *types.Plan) error {
+	if plan == nil {
+		return fmt.Errorf("plan is nil")
+	}
+
+	index := make(map[string]int, len(plan.Steps))
+	for i, step := range plan.Steps {
+		if step.ID == "" {
+			return fmt.Errorf("step %d has no ID", i+1)
+		}
+		if _, exists := index[step.ID]; exists {
+			return fmt.Errorf("duplicate step ID %q", step.ID)
+		}
+		index[step.ID] = i
+	}
+	for _, step := range plan.Steps {
+		for _, dep := range step.Dependencies {
+			if _, ok := index[dep]; !ok {
+				return fmt.Errorf("step %s depends on unknown step %q", step.ID, dep)
+			}
+		}
+	}
+
+	// Depth-first search; a step reached again while on the path closes a cycle
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(plan.Steps))
+	var path []string
+	var visit func(i int) error
+	visit = func(i int) error {
+		switch state[i] {
+		case visiting:
+			start := 0
+			for start < len(path) && path[start] != plan.Steps[i].ID {
+				start++
+			}
+			cycle := append(append([]string{}, path[start:]...), plan.Steps[i].ID)
+			return fmt.Errorf("dependency cycle: %s", strings.Join(cycle, " -> "))
+		case visited:
+			return nil
+		}
+		state[i] = visiting
+		path = append(path, plan.Steps[i].ID)
+		for _, dep := range plan.Steps[i].Dependencies {
+			if err := visit(index[dep]); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[i] = visited
+		return nil
+	}
+	for i := range plan.Steps {
+		if err := visit(i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Replan asks the LLM to revise the unfinished part of a plan after steps
+// failed. It implements Replanner.
+func (p *Planner) Replan(ctx context.Context, plan *types.Plan) ([]types.PlanStep, error) {
+	var sb strings.Builder
+	for _, step := range plan.Steps {
+		sb.WriteString(fmt.Sprintf("- %s [%s] %s", step.ID, step.Status, step.Description))
+		if len(step.Dependencies) > 0 {
+			sb.WriteString(fmt.Sprintf(" (depends on: %s)", strings.Join(step.Dependencies, ", ")))
+		}
+		switch step.Status {
+		case types.PlanStatusCompleted:
+			sb.WriteString(fmt.Sprintf("\n  Result: %s", truncate(fmt.Sprintf("%v", step.Result), 300)))
+		case types.PlanStatusFailed:
+			sb.WriteString(fmt.Sprintf("\n  Error: %s", step.Error))
+		}
+		sb.WriteString("\n")
+	}
+
+	prompt := fmt.Sprintf(`You are a task planning expert. Some steps of a plan failed. Revise the remaining work so the goal can still be reached.
+
+Goal: %s
+
+Current plan:
+%s
+Requirements:
+1. Return the steps that replace all failed and pending steps
+2. Completed steps stay as they are; do not repeat their work
+3. Work around the errors instead of repeating the failed steps unchanged
+4. Use new step IDs that are not used in the current plan
+5. Dependencies may only refer to completed steps or to your new steps
+
+Respond in JSON format:
+{
+  "steps": [
+    {
+      "id": "step-r1",
+      "description": "Clear description of what to do",
+      "dependencies": []
+    }
+  ]
+}
+
+Only return valid JSON, no additional text.`, plan.Goal, sb.String())
+
+	messages := []types.Message{
+		{Role: types.RoleUser, Content: prompt},
+	}
+	opts := &types.ChatOptions{
+		Temperature: 0.3,
+		MaxTokens:   1500,
+	}
+
+	response, err := p.provider.Chat(ctx, messages, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to replan: %w", err)
+	}
+	return parsePlanSteps(response.Content)
+}
+
+// applyReplan replaces the failed and pending steps of plan with the steps
+// returned by replan. Failed steps are kept as skipped for the record.
+func (p *Planner) applyReplan(ctx context.Context, plan *types.Plan, replan Replanner) error {
+	steps, err := replan(ctx, plan)
+	if err != nil {
+		return err
+	}
+	if len(steps) == 0 {
+		return fmt.Errorf("replanning produced no steps")
+	}
+
+	revised := &types.Plan{Steps: make([]types.PlanStep, 0, len(plan.Steps)+len(steps))}
+	completed := make(map[string]bool)
+	for _, step := range plan.Steps {
+		switch step.Status {
+		case types.PlanStatusPending:
+			continue
+		case types.PlanStatusFailed:
+			step.Status = types.PlanStatusSkipped
+		case types.PlanStatusCompleted:
+			completed[step.ID] = true
+		}
+		revised.Steps = append(revised.Steps, step)
+	}
+
+	added := make(map[string]bool, len(steps))
+	for _, step := range steps {
+		added[step.ID] = true
+	}
+	for _, step := range steps {
+		for _, dep := range step.Dependencies {
+			if !completed[dep] && !added[dep] {
+				return fmt.Errorf("revised step %s depends on %q, which is neither completed nor part of the revision", step.ID, dep)
+			}
+		}
+		step.Status = types.PlanStatusPending
+		revised.Steps = append(revised.Steps, step)
+	}
+	if err := ValidatePlan(revised); err != nil {
+		return fmt.Errorf("invalid revised plan: %w", err)
+	}
+
+	plan.Steps = revised.Steps
+	plan.Revisions++
+
+	if p.verbose {
+		p.logger.Info("📋 Revised plan with %d new steps:", len(steps))
+		for _, step := range steps {
+			p.logger.Info("   - %s: %s", step.ID, step.Description)
+		}
+	}
+	return nil
+}
+
+// parsePlanSteps extracts the steps from an LLM planning response
+func parsePlanSteps(raw string) ([]types.PlanStep, error) {
+	var planData struct {
+		Steps []struct {
+			ID           string   `json:"id"`
+			Description  string   `json:"description"`
+			Dependencies []string `json:"dependencies"`
+		} `json:"steps"`
+	}
+
+	// Clean response (remove markdown code blocks if present)
+	content := strings.TrimSpace(raw)
+	content = strings.TrimPrefix(content, "```json")
+	content = strings.TrimPrefix(content, "```")
+	content = strings.TrimSuffix(content, "```")
+	content = strings.TrimSpace(content)
+
+	if err := json.Unmarshal([]byte(content), &planData); err != nil {
+		// Try to extract JSON from response
+		startIdx := strings.Index(content, "{")
+		endIdx := strings.LastIndex(content, "}")
+		if startIdx < 0 || endIdx <= startIdx {
+			return nil, fmt.Errorf("failed to find JSON in response: %s", raw)
+		}
+		if err := json.Unmarshal([]byte(content[startIdx:endIdx+1]), &planData); err != nil {
+			return nil, fmt.Errorf("failed to parse plan JSON: %w\nResponse: %s", err, raw)
+		}
+	}
+
+	if len(planData.Steps) == 0 {
+		return nil, fmt.Errorf("plan has no steps")
+	}
+
+	steps := make([]types.PlanStep, 0, len(planData.Steps))
+	for i, step := range planData.Steps {
+		if step.ID == "" {
+			step.ID = fmt.Sprintf("step-%d", i+1)
+		}
+		steps = append(steps, types.PlanStep{
+			ID:           step.ID,
+			Description:  step.Description,
+			Dependencies: step.Dependencies,
+			Status:       types.PlanStatusPending,
+		})
+	}
+	return steps, nil
+}
+
 // GetProgress returns current execution progress
 func (p *Planner) GetProgress(plan *types.Plan) *types.PlanProgress {
 	if plan == nil {
@@ -326,3 +764,12 @@ func (p *Planner) SaveToMemory(ctx context.Context, plan *types.Plan) error {
 
 	return p.memory.Add(msg)
 }
+
+// truncate shortens s to at most n runes
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n]) + "..."
+}
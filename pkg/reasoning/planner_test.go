@@ -0,0 +1,377 @@
+package reasoning
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/taipm/go-llm-agent/pkg/logger"
+	"github.com/taipm/go-llm-agent/pkg/types"
+)
+
+func newTestPlanner(responses ...string) *Planner {
+	return NewPlanner(&MockProvider{responses: responses}, nil, &logger.NoopLogger{}, false)
+}
+
+func newTestPlan(steps ...types.PlanStep) *types.Plan {
+	for i := range steps {
+		steps[i].Status = types.PlanStatusPending
+		if steps[i].Description == "" {
+			steps[i].Description = steps[i].ID
+		}
+	}
+	return &types.Plan{ID: "plan", Goal: "test goal", Status: types.PlanStatusPending, Steps: steps}
+}
+
+func step(id string, deps ...string) types.PlanStep {
+	return types.PlanStep{ID: id, Dependencies: deps}
+}
+
+func TestValidatePlan(t *testing.T) {
+	tests := []struct {
+		name    string
+		plan    *types.Plan
+		wantErr string
+	}{
+		{"valid", newTestPlan(step("a"), step("b", "a"), step("c", "a", "b")), ""},
+		{"empty ID", newTestPlan(step("a"), step("")), "step 2 has no ID"},
+		{"duplicate", newTestPlan(step("a"), step("a")), `duplicate step ID "a"`},
+		{"unknown dependency", newTestPlan(step("a", "x")), `depends on unknown step "x"`},
+		{"self dependency", newTestPlan(step("a", "a")), "dependency cycle: a -> a"},
+		{"cycle", newTestPlan(step("a", "c"), step("b", "a"), step("c", "b"), step("d")), "dependency cycle: a -> c -> b -> a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePlan(tt.plan)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected valid plan, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestDecomposeGoalRejectsCycle(t *testing.T) {
+	planner := newTestPlanner(`{"steps":[{"id":"step-1","description":"A","dependencies":["step-2"]},{"id":"step-2","description":"B","dependencies":["step-1"]}]}`)
+	_, err := planner.DecomposeGoal(context.Background(), "goal")
+	if err == nil || !strings.Contains(err.Error(), "dependency cycle") {
+		t.Errorf("Expected dependency cycle error, got %v", err)
+	}
+}
+
+func TestExecutePlanParallel(t *testing.T) {
+	plan := newTestPlan(step("a"), step("b"), step("c"), step("d", "a", "b", "c"))
+
+	var mu sync.Mutex
+	active, maxActive := 0, 0
+	finished := make(map[string]bool)
+	executor := func(ctx context.Context, task string) (interface{}, error) {
+		mu.Lock()
+		if task == "d" && len(finished) != 3 {
+			t.Errorf("Expected d to start after its dependencies, finished: %v", finished)
+		}
+		active++
+		if active > maxActive {
+			maxActive = active
+		}
+		mu.Unlock()
+
+		time.Sleep(20 * time.Millisecond)
+
+		mu.Lock()
+		active--
+		finished[task] = true
+		mu.Unlock()
+		return "done " + task, nil
+	}
+
+	config := DefaultExecutionConfig()
+	config.MaxParallel = 2
+	if err := newTestPlanner().ExecutePlanWithConfig(context.Background(), plan, executor, config); err != nil {
+		t.Fatalf("ExecutePlanWithConfig failed: %v", err)
+	}
+	if maxActive != 2 {
+		t.Errorf("Expected 2 steps running at once, got %d", maxActive)
+	}
+	if plan.Status != types.PlanStatusCompleted {
+		t.Errorf("Expected completed plan, got %s", plan.Status)
+	}
+	if plan.Steps[3].Result != "done d" {
+		t.Errorf("Expected result of d, got %v", plan.Steps[3].Result)
+	}
+}
+
+func TestExecutePlanSequential(t *testing.T) {
+	plan := newTestPlan(step("c", "b"), step("a"), step("b"))
+	var order []string
+	executor := func(ctx context.Context, task string) (interface{}, error) {
+		order = append(order, task)
+		return nil, nil
+	}
+
+	config := DefaultExecutionConfig()
+	config.MaxParallel = 1
+	if err := newTestPlanner().ExecutePlanWithConfig(context.Background(), plan, executor, config); err != nil {
+		t.Fatalf("ExecutePlanWithConfig failed: %v", err)
+	}
+	if strings.Join(order, ",") != "a,b,c" {
+		t.Errorf("Expected order a,b,c, got %v", order)
+	}
+}
+
+func TestExecutePlanRetry(t *testing.T) {
+	errFlaky := errors.New("flaky")
+	errFatal := errors.New("fatal")
+
+	tests := []struct {
+		name         string
+		policy       RetryPolicy
+		failures     int
+		failWith     error
+		wantErr      bool
+		wantAttempts int
+	}{
+		{"no retries", RetryPolicy{MaxAttempts: 1}, 1, errFlaky, true, 1},
+		{"succeeds after retries", RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond}, 2, errFlaky, false, 3},
+		{"attempts exhausted", RetryPolicy{MaxAttempts: 2}, 5, errFlaky, true, 2},
+		{"not retryable", RetryPolicy{MaxAttempts: 3, Retryable: func(err error) bool { return err != errFatal }}, 5, errFatal, true, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := newTestPlan(step("a"), step("b", "a"))
+			calls := 0
+			executor := func(ctx context.Context, task string) (interface{}, error) {
+				if task == "a" {
+					calls++
+					if calls <= tt.failures {
+						return nil, tt.failWith
+					}
+				}
+				return "ok", nil
+			}
+
+			config := DefaultExecutionConfig()
+			config.StepRetry = map[string]RetryPolicy{"a": tt.policy}
+			err := newTestPlanner().ExecutePlanWithConfig(context.Background(), plan, executor, config)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if err != nil && !errors.Is(err, tt.failWith) {
+				t.Errorf("Expected wrapped %v, got %v", tt.failWith, err)
+			}
+			if plan.Steps[0].Attempts != tt.wantAttempts {
+				t.Errorf("Expected %d attempts, got %d", tt.wantAttempts, plan.Steps[0].Attempts)
+			}
+			if tt.wantErr {
+				if plan.Status != types.PlanStatusFailed || plan.Steps[1].Status != types.PlanStatusPending {
+					t.Errorf("Expected failed plan with b pending, got %s / %s", plan.Status, plan.Steps[1].Status)
+				}
+			}
+		})
+	}
+}
+
+func TestExecutePlanReplan(t *testing.T) {
+	plan := newTestPlan(step("fetch"), step("parse", "fetch"), step("report", "parse"))
+	executor := func(ctx context.Context, task string) (interface{}, error) {
+		if task == "parse" {
+			return nil, errors.New("unsupported format")
+		}
+		return "ok " + task, nil
+	}
+
+	var failedSeen types.PlanStatus
+	config := DefaultExecutionConfig()
+	config.MaxReplans = 1
+	config.Replan = func(ctx context.Context, p *types.Plan) ([]types.PlanStep, error) {
+		failedSeen = p.Steps[1].Status
+		return []types.PlanStep{
+			{ID: "convert", Description: "convert", Dependencies: []string{"fetch"}},
+			{ID: "report-2", Description: "report", Dependencies: []string{"convert"}},
+		}, nil
+	}
+
+	if err := newTestPlanner().ExecutePlanWithConfig(context.Background(), plan, executor, config); err != nil {
+		t.Fatalf("Expected replanning to recover, got %v", err)
+	}
+	if failedSeen != types.PlanStatusFailed {
+		t.Fatal("Expected replanner to see the failed step")
+	}
+
+	var got []string
+	for _, s := range plan.Steps {
+		got = append(got, s.ID+"="+string(s.Status))
+	}
+	want := "fetch=completed,parse=skipped,convert=completed,report-2=completed"
+	if strings.Join(got, ",") != want {
+		t.Errorf("Expected %s, got %s", want, strings.Join(got, ","))
+	}
+	if plan.Status != types.PlanStatusCompleted || plan.Revisions != 1 {
+		t.Errorf("Expected completed plan with 1 revision, got %s with %d", plan.Status, plan.Revisions)
+	}
+	if plan.Steps[1].Error != "unsupported format" {
+		t.Error("Expected skipped step to keep its error")
+	}
+}
+
+func TestExecutePlanReplanLimit(t *testing.T) {
+	plan := newTestPlan(step("a"))
+	executor := func(ctx context.Context, task string) (interface{}, error) {
+		return nil, errors.New("always fails")
+	}
+
+	replans := 0
+	config := DefaultExecutionConfig()
+	config.MaxReplans = 2
+	config.Replan = func(ctx context.Context, p *types.Plan) ([]types.PlanStep, error) {
+		replans++
+		return []types.PlanStep{{ID: "retry-" + string(rune('0'+replans)), Description: "again"}}, nil
+	}
+
+	err := newTestPlanner().ExecutePlanWithConfig(context.Background(), plan, executor, config)
+	if err == nil || !strings.Contains(err.Error(), "always fails") {
+		t.Errorf("Expected failure after replans, got %v", err)
+	}
+	if replans != 2 || plan.Revisions != 2 {
+		t.Errorf("Expected 2 replans, got %d (revisions %d)", replans, plan.Revisions)
+	}
+	if plan.Status != types.PlanStatusFailed {
+		t.Errorf("Expected failed plan, got %s", plan.Status)
+	}
+}
+
+func TestReplanWithLLM(t *testing.T) {
+	planner := newTestPlanner("```json\n{\"steps\":[{\"id\":\"step-r1\",\"description\":\"Use the backup API\",\"dependencies\":[\"step-1\"]}]}\n```")
+	plan := newTestPlan(step("step-1"), step("step-2", "step-1"))
+	executor := func(ctx context.Context, task string) (interface{}, error) {
+		if task == "step-2" {
+			return nil, errors.New("API down")
+		}
+		return "ok", nil
+	}
+
+	config := DefaultExecutionConfig()
+	config.MaxReplans = 1
+	if err := planner.ExecutePlanWithConfig(context.Background(), plan, executor, config); err != nil {
+		t.Fatalf("ExecutePlanWithConfig failed: %v", err)
+	}
+	if len(plan.Steps) != 3 || plan.Steps[2].Description != "Use the backup API" {
+		t.Errorf("Expected LLM step appended, got %+v", plan.Steps)
+	}
+}
+
+func TestReplanRejectsInvalidSteps(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps []types.PlanStep
+	}{
+		{"empty", nil},
+		{"depends on failed step", []types.PlanStep{{ID: "x", Dependencies: []string{"b"}}}},
+		{"reuses completed ID", []types.PlanStep{{ID: "a"}}},
+		{"cycle", []types.PlanStep{{ID: "x", Dependencies: []string{"y"}}, {ID: "y", Dependencies: []string{"x"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan := newTestPlan(step("a"), step("b", "a"))
+			plan.Steps[0].Status = types.PlanStatusCompleted
+			plan.Steps[1].Status = types.PlanStatusFailed
+
+			replan := func(context.Context, *types.Plan) ([]types.PlanStep, error) { return tt.steps, nil }
+			if err := newTestPlanner().applyReplan(context.Background(), plan, replan); err == nil {
+				t.Error("Expected error for invalid revision")
+			}
+			if len(plan.Steps) != 2 || plan.Revisions != 0 {
+				t.Errorf("Expected plan unchanged, got %+v", plan.Steps)
+			}
+		})
+	}
+}
+
+func TestExecutePlanCheckpointAndResume(t *testing.T) {
+	store := NewInMemoryPlanStore()
+	plan := newTestPlan(step("fetch"), step("summarize", "fetch"), step("publish", "summarize"))
+	plan.Steps[0].Description = "Fetch the data"
+
+	runs := make(map[string]int)
+	var input *StepInput
+	executor := func(ctx context.Context, task string) (interface{}, error) {
+		in, ok := StepInputFromContext(ctx)
+		if !ok {
+			t.Fatal("Expected step input in context")
+		}
+		runs[in.Step.ID]++
+		if in.Step.ID == "summarize" {
+			input = in
+			if runs["summarize"] == 1 {
+				return nil, errors.New("model overloaded")
+			}
+		}
+		return "result of " + in.Step.ID, nil
+	}
+
+	planner := newTestPlanner().WithStore(store)
+	if err := planner.ExecutePlanWithConfig(context.Background(), plan, executor, DefaultExecutionConfig()); err == nil {
+		t.Fatal("Expected first run to fail")
+	}
+
+	saved, err := store.Load(context.Background(), plan.ID)
+	if err != nil {
+		t.Fatalf("Expected checkpoint, got %v", err)
+	}
+	if saved.Status != types.PlanStatusFailed || saved.Steps[0].Status != types.PlanStatusCompleted || saved.Steps[1].Error != "model overloaded" {
+		t.Errorf("Expected checkpoint of failed run, got %+v", saved)
+	}
+
+	// A new planner (e.g. after a restart) resumes from the store
+	resumed, err := newTestPlanner().WithStore(store).ResumePlan(context.Background(), plan.ID, executor, DefaultExecutionConfig())
+	if err != nil {
+		t.Fatalf("ResumePlan failed: %v", err)
+	}
+	if resumed.Status != types.PlanStatusCompleted {
+		t.Errorf("Expected completed plan, got %s", resumed.Status)
+	}
+	if runs["fetch"] != 1 || runs["summarize"] != 2 || runs["publish"] != 1 {
+		t.Errorf("Expected completed steps to be skipped, got runs %v", runs)
+	}
+	if resumed.Steps[1].Error != "" {
+		t.Errorf("Expected error cleared after success, got %q", resumed.Steps[1].Error)
+	}
+
+	// Dependency results are passed to the executor
+	if len(input.Dependencies) != 1 || input.Dependencies[0].Result != "result of fetch" {
+		t.Fatalf("Expected fetch result as dependency, got %+v", input.Dependencies)
+	}
+	prompt := input.Prompt()
+	for _, want := range []string{"Overall goal: test goal", "- Fetch the data: result of fetch", "Current step: summarize"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("Expected prompt to contain %q, got:\n%s", want, prompt)
+		}
+	}
+
+	final, _ := store.Load(context.Background(), plan.ID)
+	if final.Status != types.PlanStatusCompleted {
+		t.Errorf("Expected final checkpoint to be completed, got %s", final.Status)
+	}
+
+	if _, err := newTestPlanner().ResumePlan(context.Background(), plan.ID, executor, DefaultExecutionConfig()); err == nil {
+		t.Error("Expected error without a store")
+	}
+	if _, err := planner.ResumePlan(context.Background(), "missing", executor, DefaultExecutionConfig()); !errors.Is(err, ErrPlanNotFound) {
+		t.Errorf("Expected ErrPlanNotFound, got %v", err)
+	}
+}
+
+func TestStepInputPromptWithoutDependencies(t *testing.T) {
+	in := &StepInput{Goal: "goal", Step: types.PlanStep{Description: "Do it"}}
+	if in.Prompt() != "Do it" {
+		t.Errorf("Expected plain description, got %q", in.Prompt())
+	}
+}
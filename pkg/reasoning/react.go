@@ -12,6 +12,19 @@ import (
 	"github.com/taipm/go-llm-agent/pkg/types"
 )
 
+// ReActMode selects how the agent asks the model for actions
+type ReActMode string
+
+const (
+	// ReActModeAuto uses native tool calls and switches to the text protocol
+	// when the provider reports that the model does not support tools
+	ReActModeAuto ReActMode = "auto"
+	// ReActModeNative uses the provider's native tool calls only
+	ReActModeNative ReActMode = "native"
+	// ReActModeText uses the Thought/Action/Action Input text protocol
+	ReActModeText ReActMode = "text"
+)
+
 // ReActAgent implements the ReAct (Reasoning + Acting) pattern
 // Paper: https://arxiv.org/abs/2210.03629
 type ReActAgent struct {
@@ -22,6 +35,14 @@ type ReActAgent struct {
 	steps    []types.ReActStep
 	maxSteps int
 	verbose  bool
+	mode     ReActMode
+
+	// Conversation of the current query
+	query    string
+	messages []types.Message
+
+	// Set in auto mode once the model turned out not to support tools
+	textFallback bool
 }
 
 // NewReActAgent creates a new ReAct agent
@@ -37,6 +58,7 @@ func NewReActAgent(provider types.LLMProvider, memory types.Memory, maxSteps int
 		steps:    make([]types.ReActStep, 0, maxSteps),
 		maxSteps: maxSteps,
 		verbose:  true,
+		mode:     ReActModeAuto,
 	}
 }
 
@@ -54,6 +76,13 @@ func (r *ReActAgent) WithLogger(log logger.Logger) *ReActAgent {
 	return r
 }
 
+// WithMode sets how actions are requested from the model (default:
+// ReActModeAuto)
+func (r *ReActAgent) WithMode(mode ReActMode) *ReActAgent {
+	r.mode = mode
+	return r
+}
+
 // SetVerbose controls whether ReAct steps are printed to stdout
 func (r *ReActAgent) SetVerbose(verbose bool) {
 	r.verbose = verbose
@@ -64,13 +93,15 @@ func (r *ReActAgent) GetSteps() []types.ReActStep {
 	return r.steps
 }
 
-// ClearSteps resets the reasoning history
+// ClearSteps resets the reasoning history and the conversation
 func (r *ReActAgent) ClearSteps() {
 	r.steps = make([]types.ReActStep, 0, r.maxSteps)
+	r.query = ""
+	r.messages = nil
 }
 
 // buildReActPrompt creates a prompt that guides the LLM to think explicitly
-func (r *ReActAgent) buildReActPrompt(query string, previousSteps []types.ReActStep) string {
+func (r *ReActAgent) buildReActPrompt(query string) string {
 	var prompt strings.Builder
 
 	prompt.WriteString("You are a helpful AI assistant using the ReAct (Reasoning + Acting) pattern.\n\n")
@@ -87,29 +118,12 @@ func (r *ReActAgent) buildReActPrompt(query string, previousSteps []types.ReActS
 		prompt.WriteString("- When you have enough information, provide your final answer\n\n")
 	}
 
-	// Show previous steps if any
-	if len(previousSteps) > 0 {
-		prompt.WriteString("Previous steps:\n")
-		for _, step := range previousSteps {
-			if step.Action != "Answer" {
-				prompt.WriteString(fmt.Sprintf("- Used %s → got result: %s\n", step.Action, step.Observation))
-			}
-		}
-		prompt.WriteString("\n")
-	}
-
 	prompt.WriteString(fmt.Sprintf("Question: %s\n\n", query))
 
-	if len(previousSteps) > 0 {
-		prompt.WriteString("Based on the previous results, what should you do next?\n")
-		prompt.WriteString("- If you need another calculation, call a tool\n")
-		prompt.WriteString("- If you have enough information, provide your final answer\n")
-	} else {
-		prompt.WriteString("Think step-by-step:\n")
-		prompt.WriteString("1. What calculations do you need?\n")
-		prompt.WriteString("2. Call the appropriate tool for the FIRST step\n")
-		prompt.WriteString("3. After seeing the result, decide the next action\n")
-	}
+	prompt.WriteString("Think step-by-step:\n")
+	prompt.WriteString("1. What calculations do you need?\n")
+	prompt.WriteString("2. Call the appropriate tool for the FIRST step\n")
+	prompt.WriteString("3. After seeing the result, decide the next action\n")
 
 	return prompt.String()
 }
@@ -133,174 +147,232 @@ func (r *ReActAgent) buildToolDefinitions() []types.ToolDefinition {
 	return defs
 }
 
-// parseReActResponse extracts Thought, Action, Observation, Reflection from LLM response
-func (r *ReActAgent) parseReActResponse(response string) (thought, action, observation, reflection string) {
-	lines := strings.Split(response, "\n")
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-
-		if strings.HasPrefix(strings.ToLower(line), "thought:") {
-			thought = strings.TrimSpace(strings.TrimPrefix(line, "Thought:"))
-			thought = strings.TrimSpace(strings.TrimPrefix(thought, "thought:"))
-		} else if strings.HasPrefix(strings.ToLower(line), "action:") {
-			action = strings.TrimSpace(strings.TrimPrefix(line, "Action:"))
-			action = strings.TrimSpace(strings.TrimPrefix(action, "action:"))
-		} else if strings.HasPrefix(strings.ToLower(line), "observation:") {
-			observation = strings.TrimSpace(strings.TrimPrefix(line, "Observation:"))
-			observation = strings.TrimSpace(strings.TrimPrefix(observation, "observation:"))
-		} else if strings.HasPrefix(strings.ToLower(line), "reflection:") {
-			reflection = strings.TrimSpace(strings.TrimPrefix(line, "Reflection:"))
-			reflection = strings.TrimSpace(strings.TrimPrefix(reflection, "reflection:"))
+// buildTextPrompt creates the first message of the text protocol, used for
+// models without native tool calls
+func (r *ReActAgent) buildTextPrompt(query string) string {
+	var prompt strings.Builder
+
+	prompt.WriteString("You are a helpful AI assistant using the ReAct (Reasoning + Acting) pattern.\n\n")
+
+	if len(r.registry.All()) > 0 {
+		prompt.WriteString("You have access to these tools:\n")
+		for _, tool := range r.registry.All() {
+			prompt.WriteString(fmt.Sprintf("- %s: %s\n", tool.Name(), tool.Description()))
+			if summary := tools.SummarizeParameters(tool.Parameters()); summary != "" {
+				prompt.WriteString(fmt.Sprintf("  Parameters: %s\n", summary))
+			}
 		}
+		prompt.WriteString("\nTo use a tool, respond with exactly:\n\n")
+		prompt.WriteString("Thought: what you need to do next\n")
+		prompt.WriteString("Action: the tool name\n")
+		prompt.WriteString("Action Input: the tool arguments as a JSON object\n\n")
+		prompt.WriteString("Then stop and wait for the Observation with the result. Use ONE tool at a time.\n")
+		prompt.WriteString("When you have enough information, respond with:\n\n")
+	} else {
+		prompt.WriteString("Respond with:\n\n")
 	}
+	prompt.WriteString("Thought: how you reached the answer\n")
+	prompt.WriteString("Final Answer: the answer to the question\n\n")
 
-	// If no explicit structure, treat entire response as thought
-	if thought == "" && action == "" && observation == "" && reflection == "" {
-		thought = response
-		action = "Answer"
-	}
+	prompt.WriteString(fmt.Sprintf("Question: %s\n", query))
+	return prompt.String()
+}
 
-	return
+// textAction is a model response in the text protocol
+type textAction struct {
+	Thought   string
+	Tool      string                 // Empty for a final answer
+	Arguments map[string]interface{} // Tool arguments
+	Answer    string
 }
 
-// executeAction parses and executes a tool call from the action string
-// Expected formats:
-//   - "tool_name(param1, param2)"
-//   - "tool_name({'key': 'value'})"
-//   - "tool_name"
-func (r *ReActAgent) executeAction(ctx context.Context, action string) (string, error) {
-	// Simple parsing: extract tool name and parameters
-	action = strings.TrimSpace(action)
-
-	// Try to find tool name (before parenthesis or the whole string)
-	toolName := action
-	paramsStr := ""
-
-	if idx := strings.Index(action, "("); idx != -1 {
-		toolName = strings.TrimSpace(action[:idx])
-		if endIdx := strings.LastIndex(action, ")"); endIdx > idx {
-			paramsStr = strings.TrimSpace(action[idx+1 : endIdx])
+// parseTextAction parses a text protocol response. Tool arguments are read
+// as one JSON object, which may span several lines, either from an
+// "Action Input:" line or from "Action: tool({...})". A response without
+// Action or Final Answer is treated as the answer. Malformed arguments are
+// returned as an error together with the tool name, never guessed.
+func parseTextAction(response string) (textAction, error) {
+	actionStart, actionEnd := findLabel(response, "Action:")
+	answerStart, answerEnd := findLabel(response, "Final Answer:")
+
+	if actionStart < 0 || (answerStart >= 0 && answerStart < actionStart) {
+		if answerStart < 0 {
+			text := strings.TrimSpace(response)
+			return textAction{Thought: stripLabel(text, "Thought:"), Answer: stripLabel(text, "Thought:")}, nil
 		}
+		return textAction{
+			Thought: stripLabel(strings.TrimSpace(response[:answerStart]), "Thought:"),
+			Answer:  strings.TrimSpace(response[answerEnd:]),
+		}, nil
 	}
 
-	// Get tool from registry
-	tool := r.registry.Get(toolName)
-	if tool == nil {
-		return "", fmt.Errorf("tool '%s' not found in registry", toolName)
-	}
+	action := textAction{Thought: stripLabel(strings.TrimSpace(response[:actionStart]), "Thought:")}
 
-	r.logger.Info("🔧 Executing tool: %s", toolName)
-	if paramsStr != "" {
-		r.logger.Debug("   Parameters: %s", paramsStr)
+	line := response[actionEnd:]
+	if idx := strings.IndexByte(line, '\n'); idx >= 0 {
+		line = line[:idx]
 	}
 
-	// Parse parameters (simple JSON or map[string]interface{})
-	params := make(map[string]interface{})
-	if paramsStr != "" {
-		// Try to parse as JSON first
-		if err := json.Unmarshal([]byte(paramsStr), &params); err != nil {
-			// If not JSON, treat as simple key-value
-			r.logger.Debug("   Using raw parameter string")
-			params["query"] = paramsStr
+	var input string
+	if idx := strings.IndexByte(line, '('); idx >= 0 {
+		// Action: tool({...}) where the arguments may continue on later lines
+		action.Tool = line[:idx]
+		input = strings.TrimSpace(response[actionEnd+idx+1:])
+		if strings.HasPrefix(input, ")") {
+			input = ""
+		}
+	} else {
+		action.Tool = line
+		if start, end := findLabel(response[actionEnd:], "Action Input:"); start >= 0 {
+			input = strings.TrimSpace(response[actionEnd+end:])
 		}
 	}
+	action.Tool = strings.Trim(strings.TrimSpace(action.Tool), "`'\"* ")
 
-	// Execute tool
-	result, err := tool.Execute(ctx, params)
+	if input == "" {
+		action.Arguments = map[string]interface{}{}
+		return action, nil
+	}
+	args, err := decodeArguments(input)
 	if err != nil {
-		return "", fmt.Errorf("tool execution failed: %w", err)
+		return action, fmt.Errorf("arguments for %s must be a JSON object: %w", action.Tool, err)
 	}
+	action.Arguments = args
+	return action, nil
+}
 
-	// Convert result to string
-	resultStr := fmt.Sprintf("%v", result)
-	r.logger.Debug("   Result: %s", resultStr)
+// decodeArguments reads the JSON object at the start of input, ignoring a
+// Markdown code fence and any text after the object
+func decodeArguments(input string) (map[string]interface{}, error) {
+	input = strings.TrimPrefix(input, "```json")
+	input = strings.TrimPrefix(input, "```")
+	input = strings.TrimSpace(input)
+
+	var args map[string]interface{}
+	decoder := json.NewDecoder(strings.NewReader(input))
+	decoder.UseNumber()
+	if err := decoder.Decode(&args); err != nil {
+		return nil, err
+	}
+	if args == nil {
+		return nil, fmt.Errorf("got null")
+	}
 
-	return resultStr, nil
+	// Numbers as float64/int64 like arguments of native tool calls
+	for key, value := range args {
+		args[key] = normalizeNumbers(value)
+	}
+	return args, nil
 }
 
-// Think performs one iteration of ReAct reasoning
-func (r *ReActAgent) Think(ctx context.Context, query string) (*types.ReActStep, error) {
-	iteration := len(r.steps) + 1
-
-	// Build prompt with previous steps
-	prompt := r.buildReActPrompt(query, r.steps)
+// normalizeNumbers converts json.Number values to float64
+func normalizeNumbers(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		f, _ := v.Float64()
+		return f
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = normalizeNumbers(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalizeNumbers(item)
+		}
+	}
+	return value
+}
 
-	// Prepare tools for LLM
-	var toolDefs []types.ToolDefinition
-	if r.registry != nil && len(r.registry.All()) > 0 {
-		toolDefs = r.buildToolDefinitions()
+// findLabel finds the first line starting with label (case-insensitive) and
+// returns the offsets of that line and of the text after the label, or -1
+func findLabel(text, label string) (int, int) {
+	offset := 0
+	for _, line := range strings.SplitAfter(text, "\n") {
+		trimmed := strings.TrimLeft(line, " \t*")
+		if len(trimmed) >= len(label) && strings.EqualFold(trimmed[:len(label)], label) {
+			end := offset + len(line) - len(trimmed) + len(label)
+			for end < len(text) && text[end] == '*' {
+				end++ // Markdown bold: **Action:**
+			}
+			return offset, end
+		}
+		offset += len(line)
 	}
+	return -1, -1
+}
 
-	// Call LLM with function calling
-	messages := []types.Message{
-		{
-			Role:    types.RoleUser,
-			Content: prompt,
-		},
+// stripLabel removes a leading label (case-insensitive) from text
+func stripLabel(text, label string) string {
+	if len(text) >= len(label) && strings.EqualFold(text[:len(label)], label) {
+		return strings.TrimSpace(text[len(label):])
 	}
+	return text
+}
 
-	response, err := r.provider.Chat(ctx, messages, &types.ChatOptions{
-		Temperature: 0.7,
-		MaxTokens:   1000,
-		Tools:       toolDefs,
-	})
+// actionAnswer is the Action of a step that answers the question
+const actionAnswer = "Answer"
+
+// executeAction runs a tool through the registry, which validates the
+// arguments against the tool's schema, and returns the observation for the
+// model. Failures are reported as observations so the model can react.
+func (r *ReActAgent) executeAction(ctx context.Context, call types.FunctionCall) string {
+	r.logger.Info("🔧 Executing tool: %s", call.Name)
+	r.logger.Debug("   Parameters: %v", call.Arguments)
+
+	result, err := r.registry.Execute(ctx, call.Name, call.Arguments)
 	if err != nil {
-		return nil, fmt.Errorf("LLM call failed: %w", err)
-	}
-
-	thought := ""
-	action := "Answer"
-	observation := ""
-	reflection := ""
-	finalAnswer := ""
-
-	// Check if LLM wants to call a tool
-	if len(response.ToolCalls) > 0 {
-		// Execute the first tool call
-		toolCall := response.ToolCalls[0]
-		action = toolCall.Function.Name
-
-		r.logger.Info("🔧 LLM requested tool: %s", action)
-		r.logger.Debug("   Parameters: %v", toolCall.Function.Arguments)
-
-		// Execute tool
-		tool := r.registry.Get(action)
-		if tool != nil {
-			result, err := tool.Execute(ctx, toolCall.Function.Arguments)
-			if err != nil {
-				r.logger.Warn("⚠️  Tool execution failed: %v", err)
-				observation = fmt.Sprintf("Tool execution failed: %v", err)
-			} else {
-				observation = fmt.Sprintf("%v", result)
-				r.logger.Info("✅ Tool executed: %s = %s", action, observation)
-			}
-		} else {
-			observation = fmt.Sprintf("Tool '%s' not found", action)
-			r.logger.Warn("⚠️  %s", observation)
-		}
+		r.logger.Warn("⚠️  Tool execution failed: %v", err)
+		return fmt.Sprintf("Tool execution failed: %v", err)
+	}
 
-		// Use LLM's thinking as thought
-		thought = response.Content
-		reflection = fmt.Sprintf("Tool %s returned: %s", action, observation)
-	} else {
-		// No tool call - this is the final answer
-		thought = response.Content
-		action = "Answer"
-		finalAnswer = response.Content
-		observation = finalAnswer
-		reflection = "Ready to provide answer"
+	observation := formatObservation(result)
+	r.logger.Info("✅ Tool executed: %s = %s", call.Name, observation)
+	return observation
+}
+
+// formatObservation renders a tool result for the model, using JSON for
+// structured values
+func formatObservation(result interface{}) string {
+	switch v := result.(type) {
+	case string:
+		return v
+	case nil:
+		return "null"
+	}
+	if data, err := json.Marshal(result); err == nil {
+		return string(data)
 	}
+	return fmt.Sprintf("%v", result)
+}
 
-	// Create ReAct step
-	step := types.ReActStep{
-		Iteration:   iteration,
-		Thought:     thought,
-		Action:      action,
-		Observation: observation,
-		Reflection:  reflection,
-		Timestamp:   time.Now(),
+// Think performs one iteration of ReAct reasoning. Iterations for the same
+// query continue one conversation with the model; a new query starts over.
+func (r *ReActAgent) Think(ctx context.Context, query string) (*types.ReActStep, error) {
+	if query != r.query || len(r.messages) == 0 {
+		r.ClearSteps()
+		r.query = query
+		r.messages = []types.Message{{Role: types.RoleUser, Content: r.initialPrompt(query)}}
 	}
+	iteration := len(r.steps) + 1
+
+	var step types.ReActStep
+	var err error
+	if r.usesTextProtocol() {
+		step, err = r.thinkText(ctx, iteration)
+	} else {
+		step, err = r.thinkNative(ctx, iteration)
+		if err != nil && r.mode == ReActModeAuto && toolsUnsupported(err) {
+			r.logger.Warn("⚠️  Model does not support native tool calls, switching to the text protocol: %v", err)
+			r.textFallback = true
+			r.messages = []types.Message{{Role: types.RoleUser, Content: r.initialPrompt(query)}}
+			step, err = r.thinkText(ctx, iteration)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	step.Iteration = iteration
+	step.Timestamp = time.Now()
 
 	// Store step
 	r.steps = append(r.steps, step)
@@ -318,7 +390,7 @@ func (r *ReActAgent) Think(ctx context.Context, query string) (*types.ReActStep,
 		}
 		msg := types.Message{
 			Role:     types.RoleAssistant,
-			Content:  fmt.Sprintf("ReAct Step %d: %s", iteration, thought),
+			Content:  fmt.Sprintf("ReAct Step %d: %s", iteration, step.Thought),
 			Metadata: metadata,
 		}
 		r.memory.Add(msg)
@@ -327,6 +399,146 @@ func (r *ReActAgent) Think(ctx context.Context, query string) (*types.ReActStep,
 	return &step, nil
 }
 
+// usesTextProtocol reports whether actions are requested as text
+func (r *ReActAgent) usesTextProtocol() bool {
+	return r.mode == ReActModeText || (r.mode == ReActModeAuto && r.textFallback)
+}
+
+// initialPrompt returns the first message of a conversation for query
+func (r *ReActAgent) initialPrompt(query string) string {
+	if r.usesTextProtocol() {
+		return r.buildTextPrompt(query)
+	}
+	return r.buildReActPrompt(query)
+}
+
+// thinkNative asks the model for the next action using native tool calls
+func (r *ReActAgent) thinkNative(ctx context.Context, iteration int) (types.ReActStep, error) {
+	response, err := r.provider.Chat(ctx, r.messages, &types.ChatOptions{
+		Temperature: 0.7,
+		MaxTokens:   1000,
+		Tools:       r.buildToolDefinitions(),
+	})
+	if err != nil {
+		return types.ReActStep{}, fmt.Errorf("LLM call failed: %w", err)
+	}
+
+	if len(response.ToolCalls) == 0 {
+		// Some models ignore the tools and write the action as text instead
+		if action, parseErr := parseTextAction(response.Content); action.Tool != "" && r.registry.Has(action.Tool) {
+			return r.textStep(ctx, response.Content, action, parseErr), nil
+		}
+		return answerStep(response.Content, response.Content), nil
+	}
+
+	// Every tool call needs a result message, matched by ID
+	calls := make([]types.ToolCall, len(response.ToolCalls))
+	copy(calls, response.ToolCalls)
+	for i := range calls {
+		if calls[i].ID == "" {
+			calls[i].ID = fmt.Sprintf("call_%d_%d", iteration, i+1)
+		}
+		if calls[i].Type == "" {
+			calls[i].Type = "function"
+		}
+	}
+	r.messages = append(r.messages, types.Message{Role: types.RoleAssistant, Content: response.Content, ToolCalls: calls})
+
+	names := make([]string, len(calls))
+	observations := make([]string, len(calls))
+	for i, call := range calls {
+		r.logger.Info("🔧 LLM requested tool: %s", call.Function.Name)
+		names[i] = call.Function.Name
+		observations[i] = r.executeAction(ctx, call.Function)
+		r.messages = append(r.messages, types.Message{Role: types.RoleTool, Content: observations[i], ToolID: call.ID})
+	}
+
+	observation := observations[0]
+	if len(calls) > 1 {
+		lines := make([]string, len(calls))
+		for i := range calls {
+			lines[i] = fmt.Sprintf("%s: %s", names[i], observations[i])
+		}
+		observation = strings.Join(lines, "\n")
+	}
+
+	action := strings.Join(names, ", ")
+	return types.ReActStep{
+		Thought:     response.Content,
+		Action:      action,
+		Observation: observation,
+		Reflection:  fmt.Sprintf("Tool %s returned: %s", action, observation),
+	}, nil
+}
+
+// thinkText asks the model for the next action using the text protocol
+func (r *ReActAgent) thinkText(ctx context.Context, iteration int) (types.ReActStep, error) {
+	response, err := r.provider.Chat(ctx, r.messages, &types.ChatOptions{
+		Temperature: 0.7,
+		MaxTokens:   1000,
+		Stop:        []string{"\nObservation:"},
+	})
+	if err != nil {
+		return types.ReActStep{}, fmt.Errorf("LLM call failed: %w", err)
+	}
+
+	action, parseErr := parseTextAction(response.Content)
+	return r.textStep(ctx, response.Content, action, parseErr), nil
+}
+
+// textStep executes an action written in the text protocol and adds the
+// exchange to the conversation
+func (r *ReActAgent) textStep(ctx context.Context, content string, action textAction, parseErr error) types.ReActStep {
+	if action.Tool == "" {
+		return answerStep(action.Thought, action.Answer)
+	}
+
+	var observation string
+	if parseErr != nil {
+		observation = fmt.Sprintf("Invalid action: %v.", parseErr)
+		if tool := r.registry.Get(action.Tool); tool != nil {
+			if summary := tools.SummarizeParameters(tool.Parameters()); summary != "" {
+				observation += " Expected parameters: " + summary + "."
+			}
+		}
+		r.logger.Warn("⚠️  %s", observation)
+	} else {
+		observation = r.executeAction(ctx, types.FunctionCall{Name: action.Tool, Arguments: action.Arguments})
+	}
+
+	r.messages = append(r.messages,
+		types.Message{Role: types.RoleAssistant, Content: strings.TrimSpace(content)},
+		types.Message{Role: types.RoleUser, Content: "Observation: " + observation},
+	)
+
+	return types.ReActStep{
+		Thought:     action.Thought,
+		Action:      action.Tool,
+		Observation: observation,
+		Reflection:  fmt.Sprintf("Tool %s returned: %s", action.Tool, observation),
+	}
+}
+
+// answerStep is the final step of a ReAct loop
+func answerStep(thought, answer string) types.ReActStep {
+	return types.ReActStep{
+		Thought:     thought,
+		Action:      actionAnswer,
+		Observation: answer,
+		Reflection:  "Ready to provide answer",
+	}
+}
+
+// toolsUnsupported reports whether a provider error says that the model
+// cannot use tools (e.g. Ollama's "does not support tools")
+func toolsUnsupported(err error) bool {
+	msg := strings.ToLower(err.Error())
+	if !strings.Contains(msg, "tool") && !strings.Contains(msg, "function call") {
+		return false
+	}
+	return strings.Contains(msg, "not support") || strings.Contains(msg, "unsupported") || strings.Contains(msg, "not supported")
+}
+
 // printStep prints a ReAct step to stdout
 func (r *ReActAgent) printStep(step types.ReActStep) {
 	fmt.Printf("\n=== ReAct Iteration %d ===\n", step.Iteration)
@@ -351,26 +563,13 @@ func (r *ReActAgent) Solve(ctx context.Context, query string) (string, error) {
 			return "", fmt.Errorf("reasoning failed at step %d: %w", i+1, err)
 		}
 
-		// Check if agent wants to provide final answer
-		if strings.Contains(strings.ToLower(step.Action), "answer") ||
-			strings.Contains(strings.ToLower(step.Action), "respond") ||
-			strings.Contains(strings.ToLower(step.Action), "final") {
-			// Extract final answer
-			finalAnswer := step.Thought
-			if step.Reflection != "" {
-				finalAnswer = step.Reflection
-			}
-
+		// Check if agent provided the final answer
+		if step.Action == actionAnswer {
 			if r.verbose {
-				fmt.Printf("✅ Final Answer: %s\n", finalAnswer)
+				fmt.Printf("✅ Final Answer: %s\n", step.Observation)
 			}
-
-			return finalAnswer, nil
+			return step.Observation, nil
 		}
-
-		// In real implementation, this is where tool execution would happen
-		// For now, we simulate observation from the action
-		step.Observation = fmt.Sprintf("Executed: %s", step.Action)
 	}
 
 	return "", fmt.Errorf("max iterations (%d) reached without final answer", r.maxSteps)
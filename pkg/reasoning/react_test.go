@@ -2,8 +2,13 @@ package reasoning
 
 import (
 	"context"
+	"errors"
+	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/taipm/go-llm-agent/pkg/logger"
+	"github.com/taipm/go-llm-agent/pkg/tools"
 	"github.com/taipm/go-llm-agent/pkg/types"
 )
 
@@ -73,18 +78,206 @@ func TestNewReActAgent(t *testing.T) {
 	}
 }
 
-func TestParseReActResponse(t *testing.T) {
-	provider := &MockProvider{}
-	memory := &MockMemory{}
-	agent := NewReActAgent(provider, memory, 5)
-	
-	response := "Thought: I need to check\nAction: get_weather"
-	thought, action, _, _ := agent.parseReActResponse(response)
-	
-	if thought != "I need to check" {
-		t.Errorf("Thought: got %q, want 'I need to check'", thought)
+// scriptedProvider returns queued responses and records every request
+type scriptedProvider struct {
+	responses []*types.Response
+	errs      []error
+	messages  [][]types.Message
+	options   []*types.ChatOptions
+}
+
+func (p *scriptedProvider) Chat(ctx context.Context, messages []types.Message, options *types.ChatOptions) (*types.Response, error) {
+	i := len(p.messages)
+	p.messages = append(p.messages, append([]types.Message(nil), messages...))
+	p.options = append(p.options, options)
+	if i < len(p.errs) && p.errs[i] != nil {
+		return nil, p.errs[i]
+	}
+	if i < len(p.responses) && p.responses[i] != nil {
+		return p.responses[i], nil
+	}
+	return &types.Response{Content: "Final answer"}, nil
+}
+
+func (p *scriptedProvider) Stream(ctx context.Context, messages []types.Message, options *types.ChatOptions, handler types.StreamHandler) error {
+	return nil
+}
+
+type addInput struct {
+	A float64 `json:"a" tool:"required"`
+	B float64 `json:"b" tool:"required"`
+}
+
+func newReActTestAgent(provider types.LLMProvider, calls *int) *ReActAgent {
+	add := tools.NewTyped("add", "Add two numbers", func(ctx context.Context, in addInput) (float64, error) {
+		*calls++
+		return in.A + in.B, nil
+	})
+	agent := NewReActAgent(provider, nil, 5).WithLogger(&logger.NoopLogger{}).WithTools(add)
+	agent.SetVerbose(false)
+	return agent
+}
+
+func TestReActNativeToolCalls(t *testing.T) {
+	provider := &scriptedProvider{responses: []*types.Response{
+		{ToolCalls: []types.ToolCall{{Function: types.FunctionCall{Name: "add", Arguments: map[string]interface{}{"a": 2, "b": "3"}}}}},
+		{Content: "The sum is 5"},
+	}}
+	calls := 0
+	agent := newReActTestAgent(provider, &calls)
+
+	answer, err := agent.Solve(context.Background(), "What is 2 + 3?")
+	if err != nil {
+		t.Fatalf("Solve failed: %v", err)
+	}
+	if answer != "The sum is 5" {
+		t.Errorf("Expected final answer, got %q", answer)
+	}
+	if calls != 1 {
+		t.Errorf("Expected tool to run once, got %d", calls)
+	}
+	if len(provider.options[0].Tools) != 1 {
+		t.Errorf("Expected native tool definitions, got %+v", provider.options[0].Tools)
+	}
+
+	// The second request continues the conversation with the tool result
+	second := provider.messages[1]
+	if len(second) != 3 {
+		t.Fatalf("Expected question, tool call and result, got %d messages", len(second))
+	}
+	if second[1].Role != types.RoleAssistant || len(second[1].ToolCalls) != 1 || second[1].ToolCalls[0].ID == "" {
+		t.Errorf("Expected assistant tool call with an ID, got %+v", second[1])
+	}
+	if second[2].Role != types.RoleTool || second[2].Content != "5" || second[2].ToolID != second[1].ToolCalls[0].ID {
+		t.Errorf("Expected tool result 5 for the call, got %+v", second[2])
+	}
+
+	steps := agent.GetSteps()
+	if len(steps) != 2 || steps[0].Action != "add" || steps[0].Observation != "5" || steps[1].Action != "Answer" {
+		t.Errorf("Expected add then Answer steps, got %+v", steps)
+	}
+}
+
+func TestReActFallsBackToTextProtocol(t *testing.T) {
+	provider := &scriptedProvider{
+		errs: []error{errors.New(`registry.ollama.ai/library/gemma:2b does not support tools`)},
+		responses: []*types.Response{
+			nil,
+			{Content: "Thought: I should add the numbers\nAction: add\nAction Input: {\n  \"a\": 2,\n  \"b\": 3\n}"},
+			{Content: "Thought: I know it\nFinal Answer: 5"},
+		},
+	}
+	calls := 0
+	agent := newReActTestAgent(provider, &calls)
+
+	answer, err := agent.Solve(context.Background(), "What is 2 + 3?")
+	if err != nil {
+		t.Fatalf("Solve failed: %v", err)
+	}
+	if answer != "5" || calls != 1 {
+		t.Errorf("Expected answer 5 after one tool call, got %q (%d calls)", answer, calls)
+	}
+	for i, opts := range provider.options[1:] {
+		if len(opts.Tools) != 0 || len(opts.Stop) == 0 {
+			t.Errorf("Expected text request %d without tools and with stop sequence, got %+v", i+1, opts)
+		}
+	}
+	last := provider.messages[2]
+	if got := last[len(last)-1]; got.Role != types.RoleUser || got.Content != "Observation: 5" {
+		t.Errorf("Expected observation message, got %+v", got)
+	}
+	if steps := agent.GetSteps(); steps[0].Thought != "I should add the numbers" {
+		t.Errorf("Expected thought from text protocol, got %q", steps[0].Thought)
+	}
+
+	// Later queries keep using the text protocol
+	agent.Solve(context.Background(), "Again?")
+	if len(provider.options[3].Tools) != 0 {
+		t.Error("Expected text protocol to stick after fallback")
+	}
+
+	// Native mode reports the error instead
+	native := newReActTestAgent(&scriptedProvider{errs: provider.errs}, &calls).WithMode(ReActModeNative)
+	if _, err := native.Solve(context.Background(), "What is 2 + 3?"); err == nil {
+		t.Error("Expected error in native mode")
+	}
+}
+
+func TestReActInvalidTextArguments(t *testing.T) {
+	provider := &scriptedProvider{responses: []*types.Response{
+		{Content: "Action: add(2, 3)"},
+		{Content: "Final Answer: 5"},
+	}}
+	calls := 0
+	agent := newReActTestAgent(provider, &calls).WithMode(ReActModeText)
+
+	if _, err := agent.Solve(context.Background(), "What is 2 + 3?"); err != nil {
+		t.Fatalf("Solve failed: %v", err)
+	}
+	if calls != 0 {
+		t.Error("Expected tool not to run with malformed arguments")
+	}
+	observation := agent.GetSteps()[0].Observation
+	for _, want := range []string{"must be a JSON object", "Expected parameters: a (number, required)"} {
+		if !strings.Contains(observation, want) {
+			t.Errorf("Expected observation to contain %q, got %q", want, observation)
+		}
+	}
+}
+
+func TestParseTextAction(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		want     textAction
+		wantErr  bool
+	}{
+		{
+			name:     "action input on several lines",
+			response: "Thought: need weather\nAction: get_weather\nAction Input: {\n  \"city\": \"Hanoi\",\n  \"days\": 3\n}\nObservation: made up",
+			want:     textAction{Thought: "need weather", Tool: "get_weather", Arguments: map[string]interface{}{"city": "Hanoi", "days": 3.0}},
+		},
+		{
+			name:     "call syntax across lines",
+			response: "Action: search({\n  \"query\": \"go (golang)\",\n  \"filters\": {\"lang\": [\"en\"]}\n})",
+			want:     textAction{Tool: "search", Arguments: map[string]interface{}{"query": "go (golang)", "filters": map[string]interface{}{"lang": []interface{}{"en"}}}},
+		},
+		{
+			name:     "code fence and markdown",
+			response: "**Action:** `lookup`\n**Action Input:** ```json\n{\"id\": 7}\n```",
+			want:     textAction{Tool: "lookup", Arguments: map[string]interface{}{"id": 7.0}},
+		},
+		{
+			name:     "no arguments",
+			response: "Thought: check\nAction: now()",
+			want:     textAction{Thought: "check", Tool: "now", Arguments: map[string]interface{}{}},
+		},
+		{
+			name:     "final answer",
+			response: "Thought: done\nFinal Answer: 42\nis the answer",
+			want:     textAction{Thought: "done", Answer: "42\nis the answer"},
+		},
+		{
+			name:     "plain text",
+			response: "It is sunny.",
+			want:     textAction{Thought: "It is sunny.", Answer: "It is sunny."},
+		},
+		{
+			name:     "invalid arguments",
+			response: "Action: add\nAction Input: a=1, b=2",
+			want:     textAction{Tool: "add"},
+			wantErr:  true,
+		},
 	}
-	if action != "get_weather" {
-		t.Errorf("Action: got %q, want 'get_weather'", action)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseTextAction(tt.response)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %+v, got %+v", tt.want, got)
+			}
+		})
 	}
 }
@@ -16,22 +16,45 @@ import (
 // Reflector implements self-reflection and verification for agent answers
 // It can verify facts, calculations, and consistency before returning answers
 type Reflector struct {
-	provider types.LLMProvider
-	memory   types.Memory
-	registry *tools.Registry
-	logger   logger.Logger
-	verbose  bool
+	provider  types.LLMProvider
+	memory    types.Memory
+	registry  *tools.Registry
+	logger    logger.Logger
+	verbose   bool
+	verifiers []Verifier
+
+	confidence          ConfidenceFunc // nil: defaultConfidence
+	correctionThreshold float64
+	maxCorrections      int
 }
 
-// NewReflector creates a new Reflector instance
+// NewReflector creates a new Reflector instance with the fact, calculation
+// and consistency verifiers
 func NewReflector(provider types.LLMProvider, memory types.Memory) *Reflector {
-	return &Reflector{
-		provider: provider,
-		memory:   memory,
-		registry: tools.NewRegistry(),
-		logger:   logger.NewConsoleLogger(),
-		verbose:  true,
+	r := &Reflector{
+		provider:            provider,
+		memory:              memory,
+		registry:            tools.NewRegistry(),
+		logger:              logger.NewConsoleLogger(),
+		verbose:             true,
+		correctionThreshold: 0.7,
+		maxCorrections:      1,
+	}
+	r.verifiers = []Verifier{
+		NewVerifier(VerifierFactCheck, "Checks factual claims such as names, dates, places and definitions",
+			func(ctx context.Context, req VerificationRequest) types.VerificationStep {
+				return r.VerifyFacts(ctx, req.Question, req.Answer)
+			}),
+		NewVerifier(VerifierCalculation, "Re-computes numbers, arithmetic and formulas",
+			func(ctx context.Context, req VerificationRequest) types.VerificationStep {
+				return r.VerifyCalculation(ctx, req.Question, req.Answer)
+			}),
+		NewVerifier(VerifierConsistency, "Checks logical consistency with the conversation history",
+			func(ctx context.Context, req VerificationRequest) types.VerificationStep {
+				return r.CheckConsistency(ctx, req.Question, req.Answer)
+			}),
 	}
+	return r
 }
 
 // WithTools adds tools to the reflector for verification
@@ -42,6 +65,52 @@ func (r *Reflector) WithTools(toolList ...tools.Tool) *Reflector {
 	return r
 }
 
+// WithVerifiers registers verifiers; a verifier replaces a registered one
+// with the same name
+func (r *Reflector) WithVerifiers(verifiers ...Verifier) *Reflector {
+	for _, v := range verifiers {
+		replaced := false
+		for i, existing := range r.verifiers {
+			if existing.Name() == v.Name() {
+				r.verifiers[i] = v
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			r.verifiers = append(r.verifiers, v)
+		}
+	}
+	return r
+}
+
+// ClearVerifiers removes all verifiers, including the built-in ones
+func (r *Reflector) ClearVerifiers() *Reflector {
+	r.verifiers = nil
+	return r
+}
+
+// Verifiers returns the registered verifiers
+func (r *Reflector) Verifiers() []Verifier {
+	return append([]Verifier(nil), r.verifiers...)
+}
+
+// WithConfidence sets how verification results are aggregated into a
+// confidence score (default: WeightedConfidence(nil, 0.05, 0.2))
+func (r *Reflector) WithConfidence(fn ConfidenceFunc) *Reflector {
+	r.confidence = fn
+	return r
+}
+
+// WithCorrection sets the confidence below which answers are corrected
+// (default 0.7) and how many correction rounds may run (default 1, 0
+// disables correction)
+func (r *Reflector) WithCorrection(threshold float64, maxCorrections int) *Reflector {
+	r.correctionThreshold = threshold
+	r.maxCorrections = maxCorrections
+	return r
+}
+
 // WithLogger sets a custom logger
 func (r *Reflector) WithLogger(log logger.Logger) *Reflector {
 	r.logger = log
@@ -54,7 +123,11 @@ func (r *Reflector) SetVerbose(verbose bool) {
 }
 
 // Reflect performs self-reflection on an answer to verify its correctness
-// Returns a ReflectionCheck with verification results and potentially corrected answer
+// Returns a ReflectionCheck with verification results and potentially corrected answer.
+// While the confidence is below the correction threshold the answer is
+// corrected and verified again, up to the configured number of corrections.
+// Concerns and Verifications accumulate over all rounds; Confidence refers
+// to FinalAnswer.
 func (r *Reflector) Reflect(ctx context.Context, question string, initialAnswer string) (*types.ReflectionCheck, error) {
 	if r.verbose {
 		r.logger.Info("🔍 Starting self-reflection on answer...")
@@ -71,20 +144,67 @@ func (r *Reflector) Reflect(ctx context.Context, question string, initialAnswer
 		CreatedAt:     time.Now(),
 	}
 
-	// Step 1: Identify concerns about the answer
-	concerns, err := r.identifyConcerns(ctx, question, initialAnswer)
+	round, err := r.verifyAnswer(ctx, question, initialAnswer)
 	if err != nil {
 		return reflection, fmt.Errorf("failed to identify concerns: %w", err)
 	}
-	reflection.Concerns = concerns
+	r.addRound(reflection, round)
+
+	// Correct the answer while confidence is low
+	for reflection.Confidence < r.correctionThreshold && reflection.Corrections < r.maxCorrections {
+		correctedAnswer, err := r.correctAnswer(ctx, question, reflection.FinalAnswer, round)
+		if err != nil || correctedAnswer == "" || correctedAnswer == reflection.FinalAnswer {
+			break
+		}
+
+		if r.verbose {
+			r.logger.Info("🔧 Answer was corrected based on verification")
+			r.logger.Info(fmt.Sprintf("   Original: %s", reflection.FinalAnswer))
+			r.logger.Info(fmt.Sprintf("   Corrected: %s", correctedAnswer))
+		}
+		reflection.FinalAnswer = correctedAnswer
+		reflection.WasCorrected = true
+		reflection.Corrections++
+
+		// Verify the corrected answer before deciding on another round
+		round, err = r.verifyAnswer(ctx, question, correctedAnswer)
+		if err != nil {
+			r.logger.Warn("⚠️  Failed to verify corrected answer: %v", err)
+			break
+		}
+		r.addRound(reflection, round)
+	}
+
+	if r.verbose {
+		r.logger.Info(fmt.Sprintf("📊 Final confidence: %.2f", reflection.Confidence))
+	}
+
+	return reflection, nil
+}
+
+// verifyAnswer identifies concerns about an answer, routes them to verifiers
+// and computes the confidence of the answer
+func (r *Reflector) verifyAnswer(ctx context.Context, question string, answer string) (*types.ReflectionCheck, error) {
+	round := &types.ReflectionCheck{
+		Question:      question,
+		InitialAnswer: answer,
+		FinalAnswer:   answer,
+		Verifications: make([]types.VerificationStep, 0),
+	}
+
+	// Step 1: Identify concerns about the answer
+	concerns, err := r.identifyConcerns(ctx, question, answer)
+	if err != nil {
+		return nil, err
+	}
+	round.Concerns = concerns
 
 	if len(concerns) == 0 {
-		// No concerns - high confidence
-		reflection.Confidence = 0.95
 		if r.verbose {
 			r.logger.Info("✅ No concerns identified - answer looks good")
 		}
-		return reflection, nil
+		round.Confidence = r.CalculateConfidence(round)
+		return round, nil
 	}
 
 	if r.verbose {
@@ -94,52 +214,176 @@ func (r *Reflector) Reflect(ctx context.Context, question string, initialAnswer
 		}
 	}
 
-	// Step 2: Perform verifications based on concerns
-	for _, concern := range concerns {
-		var verification types.VerificationStep
-
-		// Determine verification method based on concern type
-		if r.needsFactCheck(concern) {
-			verification = r.VerifyFacts(ctx, question, initialAnswer)
-		} else if r.needsCalculationCheck(concern) {
-			verification = r.VerifyCalculation(ctx, question, initialAnswer)
-		} else {
-			verification = r.CheckConsistency(ctx, question, initialAnswer)
-		}
+	// Step 2: Run the verifiers chosen for each concern
+	routes := r.routeConcerns(ctx, question, answer, concerns)
+	for i, concern := range concerns {
+		for _, verifier := range routes[i] {
+			verification := verifier.Verify(ctx, VerificationRequest{Question: question, Answer: answer, Concern: concern})
+			if verification.Method == "" {
+				verification.Method = verifier.Name()
+			}
+			if verification.Query == "" {
+				verification.Query = question
+			}
+			verification.Concern = concern
 
-		reflection.Verifications = append(reflection.Verifications, verification)
+			round.Verifications = append(round.Verifications, verification)
 
-		if r.verbose {
-			status := "✅ PASSED"
-			if !verification.Passed {
-				status = "❌ FAILED"
+			if r.verbose {
+				status := "✅ PASSED"
+				if !verification.Passed {
+					status = "❌ FAILED"
+				}
+				r.logger.Info(fmt.Sprintf("   %s: %s", verification.Method, status))
 			}
-			r.logger.Info(fmt.Sprintf("   %s: %s", verification.Method, status))
 		}
 	}
 
 	// Step 3: Calculate confidence based on verification results
-	reflection.Confidence = r.CalculateConfidence(reflection)
-
-	// Step 4: If confidence is low, attempt to correct the answer
-	if reflection.Confidence < 0.7 {
-		correctedAnswer, err := r.correctAnswer(ctx, question, initialAnswer, reflection)
-		if err == nil && correctedAnswer != "" && correctedAnswer != initialAnswer {
-			reflection.FinalAnswer = correctedAnswer
-			reflection.WasCorrected = true
-			if r.verbose {
-				r.logger.Info("🔧 Answer was corrected based on verification")
-				r.logger.Info(fmt.Sprintf("   Original: %s", initialAnswer))
-				r.logger.Info(fmt.Sprintf("   Corrected: %s", correctedAnswer))
+	round.Confidence = r.CalculateConfidence(round)
+	return round, nil
+}
+
+// addRound records the concerns and verifications of a verification round
+func (r *Reflector) addRound(reflection *types.ReflectionCheck, round *types.ReflectionCheck) {
+	reflection.Concerns = append(reflection.Concerns, round.Concerns...)
+	reflection.Verifications = append(reflection.Verifications, round.Verifications...)
+	reflection.Confidence = round.Confidence
+}
+
+// routeConcerns chooses the verifiers for each concern. With several
+// verifiers the LLM picks them from their descriptions; concerns it leaves
+// out (or all of them, if routing fails) are routed by keywords.
+func (r *Reflector) routeConcerns(ctx context.Context, question string, answer string, concerns []string) [][]Verifier {
+	routes := make([][]Verifier, len(concerns))
+	if len(r.verifiers) == 0 {
+		return routes
+	}
+	if len(r.verifiers) == 1 {
+		for i := range routes {
+			routes[i] = r.verifiers
+		}
+		return routes
+	}
+
+	if err := r.routeWithLLM(ctx, question, answer, concerns, routes); err != nil {
+		r.logger.Debug("Concern routing failed, using keywords: %v", err)
+	}
+	for i, concern := range concerns {
+		if len(routes[i]) == 0 {
+			routes[i] = []Verifier{r.routeByKeywords(concern)}
+		}
+	}
+	return routes
+}
+
+// routeWithLLM asks the LLM which verifiers should check each concern
+func (r *Reflector) routeWithLLM(ctx context.Context, question string, answer string, concerns []string, routes [][]Verifier) error {
+	var concernList, verifierList strings.Builder
+	for i, concern := range concerns {
+		concernList.WriteString(fmt.Sprintf("%d. %s\n", i+1, concern))
+	}
+	for _, v := range r.verifiers {
+		verifierList.WriteString(fmt.Sprintf("- %s: %s\n", v.Name(), v.Description()))
+	}
+
+	prompt := fmt.Sprintf(`You are routing review concerns to verification methods.
+
+Question: %s
+Answer: %s
+
+Concerns:
+%s
+Verification methods:
+%s
+For each concern, choose the methods that can best check it (usually one).
+
+Respond in JSON format:
+{"routes": [{"concern": 1, "methods": ["method_name"]}]}
+
+Only return valid JSON, no additional text.`, question, answer, concernList.String(), verifierList.String())
+
+	messages := []types.Message{
+		{Role: types.RoleUser, Content: prompt},
+	}
+
+	response, err := r.provider.Chat(ctx, messages, &types.ChatOptions{
+		Temperature: 0.1,
+		MaxTokens:   300,
+	})
+	if err != nil {
+		return err
+	}
+
+	content := strings.TrimSpace(response.Content)
+	start, end := strings.Index(content, "{"), strings.LastIndex(content, "}")
+	if start < 0 || end <= start {
+		return fmt.Errorf("no JSON in routing response")
+	}
+	var parsed struct {
+		Routes []struct {
+			Concern int      `json:"concern"`
+			Methods []string `json:"methods"`
+		} `json:"routes"`
+	}
+	if err := json.Unmarshal([]byte(content[start:end+1]), &parsed); err != nil {
+		return fmt.Errorf("invalid routing JSON: %w", err)
+	}
+
+	for _, route := range parsed.Routes {
+		if route.Concern < 1 || route.Concern > len(concerns) {
+			continue
+		}
+		for _, name := range route.Methods {
+			v := r.verifier(name)
+			if v == nil || containsVerifier(routes[route.Concern-1], v) {
+				continue
 			}
+			routes[route.Concern-1] = append(routes[route.Concern-1], v)
 		}
 	}
+	return nil
+}
 
-	if r.verbose {
-		r.logger.Info(fmt.Sprintf("📊 Final confidence: %.2f", reflection.Confidence))
+// routeByKeywords picks a verifier from the wording of a concern, falling
+// back to the consistency check and then to the first verifier
+func (r *Reflector) routeByKeywords(concern string) Verifier {
+	if r.needsFactCheck(concern) {
+		if v := r.verifier(VerifierFactCheck); v != nil {
+			return v
+		}
+	}
+	if r.needsCalculationCheck(concern) {
+		if v := r.verifier(VerifierCalculation); v != nil {
+			return v
+		}
 	}
+	if v := r.verifier(VerifierConsistency); v != nil {
+		return v
+	}
+	return r.verifiers[0]
+}
 
-	return reflection, nil
+// verifier returns the registered verifier with the given name
+func (r *Reflector) verifier(name string) Verifier {
+	for _, v := range r.verifiers {
+		if v.Name() == name {
+			return v
+		}
+	}
+	return nil
+}
+
+// containsVerifier reports whether list has a verifier named like v.
+// Verifiers are compared by name: interface values holding uncomparable
+// types (structs with func, slice or map fields) would panic with ==.
+func containsVerifier(list []Verifier, v Verifier) bool {
+	for _, existing := range list {
+		if existing.Name() == v.Name() {
+			return true
+		}
+	}
+	return false
 }
 
 // identifyConcerns asks the LLM to identify potential issues with the answer
@@ -245,7 +489,7 @@ func (r *Reflector) VerifyFacts(ctx context.Context, question string, answer str
 				if err == nil {
 					step.Result = result
 					// Simple heuristic: if result contains similar info, fact is verified
-					step.Passed = r.resultSupportsAnswer(result, answer)
+					step.Passed = resultSupportsAnswer(result, answer)
 					return step
 				}
 			}
@@ -397,40 +641,10 @@ Analysis:`, contextBuilder.String(), question, answer)
 
 // CalculateConfidence computes overall confidence score based on verifications
 func (r *Reflector) CalculateConfidence(reflection *types.ReflectionCheck) float64 {
-	if len(reflection.Verifications) == 0 {
-		// No verifications performed
-		if len(reflection.Concerns) == 0 {
-			return 0.95 // No concerns, high confidence
-		}
-		return 0.5 // Had concerns but couldn't verify
-	}
-
-	// Count passed vs failed verifications
-	passed := 0
-	for _, v := range reflection.Verifications {
-		if v.Passed {
-			passed++
-		}
+	if r.confidence != nil {
+		return r.confidence(reflection)
 	}
-
-	total := len(reflection.Verifications)
-	baseConfidence := float64(passed) / float64(total)
-
-	// Adjust based on number of concerns
-	concernsPenalty := 0.05 * float64(len(reflection.Concerns))
-	if concernsPenalty > 0.2 {
-		concernsPenalty = 0.2 // Cap penalty
-	}
-
-	confidence := baseConfidence - concernsPenalty
-	if confidence < 0.0 {
-		confidence = 0.0
-	}
-	if confidence > 1.0 {
-		confidence = 1.0
-	}
-
-	return confidence
+	return defaultConfidence(reflection)
 }
 
 // correctAnswer attempts to generate a corrected answer based on verification failures
@@ -480,7 +694,9 @@ func (r *Reflector) extractKeyFact(answer string) string {
 	return answer
 }
 
-func (r *Reflector) resultSupportsAnswer(result interface{}, answer string) bool {
+// resultSupportsAnswer reports whether a tool result mentions enough of the
+// answer's key words
+func resultSupportsAnswer(result interface{}, answer string) bool {
 	// Simple heuristic: convert result to string and check for overlap
 	resultStr := fmt.Sprintf("%v", result)
 	resultLower := strings.ToLower(resultStr)
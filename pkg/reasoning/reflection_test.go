@@ -0,0 +1,264 @@
+package reasoning
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/taipm/go-llm-agent/pkg/logger"
+	"github.com/taipm/go-llm-agent/pkg/tools"
+	"github.com/taipm/go-llm-agent/pkg/types"
+)
+
+func newReflectionTestReflector(provider types.LLMProvider) *Reflector {
+	r := NewReflector(provider, nil).WithLogger(&logger.NoopLogger{})
+	r.SetVerbose(false)
+	return r
+}
+
+// recordingVerifier passes or fails every request and records the concerns
+func recordingVerifier(name string, passed bool, concerns *[]string) Verifier {
+	return NewVerifier(name, "Checks "+name, func(ctx context.Context, req VerificationRequest) types.VerificationStep {
+		*concerns = append(*concerns, req.Concern)
+		return types.VerificationStep{Passed: passed}
+	})
+}
+
+func TestReflectorRoutesConcernsToVerifiers(t *testing.T) {
+	provider := &scriptedProvider{responses: []*types.Response{
+		{Content: "1. The schema field is missing\n2. The tests may fail\n3. Something else"},
+		{Content: `Routing: {"routes": [{"concern": 1, "methods": ["schema"]}, {"concern": 2, "methods": ["unit_tests", "unit_tests"]}, {"concern": 3, "methods": ["unknown"]}]}`},
+	}}
+
+	var schemaConcerns, unitConcerns []string
+	r := newReflectionTestReflector(provider).
+		ClearVerifiers().
+		WithVerifiers(
+			recordingVerifier("schema", true, &schemaConcerns),
+			recordingVerifier("unit_tests", true, &unitConcerns),
+		)
+
+	reflection, err := r.Reflect(context.Background(), "Build the report", "Report")
+	if err != nil {
+		t.Fatalf("Reflect failed: %v", err)
+	}
+
+	// Unrouted concerns fall back to the first verifier
+	if len(schemaConcerns) != 2 || schemaConcerns[0] != "The schema field is missing" || schemaConcerns[1] != "Something else" {
+		t.Errorf("Expected concerns 1 and 3 routed to schema, got %v", schemaConcerns)
+	}
+	if len(unitConcerns) != 1 || unitConcerns[0] != "The tests may fail" {
+		t.Errorf("Expected concern 2 routed to unit_tests once, got %v", unitConcerns)
+	}
+	if len(reflection.Verifications) != 3 {
+		t.Fatalf("Expected 3 verifications, got %d", len(reflection.Verifications))
+	}
+	if v := reflection.Verifications[0]; v.Method != "schema" || v.Concern != "The schema field is missing" || v.Query != "Build the report" {
+		t.Errorf("Expected method, query and concern on verification, got %+v", v)
+	}
+	if !strings.Contains(provider.messages[1][0].Content, "unit_tests: Checks unit_tests") {
+		t.Errorf("Expected verifier descriptions in routing prompt, got %q", provider.messages[1][0].Content)
+	}
+	if reflection.WasCorrected {
+		t.Error("Expected no correction when all verifications pass")
+	}
+}
+
+func TestReflectorSingleVerifierSkipsRouting(t *testing.T) {
+	provider := &scriptedProvider{responses: []*types.Response{
+		{Content: "- Concern A\n- Concern B"},
+	}}
+
+	var concerns []string
+	r := newReflectionTestReflector(provider).
+		ClearVerifiers().
+		WithVerifiers(recordingVerifier("only", true, &concerns))
+
+	if _, err := r.Reflect(context.Background(), "Q", "A"); err != nil {
+		t.Fatalf("Reflect failed: %v", err)
+	}
+	if len(concerns) != 2 {
+		t.Errorf("Expected both concerns verified, got %v", concerns)
+	}
+	if len(provider.messages) != 1 {
+		t.Errorf("Expected only the concerns request, got %d requests", len(provider.messages))
+	}
+}
+
+func TestReflectorIteratesCorrections(t *testing.T) {
+	provider := &scriptedProvider{responses: []*types.Response{
+		{Content: "1. Wrong value"}, // Concerns about the initial answer
+		{Content: "Answer v2"},      // First correction
+		{Content: "1. Still wrong"}, // Concerns about v2
+		{Content: "Answer v3"},      // Second correction
+		{Content: "No concerns identified"},
+	}}
+
+	// Only the third answer passes
+	check := NewVerifier("check", "Checks the answer", func(ctx context.Context, req VerificationRequest) types.VerificationStep {
+		return types.VerificationStep{Passed: req.Answer == "Answer v3"}
+	})
+	r := newReflectionTestReflector(provider).
+		ClearVerifiers().
+		WithVerifiers(check).
+		WithCorrection(0.7, 3)
+
+	reflection, err := r.Reflect(context.Background(), "Q", "Answer v1")
+	if err != nil {
+		t.Fatalf("Reflect failed: %v", err)
+	}
+
+	if reflection.FinalAnswer != "Answer v3" || reflection.Corrections != 2 || !reflection.WasCorrected {
+		t.Errorf("Expected Answer v3 after 2 corrections, got %q after %d", reflection.FinalAnswer, reflection.Corrections)
+	}
+	if reflection.InitialAnswer != "Answer v1" {
+		t.Errorf("Expected initial answer to be kept, got %q", reflection.InitialAnswer)
+	}
+	if len(reflection.Concerns) != 2 || len(reflection.Verifications) != 2 {
+		t.Errorf("Expected concerns and verifications of all rounds, got %d and %d", len(reflection.Concerns), len(reflection.Verifications))
+	}
+	if reflection.Confidence != 0.95 {
+		t.Errorf("Expected confidence of the final answer 0.95, got %.2f", reflection.Confidence)
+	}
+}
+
+func TestReflectorMaxCorrections(t *testing.T) {
+	provider := &scriptedProvider{responses: []*types.Response{
+		{Content: "1. Wrong"},
+		{Content: "Answer v2"},
+		{Content: "1. Still wrong"},
+	}}
+
+	failing := NewVerifier("check", "Checks the answer", func(ctx context.Context, req VerificationRequest) types.VerificationStep {
+		return types.VerificationStep{Passed: false}
+	})
+	r := newReflectionTestReflector(provider).ClearVerifiers().WithVerifiers(failing)
+
+	reflection, err := r.Reflect(context.Background(), "Q", "Answer v1")
+	if err != nil {
+		t.Fatalf("Reflect failed: %v", err)
+	}
+	if reflection.Corrections != 1 || reflection.FinalAnswer != "Answer v2" {
+		t.Errorf("Expected a single correction by default, got %d (%q)", reflection.Corrections, reflection.FinalAnswer)
+	}
+	if len(provider.messages) != 3 {
+		t.Errorf("Expected the corrected answer to be verified once, got %d requests", len(provider.messages))
+	}
+}
+
+// schemaVerifier is a value type that cannot be compared with ==
+type schemaVerifier struct {
+	required map[string]bool
+	checked  *int
+}
+
+func (v schemaVerifier) Name() string        { return "schema" }
+func (v schemaVerifier) Description() string { return "Checks required fields" }
+
+func (v schemaVerifier) Verify(ctx context.Context, req VerificationRequest) types.VerificationStep {
+	*v.checked++
+	return types.VerificationStep{Passed: true}
+}
+
+func TestReflectorRoutesToUncomparableVerifiers(t *testing.T) {
+	provider := &scriptedProvider{responses: []*types.Response{
+		{Content: "1. A field may be missing"},
+		{Content: `{"routes": [{"concern": 1, "methods": ["schema", "schema"]}]}`},
+	}}
+
+	checked := 0
+	var other []string
+	r := newReflectionTestReflector(provider).
+		ClearVerifiers().
+		WithVerifiers(
+			schemaVerifier{required: map[string]bool{"id": true}, checked: &checked},
+			recordingVerifier("other", true, &other),
+		)
+
+	if _, err := r.Reflect(context.Background(), "Q", "A"); err != nil {
+		t.Fatalf("Reflect failed: %v", err)
+	}
+	if checked != 1 || len(other) != 0 {
+		t.Errorf("Expected the schema verifier to run once, got %d (other: %v)", checked, other)
+	}
+}
+
+func TestWithVerifiersReplacesByName(t *testing.T) {
+	r := newReflectionTestReflector(&scriptedProvider{})
+	if len(r.Verifiers()) != 3 {
+		t.Fatalf("Expected 3 built-in verifiers, got %d", len(r.Verifiers()))
+	}
+
+	custom := NewVerifier(VerifierFactCheck, "Looks facts up in the catalog", nil)
+	r.WithVerifiers(custom)
+
+	verifiers := r.Verifiers()
+	if len(verifiers) != 3 || verifiers[0].Description() != "Looks facts up in the catalog" {
+		t.Errorf("Expected fact_check to be replaced, got %d verifiers", len(verifiers))
+	}
+}
+
+func TestWeightedConfidence(t *testing.T) {
+	verifications := []types.VerificationStep{
+		{Method: "schema", Passed: true},
+		{Method: "fact_check", Passed: false},
+	}
+
+	tests := []struct {
+		name       string
+		confidence ConfidenceFunc
+		reflection *types.ReflectionCheck
+		expected   float64
+	}{
+		{"no concerns", defaultConfidence, &types.ReflectionCheck{}, 0.95},
+		{"unverified concerns", defaultConfidence, &types.ReflectionCheck{Concerns: []string{"x"}}, 0.5},
+		{"equal weights", WeightedConfidence(nil, 0, 0), &types.ReflectionCheck{Verifications: verifications}, 0.5},
+		{"weighted", WeightedConfidence(map[string]float64{"schema": 3}, 0, 0), &types.ReflectionCheck{Verifications: verifications}, 0.75},
+		{"penalty capped", WeightedConfidence(map[string]float64{"fact_check": 0}, 0.1, 0.15),
+			&types.ReflectionCheck{Concerns: []string{"a", "b", "c"}, Verifications: verifications}, 0.85},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.confidence(tt.reflection)
+			if got < tt.expected-1e-9 || got > tt.expected+1e-9 {
+				t.Errorf("Expected %.2f, got %.2f", tt.expected, got)
+			}
+		})
+	}
+}
+
+type lookupInput struct {
+	Query string `json:"query" tool:"required"`
+}
+
+func TestToolVerifier(t *testing.T) {
+	lookup := tools.NewTyped("catalog_lookup", "Looks up products", func(ctx context.Context, in lookupInput) (string, error) {
+		return "Product " + in.Query + " costs 42 dollars", nil
+	})
+	v := &ToolVerifier{
+		Tool: lookup,
+		Arguments: func(req VerificationRequest) map[string]interface{} {
+			return map[string]interface{}{"query": req.Question}
+		},
+	}
+
+	if v.Name() != "catalog_lookup" || v.Description() != "Looks up products" {
+		t.Errorf("Expected tool name and description, got %q and %q", v.Name(), v.Description())
+	}
+
+	step := v.Verify(context.Background(), VerificationRequest{Question: "widget", Answer: "costs 42 dollars"})
+	if !step.Passed || step.Error != nil || step.Method != "catalog_lookup" {
+		t.Errorf("Expected passing verification, got %+v", step)
+	}
+
+	v.Check = func(req VerificationRequest, result interface{}) bool { return false }
+	if step := v.Verify(context.Background(), VerificationRequest{Question: "widget", Answer: "x"}); step.Passed {
+		t.Error("Expected custom check to fail the verification")
+	}
+
+	v.Arguments = nil
+	if step := v.Verify(context.Background(), VerificationRequest{}); step.Error == nil {
+		t.Error("Expected error without Arguments")
+	}
+}
@@ -0,0 +1,163 @@
+package reasoning
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/taipm/go-llm-agent/pkg/tools"
+	"github.com/taipm/go-llm-agent/pkg/types"
+)
+
+// Names of the verifiers every Reflector starts with
+const (
+	VerifierFactCheck   = "fact_check"
+	VerifierCalculation = "calculation_verify"
+	VerifierConsistency = "consistency_check"
+)
+
+// Verifier checks an answer with respect to one concern raised during
+// reflection. Applications register verifiers (schema checks, test runners,
+// domain lookups) with Reflector.WithVerifiers.
+type Verifier interface {
+	// Name identifies the verifier; it is recorded as VerificationStep.Method
+	Name() string
+
+	// Description tells the router which concerns the verifier can check
+	Description() string
+
+	// Verify checks the answer. Failures to run the check are reported in
+	// VerificationStep.Error.
+	Verify(ctx context.Context, req VerificationRequest) types.VerificationStep
+}
+
+// VerificationRequest is what a Verifier checks
+type VerificationRequest struct {
+	Question string
+	Answer   string
+	Concern  string // Concern that was routed to the verifier
+}
+
+// funcVerifier adapts a function to Verifier
+type funcVerifier struct {
+	name        string
+	description string
+	fn          func(ctx context.Context, req VerificationRequest) types.VerificationStep
+}
+
+// NewVerifier creates a Verifier from a function
+func NewVerifier(name, description string, fn func(ctx context.Context, req VerificationRequest) types.VerificationStep) Verifier {
+	return &funcVerifier{name: name, description: description, fn: fn}
+}
+
+func (v *funcVerifier) Name() string        { return v.name }
+func (v *funcVerifier) Description() string { return v.description }
+
+func (v *funcVerifier) Verify(ctx context.Context, req VerificationRequest) types.VerificationStep {
+	return v.fn(ctx, req)
+}
+
+// ToolVerifier verifies answers by calling a tool, e.g. a database or API
+// lookup for domain facts
+type ToolVerifier struct {
+	Tool tools.Tool
+
+	// Purpose describes which concerns the tool can check (default: the
+	// tool's description)
+	Purpose string
+
+	// Arguments builds the tool arguments for a request (required)
+	Arguments func(req VerificationRequest) map[string]interface{}
+
+	// Check decides whether the tool result supports the answer
+	// (default: the answer's key words appear in the result)
+	Check func(req VerificationRequest, result interface{}) bool
+}
+
+// Name implements Verifier
+func (v *ToolVerifier) Name() string {
+	return v.Tool.Name()
+}
+
+// Description implements Verifier
+func (v *ToolVerifier) Description() string {
+	if v.Purpose != "" {
+		return v.Purpose
+	}
+	return v.Tool.Description()
+}
+
+// Verify implements Verifier
+func (v *ToolVerifier) Verify(ctx context.Context, req VerificationRequest) types.VerificationStep {
+	step := types.VerificationStep{Method: v.Name(), Query: req.Question}
+	if v.Arguments == nil {
+		step.Error = fmt.Errorf("tool verifier %s has no Arguments function", v.Name())
+		return step
+	}
+
+	result, err := v.Tool.Execute(ctx, v.Arguments(req))
+	if err != nil {
+		step.Error = err
+		return step
+	}
+	step.Result = result
+
+	if v.Check != nil {
+		step.Passed = v.Check(req, result)
+	} else {
+		step.Passed = resultSupportsAnswer(result, req.Answer)
+	}
+	return step
+}
+
+// ConfidenceFunc computes the confidence (0.0 to 1.0) of an answer from the
+// concerns and verifications of a reflection
+type ConfidenceFunc func(reflection *types.ReflectionCheck) float64
+
+// WeightedConfidence returns a ConfidenceFunc that averages verification
+// results weighted by method (weight 1 for methods missing from weights)
+// and subtracts concernPenalty per concern, up to maxPenalty. Without
+// verifications the confidence is 0.95 if there were no concerns and 0.5
+// otherwise.
+func WeightedConfidence(weights map[string]float64, concernPenalty, maxPenalty float64) ConfidenceFunc {
+	return func(reflection *types.ReflectionCheck) float64 {
+		if len(reflection.Verifications) == 0 {
+			if len(reflection.Concerns) == 0 {
+				return 0.95 // No concerns, high confidence
+			}
+			return 0.5 // Had concerns but couldn't verify
+		}
+
+		var passed, total float64
+		for _, v := range reflection.Verifications {
+			weight := 1.0
+			if w, ok := weights[v.Method]; ok {
+				weight = w
+			}
+			total += weight
+			if v.Passed {
+				passed += weight
+			}
+		}
+		if total <= 0 {
+			return 0.5
+		}
+
+		penalty := concernPenalty * float64(len(reflection.Concerns))
+		if penalty > maxPenalty {
+			penalty = maxPenalty
+		}
+
+		confidence := passed/total - penalty
+		if confidence < 0.0 {
+			confidence = 0.0
+		}
+		if confidence > 1.0 {
+			confidence = 1.0
+		}
+		return confidence
+	}
+}
+
+// defaultConfidence weighs all verifications equally with a 0.05 penalty
+// per concern (at most 0.2)
+var defaultConfidence = WeightedConfidence(nil, 0.05, 0.2)
@@ -0,0 +1,325 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/taipm/go-llm-agent/pkg/agent"
+	"github.com/taipm/go-llm-agent/pkg/types"
+)
+
+// completion identifies one chat completion and builds its responses
+type completion struct {
+	id      string
+	model   string
+	created int64
+}
+
+func newCompletion(model string) completion {
+	return completion{
+		id:      "chatcmpl-" + strings.ReplaceAll(uuid.New().String(), "-", ""),
+		model:   model,
+		created: time.Now().Unix(),
+	}
+}
+
+func (c completion) response(msg *responseMessage, finish string, usage *usageReport) chatCompletionResponse {
+	return chatCompletionResponse{
+		ID:      c.id,
+		Object:  "chat.completion",
+		Created: c.created,
+		Model:   c.model,
+		Choices: []choice{{Message: msg, FinishReason: &finish}},
+		Usage:   usage,
+	}
+}
+
+func (c completion) chunk(delta *responseMessage, finish *string) chatCompletionResponse {
+	return chatCompletionResponse{
+		ID:      c.id,
+		Object:  "chat.completion.chunk",
+		Created: c.created,
+		Model:   c.model,
+		Choices: []choice{{Delta: delta, FinishReason: finish}},
+	}
+}
+
+// usageChunk is the final chunk sent when stream_options.include_usage is set
+func (c completion) usageChunk(usage *usageReport) chatCompletionResponse {
+	return chatCompletionResponse{
+		ID:      c.id,
+		Object:  "chat.completion.chunk",
+		Created: c.created,
+		Model:   c.model,
+		Choices: []choice{},
+		Usage:   usage,
+	}
+}
+
+// sseWriter writes server-sent events
+type sseWriter struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+}
+
+func newSSEWriter(w http.ResponseWriter) *sseWriter {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	flusher, _ := w.(http.Flusher)
+	return &sseWriter{w: w, flusher: flusher}
+}
+
+func (s *sseWriter) send(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(s.w, "data: %s\n\n", data); err != nil {
+		return err
+	}
+	if s.flusher != nil {
+		s.flusher.Flush()
+	}
+	return nil
+}
+
+// fail reports an error after the stream has started
+func (s *sseWriter) fail(err error) {
+	_, errType, code := errorStatus(err)
+	s.send(errorResponse{Error: errorBody{Message: err.Error(), Type: errType, Code: code}})
+	s.done()
+}
+
+func (s *sseWriter) done() {
+	fmt.Fprint(s.w, "data: [DONE]\n\n")
+	if s.flusher != nil {
+		s.flusher.Flush()
+	}
+}
+
+func (s *Server) handleChatCompletions(w http.ResponseWriter, r *http.Request) {
+	var req chatCompletionRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", "", fmt.Sprintf("invalid request body: %v", err))
+		return
+	}
+	if len(req.Messages) == 0 {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", "", "messages must not be empty")
+		return
+	}
+	if !s.knownModel(req.Model) {
+		writeError(w, http.StatusNotFound, "invalid_request_error", "model_not_found", fmt.Sprintf("model %q does not exist", req.Model))
+		return
+	}
+
+	messages, system, err := toMessages(req.Messages)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", "", err.Error())
+		return
+	}
+
+	model := req.Model
+	if model == "" {
+		model = s.models[0]
+	}
+	c := newCompletion(model)
+
+	if s.provider != nil && (s.sessions == nil || len(req.Tools) > 0 || hasToolMessages(messages)) {
+		s.logger.Debug("🌐 %s: %d messages to provider (stream=%v)", c.id, len(messages), req.Stream)
+		s.chatProvider(w, r.Context(), &req, c, messages, system)
+		return
+	}
+	if len(req.Tools) > 0 {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", "", "client-side tools require a provider-backed server; the agent runs its own tools")
+		return
+	}
+	s.logger.Debug("🌐 %s: message to agent (stream=%v)", c.id, req.Stream)
+	s.chatAgent(w, r, &req, c, messages)
+}
+
+func hasToolMessages(messages []types.Message) bool {
+	for _, msg := range messages {
+		if msg.Role == types.RoleTool || len(msg.ToolCalls) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// chatAgent answers the last user message in an agent session
+func (s *Server) chatAgent(w http.ResponseWriter, r *http.Request, req *chatCompletionRequest, c completion, messages []types.Message) {
+	last := messages[len(messages)-1]
+	if last.Role != types.RoleUser {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", "", "the last message must be a user message")
+		return
+	}
+
+	sessionID := r.Header.Get(SessionHeader)
+	if sessionID == "" {
+		sessionID = req.SessionID
+	}
+
+	// Requests without a session ID must not evict the manager's sessions
+	var session *agent.Session
+	if sessionID == "" {
+		session = s.sessions.Ephemeral(c.id)
+	} else {
+		var err error
+		if session, err = s.sessions.Get(sessionID); err != nil {
+			writeError(w, http.StatusInternalServerError, "server_error", "", err.Error())
+			return
+		}
+		w.Header().Set(SessionHeader, sessionID)
+	}
+
+	// A new session starts from the conversation the client sent
+	if history, err := session.GetHistory(); err == nil && len(history) == 0 && len(messages) > 1 {
+		if err := session.AddHistory(messages[:len(messages)-1]...); err != nil {
+			writeError(w, http.StatusInternalServerError, "server_error", "", err.Error())
+			return
+		}
+	}
+
+	ctx := r.Context()
+	if !req.Stream {
+		answer, err := session.Chat(ctx, last.Content)
+		if err != nil {
+			s.logger.Error("Agent request %s failed: %v", c.id, err)
+			status, errType, code := errorStatus(err)
+			writeError(w, status, errType, code, err.Error())
+			return
+		}
+		msg := &responseMessage{Role: string(types.RoleAssistant), Content: &answer}
+		writeJSON(w, http.StatusOK, c.response(msg, finishStop, turnUsage(session.Usage())))
+		return
+	}
+
+	sse := newSSEWriter(w)
+	empty := ""
+	sse.send(c.chunk(&responseMessage{Role: string(types.RoleAssistant), Content: &empty}, nil))
+
+	err := session.ChatStreamEvents(ctx, last.Content, func(event agent.StreamEvent) error {
+		if event.Type != agent.EventTextDelta || event.Content == "" {
+			return nil
+		}
+		content := event.Content
+		return sse.send(c.chunk(&responseMessage{Content: &content}, nil))
+	})
+	if err != nil {
+		s.logger.Error("Agent request %s failed: %v", c.id, err)
+		sse.fail(err)
+		return
+	}
+
+	sse.send(c.chunk(&responseMessage{}, &finishStop))
+	if req.StreamOptions != nil && req.StreamOptions.IncludeUsage {
+		sse.send(c.usageChunk(turnUsage(session.Usage())))
+	}
+	sse.done()
+}
+
+func turnUsage(usage agent.Usage) *usageReport {
+	return &usageReport{
+		PromptTokens:     usage.Turn.PromptTokens,
+		CompletionTokens: usage.Turn.CompletionTokens,
+		TotalTokens:      usage.Turn.TotalTokens,
+	}
+}
+
+// chatProvider passes the conversation and client tools to the provider
+func (s *Server) chatProvider(w http.ResponseWriter, ctx context.Context, req *chatCompletionRequest, c completion, messages []types.Message, system string) {
+	opts := &types.ChatOptions{
+		SystemPrompt: system,
+		Tools:        req.Tools,
+		Stop:         req.Stop,
+		MaxTokens:    max(req.MaxTokens, req.MaxCompletionTokens),
+	}
+	if req.Temperature != nil {
+		opts.Temperature = *req.Temperature
+	}
+	if req.TopP != nil {
+		opts.TopP = *req.TopP
+	}
+
+	if !req.Stream {
+		resp, err := s.provider.Chat(ctx, messages, opts)
+		if err != nil {
+			s.logger.Error("Provider request %s failed: %v", c.id, err)
+			status, errType, code := errorStatus(err)
+			writeError(w, status, errType, code, err.Error())
+			return
+		}
+
+		msg := &responseMessage{Role: string(types.RoleAssistant), ToolCalls: fromToolCalls(resp.ToolCalls)}
+		finish := finishStop
+		if len(resp.ToolCalls) > 0 {
+			finish = finishToolCalls
+		}
+		if resp.Content != "" || len(resp.ToolCalls) == 0 {
+			msg.Content = &resp.Content
+		}
+		writeJSON(w, http.StatusOK, c.response(msg, finish, usageFromMetadata(resp.Metadata)))
+		return
+	}
+
+	sse := newSSEWriter(w)
+	empty := ""
+	sse.send(c.chunk(&responseMessage{Role: string(types.RoleAssistant), Content: &empty}, nil))
+
+	// Tool calls are sent once at the end: providers may repeat calls from
+	// partial chunks in the Done chunk, whose set then replaces them
+	var meta *types.Metadata
+	var toolCalls []types.ToolCall
+	err := s.provider.Stream(ctx, messages, opts, func(chunk types.StreamChunk) error {
+		if chunk.Metadata != nil {
+			meta = chunk.Metadata
+		}
+		if chunk.Content != "" {
+			content := chunk.Content
+			if err := sse.send(c.chunk(&responseMessage{Content: &content}, nil)); err != nil {
+				return err
+			}
+		}
+		if len(chunk.ToolCalls) > 0 {
+			if chunk.Done {
+				toolCalls = chunk.ToolCalls
+			} else {
+				toolCalls = append(toolCalls, chunk.ToolCalls...)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		s.logger.Error("Provider request %s failed: %v", c.id, err)
+		sse.fail(err)
+		return
+	}
+
+	finish := finishStop
+	if len(toolCalls) > 0 {
+		calls := fromToolCalls(toolCalls)
+		for i := range calls {
+			index := i
+			calls[i].Index = &index
+		}
+		sse.send(c.chunk(&responseMessage{ToolCalls: calls}, nil))
+		finish = finishToolCalls
+	}
+	sse.send(c.chunk(&responseMessage{}, &finish))
+	if req.StreamOptions != nil && req.StreamOptions.IncludeUsage {
+		usage := usageFromMetadata(meta)
+		if usage == nil {
+			usage = &usageReport{}
+		}
+		sse.send(c.usageChunk(usage))
+	}
+	sse.done()
+}
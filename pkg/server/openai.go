@@ -0,0 +1,241 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/taipm/go-llm-agent/pkg/types"
+)
+
+// OpenAI Chat Completions wire format (the subset the server understands)
+
+type chatCompletionRequest struct {
+	Model               string                 `json:"model"`
+	Messages            []chatMessage          `json:"messages"`
+	Tools               []types.ToolDefinition `json:"tools,omitempty"`
+	Temperature         *float64               `json:"temperature,omitempty"`
+	TopP                *float64               `json:"top_p,omitempty"`
+	MaxTokens           int                    `json:"max_tokens,omitempty"`
+	MaxCompletionTokens int                    `json:"max_completion_tokens,omitempty"`
+	Stop                stopList               `json:"stop,omitempty"`
+	Stream              bool                   `json:"stream,omitempty"`
+	StreamOptions       *streamOptions         `json:"stream_options,omitempty"`
+
+	// SessionID selects an agent conversation (the X-Session-ID header takes precedence)
+	SessionID string `json:"session_id,omitempty"`
+}
+
+type streamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+type chatMessage struct {
+	Role       string         `json:"role"`
+	Content    messageContent `json:"content"`
+	Name       string         `json:"name,omitempty"`
+	ToolCalls  []wireToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string         `json:"tool_call_id,omitempty"`
+}
+
+// messageContent accepts a string, an array of content parts or null.
+// Only text parts are kept.
+type messageContent string
+
+func (c *messageContent) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		*c = ""
+		return nil
+	}
+
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*c = messageContent(text)
+		return nil
+	}
+
+	var parts []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(data, &parts); err != nil {
+		return fmt.Errorf("content must be a string or an array of parts")
+	}
+	texts := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part.Type == "text" {
+			texts = append(texts, part.Text)
+		}
+	}
+	*c = messageContent(strings.Join(texts, "\n"))
+	return nil
+}
+
+// stopList accepts a string or an array of strings
+type stopList []string
+
+func (s *stopList) UnmarshalJSON(data []byte) error {
+	var one string
+	if err := json.Unmarshal(data, &one); err == nil {
+		*s = stopList{one}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return fmt.Errorf("stop must be a string or an array of strings")
+	}
+	*s = many
+	return nil
+}
+
+type wireToolCall struct {
+	Index    *int         `json:"index,omitempty"` // Set in stream deltas only
+	ID       string       `json:"id,omitempty"`
+	Type     string       `json:"type,omitempty"`
+	Function wireFunction `json:"function"`
+}
+
+type wireFunction struct {
+	Name      string `json:"name,omitempty"`
+	Arguments string `json:"arguments"` // JSON-encoded
+}
+
+type chatCompletionResponse struct {
+	ID      string       `json:"id"`
+	Object  string       `json:"object"`
+	Created int64        `json:"created"`
+	Model   string       `json:"model"`
+	Choices []choice     `json:"choices"`
+	Usage   *usageReport `json:"usage,omitempty"`
+}
+
+type choice struct {
+	Index        int              `json:"index"`
+	Message      *responseMessage `json:"message,omitempty"`
+	Delta        *responseMessage `json:"delta,omitempty"`
+	FinishReason *string          `json:"finish_reason"`
+}
+
+type responseMessage struct {
+	Role      string         `json:"role,omitempty"`
+	Content   *string        `json:"content,omitempty"`
+	ToolCalls []wireToolCall `json:"tool_calls,omitempty"`
+}
+
+type usageReport struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+type modelList struct {
+	Object string      `json:"object"`
+	Data   []modelInfo `json:"data"`
+}
+
+type modelInfo struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	Created int64  `json:"created"`
+	OwnedBy string `json:"owned_by"`
+}
+
+type errorResponse struct {
+	Error errorBody `json:"error"`
+}
+
+type errorBody struct {
+	Message string `json:"message"`
+	Type    string `json:"type"`
+	Code    string `json:"code,omitempty"`
+}
+
+// Finish reasons
+var (
+	finishStop      = "stop"
+	finishToolCalls = "tool_calls"
+)
+
+// toMessages converts request messages to the library's types. System and
+// developer messages are joined into the system prompt.
+func toMessages(in []chatMessage) ([]types.Message, string, error) {
+	var system []string
+	messages := make([]types.Message, 0, len(in))
+
+	for i, msg := range in {
+		switch msg.Role {
+		case "system", "developer":
+			system = append(system, string(msg.Content))
+		case "user":
+			messages = append(messages, types.Message{Role: types.RoleUser, Content: string(msg.Content)})
+		case "assistant":
+			calls, err := toToolCalls(msg.ToolCalls)
+			if err != nil {
+				return nil, "", fmt.Errorf("messages[%d]: %w", i, err)
+			}
+			messages = append(messages, types.Message{Role: types.RoleAssistant, Content: string(msg.Content), ToolCalls: calls})
+		case "tool":
+			if msg.ToolCallID == "" {
+				return nil, "", fmt.Errorf("messages[%d]: tool message requires tool_call_id", i)
+			}
+			messages = append(messages, types.Message{Role: types.RoleTool, Content: string(msg.Content), ToolID: msg.ToolCallID})
+		default:
+			return nil, "", fmt.Errorf("messages[%d]: unsupported role %q", i, msg.Role)
+		}
+	}
+
+	return messages, strings.Join(system, "\n\n"), nil
+}
+
+func toToolCalls(in []wireToolCall) ([]types.ToolCall, error) {
+	if len(in) == 0 {
+		return nil, nil
+	}
+	calls := make([]types.ToolCall, len(in))
+	for i, tc := range in {
+		args := map[string]interface{}{}
+		if strings.TrimSpace(tc.Function.Arguments) != "" {
+			if err := json.Unmarshal([]byte(tc.Function.Arguments), &args); err != nil {
+				return nil, fmt.Errorf("invalid arguments for tool call %s: %w", tc.ID, err)
+			}
+		}
+		calls[i] = types.ToolCall{
+			ID:       tc.ID,
+			Type:     "function",
+			Function: types.FunctionCall{Name: tc.Function.Name, Arguments: args},
+		}
+	}
+	return calls, nil
+}
+
+// fromToolCalls encodes tool calls for the wire
+func fromToolCalls(in []types.ToolCall) []wireToolCall {
+	if len(in) == 0 {
+		return nil
+	}
+	calls := make([]wireToolCall, len(in))
+	for i, tc := range in {
+		args := []byte("{}")
+		if tc.Function.Arguments != nil {
+			args, _ = json.Marshal(tc.Function.Arguments)
+		}
+		calls[i] = wireToolCall{
+			ID:       tc.ID,
+			Type:     "function",
+			Function: wireFunction{Name: tc.Function.Name, Arguments: string(args)},
+		}
+	}
+	return calls
+}
+
+func usageFromMetadata(meta *types.Metadata) *usageReport {
+	if meta == nil {
+		return nil
+	}
+	total := meta.TotalTokens
+	if total == 0 {
+		total = meta.PromptTokens + meta.CompletionTokens
+	}
+	return &usageReport{PromptTokens: meta.PromptTokens, CompletionTokens: meta.CompletionTokens, TotalTokens: total}
+}
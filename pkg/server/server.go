@@ -0,0 +1,195 @@
+// Package server exposes agents and providers over HTTP using the OpenAI
+// Chat Completions wire format, so existing OpenAI clients and SDKs can talk
+// to them unchanged.
+//
+// Endpoints:
+//
+//	POST /v1/chat/completions  chat completions, with SSE streaming ("stream": true)
+//	GET  /v1/models            configured model IDs
+//
+// Requests are answered by an agent session or passed straight to a provider:
+//
+//   - Agent (or Sessions): the agent runs its own tools server-side. The
+//     session is taken from the X-Session-ID header or the "session_id" body
+//     field; its memory holds the history, so only the last user message is
+//     sent to the agent (earlier messages seed a new session). Without a
+//     session ID each request runs in a throwaway session outside the
+//     session manager, seeded with the request's messages. System messages
+//     are ignored; the agent keeps its own system prompt.
+//   - Provider: messages, client tools and tool results are passed through,
+//     and tool calls are returned to the client (finish_reason "tool_calls").
+//
+// When both are configured, requests that declare tools or contain tool
+// results go to the provider and all others to the agent.
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/taipm/go-llm-agent/pkg/agent"
+	"github.com/taipm/go-llm-agent/pkg/logger"
+	"github.com/taipm/go-llm-agent/pkg/types"
+)
+
+// SessionHeader carries the agent session ID in requests and responses
+const SessionHeader = "X-Session-ID"
+
+// maxRequestBytes limits the size of a request body
+const maxRequestBytes = 16 << 20
+
+// Config configures a Server
+type Config struct {
+	// Agent answers chat requests, one session per conversation
+	Agent *agent.Agent
+
+	// Sessions overrides the session manager created for Agent
+	// (e.g. to set a TTL or per-session memory)
+	Sessions *agent.SessionManager
+
+	// Provider answers requests directly with tool-call passthrough
+	Provider types.LLMProvider
+
+	// Models are the IDs listed by /v1/models (default "go-llm-agent").
+	// Requests naming another model are rejected.
+	Models []string
+
+	// APIKeys are the accepted bearer tokens; empty disables authentication
+	APIKeys []string
+
+	// Logger logs requests and failures (default: no logging)
+	Logger logger.Logger
+}
+
+// Server serves the OpenAI-compatible API
+type Server struct {
+	sessions *agent.SessionManager
+	provider types.LLMProvider
+	models   []string
+	apiKeys  [][]byte
+	logger   logger.Logger
+	mux      *http.ServeMux
+	created  int64
+}
+
+// New creates a server; at least one of Agent, Sessions or Provider is required
+func New(config Config) (*Server, error) {
+	sessions := config.Sessions
+	if sessions == nil && config.Agent != nil {
+		sessions = agent.NewSessionManager(config.Agent, agent.SessionConfig{})
+	}
+	if sessions == nil && config.Provider == nil {
+		return nil, fmt.Errorf("server requires an agent, a session manager or a provider")
+	}
+
+	if len(config.Models) == 0 {
+		config.Models = []string{"go-llm-agent"}
+	}
+	if config.Logger == nil {
+		config.Logger = &logger.NoopLogger{}
+	}
+
+	s := &Server{
+		sessions: sessions,
+		provider: config.Provider,
+		models:   config.Models,
+		logger:   config.Logger,
+		mux:      http.NewServeMux(),
+		created:  time.Now().Unix(),
+	}
+	for _, key := range config.APIKeys {
+		if key != "" {
+			s.apiKeys = append(s.apiKeys, []byte(key))
+		}
+	}
+
+	s.mux.HandleFunc("POST /v1/chat/completions", s.handleChatCompletions)
+	s.mux.HandleFunc("GET /v1/models", s.handleModels)
+	s.mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, "invalid_request_error", "not_found", fmt.Sprintf("unknown endpoint %s %s", r.Method, r.URL.Path))
+	})
+	return s, nil
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(r) {
+		writeError(w, http.StatusUnauthorized, "invalid_request_error", "invalid_api_key", "invalid or missing API key")
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// ListenAndServe serves the API on addr
+func (s *Server) ListenAndServe(addr string) error {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           s,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	s.logger.Info("🌐 OpenAI-compatible API listening on %s", addr)
+	return srv.ListenAndServe()
+}
+
+// authorized checks the bearer token against the configured keys
+func (s *Server) authorized(r *http.Request) bool {
+	if len(s.apiKeys) == 0 {
+		return true
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return false
+	}
+	for _, key := range s.apiKeys {
+		if subtle.ConstantTimeCompare([]byte(strings.TrimSpace(token)), key) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) handleModels(w http.ResponseWriter, r *http.Request) {
+	list := modelList{Object: "list", Data: make([]modelInfo, len(s.models))}
+	for i, id := range s.models {
+		list.Data[i] = modelInfo{ID: id, Object: "model", Created: s.created, OwnedBy: "go-llm-agent"}
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// knownModel reports whether model is served; an empty model means the default
+func (s *Server) knownModel(model string) bool {
+	if model == "" {
+		return true
+	}
+	for _, m := range s.models {
+		if m == model {
+			return true
+		}
+	}
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, errType, code, message string) {
+	writeJSON(w, status, errorResponse{Error: errorBody{Message: message, Type: errType, Code: code}})
+}
+
+// errorStatus maps a chat failure to an HTTP status and OpenAI error type
+func errorStatus(err error) (int, string, string) {
+	switch {
+	case errors.Is(err, agent.ErrBudgetExceeded):
+		return http.StatusTooManyRequests, "insufficient_quota", "budget_exceeded"
+	default:
+		return http.StatusInternalServerError, "server_error", ""
+	}
+}
@@ -0,0 +1,360 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/taipm/go-llm-agent/pkg/agent"
+	"github.com/taipm/go-llm-agent/pkg/memory"
+	"github.com/taipm/go-llm-agent/pkg/types"
+)
+
+// scriptedProvider returns queued responses, then echoes the last user message
+type scriptedProvider struct {
+	mu        sync.Mutex
+	responses []*types.Response
+	received  [][]types.Message
+	options   []*types.ChatOptions
+}
+
+func (p *scriptedProvider) Chat(ctx context.Context, messages []types.Message, options *types.ChatOptions) (*types.Response, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.received = append(p.received, append([]types.Message(nil), messages...))
+	p.options = append(p.options, options)
+	if len(p.responses) > 0 {
+		resp := p.responses[0]
+		p.responses = p.responses[1:]
+		return resp, nil
+	}
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == types.RoleUser {
+			return &types.Response{Content: "echo: " + messages[i].Content, Metadata: &types.Metadata{PromptTokens: 10, CompletionTokens: 5}}, nil
+		}
+	}
+	return &types.Response{Content: "echo"}, nil
+}
+
+func (p *scriptedProvider) Stream(ctx context.Context, messages []types.Message, options *types.ChatOptions, handler types.StreamHandler) error {
+	resp, err := p.Chat(ctx, messages, options)
+	if err != nil {
+		return err
+	}
+	for _, word := range strings.SplitAfter(resp.Content, " ") {
+		if word != "" {
+			if err := handler(types.StreamChunk{Content: word}); err != nil {
+				return err
+			}
+		}
+	}
+	return handler(types.StreamChunk{ToolCalls: resp.ToolCalls, Done: true, Metadata: resp.Metadata})
+}
+
+func newTestAgent(provider types.LLMProvider) *agent.Agent {
+	return agent.New(provider,
+		agent.WithMemory(memory.NewBuffer(100)),
+		agent.WithoutBuiltinTools(),
+		agent.WithoutAutoReasoning(),
+		agent.WithReflection(false),
+		agent.WithLearning(false),
+		agent.DisableLogging(),
+	)
+}
+
+func newTestServer(t *testing.T, config Config) *httptest.Server {
+	t.Helper()
+	s, err := New(config)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	srv := httptest.NewServer(s)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func post(t *testing.T, url, body string, headers map[string]string) *http.Response {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodPost, url+"/v1/chat/completions", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func decode(t *testing.T, resp *http.Response) chatCompletionResponse {
+	t.Helper()
+	var out chatCompletionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	return out
+}
+
+// readSSE returns the JSON payloads of a stream; the stream must end with [DONE]
+func readSSE(t *testing.T, resp *http.Response) []chatCompletionResponse {
+	t.Helper()
+	var chunks []chatCompletionResponse
+	done := false
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+		if !ok {
+			continue
+		}
+		if data == "[DONE]" {
+			done = true
+			break
+		}
+		var chunk chatCompletionResponse
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			t.Fatalf("Invalid chunk %q: %v", data, err)
+		}
+		chunks = append(chunks, chunk)
+	}
+	if !done {
+		t.Fatal("Expected stream to end with [DONE]")
+	}
+	return chunks
+}
+
+func TestServerAuthAndModels(t *testing.T) {
+	srv := newTestServer(t, Config{Provider: &scriptedProvider{}, APIKeys: []string{"secret"}, Models: []string{"agent-a", "agent-b"}})
+
+	resp, _ := http.Get(srv.URL + "/v1/models")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without key, got %d", resp.StatusCode)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/v1/models", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var list modelList
+	json.NewDecoder(resp.Body).Decode(&list)
+	if resp.StatusCode != http.StatusOK || len(list.Data) != 2 || list.Data[1].ID != "agent-b" {
+		t.Errorf("Unexpected models response %d: %+v", resp.StatusCode, list)
+	}
+
+	resp = post(t, srv.URL, `{"model":"gpt-4o","messages":[{"role":"user","content":"hi"}]}`, map[string]string{"Authorization": "Bearer secret"})
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected 404 for unknown model, got %d", resp.StatusCode)
+	}
+}
+
+func TestServerAgentSessions(t *testing.T) {
+	provider := &scriptedProvider{}
+	srv := newTestServer(t, Config{Agent: newTestAgent(provider)})
+	session := map[string]string{SessionHeader: "s1"}
+
+	resp := post(t, srv.URL, `{"messages":[{"role":"system","content":"ignored"},{"role":"user","content":"first"}]}`, session)
+	out := decode(t, resp)
+	if resp.StatusCode != http.StatusOK || *out.Choices[0].Message.Content != "echo: first" || *out.Choices[0].FinishReason != "stop" {
+		t.Fatalf("Unexpected response %d: %+v", resp.StatusCode, out)
+	}
+	if resp.Header.Get(SessionHeader) != "s1" || out.Object != "chat.completion" || out.Model != "go-llm-agent" {
+		t.Errorf("Unexpected envelope: %+v (session %q)", out, resp.Header.Get(SessionHeader))
+	}
+	if out.Usage == nil || out.Usage.TotalTokens != 15 {
+		t.Errorf("Expected turn usage, got %+v", out.Usage)
+	}
+
+	// The session remembers the history; only the new message is used
+	post(t, srv.URL, `{"session_id":"s1","messages":[{"role":"user","content":"stale"},{"role":"user","content":"second"}]}`, nil)
+	last := provider.received[len(provider.received)-1]
+	if len(last) != 3 || last[0].Content != "first" || last[2].Content != "second" {
+		t.Errorf("Expected session history plus the new message, got %+v", last)
+	}
+
+	// Without a session, earlier messages seed a throwaway session
+	post(t, srv.URL, `{"messages":[{"role":"user","content":"a"},{"role":"assistant","content":"b"},{"role":"user","content":"c"}]}`, nil)
+	last = provider.received[len(provider.received)-1]
+	if len(last) != 3 || last[1].Content != "b" {
+		t.Errorf("Expected the request's conversation, got %+v", last)
+	}
+
+	resp = post(t, srv.URL, `{"messages":[{"role":"user","content":"x"}],"tools":[{"type":"function","function":{"name":"f"}}]}`, nil)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected 400 for client tools without a provider, got %d", resp.StatusCode)
+	}
+}
+
+func TestServerAgentWithoutSessionKeepsSessions(t *testing.T) {
+	sessions := agent.NewSessionManager(newTestAgent(&scriptedProvider{}), agent.SessionConfig{MaxSessions: 1})
+	srv := newTestServer(t, Config{Sessions: sessions})
+
+	post(t, srv.URL, `{"messages":[{"role":"user","content":"first"}]}`, map[string]string{SessionHeader: "s1"})
+	resp := post(t, srv.URL, `{"messages":[{"role":"user","content":"one-off"}]}`, nil)
+	if out := decode(t, resp); *out.Choices[0].Message.Content != "echo: one-off" || resp.Header.Get(SessionHeader) != "" {
+		t.Errorf("Unexpected response without a session: %+v", out)
+	}
+
+	if _, ok := sessions.Lookup("s1"); !ok || sessions.Len() != 1 {
+		t.Errorf("Expected only session s1 to stay loaded, got %+v", sessions.List())
+	}
+}
+
+func TestServerAgentStreaming(t *testing.T) {
+	srv := newTestServer(t, Config{Agent: newTestAgent(&scriptedProvider{})})
+
+	resp := post(t, srv.URL, `{"stream":true,"stream_options":{"include_usage":true},"messages":[{"role":"user","content":"stream me"}]}`, nil)
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Expected SSE, got %q", ct)
+	}
+	chunks := readSSE(t, resp)
+
+	var text strings.Builder
+	for _, chunk := range chunks {
+		if len(chunk.Choices) > 0 && chunk.Choices[0].Delta.Content != nil {
+			text.WriteString(*chunk.Choices[0].Delta.Content)
+		}
+	}
+	if text.String() != "echo: stream me" {
+		t.Errorf("Expected streamed answer, got %q", text.String())
+	}
+	if chunks[0].Choices[0].Delta.Role != "assistant" || chunks[0].Object != "chat.completion.chunk" {
+		t.Errorf("Expected a role chunk first, got %+v", chunks[0])
+	}
+	finish := chunks[len(chunks)-2].Choices[0].FinishReason
+	if finish == nil || *finish != "stop" {
+		t.Errorf("Expected finish_reason stop, got %v", finish)
+	}
+	if usage := chunks[len(chunks)-1]; len(usage.Choices) != 0 || usage.Usage == nil {
+		t.Errorf("Expected a final usage chunk, got %+v", usage)
+	}
+}
+
+func TestServerProviderToolPassthrough(t *testing.T) {
+	call := types.ToolCall{ID: "call_1", Type: "function", Function: types.FunctionCall{
+		Name: "get_weather", Arguments: map[string]interface{}{"city": "Hanoi"},
+	}}
+	provider := &scriptedProvider{responses: []*types.Response{{ToolCalls: []types.ToolCall{call}}}}
+	srv := newTestServer(t, Config{Agent: newTestAgent(provider), Provider: provider})
+
+	tools := `"tools":[{"type":"function","function":{"name":"get_weather","description":"Weather","parameters":{"type":"object","properties":{"city":{"type":"string"}}}}}]`
+	resp := post(t, srv.URL, `{"temperature":0.2,"stop":"END",`+tools+`,"messages":[{"role":"system","content":"be brief"},{"role":"user","content":"weather?"}]}`, nil)
+	out := decode(t, resp)
+	msg := out.Choices[0].Message
+	if *out.Choices[0].FinishReason != "tool_calls" || msg.Content != nil || len(msg.ToolCalls) != 1 {
+		t.Fatalf("Expected a tool call, got %+v", out)
+	}
+	if msg.ToolCalls[0].Function.Arguments != `{"city":"Hanoi"}` || msg.ToolCalls[0].ID != "call_1" {
+		t.Errorf("Unexpected tool call %+v", msg.ToolCalls[0])
+	}
+	opts := provider.options[0]
+	if opts.SystemPrompt != "be brief" || opts.Temperature != 0.2 || len(opts.Stop) != 1 || len(opts.Tools) != 1 {
+		t.Errorf("Expected options to be passed through, got %+v", opts)
+	}
+
+	// The client returns the tool result
+	followUp := `{"messages":[{"role":"user","content":"weather?"},` +
+		`{"role":"assistant","content":null,"tool_calls":[{"id":"call_1","type":"function","function":{"name":"get_weather","arguments":"{\"city\":\"Hanoi\"}"}}]},` +
+		`{"role":"tool","tool_call_id":"call_1","content":[{"type":"text","text":"31°C"}]}]}`
+	resp = post(t, srv.URL, followUp, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", resp.StatusCode)
+	}
+	received := provider.received[len(provider.received)-1]
+	if len(received) != 3 || received[1].ToolCalls[0].Function.Arguments["city"] != "Hanoi" || received[2].ToolID != "call_1" || received[2].Content != "31°C" {
+		t.Errorf("Expected tool round trip to reach the provider, got %+v", received)
+	}
+}
+
+func TestServerProviderStreamingToolCalls(t *testing.T) {
+	call := types.ToolCall{ID: "call_1", Function: types.FunctionCall{Name: "lookup", Arguments: map[string]interface{}{"q": "x"}}}
+	provider := &scriptedProvider{responses: []*types.Response{{Content: "checking ", ToolCalls: []types.ToolCall{call, call}}}}
+	srv := newTestServer(t, Config{Provider: provider})
+
+	chunks := readSSE(t, post(t, srv.URL, `{"stream":true,"messages":[{"role":"user","content":"go"}]}`, nil))
+
+	var calls []wireToolCall
+	for _, chunk := range chunks {
+		calls = append(calls, chunk.Choices[0].Delta.ToolCalls...)
+	}
+	if len(calls) != 2 || *calls[0].Index != 0 || *calls[1].Index != 1 || calls[1].Function.Name != "lookup" {
+		t.Errorf("Expected indexed tool call deltas, got %+v", calls)
+	}
+	if finish := chunks[len(chunks)-1].Choices[0].FinishReason; finish == nil || *finish != "tool_calls" {
+		t.Errorf("Expected finish_reason tool_calls, got %v", finish)
+	}
+}
+
+// repeatingProvider streams each tool call in its own chunk and repeats
+// all of them in the Done chunk, as the Gemini provider does
+type repeatingProvider struct {
+	scriptedProvider
+	calls []types.ToolCall
+}
+
+func (p *repeatingProvider) Stream(ctx context.Context, messages []types.Message, options *types.ChatOptions, handler types.StreamHandler) error {
+	for _, call := range p.calls {
+		if err := handler(types.StreamChunk{ToolCalls: []types.ToolCall{call}}); err != nil {
+			return err
+		}
+	}
+	return handler(types.StreamChunk{ToolCalls: p.calls, Done: true})
+}
+
+func TestServerProviderStreamingRepeatedToolCalls(t *testing.T) {
+	provider := &repeatingProvider{calls: []types.ToolCall{
+		{ID: "call_1", Function: types.FunctionCall{Name: "lookup", Arguments: map[string]interface{}{"q": "x"}}},
+		{ID: "call_2", Function: types.FunctionCall{Name: "fetch", Arguments: map[string]interface{}{"url": "y"}}},
+	}}
+	srv := newTestServer(t, Config{Provider: provider})
+
+	chunks := readSSE(t, post(t, srv.URL, `{"stream":true,"messages":[{"role":"user","content":"go"}]}`, nil))
+
+	var calls []wireToolCall
+	for _, chunk := range chunks {
+		calls = append(calls, chunk.Choices[0].Delta.ToolCalls...)
+	}
+	if len(calls) != 2 || calls[0].ID != "call_1" || calls[1].ID != "call_2" || *calls[1].Index != 1 {
+		t.Errorf("Expected each tool call once, got %+v", calls)
+	}
+}
+
+func TestServerBadRequests(t *testing.T) {
+	srv := newTestServer(t, Config{Provider: &scriptedProvider{}})
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"invalid json", `{`, http.StatusBadRequest},
+		{"no messages", `{"messages":[]}`, http.StatusBadRequest},
+		{"unknown role", `{"messages":[{"role":"robot","content":"x"}]}`, http.StatusBadRequest},
+		{"tool without id", `{"messages":[{"role":"tool","content":"x"}]}`, http.StatusBadRequest},
+		{"bad arguments", `{"messages":[{"role":"assistant","tool_calls":[{"id":"c","function":{"name":"f","arguments":"{"}}]}]}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := post(t, srv.URL, tt.body, nil)
+			var out errorResponse
+			json.NewDecoder(resp.Body).Decode(&out)
+			if resp.StatusCode != tt.status || out.Error.Message == "" {
+				t.Errorf("Expected %d with an error message, got %d: %+v", tt.status, resp.StatusCode, out)
+			}
+		})
+	}
+
+	if _, err := New(Config{}); err == nil {
+		t.Error("Expected error without agent or provider")
+	}
+}
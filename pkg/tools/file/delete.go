@@ -21,7 +21,9 @@ type DeleteConfig struct {
 	// AllowRecursive allows recursive deletion of directories
 	AllowRecursive bool
 
-	// RequireConfirmation would require explicit confirmation (for future use)
+	// RequireConfirmation asks the agent's approval handler before deleting.
+	// file_delete is unsafe, so it is asked either way; auto-approve the tool
+	// in the agent's ApprovalPolicy to delete without confirmation.
 	RequireConfirmation bool
 }
 
@@ -69,6 +71,16 @@ func NewDeleteTool(config DeleteConfig) *DeleteTool {
 	}
 }
 
+// RequiresConfirmation implements tools.ConfirmationAware
+func (t *DeleteTool) RequiresConfirmation() bool {
+	return t.config.RequireConfirmation
+}
+
+// ConcurrencySafe implements tools.ConcurrencyAware (deletes must not race with other file operations)
+func (t *DeleteTool) ConcurrencySafe() bool {
+	return false
+}
+
 // Parameters returns the JSON schema for the tool's parameters
 func (t *DeleteTool) Parameters() *types.JSONSchema {
 	return &types.JSONSchema{
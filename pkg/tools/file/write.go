@@ -53,6 +53,11 @@ func NewWriteTool(config WriteConfig) *WriteTool {
 	}
 }
 
+// ConcurrencySafe implements tools.ConcurrencyAware (writes to the same file must not interleave)
+func (t *WriteTool) ConcurrencySafe() bool {
+	return false
+}
+
 // Parameters returns the JSON schema for the tool's parameters
 func (t *WriteTool) Parameters() *types.JSONSchema {
 	return &types.JSONSchema{
@@ -10,17 +10,33 @@ import (
 
 // Registry manages a collection of tools
 type Registry struct {
-	mu    sync.RWMutex
-	tools map[string]Tool
+	mu         sync.RWMutex
+	tools      map[string]Tool
+	validation *ValidationOptions // nil disables argument validation
 }
 
-// NewRegistry creates a new empty tool registry
+// NewRegistry creates a new empty tool registry. Arguments passed to Execute
+// are validated with DefaultValidationOptions.
 func NewRegistry() *Registry {
+	validation := DefaultValidationOptions()
 	return &Registry{
-		tools: make(map[string]Tool),
+		tools:      make(map[string]Tool),
+		validation: &validation,
 	}
 }
 
+// SetValidation sets how Execute validates arguments against each tool's
+// parameter schema; nil disables validation
+func (r *Registry) SetValidation(opts *ValidationOptions) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if opts != nil {
+		copied := *opts
+		opts = &copied
+	}
+	r.validation = opts
+}
+
 // Register adds a tool to the registry
 // Returns an error if a tool with the same name already exists
 func (r *Registry) Register(tool Tool) error {
@@ -111,12 +127,33 @@ func (r *Registry) ToToolDefinitions() []types.ToolDefinition {
 	return ToToolDefinitions(r.All())
 }
 
-// Execute executes a tool by name with the given parameters
+// Execute executes a tool by name with the given parameters.
+// The parameters are first validated (and coerced, if enabled) against the
+// tool's schema; invalid parameters return a *ValidationError without
+// running the tool. SelfValidating tools receive the parameters unchanged.
 func (r *Registry) Execute(ctx context.Context, name string, params map[string]interface{}) (interface{}, error) {
-	tool := r.Get(name)
+	r.mu.RLock()
+	tool := r.tools[name]
+	validation := r.validation
+	r.mu.RUnlock()
+
 	if tool == nil {
 		return nil, fmt.Errorf("tool %s not found", name)
 	}
+
+	if sv, ok := tool.(SelfValidating); ok && sv.ValidatesArguments() {
+		validation = nil
+	}
+	if validation != nil {
+		validated, err := ValidateArgumentsWithOptions(tool.Parameters(), params, *validation)
+		if err != nil {
+			if verr, ok := err.(*ValidationError); ok {
+				verr.Tool = name
+			}
+			return nil, err
+		}
+		params = validated
+	}
 	return tool.Execute(ctx, params)
 }
 
@@ -0,0 +1,65 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/taipm/go-llm-agent/pkg/types"
+)
+
+// recordingTool returns the parameters it was called with
+type recordingTool struct {
+	BaseTool
+}
+
+func (t *recordingTool) Parameters() *types.JSONSchema {
+	return &types.JSONSchema{
+		Type: "object",
+		Properties: map[string]*types.JSONSchema{
+			"count": {Type: "integer", Maximum: floatPtr(10)},
+		},
+		Required: []string{"count"},
+	}
+}
+
+func (t *recordingTool) Execute(ctx context.Context, params map[string]interface{}) (interface{}, error) {
+	return params, nil
+}
+
+func TestRegistryExecuteValidation(t *testing.T) {
+	registry := NewRegistry()
+	registry.MustRegister(&recordingTool{BaseTool: NewBaseTool("counter", "Counts", CategoryMath, false, true)})
+	ctx := context.Background()
+
+	// Default: lenient coercion
+	result, err := registry.Execute(ctx, "counter", map[string]interface{}{"count": "7"})
+	if err != nil {
+		t.Fatalf("Expected coerced call to succeed, got %v", err)
+	}
+	if result.(map[string]interface{})["count"] != 7.0 {
+		t.Errorf("Expected tool to receive 7, got %v", result)
+	}
+
+	_, err = registry.Execute(ctx, "counter", map[string]interface{}{"count": 11})
+	var verr *ValidationError
+	if !errors.As(err, &verr) || verr.Tool != "counter" || verr.Errors[0].Kind != ErrorRange {
+		t.Fatalf("Expected range error for counter, got %v", err)
+	}
+
+	// Strict
+	registry.SetValidation(&ValidationOptions{})
+	if _, err := registry.Execute(ctx, "counter", map[string]interface{}{"count": "7"}); err == nil {
+		t.Error("Expected strict validation to reject a string count")
+	}
+
+	// Disabled
+	registry.SetValidation(nil)
+	if _, err := registry.Execute(ctx, "counter", map[string]interface{}{}); err != nil {
+		t.Errorf("Expected no validation, got %v", err)
+	}
+
+	if _, err := registry.Execute(ctx, "missing", nil); err == nil {
+		t.Error("Expected error for unknown tool")
+	}
+}
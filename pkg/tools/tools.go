@@ -32,6 +32,51 @@ type Tool interface {
 	IsSafe() bool
 }
 
+// ConcurrencyAware is an optional interface for tools that declare whether
+// they may run in parallel with other tool calls from the same assistant turn
+type ConcurrencyAware interface {
+	// ConcurrencySafe returns false if the tool must run on its own
+	ConcurrencySafe() bool
+}
+
+// IsConcurrencySafe reports whether a tool may run concurrently with other tool calls.
+// Tools implementing ConcurrencyAware decide for themselves; otherwise only
+// safe tools (IsSafe() == true) are run concurrently.
+func IsConcurrencySafe(tool Tool) bool {
+	if ca, ok := tool.(ConcurrencyAware); ok {
+		return ca.ConcurrencySafe()
+	}
+	return tool.IsSafe()
+}
+
+// ConfirmationAware is an optional interface for tools that may need human
+// confirmation even though they are safe, depending on their configuration
+type ConfirmationAware interface {
+	// RequiresConfirmation returns true if a human must approve each call
+	RequiresConfirmation() bool
+}
+
+// NeedsConfirmation reports whether a tool call should be approved before it runs:
+// unsafe and auth-requiring tools always need confirmation, and
+// ConfirmationAware tools can require it for safe tools too. Skipping the
+// approval of unsafe tools is left to the agent's approval policy.
+func NeedsConfirmation(tool Tool) bool {
+	if tool.RequiresAuth() || !tool.IsSafe() {
+		return true
+	}
+	ca, ok := tool.(ConfirmationAware)
+	return ok && ca.RequiresConfirmation()
+}
+
+// SelfValidating is an optional interface for tools that validate their own
+// arguments, e.g. remote tools whose Parameters only approximate the schema
+// the server checks. The registry passes their arguments through unchanged.
+type SelfValidating interface {
+	// ValidatesArguments returns true if Registry.Execute must not validate
+	// or coerce the arguments
+	ValidatesArguments() bool
+}
+
 // ToolCategory represents the functional category of a tool
 type ToolCategory string
 
@@ -62,6 +107,9 @@ const (
 
 	// CategoryEmail represents tools that work with email (Gmail, etc.)
 	CategoryEmail ToolCategory = "email"
+
+	// CategoryMCP represents tools imported from Model Context Protocol servers
+	CategoryMCP ToolCategory = "mcp"
 )
 
 // BaseTool provides common functionality for all tools
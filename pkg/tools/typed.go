@@ -0,0 +1,391 @@
+package tools
+
+import (
+	"context"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/taipm/go-llm-agent/pkg/types"
+)
+
+// TypedTool is a Tool whose arguments are decoded into a Go struct.
+// Create one with NewTyped.
+type TypedTool[In, Out any] struct {
+	BaseTool
+	schema   *types.JSONSchema
+	defaults map[string]interface{}
+	fn       func(ctx context.Context, in In) (Out, error)
+}
+
+// TypedOption configures a TypedTool
+type TypedOption func(*typedConfig)
+
+type typedConfig struct {
+	category     ToolCategory
+	requiresAuth bool
+	isSafe       bool
+}
+
+// WithCategory sets the tool category (default CategoryData)
+func WithCategory(category ToolCategory) TypedOption {
+	return func(c *typedConfig) { c.category = category }
+}
+
+// WithSafe marks the tool safe to run without confirmation (default false)
+func WithSafe(safe bool) TypedOption {
+	return func(c *typedConfig) { c.isSafe = safe }
+}
+
+// WithAuth marks the tool as requiring authorization (default false)
+func WithAuth(requiresAuth bool) TypedOption {
+	return func(c *typedConfig) { c.requiresAuth = requiresAuth }
+}
+
+// NewTyped creates a tool from a function taking a struct. The parameter
+// schema is derived from the fields of In:
+//
+//   - the property name comes from the json tag (fields tagged "-" and
+//     unexported fields are skipped; embedded structs are flattened)
+//   - the desc tag is the property description
+//   - the tool tag holds comma-separated rules: required, enum=a|b|c,
+//     min=N, max=N (value bounds for numbers, length bounds for strings,
+//     item counts for slices) and default=V (used when a top-level
+//     argument is missing)
+//
+// Arguments are validated against the schema before fn runs, so the model
+// gets precise errors such as "count: must be <= 10, got 12".
+//
+//	type WeatherInput struct {
+//	    City  string `json:"city" desc:"City name" tool:"required"`
+//	    Unit  string `json:"unit" tool:"enum=celsius|fahrenheit,default=celsius"`
+//	    Days  int    `json:"days" tool:"min=1,max=7,default=1"`
+//	}
+//	tool := tools.NewTyped("weather", "Get the forecast", getWeather, tools.WithSafe(true))
+//
+// NewTyped panics if In is not a struct or has fields that cannot be
+// described (interface, channel or function types, recursive types,
+// malformed tags), like regexp.MustCompile does for invalid patterns.
+func NewTyped[In, Out any](name, description string, fn func(ctx context.Context, in In) (Out, error), opts ...TypedOption) *TypedTool[In, Out] {
+	config := typedConfig{category: CategoryData}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	inType := reflect.TypeOf((*In)(nil)).Elem()
+	if inType.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("tools.NewTyped(%s): input type %s is not a struct", name, inType))
+	}
+
+	gen := &schemaGenerator{visiting: make(map[reflect.Type]bool)}
+	schema, defaults, err := gen.object(inType)
+	if err != nil {
+		panic(fmt.Sprintf("tools.NewTyped(%s): %v", name, err))
+	}
+
+	return &TypedTool[In, Out]{
+		BaseTool: NewBaseTool(name, description, config.category, config.requiresAuth, config.isSafe),
+		schema:   schema,
+		defaults: defaults,
+		fn:       fn,
+	}
+}
+
+// Parameters implements Tool
+func (t *TypedTool[In, Out]) Parameters() *types.JSONSchema {
+	return t.schema
+}
+
+// Execute implements Tool: it applies defaults, validates the arguments,
+// decodes them into In and calls the function
+func (t *TypedTool[In, Out]) Execute(ctx context.Context, params map[string]interface{}) (interface{}, error) {
+	in, err := t.Decode(params)
+	if err != nil {
+		return nil, err
+	}
+	return t.fn(ctx, in)
+}
+
+// Decode validates params and converts them into In
+func (t *TypedTool[In, Out]) Decode(params map[string]interface{}) (In, error) {
+	var in In
+
+	args := make(map[string]interface{}, len(params)+len(t.defaults))
+	for k, v := range params {
+		args[k] = v
+	}
+	for k, v := range t.defaults {
+		if current, ok := args[k]; !ok || current == nil {
+			args[k] = v
+		}
+	}
+
+	args, err := ValidateArgumentsWithOptions(t.schema, args, DefaultValidationOptions())
+	if err != nil {
+		err.(*ValidationError).Tool = t.Name()
+		return in, err
+	}
+
+	data, err := json.Marshal(args)
+	if err != nil {
+		return in, fmt.Errorf("invalid arguments for %s: %w", t.Name(), err)
+	}
+	if err := json.Unmarshal(data, &in); err != nil {
+		return in, &ValidationError{Tool: t.Name(), Errors: []FieldError{decodeError(err)}}
+	}
+	return in, nil
+}
+
+// decodeError turns a JSON decoding failure into a field error
+func decodeError(err error) FieldError {
+	if typeErr, ok := err.(*json.UnmarshalTypeError); ok {
+		return FieldError{Path: typeErr.Field, Message: fmt.Sprintf("expected %s, got %s", typeErr.Type, typeErr.Value)}
+	}
+	return FieldError{Message: err.Error()}
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	textUnmarshalType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// schemaGenerator derives JSON schemas from Go types
+type schemaGenerator struct {
+	visiting map[reflect.Type]bool // Detects recursive types
+}
+
+// object describes a struct and collects the defaults of its fields
+func (g *schemaGenerator) object(t reflect.Type) (*types.JSONSchema, map[string]interface{}, error) {
+	if g.visiting[t] {
+		return nil, nil, fmt.Errorf("recursive type %s is not supported", t)
+	}
+	g.visiting[t] = true
+	defer delete(g.visiting, t)
+
+	schema := &types.JSONSchema{Type: "object", Properties: map[string]*types.JSONSchema{}}
+	defaults := map[string]interface{}{}
+	if err := g.fields(t, schema, defaults); err != nil {
+		return nil, nil, err
+	}
+	return schema, defaults, nil
+}
+
+func (g *schemaGenerator) fields(t reflect.Type, schema *types.JSONSchema, defaults map[string]interface{}) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, skip := jsonName(field)
+		if skip {
+			continue
+		}
+
+		// Embedded structs without a json name are flattened, as encoding/json does
+		if field.Anonymous && field.Tag.Get("json") == "" {
+			ft := field.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				if err := g.fields(ft, schema, defaults); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		prop, err := g.value(field.Type)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", field.Name, err)
+		}
+		if desc := field.Tag.Get("desc"); desc != "" && prop.Description != "" {
+			prop.Description = desc + " (" + prop.Description + ")"
+		} else if desc != "" {
+			prop.Description = desc
+		}
+
+		required, def, err := applyRules(prop, field)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", field.Name, err)
+		}
+		if required {
+			schema.Required = append(schema.Required, name)
+		}
+		if def != nil {
+			defaults[name] = def
+			encoded, _ := json.Marshal(def)
+			note := "(default: " + string(encoded) + ")"
+			if prop.Description == "" {
+				prop.Description = note
+			} else {
+				prop.Description += " " + note
+			}
+		}
+		schema.Properties[name] = prop
+	}
+	return nil
+}
+
+// value describes a field type
+func (g *schemaGenerator) value(t reflect.Type) (*types.JSONSchema, error) {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return &types.JSONSchema{Type: "string", Description: "RFC 3339 date-time"}, nil
+	}
+	if reflect.PointerTo(t).Implements(textUnmarshalType) {
+		return &types.JSONSchema{Type: "string"}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &types.JSONSchema{Type: "string"}, nil
+	case reflect.Bool:
+		return &types.JSONSchema{Type: "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &types.JSONSchema{Type: "integer"}, nil
+	case reflect.Float32, reflect.Float64:
+		return &types.JSONSchema{Type: "number"}, nil
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &types.JSONSchema{Type: "string", Description: "base64"}, nil
+		}
+		items, err := g.value(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &types.JSONSchema{Type: "array", Items: items}, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("map key type %s is not supported", t.Key())
+		}
+		values, err := g.value(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &types.JSONSchema{Type: "object", AdditionalProperties: values}, nil
+	case reflect.Struct:
+		schema, _, err := g.object(t)
+		return schema, err
+	default:
+		return nil, fmt.Errorf("type %s is not supported", t)
+	}
+}
+
+// jsonName returns the property name of a field and whether to skip it
+func jsonName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", true
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = field.Name
+	}
+	return name, false
+}
+
+// applyRules applies the tool tag of a field to its schema
+func applyRules(prop *types.JSONSchema, field reflect.StructField) (required bool, def interface{}, err error) {
+	tag := field.Tag.Get("tool")
+	if tag == "" {
+		return false, nil, nil
+	}
+
+	for _, rule := range strings.Split(tag, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		switch key {
+		case "required":
+			required = true
+		case "enum":
+			for _, option := range strings.Split(value, "|") {
+				v, err := parseTagValue(prop.Type, option)
+				if err != nil {
+					return false, nil, fmt.Errorf("enum: %w", err)
+				}
+				prop.Enum = append(prop.Enum, v)
+			}
+		case "min", "max":
+			if err := applyBound(prop, key, value); err != nil {
+				return false, nil, err
+			}
+		case "default":
+			if def, err = parseTagValue(prop.Type, value); err != nil {
+				return false, nil, fmt.Errorf("default: %w", err)
+			}
+		case "":
+		default:
+			return false, nil, fmt.Errorf("unknown tool tag rule %q", key)
+		}
+	}
+	return required, def, nil
+}
+
+// applyBound sets min/max as a value, length or item bound
+func applyBound(prop *types.JSONSchema, key, value string) error {
+	switch prop.Type {
+	case "integer", "number":
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("%s: invalid number %q", key, value)
+		}
+		if key == "min" {
+			prop.Minimum = &f
+		} else {
+			prop.Maximum = &f
+		}
+	case "string", "array":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return fmt.Errorf("%s: invalid length %q", key, value)
+		}
+		switch {
+		case prop.Type == "string" && key == "min":
+			prop.MinLength = &n
+		case prop.Type == "string":
+			prop.MaxLength = &n
+		case key == "min":
+			prop.MinItems = &n
+		default:
+			prop.MaxItems = &n
+		}
+	default:
+		return fmt.Errorf("%s is not supported for %s", key, prop.Type)
+	}
+	return nil
+}
+
+// parseTagValue converts a tag value to the JSON type of the property
+func parseTagValue(jsonType, value string) (interface{}, error) {
+	switch jsonType {
+	case "string":
+		return value, nil
+	case "integer":
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid integer %q", value)
+		}
+		return n, nil
+	case "number":
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", value)
+		}
+		return f, nil
+	case "boolean":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid boolean %q", value)
+		}
+		return b, nil
+	default:
+		return nil, fmt.Errorf("not supported for %s", jsonType)
+	}
+}
@@ -0,0 +1,175 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type weatherInput struct {
+	City   string    `json:"city" desc:"City name" tool:"required,min=2"`
+	Unit   string    `json:"unit,omitempty" desc:"Temperature unit" tool:"enum=celsius|fahrenheit,default=celsius"`
+	Days   int       `json:"days" tool:"min=1,max=7,default=1"`
+	Hourly *bool     `json:"hourly,omitempty"`
+	Tags   []string  `json:"tags" tool:"max=3"`
+	Since  time.Time `json:"since"`
+	Place  place     `json:"place"`
+	secret string
+	Ignore string `json:"-"`
+}
+
+type place struct {
+	Lat float64 `json:"lat" tool:"required,min=-90,max=90"`
+	Lon float64 `json:"lon" tool:"required"`
+}
+
+type weatherOutput struct {
+	Summary string `json:"summary"`
+}
+
+func newWeatherTool() *TypedTool[weatherInput, weatherOutput] {
+	return NewTyped("weather", "Get the forecast", func(ctx context.Context, in weatherInput) (weatherOutput, error) {
+		return weatherOutput{Summary: strings.Repeat(in.City+" "+in.Unit+" ", in.Days)}, nil
+	}, WithCategory(CategoryWeb), WithSafe(true))
+}
+
+func TestNewTypedSchema(t *testing.T) {
+	tool := newWeatherTool()
+	schema := tool.Parameters()
+
+	if tool.Name() != "weather" || tool.Category() != CategoryWeb || !tool.IsSafe() || tool.RequiresAuth() {
+		t.Errorf("Unexpected tool metadata: %s %s safe=%v auth=%v", tool.Name(), tool.Category(), tool.IsSafe(), tool.RequiresAuth())
+	}
+	if schema.Type != "object" || len(schema.Required) != 1 || schema.Required[0] != "city" {
+		t.Errorf("Expected object with required city, got %+v", schema)
+	}
+	for _, skipped := range []string{"secret", "Ignore"} {
+		if _, ok := schema.Properties[skipped]; ok {
+			t.Errorf("Expected %s to be skipped", skipped)
+		}
+	}
+
+	tests := []struct {
+		name, wantType, wantDesc string
+	}{
+		{"city", "string", "City name"},
+		{"unit", "string", `Temperature unit (default: "celsius")`},
+		{"days", "integer", "(default: 1)"},
+		{"hourly", "boolean", ""},
+		{"tags", "array", ""},
+		{"since", "string", "RFC 3339 date-time"},
+		{"place", "object", ""},
+	}
+	for _, tt := range tests {
+		prop := schema.Properties[tt.name]
+		if prop == nil {
+			t.Errorf("Expected property %s", tt.name)
+			continue
+		}
+		if prop.Type != tt.wantType || prop.Description != tt.wantDesc {
+			t.Errorf("%s: expected %s %q, got %s %q", tt.name, tt.wantType, tt.wantDesc, prop.Type, prop.Description)
+		}
+	}
+
+	if city := schema.Properties["city"]; city.MinLength == nil || *city.MinLength != 2 {
+		t.Error("Expected min=2 to become minLength on strings")
+	}
+	if days := schema.Properties["days"]; days.Minimum == nil || *days.Minimum != 1 || *days.Maximum != 7 {
+		t.Error("Expected days bounds 1..7")
+	}
+	if tags := schema.Properties["tags"]; tags.Items.Type != "string" || *tags.MaxItems != 3 {
+		t.Error("Expected tags to be an array of at most 3 strings")
+	}
+	if unit := schema.Properties["unit"]; len(unit.Enum) != 2 {
+		t.Errorf("Expected unit enum, got %v", unit.Enum)
+	}
+	if p := schema.Properties["place"]; len(p.Required) != 2 || *p.Properties["lat"].Minimum != -90 {
+		t.Errorf("Expected nested struct schema, got %+v", p)
+	}
+}
+
+func TestTypedToolExecute(t *testing.T) {
+	tool := newWeatherTool()
+	ctx := context.Background()
+
+	result, err := tool.Execute(ctx, map[string]interface{}{
+		"city":  "Hanoi",
+		"days":  2.0,
+		"place": map[string]interface{}{"lat": 21.0, "lon": 105.8},
+	})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if out := result.(weatherOutput); out.Summary != "Hanoi celsius Hanoi celsius " {
+		t.Errorf("Expected defaults applied, got %q", out.Summary)
+	}
+
+	_, err = tool.Execute(ctx, map[string]interface{}{
+		"days":  9,
+		"unit":  "kelvin",
+		"place": map[string]interface{}{"lat": 100.0},
+	})
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Expected *ValidationError, got %v", err)
+	}
+	if verr.Tool != "weather" {
+		t.Errorf("Expected tool name in error, got %q", verr.Tool)
+	}
+	for _, fragment := range []string{
+		"city: is required",
+		"days: must be <= 7, got 9",
+		`unit: must be one of ["celsius","fahrenheit"], got string "kelvin"`,
+		"place.lat: must be <= 90, got 100",
+		"place.lon: is required",
+	} {
+		if !strings.Contains(err.Error(), fragment) {
+			t.Errorf("Expected error to contain %q, got %q", fragment, err.Error())
+		}
+	}
+
+	_, err = tool.Execute(ctx, map[string]interface{}{"city": "Hanoi", "since": "yesterday"})
+	if err == nil || !strings.Contains(err.Error(), "invalid arguments for weather") {
+		t.Errorf("Expected decode error for bad time, got %v", err)
+	}
+}
+
+func TestNewTypedPanics(t *testing.T) {
+	tests := []struct {
+		name string
+		make func()
+	}{
+		{"not a struct", func() {
+			NewTyped("x", "", func(context.Context, string) (string, error) { return "", nil })
+		}},
+		{"unsupported field", func() {
+			NewTyped("x", "", func(context.Context, struct{ F func() }) (string, error) { return "", nil })
+		}},
+		{"bad tag", func() {
+			NewTyped("x", "", func(context.Context, struct {
+				N int `tool:"min=abc"`
+			}) (string, error) {
+				return "", nil
+			})
+		}},
+		{"recursive", func() {
+			NewTyped("x", "", func(context.Context, node) (string, error) { return "", nil })
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("Expected panic")
+				}
+			}()
+			tt.make()
+		})
+	}
+}
+
+type node struct {
+	Children []node `json:"children"`
+}
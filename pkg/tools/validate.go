@@ -0,0 +1,466 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/taipm/go-llm-agent/pkg/types"
+)
+
+// Kinds of argument problems reported in FieldError.Kind
+const (
+	ErrorRequired   = "required"   // Missing required property
+	ErrorType       = "type"       // Wrong JSON type
+	ErrorEnum       = "enum"       // Value not among the allowed values
+	ErrorRange      = "range"      // Number, length or item count out of bounds
+	ErrorAdditional = "additional" // Property not declared by the schema
+)
+
+// FieldError describes one invalid argument
+type FieldError struct {
+	Path    string // e.g. "items[2].name"; empty for the arguments object itself
+	Kind    string // One of the Error* kinds (empty for decoding failures)
+	Message string
+}
+
+func (e FieldError) String() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return e.Path + ": " + e.Message
+}
+
+// ValidationError lists every invalid argument of a tool call. Its message
+// is written for the model: the problems, the expected parameters and a
+// request to call the tool again.
+type ValidationError struct {
+	Tool   string
+	Errors []FieldError
+	Schema *types.JSONSchema // Expected parameters, summarized in the message
+}
+
+func (e *ValidationError) Error() string {
+	problems := make([]string, len(e.Errors))
+	for i, fe := range e.Errors {
+		problems[i] = fe.String()
+	}
+
+	var b strings.Builder
+	if e.Tool == "" {
+		b.WriteString("invalid arguments: ")
+	} else {
+		fmt.Fprintf(&b, "invalid arguments for %s: ", e.Tool)
+	}
+	b.WriteString(strings.Join(problems, "; "))
+	if summary := SummarizeParameters(e.Schema); summary != "" {
+		b.WriteString(". Expected parameters: ")
+		b.WriteString(summary)
+		b.WriteString(". Fix the arguments and call the tool again")
+	}
+	return b.String()
+}
+
+// ValidationOptions controls ValidateArgumentsWithOptions
+type ValidationOptions struct {
+	// Coerce converts values models commonly get wrong instead of rejecting
+	// them: numeric and boolean strings ("5", "true"), numbers and booleans
+	// where a string is expected, a single value where an array is expected,
+	// JSON-encoded objects and arrays, and enum values in the wrong case
+	Coerce bool
+
+	// StripUnknown drops properties a schema forbids with
+	// additionalProperties: false instead of reporting them
+	StripUnknown bool
+}
+
+// DefaultValidationOptions are the options registries start with: lenient
+// coercion, unknown properties reported
+func DefaultValidationOptions() ValidationOptions {
+	return ValidationOptions{Coerce: true}
+}
+
+// ValidateArguments checks params against schema: types, required
+// properties, enums, bounds, array items, nested objects and
+// additionalProperties. It returns a *ValidationError listing every
+// problem, or nil.
+func ValidateArguments(schema *types.JSONSchema, params map[string]interface{}) error {
+	_, err := ValidateArgumentsWithOptions(schema, params, ValidationOptions{})
+	return err
+}
+
+// ValidateArgumentsWithOptions validates params like ValidateArguments and
+// returns them with coercions applied (params itself is not modified)
+func ValidateArgumentsWithOptions(schema *types.JSONSchema, params map[string]interface{}, opts ValidationOptions) (map[string]interface{}, error) {
+	if schema == nil {
+		return params, nil
+	}
+	if params == nil {
+		params = map[string]interface{}{}
+	}
+
+	v := &validator{opts: opts}
+	result := v.object("", schema, params)
+	if len(v.errs) > 0 {
+		return params, &ValidationError{Errors: v.errs, Schema: schema}
+	}
+	return result, nil
+}
+
+type validator struct {
+	opts ValidationOptions
+	errs []FieldError
+}
+
+func (v *validator) fail(path, kind, format string, args ...interface{}) {
+	v.errs = append(v.errs, FieldError{Path: path, Kind: kind, Message: fmt.Sprintf(format, args...)})
+}
+
+// value validates one value and returns it, coerced when allowed
+func (v *validator) value(path string, schema *types.JSONSchema, value interface{}) interface{} {
+	if schema == nil || value == nil {
+		return value
+	}
+	if v.opts.Coerce {
+		value = coerce(schema, value)
+	}
+
+	switch schema.Type {
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			v.fail(path, ErrorType, "expected string, got %s", describe(value))
+			return value
+		}
+		n := utf8.RuneCountInString(s)
+		if schema.MinLength != nil && n < *schema.MinLength {
+			v.fail(path, ErrorRange, "must be at least %d characters, got %d", *schema.MinLength, n)
+		}
+		if schema.MaxLength != nil && n > *schema.MaxLength {
+			v.fail(path, ErrorRange, "must be at most %d characters, got %d", *schema.MaxLength, n)
+		}
+
+	case "integer", "number":
+		f, ok := toFloat(value)
+		if !ok {
+			v.fail(path, ErrorType, "expected %s, got %s", schema.Type, describe(value))
+			return value
+		}
+		if schema.Type == "integer" && f != math.Trunc(f) {
+			v.fail(path, ErrorType, "expected integer, got %v", f)
+			return value
+		}
+		if schema.Minimum != nil && f < *schema.Minimum {
+			v.fail(path, ErrorRange, "must be >= %v, got %v", *schema.Minimum, f)
+		}
+		if schema.Maximum != nil && f > *schema.Maximum {
+			v.fail(path, ErrorRange, "must be <= %v, got %v", *schema.Maximum, f)
+		}
+
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			v.fail(path, ErrorType, "expected boolean, got %s", describe(value))
+			return value
+		}
+
+	case "array":
+		rv := reflect.ValueOf(value)
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			v.fail(path, ErrorType, "expected array, got %s", describe(value))
+			return value
+		}
+		n := rv.Len()
+		if schema.MinItems != nil && n < *schema.MinItems {
+			v.fail(path, ErrorRange, "must have at least %d items, got %d", *schema.MinItems, n)
+		}
+		if schema.MaxItems != nil && n > *schema.MaxItems {
+			v.fail(path, ErrorRange, "must have at most %d items, got %d", *schema.MaxItems, n)
+		}
+		items := make([]interface{}, n)
+		changed := false
+		for i := 0; i < n; i++ {
+			item := rv.Index(i).Interface()
+			items[i] = v.value(fmt.Sprintf("%s[%d]", path, i), schema.Items, item)
+			changed = changed || !reflect.DeepEqual(items[i], item)
+		}
+		if changed {
+			value = items // Keep typed slices such as []string unless coerced
+		}
+
+	case "object":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			v.fail(path, ErrorType, "expected object, got %s", describe(value))
+			return value
+		}
+		value = v.object(path, schema, obj)
+	}
+
+	if len(schema.Enum) > 0 && !inEnum(schema.Enum, value) {
+		allowed, _ := json.Marshal(schema.Enum)
+		v.fail(path, ErrorEnum, "must be one of %s, got %s", allowed, describe(value))
+	}
+	return value
+}
+
+// object validates the properties of obj and returns a (coerced) copy
+func (v *validator) object(path string, schema *types.JSONSchema, obj map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(obj))
+
+	for _, name := range schema.Required {
+		if val, ok := obj[name]; !ok || val == nil {
+			v.fail(joinPath(path, name), ErrorRequired, "is required")
+		}
+	}
+
+	names := make([]string, 0, len(obj))
+	for name := range obj {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		val := obj[name]
+		if prop, ok := schema.Properties[name]; ok {
+			result[name] = v.value(joinPath(path, name), prop, val)
+			continue
+		}
+
+		switch additional := schema.AdditionalProperties.(type) {
+		case bool:
+			if additional {
+				result[name] = val
+			} else if !v.opts.StripUnknown {
+				v.fail(joinPath(path, name), ErrorAdditional, "is not a known parameter%s", knownProperties(schema))
+			}
+		case *types.JSONSchema:
+			result[name] = v.value(joinPath(path, name), additional, val)
+		default:
+			result[name] = val
+		}
+	}
+	return result
+}
+
+// knownProperties lists the declared properties for an unknown-property error
+func knownProperties(schema *types.JSONSchema) string {
+	if len(schema.Properties) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return " (known: " + strings.Join(names, ", ") + ")"
+}
+
+// coerce converts value towards the schema type where the intent is clear;
+// values it cannot convert are returned unchanged for validation to report
+func coerce(schema *types.JSONSchema, value interface{}) interface{} {
+	switch schema.Type {
+	case "integer", "number":
+		if s, ok := value.(string); ok {
+			if f, err := strconv.ParseFloat(strings.TrimSpace(s), 64); err == nil {
+				return f
+			}
+		}
+	case "boolean":
+		if s, ok := value.(string); ok {
+			if b, err := strconv.ParseBool(strings.TrimSpace(s)); err == nil {
+				return b
+			}
+		}
+	case "string":
+		switch x := value.(type) {
+		case string:
+			// Match enum values case-insensitively
+			for _, allowed := range schema.Enum {
+				if a, ok := allowed.(string); ok && a != x && strings.EqualFold(a, x) {
+					return a
+				}
+			}
+		case bool:
+			return strconv.FormatBool(x)
+		default:
+			if f, ok := toFloat(value); ok {
+				return strconv.FormatFloat(f, 'f', -1, 64)
+			}
+		}
+	case "array":
+		if s, ok := value.(string); ok && strings.HasPrefix(strings.TrimSpace(s), "[") {
+			var decoded []interface{}
+			if json.Unmarshal([]byte(s), &decoded) == nil {
+				return decoded
+			}
+		}
+		if rv := reflect.ValueOf(value); rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			return []interface{}{value}
+		}
+	case "object":
+		if s, ok := value.(string); ok && strings.HasPrefix(strings.TrimSpace(s), "{") {
+			var decoded map[string]interface{}
+			if json.Unmarshal([]byte(s), &decoded) == nil {
+				return decoded
+			}
+		}
+	}
+	return value
+}
+
+// SummarizeParameters describes the top-level parameters of a schema in one
+// line, e.g. "city (string, required), days (integer, 1..7)"
+func SummarizeParameters(schema *types.JSONSchema) string {
+	if schema == nil || len(schema.Properties) == 0 {
+		return ""
+	}
+
+	required := make(map[string]bool, len(schema.Required))
+	for _, name := range schema.Required {
+		required[name] = true
+	}
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if required[names[i]] != required[names[j]] {
+			return required[names[i]]
+		}
+		return names[i] < names[j]
+	})
+
+	parts := make([]string, len(names))
+	for i, name := range names {
+		prop := schema.Properties[name]
+		details := []string{prop.Type}
+		if prop.Type == "array" && prop.Items != nil && prop.Items.Type != "" {
+			details[0] = "array of " + prop.Items.Type
+		}
+		if len(prop.Enum) > 0 {
+			values := make([]string, len(prop.Enum))
+			for j, e := range prop.Enum {
+				values[j] = fmt.Sprint(e)
+			}
+			details = append(details, "one of "+strings.Join(values, "|"))
+		}
+		if bounds := describeBounds(prop); bounds != "" {
+			details = append(details, bounds)
+		}
+		if required[name] {
+			details = append(details, "required")
+		}
+		parts[i] = fmt.Sprintf("%s (%s)", name, strings.Join(details, ", "))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// describeBounds renders the bounds of a schema as "min..max"
+func describeBounds(schema *types.JSONSchema) string {
+	var lo, hi string
+	switch {
+	case schema.Minimum != nil || schema.Maximum != nil:
+		if schema.Minimum != nil {
+			lo = fmt.Sprint(*schema.Minimum)
+		}
+		if schema.Maximum != nil {
+			hi = fmt.Sprint(*schema.Maximum)
+		}
+	case schema.MinLength != nil || schema.MaxLength != nil:
+		if schema.MinLength != nil {
+			lo = strconv.Itoa(*schema.MinLength)
+		}
+		if schema.MaxLength != nil {
+			hi = strconv.Itoa(*schema.MaxLength)
+		}
+		return "length " + lo + ".." + hi
+	case schema.MinItems != nil || schema.MaxItems != nil:
+		if schema.MinItems != nil {
+			lo = strconv.Itoa(*schema.MinItems)
+		}
+		if schema.MaxItems != nil {
+			hi = strconv.Itoa(*schema.MaxItems)
+		}
+		return lo + ".." + hi + " items"
+	default:
+		return ""
+	}
+	return lo + ".." + hi
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// inEnum compares value with the allowed values, treating all numbers alike
+func inEnum(enum []interface{}, value interface{}) bool {
+	vf, vNumeric := toFloat(value)
+	for _, allowed := range enum {
+		if af, ok := toFloat(allowed); ok && vNumeric {
+			if af == vf {
+				return true
+			}
+			continue
+		}
+		if reflect.DeepEqual(allowed, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// toFloat converts any Go number (and json.Number) to float64
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case bool, string:
+		return 0, false
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+	return 0, false
+}
+
+// describe names the JSON type of a value for error messages
+func describe(v interface{}) string {
+	switch x := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		if utf8.RuneCountInString(x) > 40 {
+			x = string([]rune(x)[:40]) + "..."
+		}
+		return fmt.Sprintf("string %q", x)
+	case bool:
+		return fmt.Sprintf("boolean %v", x)
+	case map[string]interface{}:
+		return "object"
+	}
+	if f, ok := toFloat(v); ok {
+		return fmt.Sprintf("number %v", f)
+	}
+	switch reflect.ValueOf(v).Kind() {
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Map, reflect.Struct:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
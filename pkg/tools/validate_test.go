@@ -0,0 +1,212 @@
+package tools
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/taipm/go-llm-agent/pkg/types"
+)
+
+func intPtr(n int) *int           { return &n }
+func floatPtr(f float64) *float64 { return &f }
+
+func TestValidateArguments(t *testing.T) {
+	schema := &types.JSONSchema{
+		Type: "object",
+		Properties: map[string]*types.JSONSchema{
+			"name":  {Type: "string", MinLength: intPtr(2)},
+			"count": {Type: "integer", Minimum: floatPtr(1), Maximum: floatPtr(10)},
+			"unit":  {Type: "string", Enum: []interface{}{"s", "m", "h"}},
+			"level": {Type: "integer", Enum: []interface{}{int64(1), int64(2)}},
+			"debug": {Type: "boolean"},
+			"tags":  {Type: "array", MaxItems: intPtr(2), Items: &types.JSONSchema{Type: "string"}},
+			"owner": {
+				Type:       "object",
+				Properties: map[string]*types.JSONSchema{"id": {Type: "integer"}},
+				Required:   []string{"id"},
+			},
+		},
+		Required: []string{"name"},
+	}
+
+	tests := []struct {
+		name   string
+		params map[string]interface{}
+		want   []string // Expected error fragments; empty means valid
+	}{
+		{"valid", map[string]interface{}{"name": "ab", "count": 3.0, "unit": "m", "level": 2.0, "tags": []interface{}{"x"}, "owner": map[string]interface{}{"id": 7}}, nil},
+		{"go numbers", map[string]interface{}{"name": "ab", "count": 3, "level": int64(1)}, nil},
+		{"missing required", map[string]interface{}{}, []string{"name: is required"}},
+		{"null required", map[string]interface{}{"name": nil}, []string{"name: is required"}},
+		{"wrong type", map[string]interface{}{"name": 5}, []string{"name: expected string, got number 5"}},
+		{"too short", map[string]interface{}{"name": "a"}, []string{"name: must be at least 2 characters, got 1"}},
+		{"not integer", map[string]interface{}{"name": "ab", "count": 2.5}, []string{"count: expected integer, got 2.5"}},
+		{"string number", map[string]interface{}{"name": "ab", "count": "5"}, []string{`count: expected integer, got string "5"`}},
+		{"too large", map[string]interface{}{"name": "ab", "count": 12.0}, []string{"count: must be <= 10, got 12"}},
+		{"enum", map[string]interface{}{"name": "ab", "unit": "d"}, []string{`unit: must be one of ["s","m","h"], got string "d"`}},
+		{"numeric enum", map[string]interface{}{"name": "ab", "level": 3.0}, []string{"level: must be one of [1,2], got number 3"}},
+		{"boolean", map[string]interface{}{"name": "ab", "debug": "yes"}, []string{"debug: expected boolean"}},
+		{"items", map[string]interface{}{"name": "ab", "tags": []interface{}{"a", 1, "c"}}, []string{"tags: must have at most 2 items, got 3", "tags[1]: expected string, got number 1"}},
+		{"nested", map[string]interface{}{"name": "ab", "owner": map[string]interface{}{}}, []string{"owner.id: is required"}},
+		{"multiple", map[string]interface{}{"count": 0.0}, []string{"name: is required", "count: must be >= 1, got 0"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateArguments(schema, tt.params)
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Fatalf("Expected valid arguments, got %v", err)
+				}
+				return
+			}
+
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("Expected *ValidationError, got %v", err)
+			}
+			if len(verr.Errors) != len(tt.want) {
+				t.Errorf("Expected %d errors, got %v", len(tt.want), verr.Errors)
+			}
+			for _, fragment := range tt.want {
+				if !strings.Contains(err.Error(), fragment) {
+					t.Errorf("Expected error to contain %q, got %q", fragment, err.Error())
+				}
+			}
+		})
+	}
+}
+
+func TestValidateArgumentsNilSchema(t *testing.T) {
+	if err := ValidateArguments(nil, map[string]interface{}{"x": 1}); err != nil {
+		t.Errorf("Expected nil schema to accept anything, got %v", err)
+	}
+}
+
+func TestValidateArgumentsWithOptions(t *testing.T) {
+	schema := &types.JSONSchema{
+		Type: "object",
+		Properties: map[string]*types.JSONSchema{
+			"count":  {Type: "integer"},
+			"debug":  {Type: "boolean"},
+			"label":  {Type: "string"},
+			"unit":   {Type: "string", Enum: []interface{}{"celsius", "fahrenheit"}},
+			"tags":   {Type: "array", Items: &types.JSONSchema{Type: "string"}},
+			"ids":    {Type: "array", Items: &types.JSONSchema{Type: "integer"}},
+			"filter": {Type: "object", Properties: map[string]*types.JSONSchema{"limit": {Type: "integer"}}},
+		},
+		AdditionalProperties: false,
+	}
+
+	params := map[string]interface{}{
+		"count":  "5",
+		"debug":  "true",
+		"label":  42.0,
+		"unit":   "Celsius",
+		"tags":   "solo",
+		"ids":    `[1, "2"]`,
+		"filter": `{"limit": "3"}`,
+	}
+	got, err := ValidateArgumentsWithOptions(schema, params, ValidationOptions{Coerce: true})
+	if err != nil {
+		t.Fatalf("Expected coercion to succeed, got %v", err)
+	}
+
+	want := map[string]interface{}{
+		"count":  5.0,
+		"debug":  true,
+		"label":  "42",
+		"unit":   "celsius",
+		"tags":   []interface{}{"solo"},
+		"ids":    []interface{}{1.0, 2.0},
+		"filter": map[string]interface{}{"limit": 3.0},
+	}
+	for key, expected := range want {
+		if !reflect.DeepEqual(got[key], expected) {
+			t.Errorf("%s: expected %#v, got %#v", key, expected, got[key])
+		}
+	}
+	if params["count"] != "5" {
+		t.Error("Expected input params to be left unchanged")
+	}
+
+	// Without coercion the same arguments are rejected
+	if _, err := ValidateArgumentsWithOptions(schema, params, ValidationOptions{}); err == nil {
+		t.Error("Expected strict validation to fail")
+	}
+
+	// Values that cannot be converted are still reported
+	_, err = ValidateArgumentsWithOptions(schema, map[string]interface{}{"count": "five"}, ValidationOptions{Coerce: true})
+	if err == nil || !strings.Contains(err.Error(), `count: expected integer, got string "five"`) {
+		t.Errorf("Expected type error, got %v", err)
+	}
+
+	// Typed slices pass through unchanged
+	got, err = ValidateArgumentsWithOptions(schema, map[string]interface{}{"tags": []string{"a"}}, ValidationOptions{Coerce: true})
+	if err != nil {
+		t.Fatalf("Expected []string to validate, got %v", err)
+	}
+	if _, ok := got["tags"].([]string); !ok {
+		t.Errorf("Expected []string to be kept, got %T", got["tags"])
+	}
+}
+
+func TestValidateAdditionalProperties(t *testing.T) {
+	closed := &types.JSONSchema{
+		Type:                 "object",
+		Properties:           map[string]*types.JSONSchema{"path": {Type: "string"}},
+		AdditionalProperties: false,
+	}
+	params := map[string]interface{}{"path": "/tmp", "recursive": true}
+
+	err := ValidateArguments(closed, params)
+	var verr *ValidationError
+	if !errors.As(err, &verr) || len(verr.Errors) != 1 || verr.Errors[0].Kind != ErrorAdditional {
+		t.Fatalf("Expected one additional-property error, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "recursive: is not a known parameter (known: path)") {
+		t.Errorf("Expected known parameters in message, got %q", err.Error())
+	}
+
+	got, err := ValidateArgumentsWithOptions(closed, params, ValidationOptions{StripUnknown: true})
+	if err != nil {
+		t.Fatalf("Expected unknown property to be stripped, got %v", err)
+	}
+	if _, ok := got["recursive"]; ok {
+		t.Error("Expected recursive to be removed")
+	}
+
+	typedMap := &types.JSONSchema{Type: "object", AdditionalProperties: &types.JSONSchema{Type: "number"}}
+	if err := ValidateArguments(typedMap, map[string]interface{}{"a": 1.0, "b": "x"}); err == nil || !strings.Contains(err.Error(), "b: expected number") {
+		t.Errorf("Expected additional properties to be validated against their schema, got %v", err)
+	}
+
+	open := &types.JSONSchema{Type: "object", Properties: map[string]*types.JSONSchema{}}
+	if err := ValidateArguments(open, params); err != nil {
+		t.Errorf("Expected unknown properties to be allowed by default, got %v", err)
+	}
+}
+
+func TestValidationErrorMessage(t *testing.T) {
+	schema := &types.JSONSchema{
+		Type: "object",
+		Properties: map[string]*types.JSONSchema{
+			"city": {Type: "string"},
+			"days": {Type: "integer", Minimum: floatPtr(1), Maximum: floatPtr(7)},
+			"unit": {Type: "string", Enum: []interface{}{"c", "f"}},
+		},
+		Required: []string{"city"},
+	}
+
+	err := ValidateArguments(schema, map[string]interface{}{"days": 9})
+	err.(*ValidationError).Tool = "weather"
+
+	want := "invalid arguments for weather: city: is required; days: must be <= 7, got 9. " +
+		"Expected parameters: city (string, required), days (integer, 1..7), unit (string, one of c|f). " +
+		"Fix the arguments and call the tool again"
+	if err.Error() != want {
+		t.Errorf("Expected message:\n%s\ngot:\n%s", want, err.Error())
+	}
+}
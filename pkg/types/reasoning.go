@@ -31,13 +31,21 @@ type CoTStep struct {
 
 // CoTChain represents a complete chain-of-thought reasoning process
 type CoTChain struct {
-	Query      string    `json:"query"`      // Original question
-	Steps      []CoTStep `json:"steps"`      // Step-by-step reasoning
-	Answer     string    `json:"answer"`     // Final answer
-	Confidence float64   `json:"confidence"` // 0.0 to 1.0
-	StartTime  time.Time `json:"start_time"`
-	EndTime    time.Time `json:"end_time"`
-	CreatedAt  time.Time `json:"created_at"`
+	Query      string         `json:"query"`                // Original question
+	Steps      []CoTStep      `json:"steps"`                // Step-by-step reasoning
+	Answer     string         `json:"answer"`               // Final answer
+	Confidence float64        `json:"confidence"`           // 0.0 to 1.0 (self-consistency: agreement on Answer)
+	Samples    int            `json:"samples,omitempty"`    // Chains sampled for self-consistency
+	Candidates []CoTCandidate `json:"candidates,omitempty"` // Answers voted on, most votes first
+	StartTime  time.Time      `json:"start_time"`
+	EndTime    time.Time      `json:"end_time"`
+	CreatedAt  time.Time      `json:"created_at"`
+}
+
+// CoTCandidate is an answer reached by one or more sampled chains
+type CoTCandidate struct {
+	Answer string `json:"answer"` // Answer of the first chain that reached it
+	Votes  int    `json:"votes"`  // Chains that reached the answer
 }
 
 // ===========================
@@ -51,7 +59,8 @@ type PlanStep struct {
 	Dependencies []string    `json:"dependencies"` // IDs of steps that must complete first
 	Status       PlanStatus  `json:"status"`
 	Result       interface{} `json:"result,omitempty"`
-	Error        error       `json:"error,omitempty"`
+	Error        string      `json:"error,omitempty"`    // Error message of the last failed attempt
+	Attempts     int         `json:"attempts,omitempty"` // Executions including retries
 	StartedAt    time.Time   `json:"started_at,omitempty"`
 	CompletedAt  time.Time   `json:"completed_at,omitempty"`
 }
@@ -73,6 +82,7 @@ type Plan struct {
 	Goal        string     `json:"goal"`  // High-level goal
 	Steps       []PlanStep `json:"steps"` // Ordered list of steps
 	Status      PlanStatus `json:"status"`
+	Revisions   int        `json:"revisions,omitempty"` // Times the plan was revised after failures
 	CreatedAt   time.Time  `json:"created_at"`
 	StartedAt   time.Time  `json:"started_at,omitempty"`
 	CompletedAt time.Time  `json:"completed_at,omitempty"`
@@ -100,16 +110,18 @@ type ReflectionCheck struct {
 	FinalAnswer   string             `json:"final_answer"`
 	Confidence    float64            `json:"confidence"` // 0.0 to 1.0
 	WasCorrected  bool               `json:"was_corrected"`
+	Corrections   int                `json:"corrections,omitempty"` // Correction rounds performed
 	CreatedAt     time.Time          `json:"created_at"`
 }
 
 // VerificationStep represents one verification attempt
 type VerificationStep struct {
-	Method string      `json:"method"` // "fact_check", "calculation_verify", "consistency_check"
-	Query  string      `json:"query"`
-	Result interface{} `json:"result"`
-	Passed bool        `json:"passed"`
-	Error  error       `json:"error,omitempty"`
+	Method  string      `json:"method"` // "fact_check", "calculation_verify", "consistency_check" or a custom verifier
+	Query   string      `json:"query"`
+	Concern string      `json:"concern,omitempty"` // Concern the step checked
+	Result  interface{} `json:"result"`
+	Passed  bool        `json:"passed"`
+	Error   error       `json:"error,omitempty"`
 }
 
 // ===========================
@@ -50,6 +50,7 @@ type Metadata struct {
 	PromptTokens     int    `json:"prompt_tokens"`
 	CompletionTokens int    `json:"completion_tokens"`
 	TotalTokens      int    `json:"total_tokens"`
+	Backend          string `json:"backend,omitempty"` // Backend that served the request (set by provider.Router)
 }
 
 // ChatOptions contains options for chat completion
@@ -85,6 +86,12 @@ type JSONSchema struct {
 	Items                *JSONSchema            `json:"items,omitempty"`
 	Enum                 []interface{}          `json:"enum,omitempty"`
 	AdditionalProperties interface{}            `json:"additionalProperties,omitempty"` // OpenAI: true/false or schema
+	Minimum              *float64               `json:"minimum,omitempty"`              // Numbers
+	Maximum              *float64               `json:"maximum,omitempty"`
+	MinLength            *int                   `json:"minLength,omitempty"` // Strings
+	MaxLength            *int                   `json:"maxLength,omitempty"`
+	MinItems             *int                   `json:"minItems,omitempty"` // Arrays
+	MaxItems             *int                   `json:"maxItems,omitempty"`
 }
 
 // StreamChunk represents a chunk of streaming response